@@ -43,12 +43,12 @@ func NewTestACLAgent(t *testing.T, name string, hcl string, resolveAuthz authzRe
 	dataDir := testutil.TempDir(t, "acl-agent")
 
 	logBuffer := testutil.NewLogBuffer(t)
-	loader := func(source config.Source) (*config.RuntimeConfig, []string, error) {
+	loader := func(source config.Source, overrides ...config.Source) (*config.RuntimeConfig, []string, error) {
 		dataDir := fmt.Sprintf(`data_dir = "%s"`, dataDir)
 		opts := config.BuilderOpts{
 			HCL: []string{TestConfigHCL(NodeID()), hcl, dataDir},
 		}
-		cfg, warnings, err := config.Load(opts, source)
+		cfg, warnings, err := config.Load(opts, source, overrides...)
 		if cfg != nil {
 			cfg.Telemetry.Disable = true
 		}
@@ -23,6 +23,8 @@ import (
 	"github.com/hashicorp/go-hclog"
 	"github.com/hashicorp/go-memdb"
 	"github.com/hashicorp/go-multierror"
+	"github.com/hashicorp/go-sockaddr/template"
+	"github.com/hashicorp/mdns"
 	"github.com/hashicorp/raft"
 	"github.com/hashicorp/serf/serf"
 	"golang.org/x/net/http2"
@@ -48,6 +50,7 @@ import (
 	"github.com/hashicorp/consul/ipaddr"
 	"github.com/hashicorp/consul/lib"
 	"github.com/hashicorp/consul/lib/file"
+	"github.com/hashicorp/consul/lib/proxyproto"
 	"github.com/hashicorp/consul/logging"
 	"github.com/hashicorp/consul/tlsutil"
 	"github.com/hashicorp/consul/types"
@@ -174,6 +177,10 @@ type Agent struct {
 	// depending on the configuration
 	delegate delegate
 
+	// tracer exports spans for HTTP request handling when tracing is
+	// configured. It is nil when tracing is disabled.
+	tracer *lib.Tracer
+
 	// aclMasterAuthorizer is an object that helps manage local ACL enforcement.
 	aclMasterAuthorizer acl.Authorizer
 
@@ -200,6 +207,11 @@ type Agent struct {
 	// checkMonitors maps the check ID to an associated monitor
 	checkMonitors map[structs.CheckID]*checks.CheckMonitor
 
+	// checkExecLimits bounds the resources script checks may use. It is
+	// shared by all check monitors so that the concurrency limit applies
+	// across checks.
+	checkExecLimits *checks.ExecLimits
+
 	// checkHTTPs maps the check ID to an associated HTTP check
 	checkHTTPs map[structs.CheckID]*checks.CheckHTTP
 
@@ -215,6 +227,9 @@ type Agent struct {
 	// checkDockers maps the check ID to an associated Docker Exec based check
 	checkDockers map[structs.CheckID]*checks.CheckDocker
 
+	// checkCRIs maps the check ID to an associated CRI exec based check
+	checkCRIs map[structs.CheckID]*checks.CheckCRI
+
 	// checkAliases maps the check ID to an associated Alias checks
 	checkAliases map[structs.CheckID]*checks.CheckAlias
 
@@ -227,6 +242,9 @@ type Agent struct {
 	// dockerClient is the client for performing docker health checks.
 	dockerClient *checks.DockerClient
 
+	// criClient is the client for performing CRI exec health checks.
+	criClient *checks.CRIClient
+
 	// eventCh is used to receive user events
 	eventCh chan serf.UserEvent
 
@@ -259,6 +277,11 @@ type Agent struct {
 	// dnsServer provides the DNS API
 	dnsServers []*DNSServer
 
+	// inheritedSockets holds pre-bound sockets passed by the service
+	// manager when use_socket_activation is set. Listeners claim matching
+	// sockets from it instead of binding the address themselves.
+	inheritedSockets *socketInventory
+
 	// apiServers listening for connections. If any of these server goroutines
 	// fail, the agent will be shutdown.
 	apiServers *apiServers
@@ -307,6 +330,14 @@ type Agent struct {
 	// Envoy.
 	grpcServer *grpc.Server
 
+	// grpcTLSServer serves the same xDS API over the dedicated TLS listener
+	// configured via ports.grpc_tls.
+	grpcTLSServer *grpc.Server
+
+	// mdnsServer advertises the agent's own HTTP API over mDNS when
+	// mdns_advertise is enabled.
+	mdnsServer *mdns.Server
+
 	// tlsConfigurator is the central instance to provide a *tls.Config
 	// based on the current consul configuration.
 	tlsConfigurator *tlsutil.Configurator
@@ -329,18 +360,18 @@ type Agent struct {
 
 // New process the desired options and creates a new Agent.
 // This process will
-//   * parse the config given the config Flags
-//   * setup logging
-//      * using predefined logger given in an option
-//        OR
-//      * initialize a new logger from the configuration
-//        including setting up gRPC logging
-//   * initialize telemetry
-//   * create a TLS Configurator
-//   * build a shared connection pool
-//   * create the ServiceManager
-//   * setup the NodeID if one isn't provided in the configuration
-//   * create the AutoConfig object for future use in fully
+//   - parse the config given the config Flags
+//   - setup logging
+//   - using predefined logger given in an option
+//     OR
+//   - initialize a new logger from the configuration
+//     including setting up gRPC logging
+//   - initialize telemetry
+//   - create a TLS Configurator
+//   - build a shared connection pool
+//   - create the ServiceManager
+//   - setup the NodeID if one isn't provided in the configuration
+//   - create the AutoConfig object for future use in fully
 //     resolving the configuration
 func New(bd BaseDeps) (*Agent, error) {
 	a := Agent{
@@ -351,6 +382,7 @@ func New(bd BaseDeps) (*Agent, error) {
 		checkTCPs:       make(map[structs.CheckID]*checks.CheckTCP),
 		checkGRPCs:      make(map[structs.CheckID]*checks.CheckGRPC),
 		checkDockers:    make(map[structs.CheckID]*checks.CheckDocker),
+		checkCRIs:       make(map[structs.CheckID]*checks.CheckCRI),
 		checkAliases:    make(map[structs.CheckID]*checks.CheckAlias),
 		eventCh:         make(chan serf.UserEvent, 1024),
 		eventBuf:        make([]*UserEvent, 256),
@@ -453,6 +485,23 @@ func (a *Agent) Start(ctx context.Context) error {
 		return fmt.Errorf("Failed to load TLS configurations after applying auto-config settings: %w", err)
 	}
 
+	// apply the runtime { } tuning before any serious work starts
+	a.applyRuntimeSettings()
+
+	// start the trace exporter before the listeners so that the first
+	// requests are traced
+	a.tracer, err = lib.NewTracer(a.config.Tracing)
+	if err != nil {
+		return err
+	}
+
+	a.checkExecLimits = &checks.ExecLimits{
+		MaxConcurrent: c.CheckExecLimitsMaxConcurrent,
+		CPUSeconds:    c.CheckExecLimitsCPUSeconds,
+		MemoryBytes:   c.CheckExecLimitsMemoryBytes,
+		Nice:          c.CheckExecLimitsNice,
+	}
+
 	// create the local state
 	a.State = local.NewState(LocalConfig(c), a.logger, a.tokens)
 
@@ -525,6 +574,12 @@ func (a *Agent) Start(ctx context.Context) error {
 		return err
 	}
 
+	// Place the node into maintenance mode on startup if the operator asked
+	// for it. The node stays drained until it is explicitly cleared.
+	if c.StartDrained {
+		a.enableNodeMaintenanceLocked("", "")
+	}
+
 	var intentionDefaultAllow bool
 	switch a.config.ACLDefaultPolicy {
 	case "allow":
@@ -578,6 +633,16 @@ func (a *Agent) Start(ctx context.Context) error {
 		return err
 	}
 
+	// Collect any pre-bound sockets from the service manager before the
+	// listeners below start binding addresses themselves.
+	if a.config.UseSocketActivation {
+		inherited, err := inheritedSockets(a.logger)
+		if err != nil {
+			return err
+		}
+		a.inheritedSockets = inherited
+	}
+
 	// start DNS servers
 	if err := a.listenAndServeDNS(); err != nil {
 		return err
@@ -605,6 +670,17 @@ func (a *Agent) Start(ctx context.Context) error {
 		return err
 	}
 
+	// Record the ports the operating system picked for listeners that
+	// were configured with port 0 so test harnesses can discover them.
+	if err := a.storeEphemeralPorts(); err != nil {
+		return err
+	}
+
+	// Advertise the agent over mDNS for zero-config local discovery.
+	if err := a.startMDNSAdvertisement(); err != nil {
+		return err
+	}
+
 	// register watches
 	if err := a.reloadWatches(a.config); err != nil {
 		return err
@@ -616,6 +692,10 @@ func (a *Agent) Start(ctx context.Context) error {
 		go a.retryJoinWAN()
 	}
 
+	// seed the LAN pool from the servers file, if configured, and keep
+	// the file updated with the known servers
+	a.startServersFile()
+
 	// DEPRECATED: Warn users if they're emitting deprecated metrics. Remove this warning and the flagged metrics in a
 	// future release of Consul.
 	if !a.config.Telemetry.DisableCompatOneNine {
@@ -628,6 +708,28 @@ func (a *Agent) Start(ctx context.Context) error {
 		{Name: "pre_release", Value: a.config.VersionPrerelease},
 	})
 
+	// config fingerprint metric so fleet tooling can spot agents running
+	// divergent configuration
+	metrics.SetGaugeWithLabels([]string{"config", "hash"}, 1, []metrics.Label{
+		{Name: "hash", Value: a.config.Hash},
+	})
+
+	// deprecated config usage metrics so fleet dashboards can track
+	// migration progress before the keys are removed
+	if a.config.Telemetry.EnableDeprecatedConfigMetrics {
+		for _, key := range a.config.DeprecatedKeysInUse {
+			metrics.SetGaugeWithLabels([]string{"config", "deprecated"}, 1, []metrics.Label{
+				{Name: "key", Value: key},
+			})
+		}
+	}
+
+	// start the opt-in usage reporter, if configured
+	a.startUsageReporting()
+
+	// start the scheduled maintenance loop, if configured
+	a.startMaintenanceSchedule()
+
 	return nil
 }
 
@@ -636,6 +738,14 @@ var Gauges = []prometheus.GaugeDefinition{
 		Name: []string{"version"},
 		Help: "Represents the Consul version.",
 	},
+	{
+		Name: []string{"config", "hash"},
+		Help: "Represents the fingerprint of the agent's merged configuration, labeled by hash.",
+	},
+	{
+		Name: []string{"config", "deprecated"},
+		Help: "Set to 1 for every deprecated config key the agent was started with, labeled by key.",
+	},
 }
 
 // Failed returns a channel which is closed when the first server goroutine exits
@@ -645,54 +755,145 @@ func (a *Agent) Failed() <-chan struct{} {
 }
 
 func (a *Agent) listenAndServeGRPC() error {
-	if len(a.config.GRPCAddrs) < 1 {
+	if len(a.config.GRPCAddrs) < 1 && len(a.config.GRPCTLSAddrs) < 1 {
 		return nil
 	}
 
 	xdsServer := &xds.Server{
-		Logger:             a.logger.Named(logging.Envoy),
-		CfgMgr:             a.proxyConfig,
-		ResolveToken:       a.resolveToken,
-		CheckFetcher:       a,
-		CfgFetcher:         a,
-		AuthCheckFrequency: xds.DefaultAuthCheckFrequency,
+		Logger:               a.logger.Named(logging.Envoy),
+		CfgMgr:               a.proxyConfig,
+		ResolveToken:         a.resolveToken,
+		CheckFetcher:         a,
+		CfgFetcher:           a,
+		AuthCheckFrequency:   xds.DefaultAuthCheckFrequency,
+		MaxConcurrentStreams: uint32(a.config.XDSMaxConcurrentStreams),
+		NackBackoff:          a.config.XDSNackBackoff,
+		EnableDelta:          a.config.XDSEnableDelta,
+	}
+
+	var cfgService *configService
+	if a.config.GRPCEnableConfigService {
+		var err error
+		cfgService, err = newConfigService(a.logger.Named("config-service"), a.config)
+		if err != nil {
+			return err
+		}
+		a.configReloaders = append(a.configReloaders, cfgService.Reload)
+	}
+
+	serve := func(srv *grpc.Server, addrs []net.Addr) error {
+		if cfgService != nil {
+			cfgService.Register(srv)
+		}
+		ln, err := a.startListeners(addrs, a.config.UnixSocketGRPC, a.config.ListenerOptionsGRPC)
+		if err != nil {
+			return err
+		}
+		for _, l := range ln {
+			go func(innerL net.Listener) {
+				a.logger.Info("Started gRPC server",
+					"address", innerL.Addr().String(),
+					"network", innerL.Addr().Network(),
+				)
+				err := srv.Serve(innerL)
+				if err != nil {
+					a.logger.Error("gRPC server failed", "error", err)
+				}
+			}(l)
+		}
+		return nil
 	}
 
-	tlsConfig := a.tlsConfigurator
-	// gRPC uses the same TLS settings as the HTTPS API. If HTTPS is not enabled
-	// then gRPC should not use TLS.
-	if a.config.HTTPSPort <= 0 {
-		tlsConfig = nil
+	if len(a.config.GRPCAddrs) > 0 {
+		tlsConfig := a.tlsConfigurator
+		// gRPC uses the same TLS settings as the HTTPS API. If HTTPS is not
+		// enabled then gRPC should not use TLS. When a dedicated TLS listener
+		// is configured via ports.grpc_tls this listener always stays
+		// plaintext so that local sidecars can keep connecting without certs.
+		if a.config.HTTPSPort <= 0 || len(a.config.GRPCTLSAddrs) > 0 {
+			tlsConfig = nil
+		}
+		var err error
+		a.grpcServer, err = xdsServer.GRPCServer(tlsConfig)
+		if err != nil {
+			return err
+		}
+		if err := serve(a.grpcServer, a.config.GRPCAddrs); err != nil {
+			return err
+		}
 	}
-	var err error
-	a.grpcServer, err = xdsServer.GRPCServer(tlsConfig)
-	if err != nil {
-		return err
+
+	if len(a.config.GRPCTLSAddrs) > 0 {
+		var err error
+		a.grpcTLSServer, err = xdsServer.GRPCServer(a.tlsConfigurator)
+		if err != nil {
+			return err
+		}
+		if err := serve(a.grpcTLSServer, a.config.GRPCTLSAddrs); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// startMDNSAdvertisement advertises the agent's HTTP (or HTTPS) API over mDNS
+// so that the agent itself can be discovered on the local network without
+// prior configuration. This is meant for dev and edge deployments; servers
+// refuse it during config validation unless explicitly allowed.
+func (a *Agent) startMDNSAdvertisement() error {
+	if !a.config.MDNSAdvertiseEnabled {
+		return nil
+	}
+
+	instance := a.config.MDNSAdvertiseService
+	if instance == "" {
+		instance = a.config.NodeName
+	}
+
+	port := a.config.HTTPPort
+	if port <= 0 {
+		port = a.config.HTTPSPort
+	}
+	if port <= 0 {
+		return fmt.Errorf("mdns_advertise requires the HTTP or HTTPS API to be enabled")
 	}
 
-	ln, err := a.startListeners(a.config.GRPCAddrs)
+	var iface *net.Interface
+	if name := a.config.MDNSAdvertiseInterface; name != "" {
+		i, err := net.InterfaceByName(name)
+		if err != nil {
+			return fmt.Errorf("mdns_advertise: unknown interface %q: %s", name, err)
+		}
+		iface = i
+	}
+
+	txt := []string{
+		"node=" + a.config.NodeName,
+		"dc=" + a.config.Datacenter,
+		fmt.Sprintf("dns_port=%d", a.config.DNSPort),
+	}
+	service, err := mdns.NewMDNSService(instance, "_consul-agent._tcp", "", "", port, nil, txt)
 	if err != nil {
-		return err
+		return fmt.Errorf("mdns_advertise: %s", err)
 	}
 
-	for _, l := range ln {
-		go func(innerL net.Listener) {
-			a.logger.Info("Started gRPC server",
-				"address", innerL.Addr().String(),
-				"network", innerL.Addr().Network(),
-			)
-			err := a.grpcServer.Serve(innerL)
-			if err != nil {
-				a.logger.Error("gRPC server failed", "error", err)
-			}
-		}(l)
+	srv, err := mdns.NewServer(&mdns.Config{Zone: service, Iface: iface})
+	if err != nil {
+		return fmt.Errorf("mdns_advertise: %s", err)
 	}
+	a.mdnsServer = srv
+	a.logger.Info("Started mDNS advertisement",
+		"instance", instance,
+		"service", "_consul-agent._tcp",
+		"port", port,
+	)
 	return nil
 }
 
 func (a *Agent) listenAndServeDNS() error {
-	notif := make(chan net.Addr, len(a.config.DNSAddrs))
-	errCh := make(chan error, len(a.config.DNSAddrs))
+	total := len(a.config.DNSAddrs) + len(a.config.DNSTLSAddrs)
+	notif := make(chan net.Addr, total)
+	errCh := make(chan error, total)
 	for _, addr := range a.config.DNSAddrs {
 		// create server
 		s, err := NewDNSServer(a)
@@ -701,21 +902,74 @@ func (a *Agent) listenAndServeDNS() error {
 		}
 		a.dnsServers = append(a.dnsServers, s)
 
+		// Claim a pre-bound socket from the service manager, if one
+		// matches, so privileged ports can be used without running the
+		// agent as root.
+		inheritedLn := a.inheritedSockets.takeListener(addr)
+		inheritedPC := a.inheritedSockets.takePacketConn(addr)
+
 		// start server
 		a.wgServers.Add(1)
 		go func(addr net.Addr) {
 			defer a.wgServers.Done()
-			err := s.ListenAndServe(addr.Network(), addr.String(), func() { notif <- addr })
+			var err error
+			switch {
+			case inheritedLn != nil:
+				err = s.ServeListener(inheritedLn, func() { notif <- addr })
+			case inheritedPC != nil:
+				err = s.ServePacketConn(inheritedPC, func() { notif <- addr })
+			case a.config.ListenerOptionsDNS != (config.ListenerSocketOptions{}):
+				err = a.serveDNSWithOptions(s, addr, a.config.ListenerOptionsDNS, func() { notif <- addr })
+			default:
+				err = s.ListenAndServe(addr.Network(), addr.String(), func() { notif <- addr })
+			}
 			if err != nil && !strings.Contains(err.Error(), "accept") {
 				errCh <- err
 			}
 		}(addr)
 	}
 
+	// Serve DNS over TLS (RFC 7858) when configured.
+	if len(a.config.DNSTLSAddrs) > 0 {
+		cert, err := tls.LoadX509KeyPair(a.config.DNSTLSCertFile, a.config.DNSTLSKeyFile)
+		if err != nil {
+			return fmt.Errorf("Failed to load DNS TLS certificate: %v", err)
+		}
+		tlsConfig := &tls.Config{Certificates: []tls.Certificate{cert}}
+
+		for _, addr := range a.config.DNSTLSAddrs {
+			s, err := NewDNSServer(a)
+			if err != nil {
+				return err
+			}
+			a.dnsServers = append(a.dnsServers, s)
+
+			a.wgServers.Add(1)
+			go func(addr net.Addr) {
+				defer a.wgServers.Done()
+				err := s.ListenAndServeTLS(addr.String(), tlsConfig, func() { notif <- addr })
+				if err != nil && !strings.Contains(err.Error(), "accept") {
+					errCh <- err
+				}
+			}(addr)
+		}
+	}
+
+	// With the DNS listeners disabled a handler is still needed when the
+	// DNS-over-HTTPS endpoint is enabled.
+	if a.config.DNSDOHPath != "" && total == 0 {
+		s, err := NewDNSServer(a)
+		if err != nil {
+			return err
+		}
+		s.setupMux()
+		a.dnsServers = append(a.dnsServers, s)
+	}
+
 	// wait for servers to be up
 	timeout := time.After(time.Second)
 	var merr *multierror.Error
-	for range a.config.DNSAddrs {
+	for i := 0; i < total; i++ {
 		select {
 		case addr := <-notif:
 			a.logger.Info("Started DNS server",
@@ -733,7 +987,30 @@ func (a *Agent) listenAndServeDNS() error {
 	return merr.ErrorOrNil()
 }
 
-func (a *Agent) startListeners(addrs []net.Addr) ([]net.Listener, error) {
+// serveDNSWithOptions binds the DNS socket in the agent so the configured
+// listener options can be applied, then hands the bound socket to the
+// server. ListenAndServe cannot be used because the dns library binds the
+// socket itself.
+func (a *Agent) serveDNSWithOptions(s *DNSServer, addr net.Addr, opts config.ListenerSocketOptions, notif func()) error {
+	switch x := addr.(type) {
+	case *net.TCPAddr:
+		l, err := listenTCP(x, opts)
+		if err != nil {
+			return err
+		}
+		return s.ServeListener(l, notif)
+	case *net.UDPAddr:
+		pc, err := listenUDP(x.String(), opts)
+		if err != nil {
+			return err
+		}
+		return s.ServePacketConn(pc, notif)
+	default:
+		return s.ListenAndServe(addr.Network(), addr.String(), notif)
+	}
+}
+
+func (a *Agent) startListeners(addrs []net.Addr, perms config.UnixSocketPermissions, opts config.ListenerSocketOptions) ([]net.Listener, error) {
 	var ln []net.Listener
 	for _, addr := range addrs {
 		var l net.Listener
@@ -741,17 +1018,24 @@ func (a *Agent) startListeners(addrs []net.Addr) ([]net.Listener, error) {
 
 		switch x := addr.(type) {
 		case *net.UnixAddr:
-			l, err = a.listenSocket(x.Name)
+			l, err = a.listenSocket(x.Name, perms)
 			if err != nil {
 				return nil, err
 			}
 
 		case *net.TCPAddr:
-			l, err = net.Listen("tcp", x.String())
-			if err != nil {
-				return nil, err
+			if inherited := a.inheritedSockets.takeListener(x); inherited != nil {
+				l = inherited
+			} else {
+				l, err = listenTCP(x, opts)
+				if err != nil {
+					return nil, err
+				}
+			}
+			l = &tcpKeepAliveListener{
+				TCPListener:     l.(*net.TCPListener),
+				keepalivePeriod: opts.TCPKeepaliveInterval,
 			}
-			l = &tcpKeepAliveListener{l.(*net.TCPListener)}
 
 		default:
 			return nil, fmt.Errorf("unsupported address type %T", addr)
@@ -780,8 +1064,12 @@ func (a *Agent) listenHTTP() ([]apiServer, error) {
 	var ln []net.Listener
 	var servers []apiServer
 
-	start := func(proto string, addrs []net.Addr) error {
-		listeners, err := a.startListeners(addrs)
+	start := func(proto string, addrs []net.Addr, perms config.UnixSocketPermissions) error {
+		opts := a.config.ListenerOptionsHTTP
+		if proto == "https" {
+			opts = a.config.ListenerOptionsHTTPS
+		}
+		listeners, err := a.startListeners(addrs, perms, opts)
 		if err != nil {
 			return err
 		}
@@ -790,6 +1078,14 @@ func (a *Agent) listenHTTP() ([]apiServer, error) {
 		for _, l := range listeners {
 			var tlscfg *tls.Config
 			_, isTCP := l.(*tcpKeepAliveListener)
+			if isTCP && a.config.HTTPProxyProtocolEnabled {
+				// The PROXY header travels in cleartext ahead of the TLS
+				// handshake, so the wrapper sits below the TLS listener.
+				l = &proxyproto.Listener{
+					Listener:       l,
+					AllowedSources: a.config.HTTPProxyProtocolAllowedSources,
+				}
+			}
 			if isTCP && proto == "https" {
 				tlscfg = a.tlsConfigurator.IncomingHTTPSConfig()
 				l = tls.NewListener(l, tlscfg)
@@ -797,7 +1093,7 @@ func (a *Agent) listenHTTP() ([]apiServer, error) {
 
 			srv := &HTTPHandlers{
 				agent:    a,
-				denylist: NewDenylist(a.config.HTTPBlockEndpoints),
+				denylist: NewDenylist(a.config.HTTPBlockEndpoints, a.config.HTTPBlockEndpointRules),
 			}
 			a.configReloaders = append(a.configReloaders, srv.ReloadConfig)
 			a.httpHandlers = srv
@@ -824,11 +1120,11 @@ func (a *Agent) listenHTTP() ([]apiServer, error) {
 		return nil
 	}
 
-	if err := start("http", a.config.HTTPAddrs); err != nil {
+	if err := start("http", a.config.HTTPAddrs, a.config.UnixSocketHTTP); err != nil {
 		closeListeners(ln)
 		return nil, err
 	}
-	if err := start("https", a.config.HTTPSAddrs); err != nil {
+	if err := start("https", a.config.HTTPSAddrs, a.config.UnixSocketHTTPS); err != nil {
 		closeListeners(ln)
 		return nil, err
 	}
@@ -872,6 +1168,10 @@ func setupHTTPS(server *http.Server, connState func(net.Conn, http.ConnState), t
 // connections. It's used so dead TCP connections eventually go away.
 type tcpKeepAliveListener struct {
 	*net.TCPListener
+
+	// keepalivePeriod overrides the default keep-alive period when
+	// positive. It comes from listener_options.<type>.tcp_keepalive_interval.
+	keepalivePeriod time.Duration
 }
 
 func (ln tcpKeepAliveListener) Accept() (c net.Conn, err error) {
@@ -879,12 +1179,16 @@ func (ln tcpKeepAliveListener) Accept() (c net.Conn, err error) {
 	if err != nil {
 		return
 	}
+	period := 30 * time.Second
+	if ln.keepalivePeriod > 0 {
+		period = ln.keepalivePeriod
+	}
 	tc.SetKeepAlive(true)
-	tc.SetKeepAlivePeriod(30 * time.Second)
+	tc.SetKeepAlivePeriod(period)
 	return tc, nil
 }
 
-func (a *Agent) listenSocket(path string) (net.Listener, error) {
+func (a *Agent) listenSocket(path string, perms config.UnixSocketPermissions) (net.Listener, error) {
 	if _, err := os.Stat(path); !os.IsNotExist(err) {
 		a.logger.Warn("Replacing socket", "path", path)
 	}
@@ -895,8 +1199,7 @@ func (a *Agent) listenSocket(path string) (net.Listener, error) {
 	if err != nil {
 		return nil, err
 	}
-	user, group, mode := a.config.UnixSocketUser, a.config.UnixSocketGroup, a.config.UnixSocketMode
-	if err := setFilePermissions(path, user, group, mode); err != nil {
+	if err := setFilePermissions(path, perms.User, perms.Group, perms.Mode); err != nil {
 		return nil, fmt.Errorf("Failed setting up socket: %s", err)
 	}
 	return l, nil
@@ -959,6 +1262,9 @@ func (a *Agent) reloadWatches(cfg *config.RuntimeConfig) error {
 			a.logger.Error("Failed to run watch", "error", err)
 			continue
 		}
+		if config.Scheme == "https" {
+			applyWatchTLSProfile(config, cfg.TLSWatches)
+		}
 
 		a.watchPlans = append(a.watchPlans, wp)
 		go func(wp *watch.Plan) {
@@ -985,6 +1291,25 @@ func (a *Agent) reloadWatches(cfg *config.RuntimeConfig) error {
 	return nil
 }
 
+// applyWatchTLSProfile overlays the client TLS material from the watches
+// profile onto the API client configuration a watch plan uses to call back
+// into the agent.
+func applyWatchTLSProfile(cfg *api.Config, p tlsutil.ProtocolConfig) {
+	if p.CAFile != "" {
+		cfg.TLSConfig.CAFile = p.CAFile
+	}
+	if p.CAPath != "" {
+		cfg.TLSConfig.CAPath = p.CAPath
+	}
+	if p.CertFile != "" {
+		cfg.TLSConfig.CertFile = p.CertFile
+		cfg.TLSConfig.KeyFile = p.KeyFile
+	}
+	if p.VerifyOutgoing != nil {
+		cfg.TLSConfig.InsecureSkipVerify = !*p.VerifyOutgoing
+	}
+}
+
 // newConsulConfig translates a RuntimeConfig into a consul.Config.
 // TODO: move this function to a different file, maybe config.go
 func newConsulConfig(runtimeCfg *config.RuntimeConfig, logger hclog.Logger) (*consul.Config, error) {
@@ -1057,6 +1382,15 @@ func newConsulConfig(runtimeCfg *config.RuntimeConfig, logger hclog.Logger) (*co
 	cfg.RPCAddr = runtimeCfg.RPCBindAddr
 	cfg.RPCAdvertise = runtimeCfg.RPCAdvertiseAddr
 
+	// On multi-homed hosts bind the RPC and Serf listeners to every
+	// configured bind address.
+	if len(runtimeCfg.BindAddrs) > 1 {
+		for _, addr := range runtimeCfg.BindAddrs {
+			cfg.RPCBindAddrs = append(cfg.RPCBindAddrs, &net.TCPAddr{IP: addr.IP, Port: runtimeCfg.RPCBindAddr.Port})
+			cfg.SerfBindAddrs = append(cfg.SerfBindAddrs, addr.IP.String())
+		}
+	}
+
 	cfg.Segment = runtimeCfg.SegmentName
 	if len(runtimeCfg.Segments) > 0 {
 		segments, err := segmentConfig(runtimeCfg)
@@ -1163,6 +1497,10 @@ func newConsulConfig(runtimeCfg *config.RuntimeConfig, logger hclog.Logger) (*co
 
 	cfg.RPCConfig = runtimeCfg.RPCConfig
 
+	cfg.Tracing = runtimeCfg.Tracing
+
+	cfg.KVQuotas = runtimeCfg.KVQuotas
+
 	if runtimeCfg.LeaveDrainTime > 0 {
 		cfg.LeaveDrainTime = runtimeCfg.LeaveDrainTime
 	}
@@ -1362,6 +1700,9 @@ func (a *Agent) ShutdownAgent() error {
 	for _, chk := range a.checkDockers {
 		chk.Stop()
 	}
+	for _, chk := range a.checkCRIs {
+		chk.Stop()
+	}
 	for _, chk := range a.checkAliases {
 		chk.Stop()
 	}
@@ -1370,6 +1711,14 @@ func (a *Agent) ShutdownAgent() error {
 	if a.grpcServer != nil {
 		a.grpcServer.Stop()
 	}
+	if a.grpcTLSServer != nil {
+		a.grpcTLSServer.Stop()
+	}
+
+	// Stop mDNS advertisement
+	if a.mdnsServer != nil {
+		a.mdnsServer.Shutdown()
+	}
 
 	// Stop the proxy config manager
 	if a.proxyConfig != nil {
@@ -1391,6 +1740,9 @@ func (a *Agent) ShutdownAgent() error {
 		}
 	}
 
+	// Flush any remaining spans now that no more requests are handled.
+	a.tracer.Shutdown()
+
 	pidErr := a.deletePid()
 	if pidErr != nil {
 		a.logger.Warn("could not delete pid file", "error", pidErr)
@@ -1937,6 +2289,17 @@ func (a *Agent) addServiceLocked(req *addServiceRequest) error {
 
 	req.service.EnterpriseMeta.Normalize()
 
+	// resolve a go-sockaddr template in the service address now so that
+	// multi-homed hosts register the per-service interface address. The
+	// template syntax was already checked when the config was loaded.
+	if strings.Contains(req.service.Address, "{{") {
+		addr, err := template.Parse(req.service.Address)
+		if err != nil {
+			return fmt.Errorf("failed to resolve address template for service %q: %v", req.service.Service, err)
+		}
+		req.service.Address = strings.TrimSpace(addr)
+	}
+
 	if err := a.validateService(req.service, req.chkTypes); err != nil {
 		return err
 	}
@@ -2655,7 +3018,26 @@ func (a *Agent) addCheck(check *structs.HealthCheck, chkType *structs.CheckType,
 			}
 
 			if a.dockerClient == nil {
-				dc, err := checks.NewDockerClient(os.Getenv("DOCKER_HOST"), int64(maxOutputSize))
+				host := a.config.DockerEndpointHost
+				if host == "" {
+					host = os.Getenv("DOCKER_HOST")
+				}
+				var tlsConfig *tls.Config
+				if a.config.DockerEndpointCAFile != "" || a.config.DockerEndpointCertFile != "" ||
+					a.config.DockerEndpointTLSSkipVerify {
+					var err error
+					tlsConfig, err = api.SetupTLSConfig(&api.TLSConfig{
+						CAFile:             a.config.DockerEndpointCAFile,
+						CertFile:           a.config.DockerEndpointCertFile,
+						KeyFile:            a.config.DockerEndpointKeyFile,
+						InsecureSkipVerify: a.config.DockerEndpointTLSSkipVerify,
+					})
+					if err != nil {
+						a.logger.Error("error configuring docker TLS", "error", err)
+						return err
+					}
+				}
+				dc, err := checks.NewDockerClient(host, int64(maxOutputSize), tlsConfig)
 				if err != nil {
 					a.logger.Error("error creating docker client", "error", err)
 					return err
@@ -2681,6 +3063,44 @@ func (a *Agent) addCheck(check *structs.HealthCheck, chkType *structs.CheckType,
 			dockerCheck.Start()
 			a.checkDockers[cid] = dockerCheck
 
+		case chkType.IsCRI():
+			if existing, ok := a.checkCRIs[cid]; ok {
+				existing.Stop()
+				delete(a.checkCRIs, cid)
+			}
+			if chkType.Interval < checks.MinInterval {
+				a.logger.Warn("check has interval below minimum",
+					"check", cid.String(),
+					"minimum_interval", checks.MinInterval,
+				)
+				chkType.Interval = checks.MinInterval
+			}
+
+			if a.criClient == nil {
+				cc, err := checks.NewCRIClient(a.config.CRIEndpoint, int64(maxOutputSize))
+				if err != nil {
+					a.logger.Error("error creating CRI client", "error", err)
+					return err
+				}
+				a.logger.Debug("created CRI client", "endpoint", cc.Endpoint())
+				a.criClient = cc
+			}
+
+			criCheck := &checks.CheckCRI{
+				CheckID:        cid,
+				ServiceID:      sid,
+				CRIContainerID: chkType.CRIContainerID,
+				Shell:          chkType.Shell,
+				ScriptArgs:     chkType.ScriptArgs,
+				Interval:       chkType.Interval,
+				Timeout:        chkType.Timeout,
+				Logger:         a.logger,
+				Client:         a.criClient,
+				StatusHandler:  statusHandler,
+			}
+			criCheck.Start()
+			a.checkCRIs[cid] = criCheck
+
 		case chkType.IsMonitor():
 			if existing, ok := a.checkMonitors[cid]; ok {
 				existing.Stop()
@@ -2703,6 +3123,7 @@ func (a *Agent) addCheck(check *structs.HealthCheck, chkType *structs.CheckType,
 				Logger:        a.logger,
 				OutputMaxSize: maxOutputSize,
 				StatusHandler: statusHandler,
+				ExecLimits:    a.checkExecLimits,
 			}
 			monitor.Start()
 			a.checkMonitors[cid] = monitor
@@ -2912,6 +3333,10 @@ func (a *Agent) cancelCheckMonitors(checkID structs.CheckID) {
 		check.Stop()
 		delete(a.checkDockers, checkID)
 	}
+	if check, ok := a.checkCRIs[checkID]; ok {
+		check.Stop()
+		delete(a.checkCRIs, checkID)
+	}
 }
 
 // updateTTLCheck is used to update the status of a TTL check via the Agent API.
@@ -3073,6 +3498,26 @@ func (a *Agent) storePid() error {
 	return nil
 }
 
+// storeEphemeralPorts writes the ports the builder allocated for
+// listeners configured with port 0 to ephemeral-ports.json in the data
+// dir. The file is rewritten on every start since the ports change with
+// each allocation.
+func (a *Agent) storeEphemeralPorts() error {
+	if len(a.config.EphemeralPorts) == 0 || a.config.DataDir == "" {
+		return nil
+	}
+
+	data, err := json.MarshalIndent(a.config.EphemeralPorts, "", "  ")
+	if err != nil {
+		return fmt.Errorf("Could not marshal ephemeral ports: %s", err)
+	}
+	path := filepath.Join(a.config.DataDir, "ephemeral-ports.json")
+	if err := ioutil.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("Could not write ephemeral ports file: %s", err)
+	}
+	return nil
+}
+
 // deletePid is used to delete our PID on exit
 func (a *Agent) deletePid() error {
 	// Quit fast if no pidfile
@@ -3485,6 +3930,14 @@ func (a *Agent) DisableServiceMaintenance(serviceID structs.ServiceID) error {
 
 // EnableNodeMaintenance places a node into maintenance mode.
 func (a *Agent) EnableNodeMaintenance(reason, token string) {
+	a.stateLock.Lock()
+	defer a.stateLock.Unlock()
+	a.enableNodeMaintenanceLocked(reason, token)
+}
+
+// enableNodeMaintenanceLocked is like EnableNodeMaintenance but the stateLock
+// must be held when calling this method.
+func (a *Agent) enableNodeMaintenanceLocked(reason, token string) {
 	// Ensure node maintenance is not already enabled
 	if a.State.Check(structs.NodeMaintCheckID) != nil {
 		return
@@ -3504,7 +3957,7 @@ func (a *Agent) EnableNodeMaintenance(reason, token string) {
 		Status:  api.HealthCritical,
 		Type:    "maintenance",
 	}
-	a.AddCheck(check, nil, true, token, ConfigSourceLocal)
+	a.addCheckLocked(check, nil, true, token, ConfigSourceLocal)
 	a.logger.Info("Node entered maintenance mode")
 }
 
@@ -3524,9 +3977,10 @@ func (a *Agent) loadLimits(conf *config.RuntimeConfig) {
 
 // ReloadConfig will atomically reload all configuration, including
 // all services, checks, tokens, metadata, dnsServer configs, etc.
-// It will also reload all ongoing watches.
-func (a *Agent) ReloadConfig() error {
-	newCfg, err := a.baseDeps.AutoConfig.ReadConfig()
+// It will also reload all ongoing watches. Any overrides are merged in
+// after every other config source and apply to this reload only.
+func (a *Agent) ReloadConfig(overrides ...config.Source) error {
+	newCfg, err := a.baseDeps.AutoConfig.ReadConfig(overrides...)
 	if err != nil {
 		return err
 	}
@@ -3549,6 +4003,13 @@ func (a *Agent) ReloadConfig() error {
 // the configuration using CLI flags and on disk config, this just takes a
 // runtime configuration and applies it.
 func (a *Agent) reloadConfigInternal(newCfg *config.RuntimeConfig) error {
+	// Surface attempts to change fields that only take effect at startup so
+	// that operators are not left believing the new values were applied.
+	if changed := config.NonReloadableChanges(a.config, newCfg); len(changed) > 0 {
+		a.logger.Warn("Changes to non-reloadable config fields are ignored until the agent is restarted",
+			"fields", strings.Join(changed, ", "))
+	}
+
 	// Change the log level and update it
 	if logging.ValidateLogLevel(newCfg.Logging.LogLevel) {
 		a.logger.SetLevel(logging.LevelFromString(newCfg.Logging.LogLevel))
@@ -3604,6 +4065,11 @@ func (a *Agent) reloadConfigInternal(newCfg *config.RuntimeConfig) error {
 
 	a.loadLimits(newCfg)
 
+	// the slow query threshold is read per request by the HTTP handlers, so
+	// an in place update applies it live; the DNS servers pick it up through
+	// their own ReloadConfig below
+	a.config.LoggingSlowQueryThreshold = newCfg.LoggingSlowQueryThreshold
+
 	a.httpConnLimiter.SetConfig(connlimit.Config{
 		MaxConnsPerClientIP: newCfg.HTTPMaxConnsPerClient,
 	})
@@ -3625,6 +4091,19 @@ func (a *Agent) reloadConfigInternal(newCfg *config.RuntimeConfig) error {
 		return err
 	}
 
+	// bootstrap_expect can be lowered on a running server so a cluster can
+	// be shrunk without restarting every server in a particular order. The
+	// server validates that the new value still preserves quorum; raising
+	// the value still requires a restart.
+	if newCfg.BootstrapExpect != a.config.BootstrapExpect {
+		if srv, ok := a.delegate.(*consul.Server); ok {
+			if err := srv.ReloadBootstrapExpect(newCfg.BootstrapExpect); err != nil {
+				return fmt.Errorf("Failed reloading bootstrap_expect: %v", err)
+			}
+			a.config.BootstrapExpect = newCfg.BootstrapExpect
+		}
+	}
+
 	// create the config for the rpc server/client
 	consulCfg, err := newConsulConfig(a.config, a.logger)
 	if err != nil {
@@ -3846,7 +4325,7 @@ func (a *Agent) listenerPortLocked(svcID structs.ServiceID, checkID structs.Chec
 	var port int
 	for i := 0; i < a.config.ExposeMaxPort-a.config.ExposeMinPort; i++ {
 		port = a.config.ExposeMinPort + i
-		if !allocated[port] {
+		if !allocated[port] && !a.config.IsReservedPort(port) {
 			a.exposedPorts[key] = port
 			break
 		}
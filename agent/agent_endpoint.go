@@ -1,7 +1,9 @@
 package agent
 
 import (
+	"bytes"
 	"fmt"
+	"io/ioutil"
 	"net/http"
 	"strconv"
 	"strings"
@@ -12,6 +14,7 @@ import (
 
 	"github.com/hashicorp/consul/acl"
 	cachetype "github.com/hashicorp/consul/agent/cache-types"
+	"github.com/hashicorp/consul/agent/config"
 	"github.com/hashicorp/consul/agent/debug"
 	"github.com/hashicorp/consul/agent/structs"
 	token_store "github.com/hashicorp/consul/agent/token"
@@ -36,7 +39,8 @@ type Self struct {
 	Member      serf.Member
 	Stats       map[string]map[string]string
 	Meta        map[string]string
-	XDS         *xdsSelf `json:"xDS,omitempty"`
+	XDS         *xdsSelf                 `json:"xDS,omitempty"`
+	AutoConfig  *config.AutoConfigStatus `json:",omitempty"`
 }
 
 type xdsSelf struct {
@@ -63,6 +67,12 @@ func (s *HTTPHandlers) AgentSelf(resp http.ResponseWriter, req *http.Request) (i
 		}
 	}
 
+	var acStatus *config.AutoConfigStatus
+	if s.agent.config.ExposeAutoConfigStatus {
+		status := s.agent.baseDeps.AutoConfig.Status()
+		acStatus = &status
+	}
+
 	var xds *xdsSelf
 	if s.agent.grpcServer != nil {
 		xds = &xdsSelf{
@@ -74,6 +84,7 @@ func (s *HTTPHandlers) AgentSelf(resp http.ResponseWriter, req *http.Request) (i
 
 	config := struct {
 		Datacenter string
+		Hash       string
 		NodeName   string
 		NodeID     string
 		Revision   string
@@ -81,6 +92,7 @@ func (s *HTTPHandlers) AgentSelf(resp http.ResponseWriter, req *http.Request) (i
 		Version    string
 	}{
 		Datacenter: s.agent.config.Datacenter,
+		Hash:       s.agent.config.Hash,
 		NodeName:   s.agent.config.NodeName,
 		NodeID:     string(s.agent.config.NodeID),
 		Revision:   s.agent.config.Revision,
@@ -95,6 +107,7 @@ func (s *HTTPHandlers) AgentSelf(resp http.ResponseWriter, req *http.Request) (i
 		Stats:       s.agent.Stats(),
 		Meta:        s.agent.State.Metadata(),
 		XDS:         xds,
+		AutoConfig:  acStatus,
 	}, nil
 }
 
@@ -167,7 +180,26 @@ func (s *HTTPHandlers) AgentReload(resp http.ResponseWriter, req *http.Request)
 		return nil, acl.ErrPermissionDenied
 	}
 
-	return nil, s.agent.ReloadConfig()
+	// The body may contain a JSON config fragment that is merged in after
+	// every other config source for this reload only, so that changes to
+	// reloadable settings can be tried without editing files on the node.
+	var body []byte
+	if req.Body != nil {
+		body, err = ioutil.ReadAll(req.Body)
+		if err != nil {
+			return nil, BadRequestError{Reason: fmt.Sprintf("Failed to read body: %s", err)}
+		}
+	}
+	var overrides []config.Source
+	if len(bytes.TrimSpace(body)) > 0 {
+		overrides = append(overrides, config.FileSource{
+			Name:   "reload-override",
+			Format: "json",
+			Data:   string(body),
+		})
+	}
+
+	return nil, s.agent.ReloadConfig(overrides...)
 }
 
 func buildAgentService(s *structs.NodeService, dc string) api.AgentService {
@@ -1273,8 +1273,9 @@ func TestAgent_Self(t *testing.T) {
 	t.Parallel()
 
 	cases := map[string]struct {
-		hcl       string
-		expectXDS bool
+		hcl              string
+		expectXDS        bool
+		expectAutoConfig bool
 	}{
 		"normal": {
 			hcl: `
@@ -1295,6 +1296,16 @@ func TestAgent_Self(t *testing.T) {
 			`,
 			expectXDS: false,
 		},
+		"auto config status": {
+			hcl: `
+			node_meta {
+				somekey = "somevalue"
+			}
+			expose_auto_config_status = true
+			`,
+			expectXDS:        true,
+			expectAutoConfig: true,
+		},
 	}
 
 	for name, tc := range cases {
@@ -1329,6 +1340,13 @@ func TestAgent_Self(t *testing.T) {
 			} else {
 				require.Nil(t, val.XDS, "xds component should be missing when gRPC is disabled")
 			}
+
+			if tc.expectAutoConfig {
+				require.NotNil(t, val.AutoConfig, "auto config status missing when exposure is enabled")
+				require.False(t, val.AutoConfig.Enabled)
+			} else {
+				require.Nil(t, val.AutoConfig, "auto config status should be missing by default")
+			}
 		})
 	}
 }
@@ -1476,6 +1494,46 @@ func TestAgent_Reload(t *testing.T) {
 
 // TestAgent_ReloadDoesNotTriggerWatch Ensure watches not triggered after reload
 // see https://github.com/hashicorp/consul/issues/7446
+func TestAgent_Reload_Override(t *testing.T) {
+	if testing.Short() {
+		t.Skip("too slow for testing.Short")
+	}
+
+	t.Parallel()
+	a := NewTestAgent(t, `
+    limits = {
+      rpc_rate=1
+      rpc_max_burst=100
+    }
+	`)
+	defer a.Shutdown()
+
+	testrpc.WaitForTestAgent(t, a.RPC, "dc1")
+
+	body := bytes.NewBufferString(`{"limits": {"rpc_rate": 2, "rpc_max_burst": 200}}`)
+	req, _ := http.NewRequest("PUT", "/v1/agent/reload", body)
+	if _, err := a.srv.AgentReload(nil, req); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	if a.config.RPCRateLimit != 2 {
+		t.Fatalf("RPC rate not set to the override value: %v", a.config.RPCRateLimit)
+	}
+	if a.config.RPCMaxBurst != 200 {
+		t.Fatalf("RPC max burst not set to the override value: %v", a.config.RPCMaxBurst)
+	}
+
+	// the override only applies to the reload it was sent with
+	req, _ = http.NewRequest("PUT", "/v1/agent/reload", nil)
+	if _, err := a.srv.AgentReload(nil, req); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	if a.config.RPCRateLimit != 1 {
+		t.Fatalf("RPC rate not restored from the config files: %v", a.config.RPCRateLimit)
+	}
+}
+
 func TestAgent_ReloadDoesNotTriggerWatch(t *testing.T) {
 	if testing.Short() {
 		t.Skip("too slow for testing.Short")
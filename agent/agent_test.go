@@ -16,6 +16,7 @@ import (
 	"net/url"
 	"os"
 	"path/filepath"
+	"runtime"
 	"strconv"
 	"strings"
 	"sync"
@@ -189,6 +190,48 @@ func TestAgent_StartStop(t *testing.T) {
 	}
 }
 
+func TestAgent_GRPC_UnixSocket(t *testing.T) {
+	if testing.Short() {
+		t.Skip("too slow for testing.Short")
+	}
+
+	t.Parallel()
+	if runtime.GOOS == "windows" {
+		t.SkipNow()
+	}
+
+	tempDir := testutil.TempDir(t, "consul")
+	socket := filepath.Join(tempDir, "grpc.sock")
+
+	// Only testing mode, since uid/gid might not be settable
+	// from test environment.
+	a := NewTestAgent(t, `
+		addresses {
+			grpc = "unix://`+socket+`"
+		}
+		unix_sockets {
+			mode = "0777"
+		}
+	`)
+	defer a.Shutdown()
+
+	// Ensure the socket was created with the configured mode.
+	fi, err := os.Stat(socket)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if fi.Mode().String() != "Srwxrwxrwx" {
+		t.Fatalf("bad permissions: %s", fi.Mode())
+	}
+
+	// Ensure the gRPC server is accepting connections on the socket.
+	conn, err := net.Dial("unix", socket)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	conn.Close()
+}
+
 func TestAgent_RPCPing(t *testing.T) {
 	if testing.Short() {
 		t.Skip("too slow for testing.Short")
@@ -843,6 +886,37 @@ func testAgent_AddServiceNoExec(t *testing.T, extraHCL string) {
 	}
 }
 
+func TestAgent_AddService_AddressTemplate(t *testing.T) {
+	if testing.Short() {
+		t.Skip("too slow for testing.Short")
+	}
+
+	t.Parallel()
+	a := NewTestAgent(t, `
+		node_name = "node1"
+	`)
+	defer a.Shutdown()
+	testrpc.WaitForTestAgent(t, a.RPC, "dc1")
+
+	srv := &structs.NodeService{
+		ID:      "svcid1",
+		Service: "svcname1",
+		Address: `{{ "10.0.0.5" }}`,
+		Port:    8100,
+	}
+	if err := a.AddService(srv, nil, false, "", ConfigSourceLocal); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	got := a.State.Service(structs.NewServiceID("svcid1", nil))
+	if got == nil {
+		t.Fatal("service not registered")
+	}
+	if got.Address != "10.0.0.5" {
+		t.Fatalf("want address %q, got %q", "10.0.0.5", got.Address)
+	}
+}
+
 func TestAgent_AddServiceNoRemoteExec(t *testing.T) {
 	if testing.Short() {
 		t.Skip("too slow for testing.Short")
@@ -3374,6 +3448,26 @@ func TestAgent_AddCheck_restoresSnapshot(t *testing.T) {
 	}
 }
 
+func TestAgent_StartDrained(t *testing.T) {
+	if testing.Short() {
+		t.Skip("too slow for testing.Short")
+	}
+
+	t.Parallel()
+	a := NewTestAgent(t, `start_drained = true`)
+	defer a.Shutdown()
+
+	// Make sure the agent came up with node maintenance enabled
+	check := requireCheckExists(t, a, structs.NodeMaint)
+	if check.Notes != defaultNodeMaintReason {
+		t.Fatalf("bad: %#v", check)
+	}
+
+	// Clearing maintenance mode works as usual
+	a.DisableNodeMaintenance()
+	requireCheckMissing(t, a, structs.NodeMaint)
+}
+
 func TestAgent_NodeMaintenanceMode(t *testing.T) {
 	if testing.Short() {
 		t.Skip("too slow for testing.Short")
@@ -5185,3 +5279,25 @@ func uniqueAddrs(srvs []apiServer) map[string]struct{} {
 	}
 	return result
 }
+
+func TestAgent_StoreEphemeralPorts(t *testing.T) {
+	t.Parallel()
+	dataDir := testutil.TempDir(t, "ephemeral-ports")
+
+	a := &Agent{config: &config.RuntimeConfig{
+		DataDir:        dataDir,
+		EphemeralPorts: map[string]int{"http": 18500, "grpc": 18502},
+	}}
+	require.NoError(t, a.storeEphemeralPorts())
+
+	data, err := ioutil.ReadFile(filepath.Join(dataDir, "ephemeral-ports.json"))
+	require.NoError(t, err)
+	var ports map[string]int
+	require.NoError(t, json.Unmarshal(data, &ports))
+	require.Equal(t, a.config.EphemeralPorts, ports)
+
+	// Nothing gets written when no ports were allocated.
+	a.config.EphemeralPorts = nil
+	a.config.DataDir = filepath.Join(dataDir, "does-not-exist")
+	require.NoError(t, a.storeEphemeralPorts())
+}
@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/hashicorp/go-hclog"
+	"gopkg.in/square/go-jose.v2/jwt"
 
 	"github.com/hashicorp/consul/agent/cache"
 	"github.com/hashicorp/consul/agent/config"
@@ -100,10 +101,12 @@ func New(config Config) (*AutoConfig, error) {
 
 // ReadConfig will parse the current configuration and inject any
 // auto-config sources if present into the correct place in the parsing chain.
-func (ac *AutoConfig) ReadConfig() (*config.RuntimeConfig, error) {
+// Any overrides are merged in after every other config source so that they
+// take precedence for this read only.
+func (ac *AutoConfig) ReadConfig(overrides ...config.Source) (*config.RuntimeConfig, error) {
 	ac.Lock()
 	defer ac.Unlock()
-	cfg, warnings, err := ac.acConfig.Loader(ac.autoConfigSource)
+	cfg, warnings, err := ac.acConfig.Loader(ac.autoConfigSource, overrides...)
 	if err != nil {
 		return cfg, err
 	}
@@ -207,6 +210,59 @@ func (ac *AutoConfig) introToken() (string, error) {
 	return token, nil
 }
 
+// Status returns a sanitized summary of the agents auto configuration state
+// for exposure over the HTTP API. The set of fields that is safe to expose
+// is defined by config.AutoConfigStatus; in particular the intro token itself
+// is never returned, only its expiry claim.
+func (ac *AutoConfig) Status() config.AutoConfigStatus {
+	ac.Lock()
+	defer ac.Unlock()
+
+	var status config.AutoConfigStatus
+	if ac.config == nil {
+		return status
+	}
+
+	conf := ac.config.AutoConfig
+	status.Enabled = conf.Enabled
+	if conf.Authorizer.Enabled {
+		status.AuthorizerAuthMethod = conf.Authorizer.AuthMethod.Type
+	}
+
+	if conf.IntroToken != "" || conf.IntroTokenFile != "" {
+		if token, err := ac.introToken(); err == nil {
+			status.IntroTokenExpiry = introTokenExpiry(token)
+		}
+	}
+
+	if cert := ac.acConfig.TLSConfigurator.AutoEncryptCert(); cert != nil {
+		status.CertificateNotBefore = cert.NotBefore
+		status.CertificateNotAfter = cert.NotAfter
+	}
+
+	return status
+}
+
+// introTokenExpiry pulls the expiry claim out of a JWT intro token without
+// verifying its signature. A zero time is returned when the token is not a
+// JWT or carries no expiry claim.
+func introTokenExpiry(token string) time.Time {
+	parsed, err := jwt.ParseSigned(token)
+	if err != nil {
+		return time.Time{}
+	}
+
+	var claims jwt.Claims
+	if err := parsed.UnsafeClaimsWithoutVerification(&claims); err != nil {
+		return time.Time{}
+	}
+
+	if claims.Expiry == nil {
+		return time.Time{}
+	}
+	return claims.Expiry.Time()
+}
+
 // recordInitialConfiguration is responsible for recording the AutoConfigResponse from
 // the AutoConfig.InitialConfiguration RPC. It is an all-in-one function to do the following
 //   * update the Agent token in the token store
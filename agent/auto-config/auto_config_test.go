@@ -2,6 +2,7 @@ package autoconf
 
 import (
 	"context"
+	"crypto/x509"
 	"fmt"
 	"io/ioutil"
 	"net"
@@ -13,6 +14,7 @@ import (
 
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
+	"gopkg.in/square/go-jose.v2/jwt"
 
 	"github.com/hashicorp/consul/agent/cache"
 	cachetype "github.com/hashicorp/consul/agent/cache-types"
@@ -21,6 +23,7 @@ import (
 	"github.com/hashicorp/consul/agent/metadata"
 	"github.com/hashicorp/consul/agent/structs"
 	"github.com/hashicorp/consul/agent/token"
+	"github.com/hashicorp/consul/internal/go-sso/oidcauth/oidcauthtest"
 	"github.com/hashicorp/consul/lib/retry"
 	"github.com/hashicorp/consul/proto/pbautoconf"
 	"github.com/hashicorp/consul/proto/pbconfig"
@@ -32,8 +35,8 @@ type configLoader struct {
 	opts config.BuilderOpts
 }
 
-func (c *configLoader) Load(source config.Source) (*config.RuntimeConfig, []string, error) {
-	return config.Load(c.opts, source)
+func (c *configLoader) Load(source config.Source, overrides ...config.Source) (*config.RuntimeConfig, []string, error) {
+	return config.Load(c.opts, source, overrides...)
 }
 
 func (c *configLoader) addConfigHCL(cfg string) {
@@ -131,7 +134,7 @@ func TestNew(t *testing.T) {
 	for name, tcase := range cases {
 		t.Run(name, func(t *testing.T) {
 			cfg := Config{
-				Loader: func(source config.Source) (cfg *config.RuntimeConfig, warnings []string, err error) {
+				Loader: func(source config.Source, overrides ...config.Source) (cfg *config.RuntimeConfig, warnings []string, err error) {
 					return nil, nil, nil
 				},
 				DirectRPC:       newMockDirectRPC(t),
@@ -168,7 +171,7 @@ func TestReadConfig(t *testing.T) {
 		},
 		logger: testutil.Logger(t),
 		acConfig: Config{
-			Loader: func(source config.Source) (*config.RuntimeConfig, []string, error) {
+			Loader: func(source config.Source, overrides ...config.Source) (*config.RuntimeConfig, []string, error) {
 				cfg, _, err := source.Parse()
 				if err != nil {
 					return nil, nil, err
@@ -1177,3 +1180,66 @@ func TestIntroToken(t *testing.T) {
 	}
 
 }
+
+func TestStatus(t *testing.T) {
+	expiry := time.Now().Add(2 * time.Hour)
+	introToken, err := oidcauthtest.SignJWT("", jwt.Claims{
+		Expiry: jwt.NewNumericDate(expiry),
+	}, map[string]interface{}{})
+	require.NoError(t, err)
+
+	notBefore := time.Now().Add(-1 * time.Hour)
+	notAfter := time.Now().Add(71 * time.Hour)
+
+	tlsCfg := newMockTLSConfigurator(t)
+	tlsCfg.On("AutoEncryptCert").Return(&x509.Certificate{
+		NotBefore: notBefore,
+		NotAfter:  notAfter,
+	}).Once()
+
+	ac := AutoConfig{
+		acConfig: Config{
+			TLSConfigurator: tlsCfg,
+		},
+		config: &config.RuntimeConfig{
+			AutoConfig: config.AutoConfig{
+				Enabled:    true,
+				IntroToken: introToken,
+				Authorizer: config.AutoConfigAuthorizer{
+					Enabled: true,
+					AuthMethod: structs.ACLAuthMethod{
+						Type: "jwt",
+					},
+				},
+			},
+		},
+	}
+
+	status := ac.Status()
+	require.True(t, status.Enabled)
+	require.Equal(t, "jwt", status.AuthorizerAuthMethod)
+	// the expiry claim only has second precision
+	require.WithinDuration(t, expiry, status.IntroTokenExpiry, time.Second)
+	require.Equal(t, notBefore, status.CertificateNotBefore)
+	require.Equal(t, notAfter, status.CertificateNotAfter)
+
+	// the intro token itself must never leak into the status
+	require.NotContains(t, fmt.Sprintf("%v", status), introToken)
+
+	tlsCfg.AssertExpectations(t)
+}
+
+func TestStatus_Defaults(t *testing.T) {
+	tlsCfg := newMockTLSConfigurator(t)
+	tlsCfg.On("AutoEncryptCert").Return(nil).Once()
+
+	ac := AutoConfig{
+		acConfig: Config{
+			TLSConfigurator: tlsCfg,
+		},
+		config: &config.RuntimeConfig{},
+	}
+
+	require.Equal(t, config.AutoConfigStatus{}, ac.Status())
+	tlsCfg.AssertExpectations(t)
+}
@@ -2,6 +2,7 @@ package autoconf
 
 import (
 	"context"
+	"crypto/x509"
 	"net"
 	"time"
 
@@ -41,6 +42,7 @@ type TLSConfigurator interface {
 	UpdateAutoTLS(manualCAPEMs, connectCAPEMs []string, pub, priv string, verifyServerHostname bool) error
 	UpdateAutoTLSCA([]string) error
 	UpdateAutoTLSCert(pub, priv string) error
+	AutoEncryptCert() *x509.Certificate
 	AutoEncryptCertNotAfter() time.Time
 	AutoEncryptCertExpired() bool
 }
@@ -81,8 +83,10 @@ type Config struct {
 	Waiter *retry.Waiter
 
 	// Loader merges source with the existing FileSources and returns the complete
-	// RuntimeConfig.
-	Loader func(source config.Source) (cfg *config.RuntimeConfig, warnings []string, err error)
+	// RuntimeConfig. Additional overrides are merged in after every other
+	// source and are used for one-off config changes such as the fragment
+	// accepted by the agent reload endpoint.
+	Loader func(source config.Source, overrides ...config.Source) (cfg *config.RuntimeConfig, warnings []string, err error)
 
 	// TLSConfigurator is the shared TLS Configurator. AutoConfig will update the
 	// auto encrypt/auto config certs as they are renewed.
@@ -2,6 +2,7 @@ package autoconf
 
 import (
 	"context"
+	"crypto/x509"
 	"net"
 	"sync"
 	"testing"
@@ -79,6 +80,12 @@ func (m *mockTLSConfigurator) UpdateAutoTLSCert(pub, priv string) error {
 	ret := m.Called(pub, priv)
 	return ret.Error(0)
 }
+func (m *mockTLSConfigurator) AutoEncryptCert() *x509.Certificate {
+	ret := m.Called()
+	cert, _ := ret.Get(0).(*x509.Certificate)
+
+	return cert
+}
 func (m *mockTLSConfigurator) AutoEncryptCertNotAfter() time.Time {
 	ret := m.Called()
 	ts, _ := ret.Get(0).(time.Time)
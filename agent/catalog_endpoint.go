@@ -401,6 +401,12 @@ func (s *HTTPHandlers) catalogServiceNodes(resp http.ResponseWriter, req *http.R
 	}
 
 	out.ConsistencyLevel = args.QueryOptions.ConsistencyLevel()
+
+	// Cap the fan-out before spending effort on translation.
+	if limit := s.agent.config.DiscoveryMaxResultsHTTP; limit > 0 && len(out.ServiceNodes) > limit {
+		out.ServiceNodes = out.ServiceNodes[:limit]
+	}
+
 	s.agent.TranslateAddresses(args.Datacenter, out.ServiceNodes, TranslateAddressAcceptAny)
 
 	// Use empty list instead of nil
@@ -71,6 +71,7 @@ type CheckMonitor struct {
 	Logger        hclog.Logger
 	OutputMaxSize int
 	StatusHandler *StatusHandler
+	ExecLimits    *ExecLimits
 
 	stop     bool
 	stopCh   chan struct{}
@@ -115,6 +116,12 @@ func (c *CheckMonitor) run() {
 
 // check is invoked periodically to perform the script check
 func (c *CheckMonitor) check() {
+	// Wait for a free execution slot
+	if !c.ExecLimits.acquire(c.stopCh) {
+		return
+	}
+	defer c.ExecLimits.release()
+
 	// Create the command
 	var cmd *osexec.Cmd
 	var err error
@@ -161,6 +168,14 @@ func (c *CheckMonitor) check() {
 		return
 	}
 
+	// Apply the per-check resource limits
+	if err := c.ExecLimits.apply(cmd.Process.Pid); err != nil {
+		c.Logger.Warn("Check failed to apply resource limits",
+			"check", c.CheckID.String(),
+			"error", err,
+		)
+	}
+
 	// Wait for the check to complete
 	waitCh := make(chan error, 1)
 	go func() {
@@ -1224,7 +1224,7 @@ func TestCheck_Docker(t *testing.T) {
 
 			// create a docker client with a tiny output buffer
 			// to test the truncation
-			c, err := NewDockerClient(srv.URL, 20)
+			c, err := NewDockerClient(srv.URL, 20, nil)
 			if err != nil {
 				t.Fatal(err)
 			}
@@ -0,0 +1,237 @@
+package checks
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"time"
+
+	"github.com/armon/circbuf"
+	"github.com/hashicorp/consul/agent/structs"
+	"github.com/hashicorp/consul/api"
+	"github.com/hashicorp/consul/lib"
+	"github.com/hashicorp/go-hclog"
+	"google.golang.org/grpc"
+)
+
+// DefaultCRIEndpoint is the default containerd socket used when no
+// cri_endpoint is configured.
+const DefaultCRIEndpoint = "/run/containerd/containerd.sock"
+
+// criExecSyncMethod is the full method name of the ExecSync RPC on the
+// CRI runtime service. ExecSync runs a command synchronously inside a
+// container and returns its combined output and exit code, which is
+// exactly the contract a script check needs.
+const criExecSyncMethod = "/runtime.v1alpha2.RuntimeService/ExecSync"
+
+// criExecSyncRequest mirrors runtime.v1alpha2.ExecSyncRequest. The
+// messages are hand-defined here to exec through the CRI socket without
+// pulling the full cri-api dependency in.
+type criExecSyncRequest struct {
+	ContainerId string   `protobuf:"bytes,1,opt,name=container_id,proto3"`
+	Cmd         []string `protobuf:"bytes,2,rep,name=cmd,proto3"`
+	Timeout     int64    `protobuf:"varint,3,opt,name=timeout,proto3"`
+}
+
+func (m *criExecSyncRequest) Reset()         { *m = criExecSyncRequest{} }
+func (m *criExecSyncRequest) String() string { return fmt.Sprintf("%+v", *m) }
+func (m *criExecSyncRequest) ProtoMessage()  {}
+
+// criExecSyncResponse mirrors runtime.v1alpha2.ExecSyncResponse.
+type criExecSyncResponse struct {
+	Stdout   []byte `protobuf:"bytes,1,opt,name=stdout,proto3"`
+	Stderr   []byte `protobuf:"bytes,2,opt,name=stderr,proto3"`
+	ExitCode int32  `protobuf:"varint,3,opt,name=exit_code,proto3"`
+}
+
+func (m *criExecSyncResponse) Reset()         { *m = criExecSyncResponse{} }
+func (m *criExecSyncResponse) String() string { return fmt.Sprintf("%+v", *m) }
+func (m *criExecSyncResponse) ProtoMessage()  {}
+
+// CRIClient is a minimal client for the Kubernetes Container Runtime
+// Interface runtime service, speaking gRPC over the local containerd or
+// CRI-O socket. Like DockerClient it caps the captured output with a
+// ring buffer to avoid excessive resource consumption.
+type CRIClient struct {
+	endpoint string
+	maxbuf   int64
+	conn     *grpc.ClientConn
+}
+
+// NewCRIClient creates a client for the CRI runtime service listening
+// on the given unix socket. An empty endpoint falls back to the default
+// containerd socket.
+func NewCRIClient(endpoint string, maxbuf int64) (*CRIClient, error) {
+	if endpoint == "" {
+		endpoint = DefaultCRIEndpoint
+	}
+
+	dialer := func(ctx context.Context, addr string) (net.Conn, error) {
+		var d net.Dialer
+		return d.DialContext(ctx, "unix", addr)
+	}
+	conn, err := grpc.Dial(endpoint, grpc.WithInsecure(), grpc.WithContextDialer(dialer))
+	if err != nil {
+		return nil, fmt.Errorf("unable to connect to CRI endpoint %q: %s", endpoint, err)
+	}
+
+	return &CRIClient{
+		endpoint: endpoint,
+		maxbuf:   maxbuf,
+		conn:     conn,
+	}, nil
+}
+
+func (c *CRIClient) Close() error {
+	return c.conn.Close()
+}
+
+func (c *CRIClient) Endpoint() string {
+	return c.endpoint
+}
+
+// ExecSync runs cmd inside the given container and returns the exit
+// code together with the combined output, truncated to maxbuf bytes.
+func (c *CRIClient) ExecSync(containerID string, cmd []string, timeout time.Duration) (int, *circbuf.Buffer, error) {
+	req := &criExecSyncRequest{
+		ContainerId: containerID,
+		Cmd:         cmd,
+		Timeout:     int64(timeout / time.Second),
+	}
+	resp := &criExecSyncResponse{}
+
+	ctx := context.Background()
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	if err := c.conn.Invoke(ctx, criExecSyncMethod, req, resp); err != nil {
+		return 0, nil, fmt.Errorf("CRI exec in container %s failed: %s", containerID, err)
+	}
+
+	buf, err := circbuf.NewBuffer(c.maxbuf)
+	if err != nil {
+		return 0, nil, err
+	}
+	buf.Write(resp.Stdout)
+	buf.Write(resp.Stderr)
+	return int(resp.ExitCode), buf, nil
+}
+
+// CheckCRI is used to periodically invoke a script inside a container
+// through the CRI runtime service, giving parity with kubelet exec
+// probes for containers that are not managed by docker. We assume that
+// the script is compatible with nagios plugins and expects the output
+// in the same format.
+// Supports failures_before_critical and success_before_passing.
+type CheckCRI struct {
+	CheckID        structs.CheckID
+	ServiceID      structs.ServiceID
+	ScriptArgs     []string
+	CRIContainerID string
+	Shell          string
+	Interval       time.Duration
+	Timeout        time.Duration
+	Logger         hclog.Logger
+	Client         *CRIClient
+	StatusHandler  *StatusHandler
+
+	stop chan struct{}
+}
+
+func (c *CheckCRI) Start() {
+	if c.stop != nil {
+		panic("CRI check already started")
+	}
+
+	if c.Logger == nil {
+		c.Logger = hclog.New(&hclog.LoggerOptions{Output: ioutil.Discard})
+	}
+
+	if c.Shell == "" {
+		c.Shell = os.Getenv("SHELL")
+		if c.Shell == "" {
+			c.Shell = "/bin/sh"
+		}
+	}
+	c.stop = make(chan struct{})
+	go c.run()
+}
+
+func (c *CheckCRI) Stop() {
+	if c.stop == nil {
+		panic("Stop called before start")
+	}
+	close(c.stop)
+}
+
+func (c *CheckCRI) run() {
+	firstWait := lib.RandomStagger(c.Interval)
+	next := time.After(firstWait)
+	for {
+		select {
+		case <-next:
+			c.check()
+			next = time.After(c.Interval)
+		case <-c.stop:
+			return
+		}
+	}
+}
+
+func (c *CheckCRI) check() {
+	var out string
+	status, b, err := c.doCheck()
+	if err != nil {
+		c.Logger.Debug("Check failed",
+			"check", c.CheckID.String(),
+			"error", err,
+		)
+		out = err.Error()
+	} else {
+		// out is already limited to CheckBufSize since we're getting a
+		// limited buffer. So we don't need to truncate it just report
+		// that it was truncated.
+		out = string(b.Bytes())
+		if int(b.TotalWritten()) > len(out) {
+			out = fmt.Sprintf("Captured %d of %d bytes\n...\n%s", len(out), b.TotalWritten(), out)
+		}
+		c.Logger.Trace("Check output",
+			"check", c.CheckID.String(),
+			"output", out,
+		)
+	}
+	c.StatusHandler.updateCheck(c.CheckID, status, out)
+}
+
+func (c *CheckCRI) doCheck() (string, *circbuf.Buffer, error) {
+	cmd := c.ScriptArgs
+	if len(cmd) == 0 {
+		cmd = []string{c.Shell}
+	}
+
+	exitCode, buf, err := c.Client.ExecSync(c.CRIContainerID, cmd, c.Timeout)
+	if err != nil {
+		return api.HealthCritical, nil, err
+	}
+
+	switch exitCode {
+	case 0:
+		return api.HealthPassing, buf, nil
+	case 1:
+		c.Logger.Debug("Check failed",
+			"check", c.CheckID.String(),
+			"exit_code", exitCode,
+		)
+		return api.HealthWarning, buf, nil
+	default:
+		c.Logger.Debug("Check failed",
+			"check", c.CheckID.String(),
+			"exit_code", exitCode,
+		)
+		return api.HealthCritical, buf, nil
+	}
+}
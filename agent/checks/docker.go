@@ -2,6 +2,7 @@ package checks
 
 import (
 	"bytes"
+	"crypto/tls"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -29,7 +30,10 @@ type DockerClient struct {
 	client   *http.Client
 }
 
-func NewDockerClient(host string, maxbuf int64) (*DockerClient, error) {
+// NewDockerClient creates a client for the given docker host. An empty
+// host falls back to the platform default socket. A non-nil tlsConfig
+// upgrades the connection to HTTPS and is only valid for tcp hosts.
+func NewDockerClient(host string, maxbuf int64, tlsConfig *tls.Config) (*DockerClient, error) {
 	if host == "" {
 		host = DefaultDockerHost
 	}
@@ -39,13 +43,21 @@ func NewDockerClient(host string, maxbuf int64) (*DockerClient, error) {
 		return nil, err
 	}
 
+	scheme := "http"
 	transport := new(http.Transport)
 	sockets.ConfigureTransport(transport, proto, addr)
+	if tlsConfig != nil {
+		if proto != "tcp" {
+			return nil, fmt.Errorf("TLS is only supported for tcp docker hosts, got %q", host)
+		}
+		transport.TLSClientConfig = tlsConfig
+		scheme = "https"
+	}
 	client := &http.Client{Transport: transport}
 
 	return &DockerClient{
 		host:     host,
-		scheme:   "http",
+		scheme:   scheme,
 		proto:    proto,
 		addr:     addr,
 		basepath: basepath,
@@ -0,0 +1,65 @@
+package checks
+
+import (
+	"sync"
+
+	"github.com/hashicorp/consul/agent/exec"
+)
+
+// ExecLimits bounds the resources script check processes may use so that a
+// heavy check cannot starve the agent. It is shared by all check monitors
+// of an agent so that MaxConcurrent applies across checks. The zero value
+// and a nil receiver apply no limits.
+type ExecLimits struct {
+	// MaxConcurrent bounds how many script checks run at the same time.
+	// Additional checks wait for a free slot before running. Zero applies
+	// no limit.
+	MaxConcurrent int
+
+	// CPUSeconds is a per-check CPU time rlimit in seconds. Zero applies
+	// no limit.
+	CPUSeconds int
+
+	// MemoryBytes is a per-check address space rlimit in bytes. Zero
+	// applies no limit.
+	MemoryBytes int64
+
+	// Nice is the scheduling priority check processes run with. Zero
+	// leaves the priority unchanged.
+	Nice int
+
+	initOnce sync.Once
+	sem      chan struct{}
+}
+
+// acquire blocks until the check may run or stopCh is closed and reports
+// whether a slot was acquired.
+func (l *ExecLimits) acquire(stopCh chan struct{}) bool {
+	if l == nil || l.MaxConcurrent <= 0 {
+		return true
+	}
+	l.initOnce.Do(func() { l.sem = make(chan struct{}, l.MaxConcurrent) })
+	select {
+	case l.sem <- struct{}{}:
+		return true
+	case <-stopCh:
+		return false
+	}
+}
+
+// release returns the slot taken by acquire.
+func (l *ExecLimits) release() {
+	if l == nil || l.MaxConcurrent <= 0 {
+		return
+	}
+	<-l.sem
+}
+
+// apply sets the per-check rlimits and nice value on a started check
+// process.
+func (l *ExecLimits) apply(pid int) error {
+	if l == nil || (l.CPUSeconds == 0 && l.MemoryBytes == 0 && l.Nice == 0) {
+		return nil
+	}
+	return exec.ApplyLimits(pid, l.CPUSeconds, l.MemoryBytes, l.Nice)
+}
@@ -0,0 +1,52 @@
+package checks
+
+import (
+	"testing"
+)
+
+func TestExecLimits_acquireRelease(t *testing.T) {
+	stopCh := make(chan struct{})
+
+	// nil limits and no concurrency limit never block
+	var nilLimits *ExecLimits
+	if !nilLimits.acquire(stopCh) {
+		t.Fatal("nil limits should always acquire")
+	}
+	nilLimits.release()
+
+	unlimited := &ExecLimits{}
+	if !unlimited.acquire(stopCh) {
+		t.Fatal("unlimited should always acquire")
+	}
+	unlimited.release()
+
+	// with a limit of one the second acquire blocks until release
+	l := &ExecLimits{MaxConcurrent: 1}
+	if !l.acquire(stopCh) {
+		t.Fatal("first acquire should succeed")
+	}
+
+	acquired := make(chan bool, 1)
+	go func() {
+		acquired <- l.acquire(stopCh)
+	}()
+	select {
+	case <-acquired:
+		t.Fatal("second acquire should block until release")
+	default:
+	}
+
+	l.release()
+	if !<-acquired {
+		t.Fatal("second acquire should succeed after release")
+	}
+
+	// a blocked acquire returns false when the check is stopped
+	go func() {
+		acquired <- l.acquire(stopCh)
+	}()
+	close(stopCh)
+	if <-acquired {
+		t.Fatal("acquire should fail when stopCh is closed")
+	}
+}
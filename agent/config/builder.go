@@ -5,24 +5,29 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"net"
+	"net/http"
 	"net/url"
 	"os"
 	"path"
 	"path/filepath"
 	"reflect"
 	"regexp"
+	"runtime"
 	"sort"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/armon/go-metrics/prometheus"
 	"github.com/hashicorp/go-bexpr"
 	"github.com/hashicorp/go-hclog"
 	"github.com/hashicorp/go-multierror"
-	"github.com/hashicorp/go-sockaddr/template"
 	"github.com/hashicorp/memberlist"
+	"github.com/mitchellh/mapstructure"
 	"golang.org/x/time/rate"
 
 	"github.com/hashicorp/consul/agent/cache"
@@ -63,7 +68,7 @@ func Load(opts BuilderOpts, extraHead Source, overrides ...Source) (*RuntimeConf
 		return nil, nil, err
 	}
 
-	return &cfg, b.Warnings, nil
+	return &cfg, b.WarningStrings(), nil
 }
 
 // Builder constructs a valid runtime configuration from multiple
@@ -102,7 +107,29 @@ type Builder struct {
 
 	// Warnings contains the warnings encountered when
 	// parsing the configuration.
-	Warnings []string
+	Warnings []Warning
+
+	// suppressWarnings is the merged suppress_warnings list from the config.
+	suppressWarnings []string
+
+	// deprecatedInUse are the deprecated keys with a scheduled removal
+	// version that the config actually used, recorded for
+	// deprecation_policy enforcement.
+	deprecatedInUse []string
+
+	// upstreamDefaults is derived from connect.upstream_defaults and merged
+	// into the opaque config of every upstream of locally defined proxies.
+	upstreamDefaults map[string]interface{}
+
+	// provenance records which sources contributed to each config key, in
+	// merge order. It is nil unless Build is invoked through
+	// BuildWithProvenance.
+	provenance map[string][]string
+
+	// ephemeralPorts records the ports the builder picked for listeners
+	// configured with port 0, keyed by the ports.* name without the
+	// prefix. It is nil unless at least one port was allocated.
+	ephemeralPorts map[string]int
 
 	// err contains the first error that occurred during
 	// building the runtime configuration.
@@ -125,6 +152,14 @@ func NewBuilder(opts BuilderOpts) (*Builder, error) {
 		b.Head = append(b.Head, DevSource())
 	}
 
+	if opts.Profile != "" {
+		src, err := ProfileSource(opts.Profile)
+		if err != nil {
+			return nil, err
+		}
+		b.Head = append(b.Head, src)
+	}
+
 	// Since the merge logic is to overwrite all fields with later
 	// values except slices which are merged by appending later values
 	// we need to merge all slice values defined in flags before we
@@ -133,11 +168,29 @@ func NewBuilder(opts BuilderOpts) (*Builder, error) {
 	slices, values := splitSlicesAndValues(opts.Config)
 	b.Head = append(b.Head, LiteralSource{Name: "flags.slices", Config: slices})
 	for _, path := range opts.ConfigFiles {
-		sources, err := b.sourcesFromPath(path, opts.ConfigFormat)
+		path, format := splitPathFormat(path, opts.ConfigFormat)
+		if path == "-" {
+			src, err := newSourceFromStdin(opts.stdinInput(), format)
+			if err != nil {
+				return nil, err
+			}
+			b.Sources = append(b.Sources, src)
+			continue
+		}
+		sources, err := b.sourcesFromPath(path, format)
 		if err != nil {
 			return nil, err
 		}
-		b.Sources = append(b.Sources, sources...)
+		for _, src := range sources {
+			expanded, err := b.expandIncludes(src, opts.ConfigFormat, map[string]bool{})
+			if err != nil {
+				return nil, err
+			}
+			b.Sources = append(b.Sources, expanded...)
+		}
+	}
+	if err := b.verifyConfigSources(opts.Config.ConfigVerification); err != nil {
+		return nil, err
 	}
 	b.Tail = append(b.Tail, LiteralSource{Name: "flags.values", Config: values})
 	for i, s := range opts.HCL {
@@ -147,17 +200,46 @@ func NewBuilder(opts BuilderOpts) (*Builder, error) {
 			Data:   s,
 		})
 	}
+	for i, s := range opts.JSON {
+		b.Tail = append(b.Tail, FileSource{
+			Name:   fmt.Sprintf("flags-%d.json", i),
+			Format: "json",
+			Data:   s,
+		})
+	}
 	b.Tail = append(b.Tail, NonUserSource(), DefaultConsulSource(), OverrideEnterpriseSource(), defaultVersionSource())
+	if opts.VersionMetadata != nil {
+		src, err := versionMetadataSource(*opts.VersionMetadata)
+		if err != nil {
+			return nil, err
+		}
+		b.Tail = append(b.Tail, src)
+	}
 	if b.boolVal(opts.DevMode) {
 		b.Tail = append(b.Tail, DevConsulSource())
 	}
 	return b, nil
 }
 
-// sourcesFromPath reads a single config file or all files in a directory (but
-// not its sub-directories) and returns Sources created from the
-// files.
+// sourcesFromPath reads a single config file, all files in a directory (but
+// not its sub-directories) or all files in a tar/zip bundle and returns
+// Sources created from the files.
+//
+// With config_dir_follow_symlink the path is re-resolved through its symlink
+// every time it is read. Since reloads run through here again this activates
+// a new generation directory atomically by swapping the symlink, like
+// Kubernetes ConfigMap volumes do. The sources keep the symlinked path as
+// their name so that file references stay stable across generations.
 func (b *Builder) sourcesFromPath(path string, format string) ([]Source, error) {
+	displayPath := path
+	if b.boolVal(b.opts.Config.ConfigDirFollowSymlink) {
+		resolved, err := filepath.EvalSymlinks(path)
+		if err != nil {
+			return nil, fmt.Errorf("config: failed to resolve symlink %s. %s", path, err)
+		}
+		path = resolved
+	}
+
 	f, err := os.Open(path)
 	if err != nil {
 		return nil, fmt.Errorf("config: Open failed on %s. %s", path, err)
@@ -170,15 +252,28 @@ func (b *Builder) sourcesFromPath(path string, format string) ([]Source, error)
 	}
 
 	if !fi.IsDir() {
+		if isBundle(path) {
+			return b.sourcesFromBundle(path, format)
+		}
+
 		if !shouldParseFile(path, format) {
-			b.warn("skipping file %v, extension must be .hcl or .json, or config format must be set", path)
+			b.warning(Warning{
+				Code:    "skipped-file",
+				Source:  path,
+				Message: fmt.Sprintf("skipping file %v, extension must be .hcl or .json, or config format must be set", path),
+			})
 			return nil, nil
 		}
 
-		src, err := newSourceFromFile(path, format)
+		src, err := b.newSourceFromFile(path, format)
 		if err != nil {
 			return nil, err
 		}
+		if displayPath != path {
+			fs := src.(FileSource)
+			fs.Name = displayPath
+			src = fs
+		}
 		return []Source{src}, nil
 	}
 
@@ -192,7 +287,8 @@ func (b *Builder) sourcesFromPath(path string, format string) ([]Source, error)
 
 	var sources []Source
 	for _, fi := range fis {
-		fp := filepath.Join(path, fi.Name())
+		name := fi.Name()
+		fp := filepath.Join(path, name)
 		// check for a symlink and resolve the path
 		if fi.Mode()&os.ModeSymlink > 0 {
 			var err error
@@ -211,30 +307,181 @@ func (b *Builder) sourcesFromPath(path string, format string) ([]Source, error)
 		}
 
 		if !shouldParseFile(fp, format) {
-			b.warn("skipping file %v, extension must be .hcl or .json, or config format must be set", fp)
+			b.warning(Warning{
+				Code:    "skipped-file",
+				Source:  fp,
+				Message: fmt.Sprintf("skipping file %v, extension must be .hcl or .json, or config format must be set", fp),
+			})
 			continue
 		}
-		src, err := newSourceFromFile(fp, format)
+		src, err := b.newSourceFromFile(fp, format)
 		if err != nil {
 			return nil, err
 		}
+		if displayPath != path {
+			fs := src.(FileSource)
+			fs.Name = filepath.Join(displayPath, name)
+			src = fs
+		}
 		sources = append(sources, src)
 	}
+
+	// an explicit priority key inside a file overrides the alphabetical
+	// ordering
+	sortByPriority(sources)
+
+	return sources, nil
+}
+
+// sortByPriority reorders the sources of a config directory by the optional
+// priority key at the top of each file. Files are merged in ascending
+// priority order so that files with a higher priority overwrite files with a
+// lower one. Files without a priority default to 0 and keep their
+// alphabetical order relative to each other.
+func sortByPriority(sources []Source) {
+	type prioritized struct {
+		src      Source
+		priority int
+	}
+	ps := make([]prioritized, len(sources))
+	for i, src := range sources {
+		ps[i] = prioritized{src: src, priority: sourcePriority(src)}
+	}
+	sort.SliceStable(ps, func(i, j int) bool { return ps[i].priority < ps[j].priority })
+	for i, p := range ps {
+		sources[i] = p.src
+	}
+}
+
+// sourcePriority returns the value of the priority key of a config file, or
+// zero if the file has none. Parse errors are ignored here since they are
+// reported with the proper context during Build.
+func sourcePriority(src Source) int {
+	c, _, err := src.Parse()
+	if err != nil || c.Priority == nil {
+		return 0
+	}
+	return *c.Priority
+}
+
+// expandIncludes returns src followed by a Source for every file matched by
+// the glob patterns in its include key, in the order the patterns are given.
+// Relative patterns are resolved against the directory of the including file
+// and included files may themselves use include. seen holds the files on the
+// current include chain so that cycles are detected instead of recursing
+// forever.
+func (b *Builder) expandIncludes(src Source, format string, seen map[string]bool) ([]Source, error) {
+	fs, ok := src.(FileSource)
+	if !ok {
+		return []Source{src}, nil
+	}
+
+	path, err := filepath.Abs(fs.Name)
+	if err != nil {
+		return nil, fmt.Errorf("config: failed to resolve %s: %s", fs.Name, err)
+	}
+	if seen[path] {
+		return nil, fmt.Errorf("config: include cycle detected in %s", fs.Name)
+	}
+	seen[path] = true
+	defer delete(seen, path)
+
+	c, _, err := fs.Parse()
+	if err != nil {
+		// parse errors are reported with the proper context during Build
+		return []Source{src}, nil
+	}
+
+	sources := []Source{src}
+	for _, pattern := range c.Include {
+		p := pattern
+		if !filepath.IsAbs(p) {
+			p = filepath.Join(filepath.Dir(path), p)
+		}
+		matches, err := filepath.Glob(p)
+		if err != nil {
+			return nil, fmt.Errorf("config: invalid include pattern %q in %s: %s", pattern, fs.Name, err)
+		}
+		sort.Strings(matches)
+		for _, match := range matches {
+			if !shouldParseFile(match, format) {
+				b.warning(Warning{
+					Code:    "skipped-file",
+					Source:  match,
+					Message: fmt.Sprintf("skipping file %v, extension must be .hcl or .json, or config format must be set", match),
+				})
+				continue
+			}
+			inc, err := b.newSourceFromFile(match, format)
+			if err != nil {
+				return nil, err
+			}
+			expanded, err := b.expandIncludes(inc, format, seen)
+			if err != nil {
+				return nil, err
+			}
+			sources = append(sources, expanded...)
+		}
+	}
 	return sources, nil
 }
 
-// newSourceFromFile creates a Source from the contents of the file at path.
-func newSourceFromFile(path string, format string) (Source, error) {
+// newSourceFromStdin creates a Source from the data read from r. It is used
+// when '-config-file=-' is given so that generated configuration can be piped
+// into the agent without a temporary file. Since stdin has no file extension
+// the format must be forced with -config-format.
+func newSourceFromStdin(r io.Reader, format string) (Source, error) {
+	if format != "json" && format != "hcl" {
+		return nil, fmt.Errorf("config: -config-format must be set to either 'hcl' or 'json' when reading config from stdin")
+	}
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("config: failed to read stdin: %s", err)
+	}
+	return FileSource{Name: "stdin", Data: string(data), Format: format}, nil
+}
+
+// newSourceFromFile creates a Source from the contents of the file at path,
+// decrypting it first when it carries an encrypted config extension.
+func (b *Builder) newSourceFromFile(path string, format string) (Source, error) {
 	data, err := ioutil.ReadFile(path)
 	if err != nil {
 		return nil, fmt.Errorf("config: failed to read %s: %s", path, err)
 	}
+	if isEncryptedFile(path) {
+		if b.opts.ConfigDecryptKey == "" {
+			return nil, fmt.Errorf("config: %s is encrypted and -config-decrypt-key is not set", path)
+		}
+		key, err := loadDecryptKey(b.opts.ConfigDecryptKey)
+		if err != nil {
+			return nil, err
+		}
+		data, err = decryptConfig(data, key)
+		if err != nil {
+			return nil, fmt.Errorf("config: failed to decrypt %s: %s", path, err)
+		}
+	}
 	if format == "" {
 		format = formatFromFileExtension(path)
 	}
 	return FileSource{Name: path, Data: string(data), Format: format}, nil
 }
 
+// splitPathFormat splits an optional per-file format suffix of the form
+// 'path:hcl' or 'path:json' off a -config-file argument. The suffix
+// overrides the global -config-format for that file only, so files with
+// non-standard extensions can be loaded without changing how every other
+// file is handled. Anything other than the two known formats is kept as
+// part of the path.
+func splitPathFormat(path, format string) (string, string) {
+	for _, f := range []string{"hcl", "json"} {
+		if strings.HasSuffix(path, ":"+f) {
+			return strings.TrimSuffix(path, ":"+f), f
+		}
+	}
+	return path, format
+}
+
 // shouldParse file determines whether the file to be read is of a supported extension
 func shouldParseFile(path string, configFormat string) bool {
 	srcFormat := formatFromFileExtension(path)
@@ -243,9 +490,9 @@ func shouldParseFile(path string, configFormat string) bool {
 
 func formatFromFileExtension(name string) string {
 	switch {
-	case strings.HasSuffix(name, ".json"):
+	case strings.HasSuffix(name, ".json"), strings.HasSuffix(name, ".json.age"):
 		return "json"
-	case strings.HasSuffix(name, ".hcl"):
+	case strings.HasSuffix(name, ".hcl"), strings.HasSuffix(name, ".hcl.age"):
 		return "hcl"
 	default:
 		return ""
@@ -266,6 +513,10 @@ func (b *Builder) BuildAndValidate() (RuntimeConfig, error) {
 	if err := b.Validate(rt); err != nil {
 		return RuntimeConfig{}, err
 	}
+	if rt.StrictMode && len(b.Warnings) > 0 {
+		return RuntimeConfig{}, fmt.Errorf("strict_mode is enabled and the config generated %d warning(s):\n%s",
+			len(b.Warnings), strings.Join(b.WarningStrings(), "\n"))
+	}
 	return rt, nil
 }
 
@@ -283,9 +534,60 @@ func (b *Builder) Build() (rt RuntimeConfig, err error) {
 
 	// parse the config sources into a configuration
 	var c Config
-	for _, s := range srcs {
+	// configEntrySources tracks, in merge order, which config source each
+	// config_entries.bootstrap entry came from so that validation failures
+	// after merging can still point back at the offending file.
+	var configEntrySources []string
+
+	// serviceSources tracks, in merge order, which config source each
+	// service definition came from so that validation failures can point
+	// back at the offending file.
+	var serviceSources []string
+
+	// unknown config keys are collected across all sources and handled
+	// after merging since the unknown_keys policy that decides their fate
+	// is itself part of the merged config.
+	type unknownKey struct {
+		source string
+		key    string
+	}
+	var unknownKeys []unknownKey
+
+	// parse the sources concurrently since config dirs can contain
+	// hundreds of service and check definition files and the parse time
+	// dominates startup on slow disks. The results are merged in source
+	// order below so the outcome is identical to a sequential parse.
+	type parseResult struct {
+		c   Config
+		md  mapstructure.Metadata
+		err error
+	}
+	results := make([]parseResult, len(srcs))
+	workers := runtime.NumCPU()
+	if workers > len(srcs) {
+		workers = len(srcs)
+	}
+	var wg sync.WaitGroup
+	idxCh := make(chan int)
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range idxCh {
+				c2, md, err := srcs[i].Parse()
+				results[i] = parseResult{c: c2, md: md, err: err}
+			}
+		}()
+	}
+	for i := range srcs {
+		idxCh <- i
+	}
+	close(idxCh)
+	wg.Wait()
 
-		c2, md, err := s.Parse()
+	for i, s := range srcs {
+
+		c2, md, err := results[i].c, results[i].md, results[i].err
 		switch {
 		case err == ErrNoData:
 			continue
@@ -293,21 +595,17 @@ func (b *Builder) Build() (rt RuntimeConfig, err error) {
 			return RuntimeConfig{}, fmt.Errorf("failed to parse %v: %w", s.Source(), err)
 		}
 
-		var unusedErr error
 		for _, k := range md.Unused {
 			switch k {
 			case "acl_enforce_version_8":
-				b.warn("config key %q is deprecated and should be removed", k)
+				b.warnDeprecated(k, "config key %q is deprecated and should be removed", k)
 			default:
-				unusedErr = multierror.Append(unusedErr, fmt.Errorf("invalid config key %s", k))
+				unknownKeys = append(unknownKeys, unknownKey{source: s.Source(), key: k})
 			}
 		}
-		if unusedErr != nil {
-			return RuntimeConfig{}, fmt.Errorf("failed to parse %v: %s", s.Source(), unusedErr)
-		}
 
 		for _, err := range validateEnterpriseConfigKeys(&c2) {
-			b.warn("%s", err)
+			b.warnc("enterprise-key", "%s", err)
 		}
 
 		// if we have a single 'check' or 'service' we need to add them to the
@@ -322,9 +620,48 @@ func (b *Builder) Build() (rt RuntimeConfig, err error) {
 			c2.Service = nil
 		}
 
+		for range c2.ConfigEntries.Bootstrap {
+			configEntrySources = append(configEntrySources, s.Source())
+		}
+		for range c2.Services {
+			serviceSources = append(serviceSources, s.Source())
+		}
+
+		b.recordProvenance(s.Source(), c2)
+
+		if err := applyReplaceStrategy(&c, c2); err != nil {
+			return RuntimeConfig{}, fmt.Errorf("failed to parse %v: %s", s.Source(), err)
+		}
+
 		c = Merge(c, c2)
 	}
 
+	// apply the unknown_keys policy now that all sources are merged
+	switch action := b.stringValWithDefault(c.UnknownKeys, "error"); action {
+	case "error":
+		var unusedErr error
+		for _, u := range unknownKeys {
+			unusedErr = multierror.Append(unusedErr, fmt.Errorf("failed to parse %v: invalid config key %s", u.source, u.key))
+		}
+		if unusedErr != nil {
+			return RuntimeConfig{}, unusedErr
+		}
+	case "warn":
+		for _, u := range unknownKeys {
+			b.warnc("unknown-key", "invalid config key %s", u.key)
+		}
+	case "ignore":
+	default:
+		return RuntimeConfig{}, fmt.Errorf("unknown_keys cannot be %q. Must be \"error\", \"warn\" or \"ignore\"", action)
+	}
+
+	// fingerprint the merged config before validation so that agents can
+	// compare configurations without diffing full payloads
+	configHash, err := fingerprint(c)
+	if err != nil {
+		return RuntimeConfig{}, fmt.Errorf("Error computing config hash: %s", err)
+	}
+
 	// ----------------------------------------------------------------
 	// process/merge some complex values
 	//
@@ -364,6 +701,13 @@ func (b *Builder) Build() (rt RuntimeConfig, err error) {
 	// checks and services
 	//
 
+	// the checks package is shadowed by the check definitions below
+	criEndpoint := b.stringValWithDefault(c.CRIEndpoint, checks.DefaultCRIEndpoint)
+
+	// connect.upstream_defaults are merged into the upstreams of locally
+	// defined proxies below so they must be computed first.
+	b.upstreamDefaults = b.upstreamDefaultsVal(c.Connect.UpstreamDefaults)
+
 	var checks []*structs.CheckDefinition
 	if c.Check != nil {
 		checks = append(checks, b.checkVal(c.Check))
@@ -373,25 +717,47 @@ func (b *Builder) Build() (rt RuntimeConfig, err error) {
 	}
 
 	var services []*structs.ServiceDefinition
-	for _, service := range c.Services {
-		services = append(services, b.serviceVal(&service))
+	for i, service := range c.Services {
+		svc := b.serviceVal(&service)
+		source := "config"
+		if i < len(serviceSources) {
+			source = serviceSources[i]
+		}
+		b.validateTaggedAddresses(svc, source)
+		services = append(services, svc)
 	}
 	if c.Service != nil {
-		services = append(services, b.serviceVal(c.Service))
+		svc := b.serviceVal(c.Service)
+		b.validateTaggedAddresses(svc, "config")
+		services = append(services, svc)
 	}
 
 	// ----------------------------------------------------------------
 	// addresses
 	//
 
-	// determine port values and replace values <= 0 and > 65535 with -1
-	dnsPort := b.portVal("ports.dns", c.Ports.DNS)
-	httpPort := b.portVal("ports.http", c.Ports.HTTP)
-	httpsPort := b.portVal("ports.https", c.Ports.HTTPS)
-	serverPort := b.portVal("ports.server", c.Ports.Server)
-	grpcPort := b.portVal("ports.grpc", c.Ports.GRPC)
-	serfPortLAN := b.portVal("ports.serf_lan", c.Ports.SerfLAN)
-	serfPortWAN := b.portVal("ports.serf_wan", c.Ports.SerfWAN)
+	// determine port values and replace values < 0 and > 65535 with -1.
+	// Listener ports explicitly set to 0 are resolved to a free port
+	// picked by the operating system.
+	dnsPort := b.ephemeralPortVal("ports.dns", c.Ports.DNS)
+	// The TCP and UDP listeners can be configured independently and fall
+	// back to ports.dns.
+	dnsTCPPort := dnsPort
+	if c.Ports.DNSTCP != nil {
+		dnsTCPPort = b.ephemeralPortVal("ports.dns_tcp", c.Ports.DNSTCP)
+	}
+	dnsUDPPort := dnsPort
+	if c.Ports.DNSUDP != nil {
+		dnsUDPPort = b.ephemeralPortVal("ports.dns_udp", c.Ports.DNSUDP)
+	}
+	dnsTLSPort := b.ephemeralPortVal("ports.dns_tls", c.Ports.DNSTLS)
+	httpPort := b.ephemeralPortVal("ports.http", c.Ports.HTTP)
+	httpsPort := b.ephemeralPortVal("ports.https", c.Ports.HTTPS)
+	serverPort := b.ephemeralPortVal("ports.server", c.Ports.Server)
+	grpcPort := b.ephemeralPortVal("ports.grpc", c.Ports.GRPC)
+	grpcTLSPort := b.ephemeralPortVal("ports.grpc_tls", c.Ports.GRPCTLS)
+	serfPortLAN := b.ephemeralPortVal("ports.serf_lan", c.Ports.SerfLAN)
+	serfPortWAN := b.ephemeralPortVal("ports.serf_wan", c.Ports.SerfWAN)
 	proxyMinPort := b.portVal("ports.proxy_min_port", c.Ports.ProxyMinPort)
 	proxyMaxPort := b.portVal("ports.proxy_max_port", c.Ports.ProxyMaxPort)
 	sidecarMinPort := b.portVal("ports.sidecar_min_port", c.Ports.SidecarMinPort)
@@ -420,18 +786,47 @@ func (b *Builder) Build() (rt RuntimeConfig, err error) {
 	// traffic but cannot advertise it as the address on which the
 	// server can be reached.
 
+	// bind_interface and advertise_interface are shorthands for the
+	// address of the named interface and are resolved here so that users
+	// do not have to reach for sockaddr templates. The preferred address
+	// family can be chosen with interface_addr_preference.
+	ifacePreference := b.stringValWithDefault(c.InterfaceAddrPreference, "ipv4")
+	if ifacePreference != "ipv4" && ifacePreference != "ipv6" {
+		return RuntimeConfig{}, fmt.Errorf("interface_addr_preference cannot be %q. Must be \"ipv4\" or \"ipv6\"", ifacePreference)
+	}
+	if name := b.stringVal(c.BindInterface); name != "" {
+		if addr := b.stringVal(c.BindAddr); !ipaddr.IsAny(addr) {
+			return RuntimeConfig{}, fmt.Errorf("bind_addr and bind_interface cannot both be specified")
+		}
+		addr, err := interfaceAddr(name, ifacePreference)
+		if err != nil {
+			return RuntimeConfig{}, fmt.Errorf("bind_interface: %s", err)
+		}
+		c.BindAddr = &addr
+	}
+	if name := b.stringVal(c.AdvertiseInterface); name != "" {
+		if c.AdvertiseAddrLAN != nil {
+			return RuntimeConfig{}, fmt.Errorf("advertise_addr and advertise_interface cannot both be specified")
+		}
+		addr, err := interfaceAddr(name, ifacePreference)
+		if err != nil {
+			return RuntimeConfig{}, fmt.Errorf("advertise_interface: %s", err)
+		}
+		c.AdvertiseAddrLAN = &addr
+	}
+
 	bindAddrs := b.expandAddrs("bind_addr", c.BindAddr)
 	if len(bindAddrs) == 0 {
-		return RuntimeConfig{}, fmt.Errorf("bind_addr cannot be empty")
+		return RuntimeConfig{}, ValidationError{Field: "bind_addr", Constraint: "cannot be empty"}
 	}
 	if len(bindAddrs) > 1 {
 		return RuntimeConfig{}, fmt.Errorf("bind_addr cannot contain multiple addresses. Use 'addresses.{dns,http,https}' instead.")
 	}
 	if isUnixAddr(bindAddrs[0]) {
-		return RuntimeConfig{}, fmt.Errorf("bind_addr cannot be a unix socket")
+		return RuntimeConfig{}, ValidationError{Field: "bind_addr", Constraint: "cannot be a unix socket"}
 	}
 	if !isIPAddr(bindAddrs[0]) {
-		return RuntimeConfig{}, fmt.Errorf("bind_addr must be an ip address")
+		return RuntimeConfig{}, ValidationError{Field: "bind_addr", Constraint: "must be an ip address", Value: bindAddrs[0]}
 	}
 	if ipaddr.IsAny(b.stringVal(c.AdvertiseAddrLAN)) {
 		return RuntimeConfig{}, fmt.Errorf("Advertise address cannot be 0.0.0.0, :: or [::]")
@@ -441,8 +836,29 @@ func (b *Builder) Build() (rt RuntimeConfig, err error) {
 	}
 
 	bindAddr := bindAddrs[0].(*net.IPAddr)
+
+	// bind_addrs binds the RPC and Serf listeners to an explicit list of
+	// addresses on multi-homed hosts. The first entry becomes the primary
+	// bind address from which the advertise address and the other bind
+	// addresses are derived.
+	extraBindAddrs, err := expandBindAddrs(c.BindAddrs)
+	if err != nil {
+		return RuntimeConfig{}, err
+	}
+	if len(extraBindAddrs) > 0 {
+		if !ipaddr.IsAny(bindAddr) {
+			return RuntimeConfig{}, fmt.Errorf("bind_addr and bind_addrs cannot both be specified")
+		}
+		bindAddr = extraBindAddrs[0]
+	}
+
 	advertiseAddr := b.makeIPAddr(b.expandFirstIP("advertise_addr", c.AdvertiseAddrLAN), bindAddr)
 
+	advertiseSelection := b.stringValWithDefault(c.AdvertiseAddrSelection, "error")
+	if err := validateAdvertiseSelection(advertiseSelection); err != nil {
+		return RuntimeConfig{}, err
+	}
+
 	if ipaddr.IsAny(advertiseAddr) {
 
 		var addrtyp string
@@ -471,7 +887,11 @@ func (b *Builder) Build() (rt RuntimeConfig, err error) {
 			return RuntimeConfig{}, fmt.Errorf("No %s address found", addrtyp)
 		}
 		if len(advertiseAddrs) > 1 {
-			return RuntimeConfig{}, fmt.Errorf("Multiple %s addresses found. Please configure one with 'bind' and/or 'advertise'.", addrtyp)
+			selected, err := selectAdvertiseAddr(addrtyp, advertiseAddrs, advertiseSelection)
+			if err != nil {
+				return RuntimeConfig{}, err
+			}
+			advertiseAddrs = []*net.IPAddr{selected}
 		}
 		advertiseAddr = advertiseAddrs[0]
 	}
@@ -497,11 +917,11 @@ func (b *Builder) Build() (rt RuntimeConfig, err error) {
 	}
 	advertiseAddrLANIPv4 := b.makeIPAddr(b.expandFirstIP("advertise_addr_ipv4", c.AdvertiseAddrLANIPv4), advertiseAddrV4)
 	if advertiseAddrLANIPv4 != nil && advertiseAddrLANIPv4.IP.To4() == nil {
-		return RuntimeConfig{}, fmt.Errorf("advertise_addr_ipv4 must be an ipv4 address")
+		return RuntimeConfig{}, ValidationError{Field: "advertise_addr_ipv4", Constraint: "must be an ipv4 address"}
 	}
 	advertiseAddrLANIPv6 := b.makeIPAddr(b.expandFirstIP("advertise_addr_ipv6", c.AdvertiseAddrLANIPv6), advertiseAddrV6)
 	if advertiseAddrLANIPv6 != nil && advertiseAddrLANIPv6.IP.To4() != nil {
-		return RuntimeConfig{}, fmt.Errorf("advertise_addr_ipv6 must be an ipv6 address")
+		return RuntimeConfig{}, ValidationError{Field: "advertise_addr_ipv6", Constraint: "must be an ipv6 address"}
 	}
 
 	advertiseAddrWAN := b.makeIPAddr(b.expandFirstIP("advertise_addr_wan", c.AdvertiseAddrWAN), advertiseAddrLAN)
@@ -512,13 +932,25 @@ func (b *Builder) Build() (rt RuntimeConfig, err error) {
 	} else {
 		advertiseAddrWANv6 = advertiseAddrWAN
 	}
+	// Unless a separate WAN advertise address is configured, the
+	// per-family WAN addresses follow their LAN counterparts so that a
+	// dual-stack host advertising both families on the LAN gets the
+	// wan_ipv4/wan_ipv6 tagged addresses as well.
+	if c.AdvertiseAddrWAN == nil {
+		if advertiseAddrWANv4 == nil {
+			advertiseAddrWANv4 = advertiseAddrLANIPv4
+		}
+		if advertiseAddrWANv6 == nil {
+			advertiseAddrWANv6 = advertiseAddrLANIPv6
+		}
+	}
 	advertiseAddrWANIPv4 := b.makeIPAddr(b.expandFirstIP("advertise_addr_wan_ipv4", c.AdvertiseAddrWANIPv4), advertiseAddrWANv4)
 	if advertiseAddrWANIPv4 != nil && advertiseAddrWANIPv4.IP.To4() == nil {
-		return RuntimeConfig{}, fmt.Errorf("advertise_addr_wan_ipv4 must be an ipv4 address")
+		return RuntimeConfig{}, ValidationError{Field: "advertise_addr_wan_ipv4", Constraint: "must be an ipv4 address"}
 	}
 	advertiseAddrWANIPv6 := b.makeIPAddr(b.expandFirstIP("advertise_addr_wan_ipv6", c.AdvertiseAddrWANIPv6), advertiseAddrWANv6)
 	if advertiseAddrWANIPv6 != nil && advertiseAddrWANIPv6.IP.To4() != nil {
-		return RuntimeConfig{}, fmt.Errorf("advertise_addr_wan_ipv6 must be an ipv6 address")
+		return RuntimeConfig{}, ValidationError{Field: "advertise_addr_wan_ipv6", Constraint: "must be an ipv6 address"}
 	}
 
 	rpcAdvertiseAddr := &net.TCPAddr{IP: advertiseAddrLAN.IP, Port: serverPort}
@@ -531,22 +963,51 @@ func (b *Builder) Build() (rt RuntimeConfig, err error) {
 
 	// determine client addresses
 	clientAddrs := b.expandIPs("client_addr", c.ClientAddr)
-	dnsAddrs := b.makeAddrs(b.expandAddrs("addresses.dns", c.Addresses.DNS), clientAddrs, dnsPort)
+	clientAddrExclude := b.cidrsVal("client_addr_exclude", c.ClientAddrExclude)
+	if len(clientAddrExclude) > 0 {
+		var kept []*net.IPAddr
+		for _, a := range clientAddrs {
+			excluded := false
+			for _, network := range clientAddrExclude {
+				if network.Contains(a.IP) {
+					excluded = true
+					break
+				}
+			}
+			if !excluded {
+				kept = append(kept, a)
+			}
+		}
+		if len(clientAddrs) > 0 && len(kept) == 0 {
+			b.warn("client_addr_exclude removed all client addresses")
+		}
+		clientAddrs = kept
+	}
+	dnsAddrs := b.makeAddrs(b.expandAddrs("addresses.dns", c.Addresses.DNS), clientAddrs, dnsTCPPort)
 	httpAddrs := b.makeAddrs(b.expandAddrs("addresses.http", c.Addresses.HTTP), clientAddrs, httpPort)
 	httpsAddrs := b.makeAddrs(b.expandAddrs("addresses.https", c.Addresses.HTTPS), clientAddrs, httpsPort)
 	grpcAddrs := b.makeAddrs(b.expandAddrs("addresses.grpc", c.Addresses.GRPC), clientAddrs, grpcPort)
+	grpcTLSAddrs := b.makeAddrs(b.expandAddrs("addresses.grpc_tls", c.Addresses.GRPCTLS), clientAddrs, grpcTLSPort)
 
-	for _, a := range dnsAddrs {
+	for _, a := range b.makeAddrs(b.expandAddrs("addresses.dns", c.Addresses.DNS), clientAddrs, dnsUDPPort) {
 		if x, ok := a.(*net.TCPAddr); ok {
 			dnsAddrs = append(dnsAddrs, &net.UDPAddr{IP: x.IP, Port: x.Port})
 		}
 	}
 
+	// The DNS-over-TLS listener is TCP only.
+	var dnsTLSAddrs []net.Addr
+	for _, a := range b.makeAddrs(b.expandAddrs("addresses.dns", c.Addresses.DNS), clientAddrs, dnsTLSPort) {
+		if _, ok := a.(*net.TCPAddr); ok {
+			dnsTLSAddrs = append(dnsTLSAddrs, a)
+		}
+	}
+
 	// expand dns recursors
 	uniq := map[string]bool{}
 	dnsRecursors := []string{}
 	for _, r := range c.DNSRecursors {
-		x, err := template.Parse(r)
+		x, err := parseTemplate(r)
 		if err != nil {
 			return RuntimeConfig{}, fmt.Errorf("Invalid DNS recursor template %q: %s", r, err)
 		}
@@ -554,6 +1015,9 @@ func (b *Builder) Build() (rt RuntimeConfig, err error) {
 			if strings.HasPrefix(addr, "unix://") {
 				return RuntimeConfig{}, fmt.Errorf("DNS Recursors cannot be unix sockets: %s", addr)
 			}
+			if err := validateRecursor(addr); err != nil {
+				return RuntimeConfig{}, fmt.Errorf("Invalid DNS recursor %q: %s", addr, err)
+			}
 			if uniq[addr] {
 				continue
 			}
@@ -565,9 +1029,18 @@ func (b *Builder) Build() (rt RuntimeConfig, err error) {
 	datacenter := strings.ToLower(b.stringVal(c.Datacenter))
 	altDomain := b.stringVal(c.DNSAltDomain)
 
+	// Like datacenter names themselves, aliases are case folded.
+	var datacenterAliases map[string]string
+	if len(c.DatacenterAliases) > 0 {
+		datacenterAliases = make(map[string]string, len(c.DatacenterAliases))
+		for alias, dc := range c.DatacenterAliases {
+			datacenterAliases[strings.ToLower(alias)] = strings.ToLower(dc)
+		}
+	}
+
 	// Create the default set of tagged addresses.
 	if c.TaggedAddresses == nil {
-		c.TaggedAddresses = make(map[string]string)
+		c.TaggedAddresses = make(map[string]interface{})
 	}
 
 	c.TaggedAddresses[structs.TaggedAddressLAN] = advertiseAddrLAN.IP.String()
@@ -619,7 +1092,7 @@ func (b *Builder) Build() (rt RuntimeConfig, err error) {
 	var telemetryAllowedPrefixes, telemetryBlockedPrefixes []string
 	for _, rule := range c.Telemetry.PrefixFilter {
 		if rule == "" {
-			b.warn("Cannot have empty filter rule in prefix_filter")
+			b.warnc("telemetry-prefix-filter", "Cannot have empty filter rule in prefix_filter")
 			continue
 		}
 		switch rule[0] {
@@ -628,7 +1101,7 @@ func (b *Builder) Build() (rt RuntimeConfig, err error) {
 		case '-':
 			telemetryBlockedPrefixes = append(telemetryBlockedPrefixes, rule[1:])
 		default:
-			b.warn("Filter rule must begin with either '+' or '-': %q", rule)
+			b.warnc("telemetry-prefix-filter", "Filter rule must begin with either '+' or '-': %q", rule)
 		}
 	}
 
@@ -657,7 +1130,7 @@ func (b *Builder) Build() (rt RuntimeConfig, err error) {
 	for _, i := range c.AutoEncrypt.IPSAN {
 		ip := net.ParseIP(i)
 		if ip == nil {
-			b.warn(fmt.Sprintf("Cannot parse ip %q from AutoEncrypt.IPSAN", i))
+			b.warnc("auto-encrypt-ip-san", fmt.Sprintf("Cannot parse ip %q from AutoEncrypt.IPSAN", i))
 			continue
 		}
 		autoEncryptIPSAN = append(autoEncryptIPSAN, ip)
@@ -670,6 +1143,28 @@ func (b *Builder) Build() (rt RuntimeConfig, err error) {
 	}
 
 	autoConfig := b.autoConfigVal(c.AutoConfig)
+
+	// tls_bootstrap_mode is an umbrella over the auto_config and
+	// auto_encrypt sub-blocks so a typical agent only needs a single key.
+	// Explicitly configured legacy keys keep their meaning and win over the
+	// umbrella; they only produce a migration warning.
+	tlsBootstrapMode := b.stringVal(c.TLSBootstrapMode)
+	switch tlsBootstrapMode {
+	case "":
+	case "manual", "auto_config", "auto_encrypt":
+		if c.AutoConfig.Enabled != nil || c.AutoEncrypt.TLS != nil {
+			b.warnc("tls-bootstrap-mode-migration", "tls_bootstrap_mode = %q is set while the legacy auto_config.enabled or auto_encrypt.tls keys are still present. The legacy keys win; to finish the migration remove them and keep tls_bootstrap_mode.", tlsBootstrapMode)
+		}
+		if tlsBootstrapMode == "auto_config" && c.AutoConfig.Enabled == nil {
+			autoConfig.Enabled = true
+		}
+		if tlsBootstrapMode == "auto_encrypt" && c.AutoEncrypt.TLS == nil {
+			autoEncryptTLS = true
+		}
+	default:
+		return RuntimeConfig{}, fmt.Errorf("tls_bootstrap_mode: invalid value: %q. Valid values are 'manual', 'auto_config' and 'auto_encrypt'", tlsBootstrapMode)
+	}
+
 	if autoConfig.Enabled {
 		connectEnabled = true
 	}
@@ -716,7 +1211,7 @@ func (b *Builder) Build() (rt RuntimeConfig, err error) {
 	aclsEnabled := false
 	primaryDatacenter := strings.ToLower(b.stringVal(c.PrimaryDatacenter))
 	if c.ACLDatacenter != nil {
-		b.warn("The 'acl_datacenter' field is deprecated. Use the 'primary_datacenter' field instead.")
+		b.warnDeprecated("acl_datacenter", "The 'acl_datacenter' field is deprecated. Use the 'primary_datacenter' field instead.")
 
 		if primaryDatacenter == "" {
 			primaryDatacenter = strings.ToLower(b.stringVal(c.ACLDatacenter))
@@ -735,6 +1230,57 @@ func (b *Builder) Build() (rt RuntimeConfig, err error) {
 		primaryDatacenter = datacenter
 	}
 
+	// primary_datacenter_migration lets agents ride through a controlled
+	// rename of the primary datacenter. The effective primary follows the
+	// cutover time and the retired name keeps resolving through an
+	// implicit datacenter alias so peers can use either name.
+	var pdmOld, pdmNew string
+	var pdmCutover time.Time
+	if pdm := c.PrimaryDatacenterMigration; pdm.Old != nil || pdm.New != nil || pdm.CutoverTime != nil {
+		pdmOld = strings.ToLower(b.stringVal(pdm.Old))
+		pdmNew = strings.ToLower(b.stringVal(pdm.New))
+		switch {
+		case pdmOld == "" || pdmNew == "":
+			return RuntimeConfig{}, fmt.Errorf("primary_datacenter_migration: old and new are required")
+		case pdmOld == pdmNew:
+			return RuntimeConfig{}, fmt.Errorf("primary_datacenter_migration: old and new cannot be the same")
+		case pdm.CutoverTime == nil:
+			return RuntimeConfig{}, fmt.Errorf("primary_datacenter_migration: cutover_time is required")
+		}
+		if err := validateBasicName("primary_datacenter_migration.old", pdmOld, false); err != nil {
+			return RuntimeConfig{}, err
+		}
+		if err := validateBasicName("primary_datacenter_migration.new", pdmNew, false); err != nil {
+			return RuntimeConfig{}, err
+		}
+		var err error
+		pdmCutover, err = time.Parse(time.RFC3339, b.stringVal(pdm.CutoverTime))
+		if err != nil {
+			return RuntimeConfig{}, fmt.Errorf("primary_datacenter_migration: cutover_time must be a RFC3339 timestamp: %s", err)
+		}
+		if primaryDatacenter != pdmOld && primaryDatacenter != pdmNew {
+			return RuntimeConfig{}, fmt.Errorf("primary_datacenter_migration: primary_datacenter %q is neither the old nor the new name", primaryDatacenter)
+		}
+
+		effective, retired := pdmOld, pdmNew
+		if !time.Now().Before(pdmCutover) {
+			effective, retired = pdmNew, pdmOld
+			b.warn("primary_datacenter_migration: cutover at %s has passed, using new primary datacenter %q", pdmCutover.Format(time.RFC3339), pdmNew)
+		} else {
+			b.warn("primary_datacenter_migration: using old primary datacenter %q until cutover at %s", pdmOld, pdmCutover.Format(time.RFC3339))
+		}
+		primaryDatacenter = effective
+
+		if retired != datacenter {
+			if datacenterAliases == nil {
+				datacenterAliases = make(map[string]string)
+			}
+			if _, ok := datacenterAliases[retired]; !ok {
+				datacenterAliases[retired] = effective
+			}
+		}
+	}
+
 	enableTokenReplication := false
 	if c.ACLReplicationToken != nil {
 		enableTokenReplication = true
@@ -745,9 +1291,149 @@ func (b *Builder) Build() (rt RuntimeConfig, err error) {
 	enableRemoteScriptChecks := b.boolVal(c.EnableScriptChecks)
 	enableLocalScriptChecks := b.boolValWithDefault(c.EnableLocalScriptChecks, enableRemoteScriptChecks)
 
+	// Resolve the per-listener tls { } stanza. Each listener block inherits
+	// from tls.defaults, which itself falls back to the flat top-level cert
+	// and verify fields so existing configurations keep working.
+	coalesceStr := func(vals ...*string) *string {
+		for _, v := range vals {
+			if v != nil {
+				return v
+			}
+		}
+		return nil
+	}
+	coalesceBool := func(vals ...*bool) *bool {
+		for _, v := range vals {
+			if v != nil {
+				return v
+			}
+		}
+		return nil
+	}
+	// Named TLS profiles are resolved here so that a dangling reference
+	// fails at build time instead of when a connection is first made. The
+	// referencing block's own settings win over the profile's.
+	for name, profile := range c.TLS.Profiles {
+		if profile.Profile != nil {
+			return RuntimeConfig{}, fmt.Errorf("tls.profiles.%s.profile is not allowed: profiles cannot reference other profiles", name)
+		}
+		if profile.TLSMinVersion != nil {
+			return RuntimeConfig{}, fmt.Errorf("tls.profiles.%s.tls_min_version is only valid in tls.defaults", name)
+		}
+		if profile.TLSCipherSuites != nil {
+			return RuntimeConfig{}, fmt.Errorf("tls.profiles.%s.tls_cipher_suites is only valid in tls.defaults", name)
+		}
+	}
+	tlsProfileVal := func(field string, ref *string) (TLSProtocolConfig, error) {
+		if ref == nil {
+			return TLSProtocolConfig{}, nil
+		}
+		profile, ok := c.TLS.Profiles[*ref]
+		if !ok {
+			return TLSProtocolConfig{}, fmt.Errorf("%s references undefined tls profile %q", field, *ref)
+		}
+		return profile, nil
+	}
+	resolveTLSProfile := func(name string, block TLSProtocolConfig) (TLSProtocolConfig, error) {
+		if block.Profile == nil {
+			return block, nil
+		}
+		merged, err := tlsProfileVal(name+".profile", block.Profile)
+		if err != nil {
+			return TLSProtocolConfig{}, err
+		}
+		merged.CAFile = coalesceStr(block.CAFile, merged.CAFile)
+		merged.CAPath = coalesceStr(block.CAPath, merged.CAPath)
+		merged.CertFile = coalesceStr(block.CertFile, merged.CertFile)
+		merged.KeyFile = coalesceStr(block.KeyFile, merged.KeyFile)
+		merged.VerifyIncoming = coalesceBool(block.VerifyIncoming, merged.VerifyIncoming)
+		merged.VerifyOutgoing = coalesceBool(block.VerifyOutgoing, merged.VerifyOutgoing)
+		merged.VerifyServerHostname = coalesceBool(block.VerifyServerHostname, merged.VerifyServerHostname)
+		return merged, nil
+	}
+	tlsHTTPS, err := resolveTLSProfile("tls.https", c.TLS.HTTPS)
+	if err != nil {
+		return RuntimeConfig{}, err
+	}
+	tlsGRPC, err := resolveTLSProfile("tls.grpc", c.TLS.GRPC)
+	if err != nil {
+		return RuntimeConfig{}, err
+	}
+	tlsChecks, err := tlsProfileVal("tls.checks_profile", c.TLS.ChecksProfile)
+	if err != nil {
+		return RuntimeConfig{}, err
+	}
+	tlsWatches, err := tlsProfileVal("tls.watches_profile", c.TLS.WatchesProfile)
+	if err != nil {
+		return RuntimeConfig{}, err
+	}
+	for _, block := range []struct {
+		name string
+		cfg  TLSProtocolConfig
+	}{
+		{"tls.defaults", c.TLS.Defaults},
+		{"tls.https", tlsHTTPS},
+		{"tls.internal_rpc", c.TLS.InternalRPC},
+		{"tls.grpc", tlsGRPC},
+		{"tls.wan", c.TLS.WAN},
+	} {
+		if (block.cfg.CertFile == nil) != (block.cfg.KeyFile == nil) {
+			return RuntimeConfig{}, fmt.Errorf("%s.cert_file and %s.key_file must both be set", block.name, block.name)
+		}
+		if block.name == "tls.defaults" || block.name == "tls.internal_rpc" || block.name == "tls.wan" {
+			if block.cfg.Profile != nil {
+				return RuntimeConfig{}, fmt.Errorf("%s.profile is only valid in tls.https or tls.grpc", block.name)
+			}
+			continue
+		}
+		if block.cfg.VerifyOutgoing != nil {
+			return RuntimeConfig{}, fmt.Errorf("%s.verify_outgoing is only valid in tls.defaults, tls.internal_rpc or tls.wan", block.name)
+		}
+		if block.cfg.VerifyServerHostname != nil {
+			return RuntimeConfig{}, fmt.Errorf("%s.verify_server_hostname is only valid in tls.defaults, tls.internal_rpc or tls.wan", block.name)
+		}
+	}
+	if c.TLS.WAN.VerifyIncoming != nil {
+		return RuntimeConfig{}, fmt.Errorf("tls.wan.verify_incoming is not supported: cross-datacenter connections delivered through mesh gateways always verify the client certificate")
+	}
+	for _, block := range []struct {
+		name string
+		cfg  TLSProtocolConfig
+	}{
+		{"tls.https", tlsHTTPS},
+		{"tls.internal_rpc", c.TLS.InternalRPC},
+		{"tls.grpc", tlsGRPC},
+		{"tls.wan", c.TLS.WAN},
+	} {
+		if block.cfg.TLSMinVersion != nil {
+			return RuntimeConfig{}, fmt.Errorf("%s.tls_min_version is only valid in tls.defaults", block.name)
+		}
+		if block.cfg.TLSCipherSuites != nil {
+			return RuntimeConfig{}, fmt.Errorf("%s.tls_cipher_suites is only valid in tls.defaults", block.name)
+		}
+	}
+	tlsCAFile := coalesceStr(c.TLS.Defaults.CAFile, c.CAFile)
+	tlsCAPath := coalesceStr(c.TLS.Defaults.CAPath, c.CAPath)
+	tlsCertFile := coalesceStr(c.TLS.Defaults.CertFile, c.CertFile)
+	tlsKeyFile := coalesceStr(c.TLS.Defaults.KeyFile, c.KeyFile)
+	tlsMinVersion := coalesceStr(c.TLS.Defaults.TLSMinVersion, c.TLSMinVersion)
+	tlsCipherSuites := coalesceStr(c.TLS.Defaults.TLSCipherSuites, c.TLSCipherSuites)
+	tlsVerifyIncoming := coalesceBool(c.TLS.Defaults.VerifyIncoming, c.VerifyIncoming)
+	tlsProtocol := func(block TLSProtocolConfig) tlsutil.ProtocolConfig {
+		return tlsutil.ProtocolConfig{
+			CAFile:               b.stringVal(block.CAFile),
+			CAPath:               b.stringVal(block.CAPath),
+			CertFile:             b.stringVal(block.CertFile),
+			KeyFile:              b.stringVal(block.KeyFile),
+			VerifyIncoming:       block.VerifyIncoming,
+			VerifyOutgoing:       block.VerifyOutgoing,
+			VerifyServerHostname: block.VerifyServerHostname,
+		}
+	}
+
 	// VerifyServerHostname implies VerifyOutgoing
-	verifyServerName := b.boolVal(c.VerifyServerHostname)
-	verifyOutgoing := b.boolVal(c.VerifyOutgoing)
+	verifyServerName := b.boolVal(coalesceBool(c.TLS.InternalRPC.VerifyServerHostname, c.TLS.Defaults.VerifyServerHostname, c.VerifyServerHostname))
+	verifyOutgoing := b.boolVal(coalesceBool(c.TLS.InternalRPC.VerifyOutgoing, c.TLS.Defaults.VerifyOutgoing, c.VerifyOutgoing))
 	if verifyServerName {
 		// Setting only verify_server_hostname is documented to imply
 		// verify_outgoing. If it doesn't then we risk sending communication over TCP
@@ -761,15 +1447,22 @@ func (b *Builder) Build() (rt RuntimeConfig, err error) {
 
 	if len(c.ConfigEntries.Bootstrap) > 0 {
 		for i, rawEntry := range c.ConfigEntries.Bootstrap {
+			source := "config"
+			if i < len(configEntrySources) {
+				source = configEntrySources[i]
+			}
 			entry, err := structs.DecodeConfigEntry(rawEntry)
 			if err != nil {
-				return RuntimeConfig{}, fmt.Errorf("config_entries.bootstrap[%d]: %s", i, err)
+				return RuntimeConfig{}, fmt.Errorf("config_entries.bootstrap[%d]: %s (in %s)", i, err, source)
 			}
 			if err := entry.Normalize(); err != nil {
-				return RuntimeConfig{}, fmt.Errorf("config_entries.bootstrap[%d]: %s", i, err)
+				return RuntimeConfig{}, fmt.Errorf("config_entries.bootstrap[%d]: %s (in %s)", i, err, source)
 			}
 			if err := entry.Validate(); err != nil {
-				return RuntimeConfig{}, fmt.Errorf("config_entries.bootstrap[%d]: %s", i, err)
+				return RuntimeConfig{}, fmt.Errorf("config_entries.bootstrap[%d]: %s (in %s)", i, err, source)
+			}
+			if ixn, ok := entry.(*structs.ServiceIntentionsConfigEntry); ok {
+				b.warnBootstrapIntentions(ixn)
 			}
 			configEntries = append(configEntries, entry)
 		}
@@ -786,34 +1479,70 @@ func (b *Builder) Build() (rt RuntimeConfig, err error) {
 
 	// Handle Deprecated UI config fields
 	if c.UI != nil {
-		b.warn("The 'ui' field is deprecated. Use the 'ui_config.enabled' field instead.")
+		b.warnDeprecated("ui", "The 'ui' field is deprecated. Use the 'ui_config.enabled' field instead.")
 		if c.UIConfig.Enabled == nil {
 			c.UIConfig.Enabled = c.UI
 		}
 	}
 	if c.UIDir != nil {
-		b.warn("The 'ui_dir' field is deprecated. Use the 'ui_config.dir' field instead.")
+		b.warnDeprecated("ui_dir", "The 'ui_dir' field is deprecated. Use the 'ui_config.dir' field instead.")
 		if c.UIConfig.Dir == nil {
 			c.UIConfig.Dir = c.UIDir
 		}
 	}
 	if c.UIContentPath != nil {
-		b.warn("The 'ui_content_path' field is deprecated. Use the 'ui_config.content_path' field instead.")
+		b.warnDeprecated("ui_content_path", "The 'ui_content_path' field is deprecated. Use the 'ui_config.content_path' field instead.")
 		if c.UIConfig.ContentPath == nil {
 			c.UIConfig.ContentPath = c.UIContentPath
 		}
 	}
 
+	// Raft tuning. A profile provides a consistent set of values for the
+	// individual raft_* settings so operators don't have to pick them by
+	// hand; explicitly configured values still take precedence.
+	raftSnapshotThreshold := b.intVal(c.RaftSnapshotThreshold)
+	raftTrailingLogs := b.intVal(c.RaftTrailingLogs)
+	raftTuningProfile := b.stringVal(c.RaftTuningProfile)
+	switch raftTuningProfile {
+	case "", "default":
+	case "write-heavy":
+		if c.RaftSnapshotThreshold == nil {
+			raftSnapshotThreshold = 65536
+		}
+		if c.RaftTrailingLogs == nil {
+			raftTrailingLogs = 131072
+		}
+	case "low-memory":
+		if c.RaftSnapshotThreshold == nil {
+			raftSnapshotThreshold = 4096
+		}
+		if c.RaftTrailingLogs == nil {
+			raftTrailingLogs = 8192
+		}
+	default:
+		return RuntimeConfig{}, fmt.Errorf("raft_tuning_profile: invalid value: %q. Valid values are 'default', 'write-heavy' and 'low-memory'", raftTuningProfile)
+	}
+
 	// ----------------------------------------------------------------
 	// build runtime config
 	//
 	dataDir := b.stringVal(c.DataDir)
+
+	// Resolve the servers file against the data directory so that a bare
+	// file name lands next to the rest of the agent state.
+	serversFile := b.stringVal(c.ServersFile)
+	if serversFile != "" && !filepath.IsAbs(serversFile) && dataDir != "" {
+		serversFile = filepath.Join(dataDir, serversFile)
+	}
+
 	rt = RuntimeConfig{
 		// non-user configurable values
 		ACLDisabledTTL:             b.durationVal("acl.disabled_ttl", c.ACL.DisabledTTL),
 		AEInterval:                 b.durationVal("ae_interval", c.AEInterval),
 		CheckDeregisterIntervalMin: b.durationVal("check_deregister_interval_min", c.CheckDeregisterIntervalMin),
 		CheckReapInterval:          b.durationVal("check_reap_interval", c.CheckReapInterval),
+		DeprecatedKeysInUse:        b.deprecatedKeysVal(),
+		Hash:                       configHash,
 		Revision:                   b.stringVal(c.Revision),
 		SegmentLimit:               b.intVal(c.SegmentLimit),
 		SegmentNameLimit:           b.intVal(c.SegmentNameLimit),
@@ -881,6 +1610,7 @@ func (b *Builder) Build() (rt RuntimeConfig, err error) {
 		DNSAllowStale:         b.boolVal(c.DNS.AllowStale),
 		DNSARecordLimit:       b.intVal(c.DNS.ARecordLimit),
 		DNSDisableCompression: b.boolVal(c.DNS.DisableCompression),
+		DNSDOHPath:            b.stringVal(c.DNS.DOHPath),
 		DNSDomain:             b.stringVal(c.DNSDomain),
 		DNSAltDomain:          altDomain,
 		DNSEnableTruncate:     b.boolVal(c.DNS.EnableTruncate),
@@ -892,21 +1622,32 @@ func (b *Builder) Build() (rt RuntimeConfig, err error) {
 		DNSRecursors:          dnsRecursors,
 		DNSServiceTTL:         dnsServiceTTL,
 		DNSSOA:                soa,
+		DNSTLSAddrs:           dnsTLSAddrs,
+		DNSTLSCertFile:        b.stringVal(c.DNS.TLS.CertFile),
+		DNSTLSKeyFile:         b.stringVal(c.DNS.TLS.KeyFile),
+		DNSTLSPort:            dnsTLSPort,
 		DNSUDPAnswerLimit:     b.intVal(c.DNS.UDPAnswerLimit),
 		DNSNodeMetaTXT:        b.boolValWithDefault(c.DNS.NodeMetaTXT, true),
+		DNSNodeMetaTXTKeys:    c.DNS.NodeMetaTXTKeys,
+		DNSTagAllowlist:       b.dnsTagAllowlistVal(c.DNS.TagAllowlist),
 		DNSUseCache:           b.boolVal(c.DNS.UseCache),
 		DNSCacheMaxAge:        b.durationVal("dns_config.cache_max_age", c.DNS.CacheMaxAge),
 
 		// HTTP
-		HTTPPort:            httpPort,
-		HTTPSPort:           httpsPort,
-		HTTPAddrs:           httpAddrs,
-		HTTPSAddrs:          httpsAddrs,
-		HTTPBlockEndpoints:  c.HTTPConfig.BlockEndpoints,
-		HTTPMaxHeaderBytes:  b.intVal(c.HTTPConfig.MaxHeaderBytes),
-		HTTPResponseHeaders: c.HTTPConfig.ResponseHeaders,
-		AllowWriteHTTPFrom:  b.cidrsVal("allow_write_http_from", c.HTTPConfig.AllowWriteHTTPFrom),
-		HTTPUseCache:        b.boolValWithDefault(c.HTTPConfig.UseCache, true),
+		HTTPPort:                        httpPort,
+		HTTPSPort:                       httpsPort,
+		HTTPAddrs:                       httpAddrs,
+		HTTPSAddrs:                      httpsAddrs,
+		HTTPBlockEndpoints:              c.HTTPConfig.BlockEndpoints,
+		HTTPBlockEndpointRules:          b.blockEndpointRulesVal(c.HTTPConfig.BlockEndpointRules),
+		HTTPDisableLegacyEndpoints:      b.disableLegacyEndpointsVal(c.HTTPConfig.DisableLegacyEndpoints),
+		HTTPMaxHeaderBytes:              b.intVal(c.HTTPConfig.MaxHeaderBytes),
+		HTTPProxyProtocolEnabled:        b.boolVal(c.HTTPConfig.ProxyProtocol.Enabled),
+		HTTPProxyProtocolAllowedSources: b.cidrsVal("http_config.proxy_protocol.allowed_sources", c.HTTPConfig.ProxyProtocol.AllowedSources),
+		HTTPRequestIDHeader:             b.requestIDHeaderVal(c.HTTPConfig.RequestIDHeader),
+		HTTPResponseHeaders:             c.HTTPConfig.ResponseHeaders,
+		AllowWriteHTTPFrom:              b.cidrsVal("allow_write_http_from", c.HTTPConfig.AllowWriteHTTPFrom),
+		HTTPUseCache:                    b.boolValWithDefault(c.HTTPConfig.UseCache, true),
 
 		// Telemetry
 		Telemetry: lib.TelemetryConfig{
@@ -927,6 +1668,7 @@ func (b *Builder) Build() (rt RuntimeConfig, err error) {
 			DisableHostname:                    b.boolVal(c.Telemetry.DisableHostname),
 			DogstatsdAddr:                      b.stringVal(c.Telemetry.DogstatsdAddr),
 			DogstatsdTags:                      c.Telemetry.DogstatsdTags,
+			EnableDeprecatedConfigMetrics:      b.boolVal(c.Telemetry.EnableDeprecatedConfigMetrics),
 			FilterDefault:                      b.boolVal(c.Telemetry.FilterDefault),
 			AllowedPrefixes:                    telemetryAllowedPrefixes,
 			BlockedPrefixes:                    telemetryBlockedPrefixes,
@@ -940,9 +1682,11 @@ func (b *Builder) Build() (rt RuntimeConfig, err error) {
 
 		// Agent
 		AdvertiseAddrLAN:          advertiseAddrLAN,
+		AdvertiseAddrSelection:    advertiseSelection,
 		AdvertiseAddrWAN:          advertiseAddrWAN,
 		AdvertiseReconnectTimeout: b.durationVal("advertise_reconnect_timeout", c.AdvertiseReconnectTimeout),
 		BindAddr:                  bindAddr,
+		BindAddrs:                 extraBindAddrs,
 		Bootstrap:                 b.boolVal(c.Bootstrap),
 		BootstrapExpect:           b.intVal(c.BootstrapExpect),
 		Cache: cache.Options{
@@ -953,19 +1697,29 @@ func (b *Builder) Build() (rt RuntimeConfig, err error) {
 				c.Cache.EntryFetchMaxBurst, cache.DefaultEntryFetchMaxBurst,
 			),
 		},
-		CAFile:                                 b.stringVal(c.CAFile),
-		CAPath:                                 b.stringVal(c.CAPath),
-		CertFile:                               b.stringVal(c.CertFile),
+		Tracing: b.tracingVal(c.Tracing),
+
+		CAFile:                                 b.stringVal(tlsCAFile),
+		CAFileContents:                         b.fileContentsVal("ca_file_contents", c.CAFileContents),
+		CAPath:                                 b.stringVal(tlsCAPath),
+		CertFile:                               b.stringVal(tlsCertFile),
+		CertFileContents:                       b.fileContentsVal("cert_file_contents", c.CertFileContents),
+		CheckExecLimitsMaxConcurrent:           b.intVal(c.CheckExecLimits.MaxConcurrent),
+		CheckExecLimitsCPUSeconds:              b.intVal(c.CheckExecLimits.CPUSeconds),
+		CheckExecLimitsMemoryBytes:             b.int64Val(c.CheckExecLimits.MemoryBytes),
+		CheckExecLimitsNice:                    b.intVal(c.CheckExecLimits.Nice),
 		CheckUpdateInterval:                    b.durationVal("check_update_interval", c.CheckUpdateInterval),
 		CheckOutputMaxSize:                     b.intValWithDefault(c.CheckOutputMaxSize, 4096),
 		Checks:                                 checks,
 		ClientAddrs:                            clientAddrs,
+		ClientAddrExclude:                      clientAddrExclude,
 		ConfigEntryBootstrap:                   configEntries,
 		AutoEncryptTLS:                         autoEncryptTLS,
 		AutoEncryptDNSSAN:                      autoEncryptDNSSAN,
 		AutoEncryptIPSAN:                       autoEncryptIPSAN,
 		AutoEncryptAllowTLS:                    autoEncryptAllowTLS,
 		AutoConfig:                             autoConfig,
+		AutoReloadConfig:                       b.boolVal(c.AutoReloadConfig),
 		ConnectEnabled:                         connectEnabled,
 		ConnectCAProvider:                      connectCAProvider,
 		ConnectCAConfig:                        connectCAConfig,
@@ -973,10 +1727,12 @@ func (b *Builder) Build() (rt RuntimeConfig, err error) {
 		ConnectSidecarMinPort:                  sidecarMinPort,
 		ConnectSidecarMaxPort:                  sidecarMaxPort,
 		ConnectTestCALeafRootChangeSpread:      b.durationVal("connect.test_ca_leaf_root_change_spread", c.Connect.TestCALeafRootChangeSpread),
+		CRIEndpoint:                            criEndpoint,
 		ExposeMinPort:                          exposeMinPort,
 		ExposeMaxPort:                          exposeMaxPort,
 		DataDir:                                dataDir,
 		Datacenter:                             datacenter,
+		DatacenterAliases:                      datacenterAliases,
 		DefaultQueryTime:                       b.durationVal("default_query_time", c.DefaultQueryTime),
 		DevMode:                                b.boolVal(b.opts.DevMode),
 		DisableAnonymousSignature:              b.boolVal(c.DisableAnonymousSignature),
@@ -987,7 +1743,14 @@ func (b *Builder) Build() (rt RuntimeConfig, err error) {
 		DisableRemoteExec:                      b.boolVal(c.DisableRemoteExec),
 		DisableUpdateCheck:                     b.boolVal(c.DisableUpdateCheck),
 		DiscardCheckOutput:                     b.boolVal(c.DiscardCheckOutput),
+		DiscoveryMaxResultsDNS:                 b.intVal(c.DiscoveryMaxResults.DNS),
+		DiscoveryMaxResultsHTTP:                b.intVal(c.DiscoveryMaxResults.HTTP),
 		DiscoveryMaxStale:                      b.durationVal("discovery_max_stale", c.DiscoveryMaxStale),
+		DockerEndpointHost:                     b.stringVal(c.DockerEndpoint.Host),
+		DockerEndpointCAFile:                   b.stringVal(c.DockerEndpoint.CAFile),
+		DockerEndpointCertFile:                 b.stringVal(c.DockerEndpoint.CertFile),
+		DockerEndpointKeyFile:                  b.stringVal(c.DockerEndpoint.KeyFile),
+		DockerEndpointTLSSkipVerify:            b.boolVal(c.DockerEndpoint.TLSSkipVerify),
 		EnableAgentTLSForChecks:                b.boolVal(c.EnableAgentTLSForChecks),
 		EnableCentralServiceConfig:             b.boolVal(c.EnableCentralServiceConfig),
 		EnableDebug:                            b.boolVal(c.EnableDebug),
@@ -996,14 +1759,25 @@ func (b *Builder) Build() (rt RuntimeConfig, err error) {
 		EncryptKey:                             b.stringVal(c.EncryptKey),
 		EncryptVerifyIncoming:                  b.boolVal(c.EncryptVerifyIncoming),
 		EncryptVerifyOutgoing:                  b.boolVal(c.EncryptVerifyOutgoing),
+		ExposeAutoConfigStatus:                 b.boolVal(c.ExposeAutoConfigStatus),
+		EphemeralPorts:                         b.ephemeralPorts,
+		GRPCEnableConfigService:                b.boolVal(c.GRPC.EnableConfigService),
 		GRPCPort:                               grpcPort,
 		GRPCAddrs:                              grpcAddrs,
+		GRPCTLSPort:                            grpcTLSPort,
+		GRPCTLSAddrs:                           grpcTLSAddrs,
 		HTTPMaxConnsPerClient:                  b.intVal(c.Limits.HTTPMaxConnsPerClient),
 		HTTPSHandshakeTimeout:                  b.durationVal("limits.https_handshake_timeout", c.Limits.HTTPSHandshakeTimeout),
-		KeyFile:                                b.stringVal(c.KeyFile),
+		KeyFile:                                b.stringVal(tlsKeyFile),
+		KeyFileContents:                        b.fileContentsVal("key_file_contents", c.KeyFileContents),
 		KVMaxValueSize:                         b.uint64Val(c.Limits.KVMaxValueSize),
+		KVQuotas:                               b.kvQuotasVal(c.Limits.KVQuota),
 		LeaveDrainTime:                         b.durationVal("performance.leave_drain_time", c.Performance.LeaveDrainTime),
 		LeaveOnTerm:                            leaveOnTerm,
+		ListenerOptionsDNS:                     b.listenerOptionsVal("listener_options.dns", c.ListenerOptions.DNS),
+		ListenerOptionsGRPC:                    b.listenerOptionsVal("listener_options.grpc", c.ListenerOptions.GRPC),
+		ListenerOptionsHTTP:                    b.listenerOptionsVal("listener_options.http", c.ListenerOptions.HTTP),
+		ListenerOptionsHTTPS:                   b.listenerOptionsVal("listener_options.https", c.ListenerOptions.HTTPS),
 		Logging: logging.Config{
 			LogLevel:          b.stringVal(c.LogLevel),
 			LogJSON:           b.boolVal(c.LogJSON),
@@ -1014,80 +1788,127 @@ func (b *Builder) Build() (rt RuntimeConfig, err error) {
 			LogRotateBytes:    b.intVal(c.LogRotateBytes),
 			LogRotateMaxFiles: b.intVal(c.LogRotateMaxFiles),
 		},
-		MaxQueryTime:                b.durationVal("max_query_time", c.MaxQueryTime),
-		NodeID:                      types.NodeID(b.stringVal(c.NodeID)),
-		NodeMeta:                    c.NodeMeta,
-		NodeName:                    b.nodeName(c.NodeName),
-		ReadReplica:                 b.boolVal(c.ReadReplica),
-		PidFile:                     b.stringVal(c.PidFile),
-		PrimaryDatacenter:           primaryDatacenter,
-		PrimaryGateways:             b.expandAllOptionalAddrs("primary_gateways", c.PrimaryGateways),
-		PrimaryGatewaysInterval:     b.durationVal("primary_gateways_interval", c.PrimaryGatewaysInterval),
-		RPCAdvertiseAddr:            rpcAdvertiseAddr,
-		RPCBindAddr:                 rpcBindAddr,
-		RPCHandshakeTimeout:         b.durationVal("limits.rpc_handshake_timeout", c.Limits.RPCHandshakeTimeout),
-		RPCHoldTimeout:              b.durationVal("performance.rpc_hold_timeout", c.Performance.RPCHoldTimeout),
-		RPCMaxBurst:                 b.intVal(c.Limits.RPCMaxBurst),
-		RPCMaxConnsPerClient:        b.intVal(c.Limits.RPCMaxConnsPerClient),
-		RPCProtocol:                 b.intVal(c.RPCProtocol),
-		RPCRateLimit:                rate.Limit(b.float64Val(c.Limits.RPCRate)),
-		RPCConfig:                   consul.RPCConfig{EnableStreaming: b.boolVal(c.RPC.EnableStreaming)},
-		RaftProtocol:                b.intVal(c.RaftProtocol),
-		RaftSnapshotThreshold:       b.intVal(c.RaftSnapshotThreshold),
-		RaftSnapshotInterval:        b.durationVal("raft_snapshot_interval", c.RaftSnapshotInterval),
-		RaftTrailingLogs:            b.intVal(c.RaftTrailingLogs),
-		ReconnectTimeoutLAN:         b.durationVal("reconnect_timeout", c.ReconnectTimeoutLAN),
-		ReconnectTimeoutWAN:         b.durationVal("reconnect_timeout_wan", c.ReconnectTimeoutWAN),
-		RejoinAfterLeave:            b.boolVal(c.RejoinAfterLeave),
-		RetryJoinIntervalLAN:        b.durationVal("retry_interval", c.RetryJoinIntervalLAN),
-		RetryJoinIntervalWAN:        b.durationVal("retry_interval_wan", c.RetryJoinIntervalWAN),
-		RetryJoinLAN:                b.expandAllOptionalAddrs("retry_join", c.RetryJoinLAN),
-		RetryJoinMaxAttemptsLAN:     b.intVal(c.RetryJoinMaxAttemptsLAN),
-		RetryJoinMaxAttemptsWAN:     b.intVal(c.RetryJoinMaxAttemptsWAN),
-		RetryJoinWAN:                b.expandAllOptionalAddrs("retry_join_wan", c.RetryJoinWAN),
-		SegmentName:                 b.stringVal(c.SegmentName),
-		Segments:                    segments,
-		SerfAdvertiseAddrLAN:        serfAdvertiseAddrLAN,
-		SerfAdvertiseAddrWAN:        serfAdvertiseAddrWAN,
-		SerfAllowedCIDRsLAN:         serfAllowedCIDRSLAN,
-		SerfAllowedCIDRsWAN:         serfAllowedCIDRSWAN,
-		SerfBindAddrLAN:             serfBindAddrLAN,
-		SerfBindAddrWAN:             serfBindAddrWAN,
-		SerfPortLAN:                 serfPortLAN,
-		SerfPortWAN:                 serfPortWAN,
-		ServerMode:                  b.boolVal(c.ServerMode),
-		ServerName:                  b.stringVal(c.ServerName),
-		ServerPort:                  serverPort,
-		Services:                    services,
-		SessionTTLMin:               b.durationVal("session_ttl_min", c.SessionTTLMin),
-		SkipLeaveOnInt:              skipLeaveOnInt,
-		StartJoinAddrsLAN:           b.expandAllOptionalAddrs("start_join", c.StartJoinAddrsLAN),
-		StartJoinAddrsWAN:           b.expandAllOptionalAddrs("start_join_wan", c.StartJoinAddrsWAN),
-		TLSCipherSuites:             b.tlsCipherSuites("tls_cipher_suites", c.TLSCipherSuites),
-		TLSMinVersion:               b.stringVal(c.TLSMinVersion),
-		TLSPreferServerCipherSuites: b.boolVal(c.TLSPreferServerCipherSuites),
-		TaggedAddresses:             c.TaggedAddresses,
-		TranslateWANAddrs:           b.boolVal(c.TranslateWANAddrs),
-		TxnMaxReqLen:                b.uint64Val(c.Limits.TxnMaxReqLen),
-		UIConfig:                    b.uiConfigVal(c.UIConfig),
-		UnixSocketGroup:             b.stringVal(c.UnixSocket.Group),
-		UnixSocketMode:              b.stringVal(c.UnixSocket.Mode),
-		UnixSocketUser:              b.stringVal(c.UnixSocket.User),
-		VerifyIncoming:              b.boolVal(c.VerifyIncoming),
-		VerifyIncomingHTTPS:         b.boolVal(c.VerifyIncomingHTTPS),
-		VerifyIncomingRPC:           b.boolVal(c.VerifyIncomingRPC),
-		VerifyOutgoing:              verifyOutgoing,
-		VerifyServerHostname:        verifyServerName,
-		Watches:                     c.Watches,
+		LoggingSlowQueryThreshold: b.durationVal("logging.slow_query_threshold", c.Logging.SlowQueryThreshold),
+
+		MaintenanceScheduleCron:     b.stringVal(c.MaintenanceSchedule.Cron),
+		MaintenanceScheduleDuration: b.durationVal("maintenance_schedule.duration", c.MaintenanceSchedule.Duration),
+		MaintenanceScheduleReason:   b.stringVal(c.MaintenanceSchedule.Reason),
+
+		MaxQueryTime:                          b.durationVal("max_query_time", c.MaxQueryTime),
+		MDNSAdvertiseAllowServer:              b.boolVal(c.MDNSAdvertise.AllowServer),
+		MDNSAdvertiseEnabled:                  b.boolVal(c.MDNSAdvertise.Enabled),
+		MDNSAdvertiseInterface:                b.stringVal(c.MDNSAdvertise.Interface),
+		MDNSAdvertiseService:                  b.stringVal(c.MDNSAdvertise.Service),
+		NodeID:                                types.NodeID(b.stringVal(c.NodeID)),
+		NodeMeta:                              b.nodeMetaVal(c.NodeMeta),
+		NodeName:                              b.nodeName(c.NodeName),
+		OutboundProxyURL:                      b.stringVal(c.OutboundProxy.URL),
+		OutboundProxyNoProxy:                  c.OutboundProxy.NoProxy,
+		OutboundProxyCredentialsFile:          b.stringVal(c.OutboundProxy.CredentialsFile),
+		ReadReplica:                           b.boolVal(c.ReadReplica),
+		ReservedPorts:                         b.portRangeVals("limits.reserved_ports", c.Limits.ReservedPorts),
+		PidFile:                               b.stringVal(c.PidFile),
+		PrimaryDatacenter:                     primaryDatacenter,
+		PrimaryDatacenterMigrationOld:         pdmOld,
+		PrimaryDatacenterMigrationNew:         pdmNew,
+		PrimaryDatacenterMigrationCutoverTime: pdmCutover,
+		PrimaryGateways:                       b.expandAllOptionalAddrs("primary_gateways", c.PrimaryGateways),
+		PrimaryGatewaysInterval:               b.durationVal("primary_gateways_interval", c.PrimaryGatewaysInterval),
+		RPCAdvertiseAddr:                      rpcAdvertiseAddr,
+		RPCBindAddr:                           rpcBindAddr,
+		RPCHandshakeTimeout:                   b.durationVal("limits.rpc_handshake_timeout", c.Limits.RPCHandshakeTimeout),
+		RPCHoldTimeout:                        b.durationVal("performance.rpc_hold_timeout", c.Performance.RPCHoldTimeout),
+		RPCMaxBurst:                           b.intVal(c.Limits.RPCMaxBurst),
+		RPCMaxConnsPerClient:                  b.intVal(c.Limits.RPCMaxConnsPerClient),
+		RPCProtocol:                           b.intVal(c.RPCProtocol),
+		RPCRateLimit:                          rate.Limit(b.float64Val(c.Limits.RPCRate)),
+		RPCConfig:                             consul.RPCConfig{EnableStreaming: b.boolVal(c.RPC.EnableStreaming)},
+		RaftProtocol:                          b.intVal(c.RaftProtocol),
+		RaftSnapshotThreshold:                 raftSnapshotThreshold,
+		RaftSnapshotInterval:                  b.durationVal("raft_snapshot_interval", c.RaftSnapshotInterval),
+		RaftTrailingLogs:                      raftTrailingLogs,
+		RaftTrailingLogsSafetyFactor:          b.float64ValWithDefault(c.RaftTrailingLogsSafetyFactor, 1.25),
+		RaftTuningProfile:                     raftTuningProfile,
+		ReconnectTimeoutLAN:                   b.durationVal("reconnect_timeout", c.ReconnectTimeoutLAN),
+		ReconnectTimeoutWAN:                   b.durationVal("reconnect_timeout_wan", c.ReconnectTimeoutWAN),
+		RejoinAfterLeave:                      b.boolVal(c.RejoinAfterLeave),
+		RetryJoinIntervalLAN:                  b.durationVal("retry_interval", c.RetryJoinIntervalLAN),
+		RetryJoinIntervalWAN:                  b.durationVal("retry_interval_wan", c.RetryJoinIntervalWAN),
+		RetryJoinLAN:                          b.expandAllOptionalAddrs("retry_join", c.RetryJoinLAN),
+		RetryJoinMaxAttemptsLAN:               b.intVal(c.RetryJoinMaxAttemptsLAN),
+		RetryJoinMaxAttemptsWAN:               b.intVal(c.RetryJoinMaxAttemptsWAN),
+		RetryJoinWAN:                          b.expandAllOptionalAddrs("retry_join_wan", c.RetryJoinWAN),
+		RuntimeGOGC:                           b.intVal(c.Runtime.GOGC),
+		RuntimeGOMAXPROCS:                     b.intVal(c.Runtime.GOMAXPROCS),
+		RuntimeMemoryLimit:                    b.int64Val(c.Runtime.MemoryLimit),
+		SanitizeRedactKeys:                    c.Sanitize.RedactKeys,
+		SanitizeRedactNone:                    b.boolVal(c.Sanitize.RedactNone),
+		SegmentName:                           b.stringVal(c.SegmentName),
+		Segments:                              segments,
+		SerfAdvertiseAddrLAN:                  serfAdvertiseAddrLAN,
+		SerfAdvertiseAddrWAN:                  serfAdvertiseAddrWAN,
+		SerfAllowedCIDRsLAN:                   serfAllowedCIDRSLAN,
+		SerfAllowedCIDRsWAN:                   serfAllowedCIDRSWAN,
+		SerfBindAddrLAN:                       serfBindAddrLAN,
+		SerfBindAddrWAN:                       serfBindAddrWAN,
+		SerfPortLAN:                           serfPortLAN,
+		SerfPortWAN:                           serfPortWAN,
+		ServerMode:                            b.boolVal(c.ServerMode),
+		ServerName:                            b.stringVal(c.ServerName),
+		ServerPort:                            serverPort,
+		ServersFile:                           serversFile,
+		Services:                              services,
+		SessionTTLMin:                         b.durationVal("session_ttl_min", c.SessionTTLMin),
+		SkipLeaveOnInt:                        skipLeaveOnInt,
+		StartDrained:                          b.boolVal(c.StartDrained),
+		StartJoinAddrsLAN:                     b.expandAllOptionalAddrs("start_join", c.StartJoinAddrsLAN),
+		StartJoinAddrsWAN:                     b.expandAllOptionalAddrs("start_join_wan", c.StartJoinAddrsWAN),
+		StrictMode:                            b.boolVal(c.StrictMode),
+		TLSBootstrapMode:                      tlsBootstrapMode,
+		TLSCipherSuites:                       b.tlsCipherSuites("tls_cipher_suites", tlsCipherSuites),
+		TLSChecks:                             tlsProtocol(tlsChecks),
+		TLSGRPC:                               tlsProtocol(tlsGRPC),
+		TLSHTTPS:                              tlsProtocol(tlsHTTPS),
+		TLSInternalRPC:                        tlsProtocol(c.TLS.InternalRPC),
+		TLSMinVersion:                         b.stringVal(tlsMinVersion),
+		TLSWAN:                                tlsProtocol(c.TLS.WAN),
+		TLSWatches:                            tlsProtocol(tlsWatches),
+		TLSPreferServerCipherSuites:           b.boolVal(c.TLSPreferServerCipherSuites),
+		TaggedAddresses:                       b.taggedAddressesVal(c.TaggedAddresses),
+		TranslateWANAddrs:                     b.boolVal(c.TranslateWANAddrs),
+		TxnMaxReqLen:                          b.uint64Val(c.Limits.TxnMaxReqLen),
+		UIConfig:                              b.uiConfigVal(c.UIConfig),
+		UnixSocketGRPC:                        b.unixSocketPermissionsVal(c.UnixSocket, c.UnixSocket.GRPC),
+		UnixSocketGroup:                       b.stringVal(c.UnixSocket.Group),
+		UnixSocketHTTP:                        b.unixSocketPermissionsVal(c.UnixSocket, c.UnixSocket.HTTP),
+		UnixSocketHTTPS:                       b.unixSocketPermissionsVal(c.UnixSocket, c.UnixSocket.HTTPS),
+		UnixSocketMode:                        b.stringVal(c.UnixSocket.Mode),
+		UnixSocketUser:                        b.stringVal(c.UnixSocket.User),
+		UnknownKeys:                           b.stringValWithDefault(c.UnknownKeys, "error"),
+		UsageReportingEnabled:                 b.boolVal(c.UsageReporting.Enabled),
+		UsageReportingEndpoint:                b.stringVal(c.UsageReporting.Endpoint),
+		UsageReportingInterval:                b.durationValWithDefault("usage_reporting.interval", c.UsageReporting.Interval, time.Hour),
+		UseSocketActivation:                   b.boolVal(c.UseSocketActivation),
+		ValidateListeners:                     b.boolVal(c.ValidateListeners),
+		VerifyIncoming:                        b.boolVal(tlsVerifyIncoming),
+		VerifyIncomingHTTPS:                   b.boolVal(c.VerifyIncomingHTTPS),
+		VerifyIncomingHTTPSExemptCIDRs:        b.cidrsVal("verify_incoming_https_exempt_cidrs", c.VerifyIncomingHTTPSExemptCIDRs),
+		VerifyIncomingRPC:                     b.boolVal(c.VerifyIncomingRPC),
+		VerifyOutgoing:                        verifyOutgoing,
+		VerifyServerHostname:                  verifyServerName,
+		Watches:                               c.Watches,
+		XDSEnableDelta:                        b.boolVal(c.XDS.EnableDelta),
+		XDSMaxConcurrentStreams:               b.intVal(c.XDS.MaxConcurrentStreams),
+		XDSNackBackoff:                        b.durationVal("xds.nack_backoff", c.XDS.NackBackoff),
 	}
 
 	rt.UseStreamingBackend = b.boolVal(c.UseStreamingBackend)
 
 	if rt.Cache.EntryFetchMaxBurst <= 0 {
-		return RuntimeConfig{}, fmt.Errorf("cache.entry_fetch_max_burst must be strictly positive, was: %v", rt.Cache.EntryFetchMaxBurst)
+		return RuntimeConfig{}, ValidationError{Field: "cache.entry_fetch_max_burst", Constraint: "must be strictly positive", Value: rt.Cache.EntryFetchMaxBurst}
 	}
 	if rt.Cache.EntryFetchRate <= 0 {
-		return RuntimeConfig{}, fmt.Errorf("cache.entry_fetch_rate must be strictly positive, was: %v", rt.Cache.EntryFetchRate)
+		return RuntimeConfig{}, ValidationError{Field: "cache.entry_fetch_rate", Constraint: "must be strictly positive", Value: rt.Cache.EntryFetchRate}
 	}
 
 	if rt.UIConfig.MetricsProvider == "prometheus" {
@@ -1107,9 +1928,16 @@ func (b *Builder) Build() (rt RuntimeConfig, err error) {
 	if rt.BootstrapExpect == 1 {
 		rt.Bootstrap = true
 		rt.BootstrapExpect = 0
-		b.warn(`BootstrapExpect is set to 1; this is the same as Bootstrap mode.`)
+		b.warnc("bootstrap-expect", `BootstrapExpect is set to 1; this is the same as Bootstrap mode.`)
 	}
 
+	if err := b.checkDeprecationPolicy(b.stringVal(c.DeprecationPolicy)); err != nil {
+		return RuntimeConfig{}, err
+	}
+
+	b.suppressWarnings = c.SuppressWarnings
+	b.applyWarningSuppressions()
+
 	return rt, nil
 }
 
@@ -1122,7 +1950,7 @@ func validateBasicName(field, value string, allowEmpty bool) error {
 		if allowEmpty {
 			return nil
 		}
-		return fmt.Errorf("%s cannot be empty", field)
+		return ValidationError{Field: field, Constraint: "cannot be empty"}
 	}
 	if !reBasicName.MatchString(value) {
 		return fmt.Errorf("%s can only contain lowercase alphanumeric, - or _ characters."+
@@ -1149,53 +1977,150 @@ func (b *Builder) Validate(rt RuntimeConfig) error {
 		return err
 	}
 	if rt.DataDir == "" && !rt.DevMode {
-		return fmt.Errorf("data_dir cannot be empty")
+		return ValidationError{Field: "data_dir", Constraint: "cannot be empty"}
 	}
 
-	if !validContentPath.MatchString(rt.UIConfig.ContentPath) {
-		return fmt.Errorf("ui-content-path can only contain alphanumeric, -, _, or /. received: %q", rt.UIConfig.ContentPath)
+	// all remaining violations are independent of one another and are
+	// collected so that operators can fix everything in one pass instead
+	// of an edit-retry loop.
+	var errs *multierror.Error
+
+	aliases := make([]string, 0, len(rt.DatacenterAliases))
+	for alias := range rt.DatacenterAliases {
+		aliases = append(aliases, alias)
+	}
+	sort.Strings(aliases)
+	for _, alias := range aliases {
+		dc := rt.DatacenterAliases[alias]
+		if err := validateBasicName("datacenter_aliases", alias, false); err != nil {
+			errs = multierror.Append(errs, err)
+			continue
+		}
+		if err := validateBasicName("datacenter_aliases", dc, false); err != nil {
+			errs = multierror.Append(errs, err)
+			continue
+		}
+		switch {
+		case alias == dc:
+			errs = multierror.Append(errs, fmt.Errorf("datacenter_aliases: alias %q points to itself", alias))
+		case alias == rt.Datacenter:
+			errs = multierror.Append(errs, fmt.Errorf("datacenter_aliases: alias %q duplicates the local datacenter name", alias))
+		default:
+			if _, ok := rt.DatacenterAliases[dc]; ok {
+				errs = multierror.Append(errs, fmt.Errorf("datacenter_aliases: target %q of alias %q is itself an alias", dc, alias))
+			}
+		}
 	}
 
-	if hasVersion.MatchString(rt.UIConfig.ContentPath) {
-		return fmt.Errorf("ui-content-path cannot have 'v[0-9]'. received: %q", rt.UIConfig.ContentPath)
+	if rt.RaftTrailingLogsSafetyFactor < 1 {
+		errs = multierror.Append(errs, fmt.Errorf("raft_trailing_logs_safety_factor cannot be less than 1. received: %v", rt.RaftTrailingLogsSafetyFactor))
+	} else if rt.RaftSnapshotThreshold > 0 && rt.RaftTrailingLogs > 0 {
+		if min := int(float64(rt.RaftSnapshotThreshold) * rt.RaftTrailingLogsSafetyFactor); rt.RaftTrailingLogs < min {
+			errs = multierror.Append(errs, fmt.Errorf(
+				"raft_trailing_logs (%d) must be at least %v times raft_snapshot_threshold (%d), i.e. at least %d, "+
+					"otherwise followers may be unable to catch up between snapshots",
+				rt.RaftTrailingLogs, rt.RaftTrailingLogsSafetyFactor, rt.RaftSnapshotThreshold, min))
+		}
 	}
 
-	if err := validateBasicName("ui_config.metrics_provider", rt.UIConfig.MetricsProvider, true); err != nil {
-		return err
+	for _, opts := range []struct {
+		name string
+		ListenerSocketOptions
+	}{
+		{"dns", rt.ListenerOptionsDNS},
+		{"grpc", rt.ListenerOptionsGRPC},
+		{"http", rt.ListenerOptionsHTTP},
+		{"https", rt.ListenerOptionsHTTPS},
+	} {
+		if opts.Backlog < 0 {
+			errs = multierror.Append(errs, fmt.Errorf("listener_options.%s.backlog cannot be negative", opts.name))
+		}
+		if opts.TCPKeepaliveInterval < 0 {
+			errs = multierror.Append(errs, fmt.Errorf("listener_options.%s.tcp_keepalive_interval cannot be negative", opts.name))
+		}
 	}
-	if rt.UIConfig.MetricsProviderOptionsJSON != "" {
-		// Attempt to parse the JSON to ensure it's valid, parsing into a map
-		// ensures we get an object.
+	if rt.ListenerOptionsDNS.TCPKeepaliveInterval > 0 {
+		b.warn("listener_options.dns.tcp_keepalive_interval has no effect: DNS connections are short-lived")
+	}
+
+	if rt.DiscoveryMaxResultsDNS < 0 {
+		errs = multierror.Append(errs, fmt.Errorf("discovery_max_results.dns cannot be negative, got %d", rt.DiscoveryMaxResultsDNS))
+	}
+	if rt.DiscoveryMaxResultsHTTP < 0 {
+		errs = multierror.Append(errs, fmt.Errorf("discovery_max_results.http cannot be negative, got %d", rt.DiscoveryMaxResultsHTTP))
+	}
+
+	if rt.OutboundProxyURL != "" {
+		u, err := url.Parse(rt.OutboundProxyURL)
+		switch {
+		case err != nil:
+			errs = multierror.Append(errs, fmt.Errorf("outbound_proxy.url is invalid: %s", err))
+		case u.Scheme != "http" && u.Scheme != "https" && u.Scheme != "socks5":
+			errs = multierror.Append(errs, fmt.Errorf("outbound_proxy.url scheme must be http, https or socks5, got %q", u.Scheme))
+		case u.Host == "":
+			errs = multierror.Append(errs, fmt.Errorf("outbound_proxy.url must include a host"))
+		case u.User != nil:
+			errs = multierror.Append(errs, fmt.Errorf("outbound_proxy.url must not embed credentials, use outbound_proxy.credentials_file"))
+		}
+		if rt.OutboundProxyCredentialsFile != "" {
+			if _, err := OutboundProxyCredentials(rt.OutboundProxyCredentialsFile); err != nil {
+				errs = multierror.Append(errs, err)
+			}
+		}
+	} else if rt.OutboundProxyCredentialsFile != "" {
+		errs = multierror.Append(errs, fmt.Errorf("outbound_proxy.credentials_file requires outbound_proxy.url"))
+	}
+
+	if rt.ValidateListeners {
+		if err := validateListenerBinds(rt); err != nil {
+			errs = multierror.Append(errs, err)
+		}
+	}
+
+	if !validContentPath.MatchString(rt.UIConfig.ContentPath) {
+		errs = multierror.Append(errs, fmt.Errorf("ui-content-path can only contain alphanumeric, -, _, or /. received: %q", rt.UIConfig.ContentPath))
+	}
+
+	if hasVersion.MatchString(rt.UIConfig.ContentPath) {
+		errs = multierror.Append(errs, fmt.Errorf("ui-content-path cannot have 'v[0-9]'. received: %q", rt.UIConfig.ContentPath))
+	}
+
+	if err := validateBasicName("ui_config.metrics_provider", rt.UIConfig.MetricsProvider, true); err != nil {
+		errs = multierror.Append(errs, err)
+	}
+	if rt.UIConfig.MetricsProviderOptionsJSON != "" {
+		// Attempt to parse the JSON to ensure it's valid, parsing into a map
+		// ensures we get an object.
 		var dummyMap map[string]interface{}
 		err := json.Unmarshal([]byte(rt.UIConfig.MetricsProviderOptionsJSON), &dummyMap)
 		if err != nil {
-			return fmt.Errorf("ui_config.metrics_provider_options_json must be empty "+
+			errs = multierror.Append(errs, fmt.Errorf("ui_config.metrics_provider_options_json must be empty "+
 				"or a string containing a valid JSON object. received: %q",
-				rt.UIConfig.MetricsProviderOptionsJSON)
+				rt.UIConfig.MetricsProviderOptionsJSON))
 		}
 	}
 	if rt.UIConfig.MetricsProxy.BaseURL != "" {
 		u, err := url.Parse(rt.UIConfig.MetricsProxy.BaseURL)
 		if err != nil || !(u.Scheme == "http" || u.Scheme == "https") {
-			return fmt.Errorf("ui_config.metrics_proxy.base_url must be a valid http"+
+			errs = multierror.Append(errs, fmt.Errorf("ui_config.metrics_proxy.base_url must be a valid http"+
 				" or https URL. received: %q",
-				rt.UIConfig.MetricsProxy.BaseURL)
+				rt.UIConfig.MetricsProxy.BaseURL))
 		}
 	}
 	for _, allowedPath := range rt.UIConfig.MetricsProxy.PathAllowlist {
 		if err := validateAbsoluteURLPath(allowedPath); err != nil {
-			return fmt.Errorf("ui_config.metrics_proxy.path_allowlist: %v", err)
+			errs = multierror.Append(errs, fmt.Errorf("ui_config.metrics_proxy.path_allowlist: %v", err))
 		}
 	}
 	for k, v := range rt.UIConfig.DashboardURLTemplates {
 		if err := validateBasicName("ui_config.dashboard_url_templates key names", k, false); err != nil {
-			return err
+			errs = multierror.Append(errs, err)
 		}
 		u, err := url.Parse(v)
 		if err != nil || !(u.Scheme == "http" || u.Scheme == "https") {
-			return fmt.Errorf("ui_config.dashboard_url_templates values must be a"+
+			errs = multierror.Append(errs, fmt.Errorf("ui_config.dashboard_url_templates values must be a"+
 				" valid http or https URL. received: %q",
-				rt.UIConfig.MetricsProxy.BaseURL)
+				rt.UIConfig.MetricsProxy.BaseURL))
 		}
 	}
 
@@ -1203,119 +2128,240 @@ func (b *Builder) Validate(rt RuntimeConfig) error {
 		fi, err := os.Stat(rt.DataDir)
 		switch {
 		case err != nil && !os.IsNotExist(err):
-			return fmt.Errorf("Error getting info on data_dir: %s", err)
+			errs = multierror.Append(errs, fmt.Errorf("Error getting info on data_dir: %s", err))
 		case err == nil && !fi.IsDir():
-			return fmt.Errorf("data_dir %q is not a directory", rt.DataDir)
+			errs = multierror.Append(errs, fmt.Errorf("data_dir %q is not a directory", rt.DataDir))
 		}
 	}
 
 	switch {
 	case rt.NodeName == "":
-		return fmt.Errorf("node_name cannot be empty")
+		errs = multierror.Append(errs, ValidationError{Field: "node_name", Constraint: "cannot be empty"})
 	case dns.InvalidNameRe.MatchString(rt.NodeName):
-		b.warn("Node name %q will not be discoverable "+
+		b.warnc("node-name-dns", "Node name %q will not be discoverable "+
 			"via DNS due to invalid characters. Valid characters include "+
 			"all alpha-numerics and dashes.", rt.NodeName)
 	case len(rt.NodeName) > dns.MaxLabelLength:
-		b.warn("Node name %q will not be discoverable "+
+		b.warnc("node-name-dns", "Node name %q will not be discoverable "+
 			"via DNS due to it being too long. Valid lengths are between "+
 			"1 and 63 bytes.", rt.NodeName)
 	}
 
 	if ipaddr.IsAny(rt.AdvertiseAddrLAN.IP) {
-		return fmt.Errorf("Advertise address cannot be 0.0.0.0, :: or [::]")
+		errs = multierror.Append(errs, fmt.Errorf("Advertise address cannot be 0.0.0.0, :: or [::]"))
 	}
 	if ipaddr.IsAny(rt.AdvertiseAddrWAN.IP) {
-		return fmt.Errorf("Advertise WAN address cannot be 0.0.0.0, :: or [::]")
+		errs = multierror.Append(errs, fmt.Errorf("Advertise WAN address cannot be 0.0.0.0, :: or [::]"))
 	}
 	if err := b.validateSegments(rt); err != nil {
-		return err
+		errs = multierror.Append(errs, err)
 	}
 	for _, a := range rt.DNSAddrs {
 		if _, ok := a.(*net.UnixAddr); ok {
-			return fmt.Errorf("DNS address cannot be a unix socket")
+			errs = multierror.Append(errs, fmt.Errorf("DNS address cannot be a unix socket"))
 		}
 	}
 	for _, a := range rt.DNSRecursors {
 		if ipaddr.IsAny(a) {
-			return fmt.Errorf("DNS recursor address cannot be 0.0.0.0, :: or [::]")
+			errs = multierror.Append(errs, fmt.Errorf("DNS recursor address cannot be 0.0.0.0, :: or [::]"))
 		}
 	}
 	if !isValidAltDomain(rt.DNSAltDomain, rt.Datacenter) {
-		return fmt.Errorf("alt_domain cannot start with {service,connect,node,query,addr,%s}", rt.Datacenter)
+		errs = multierror.Append(errs, fmt.Errorf("alt_domain cannot start with {service,connect,node,query,addr,%s}", rt.Datacenter))
 	}
 	if rt.Bootstrap && !rt.ServerMode {
-		return fmt.Errorf("'bootstrap = true' requires 'server = true'")
+		errs = multierror.Append(errs, fmt.Errorf("'bootstrap = true' requires 'server = true'"))
 	}
 	if rt.BootstrapExpect < 0 {
-		return fmt.Errorf("bootstrap_expect cannot be %d. Must be greater than or equal to zero", rt.BootstrapExpect)
+		errs = multierror.Append(errs, fmt.Errorf("bootstrap_expect cannot be %d. Must be greater than or equal to zero", rt.BootstrapExpect))
 	}
 	if rt.BootstrapExpect > 0 && !rt.ServerMode {
-		return fmt.Errorf("'bootstrap_expect > 0' requires 'server = true'")
+		errs = multierror.Append(errs, fmt.Errorf("'bootstrap_expect > 0' requires 'server = true'"))
 	}
 	if rt.BootstrapExpect > 0 && rt.DevMode {
-		return fmt.Errorf("'bootstrap_expect > 0' not allowed in dev mode")
+		errs = multierror.Append(errs, fmt.Errorf("'bootstrap_expect > 0' not allowed in dev mode"))
 	}
 	if rt.BootstrapExpect > 0 && rt.Bootstrap {
-		return fmt.Errorf("'bootstrap_expect > 0' and 'bootstrap = true' are mutually exclusive")
+		errs = multierror.Append(errs, fmt.Errorf("'bootstrap_expect > 0' and 'bootstrap = true' are mutually exclusive"))
 	}
 	if rt.CheckOutputMaxSize < 1 {
-		return fmt.Errorf("check_output_max_size must be positive, to discard check output use the discard_check_output flag")
+		errs = multierror.Append(errs, ValidationError{Field: "check_output_max_size", Constraint: "must be positive, to discard check output use the discard_check_output flag"})
+	}
+	if rt.CheckExecLimitsMaxConcurrent < 0 {
+		errs = multierror.Append(errs, fmt.Errorf("check_exec_limits.max_concurrent cannot be %d. Must be greater than or equal to zero", rt.CheckExecLimitsMaxConcurrent))
+	}
+	if rt.CheckExecLimitsCPUSeconds < 0 {
+		errs = multierror.Append(errs, fmt.Errorf("check_exec_limits.cpu_seconds cannot be %d. Must be greater than or equal to zero", rt.CheckExecLimitsCPUSeconds))
+	}
+	if rt.CheckExecLimitsMemoryBytes < 0 {
+		errs = multierror.Append(errs, fmt.Errorf("check_exec_limits.memory_bytes cannot be %d. Must be greater than or equal to zero", rt.CheckExecLimitsMemoryBytes))
+	}
+	if rt.CheckExecLimitsNice < -20 || rt.CheckExecLimitsNice > 19 {
+		errs = multierror.Append(errs, fmt.Errorf("check_exec_limits.nice must be between -20 and 19, was: %d", rt.CheckExecLimitsNice))
+	}
+	if rt.DockerEndpointHost != "" {
+		if _, _, _, err := checks.ParseHost(rt.DockerEndpointHost); err != nil {
+			errs = multierror.Append(errs, fmt.Errorf("docker_endpoint.host is invalid: %s", err))
+		}
+	}
+	dockerTLS := rt.DockerEndpointCAFile != "" || rt.DockerEndpointCertFile != "" ||
+		rt.DockerEndpointKeyFile != "" || rt.DockerEndpointTLSSkipVerify
+	if dockerTLS && !strings.HasPrefix(rt.DockerEndpointHost, "tcp://") {
+		errs = multierror.Append(errs, fmt.Errorf("docker_endpoint TLS settings require a tcp:// host"))
+	}
+	if (rt.DockerEndpointCertFile == "") != (rt.DockerEndpointKeyFile == "") {
+		errs = multierror.Append(errs, fmt.Errorf("docker_endpoint.cert_file and docker_endpoint.key_file must be set together"))
+	}
+	criCheck := false
+	for _, c := range rt.Checks {
+		if c.CheckType().IsCRI() {
+			criCheck = true
+		}
+	}
+	for _, s := range rt.Services {
+		if chkTypes, err := s.CheckTypes(); err == nil {
+			for _, t := range chkTypes {
+				if t.IsCRI() {
+					criCheck = true
+				}
+			}
+		}
+	}
+	if criCheck {
+		if fi, err := os.Stat(rt.CRIEndpoint); err != nil {
+			errs = multierror.Append(errs, fmt.Errorf("cri_endpoint %q is not accessible: %s", rt.CRIEndpoint, err))
+		} else if fi.Mode()&os.ModeSocket == 0 {
+			errs = multierror.Append(errs, fmt.Errorf("cri_endpoint %q is not a unix socket", rt.CRIEndpoint))
+		}
 	}
 	if rt.AEInterval <= 0 {
-		return fmt.Errorf("ae_interval cannot be %s. Must be positive", rt.AEInterval)
+		errs = multierror.Append(errs, fmt.Errorf("ae_interval cannot be %s. Must be positive", rt.AEInterval))
 	}
 	if rt.AutopilotMaxTrailingLogs < 0 {
-		return fmt.Errorf("autopilot.max_trailing_logs cannot be %d. Must be greater than or equal to zero", rt.AutopilotMaxTrailingLogs)
+		errs = multierror.Append(errs, fmt.Errorf("autopilot.max_trailing_logs cannot be %d. Must be greater than or equal to zero", rt.AutopilotMaxTrailingLogs))
 	}
 	if err := validateBasicName("acl_datacenter", rt.ACLDatacenter, true); err != nil {
-		return err
+		errs = multierror.Append(errs, err)
 	}
 	// In DevMode, UI is enabled by default, so to enable rt.UIDir, don't perform this check
 	if !rt.DevMode && rt.UIConfig.Enabled && rt.UIConfig.Dir != "" {
-		return fmt.Errorf(
-			"Both the ui_config.enabled and ui_config.dir (or -ui and -ui-dir) were specified, please provide only one.\n" +
-				"If trying to use your own web UI resources, use ui_config.dir or the -ui-dir flag.\n" +
-				"The web UI is included in the binary so use ui_config.enabled or the -ui flag to enable it")
+		errs = multierror.Append(errs, fmt.Errorf(
+			"Both the ui_config.enabled and ui_config.dir (or -ui and -ui-dir) were specified, please provide only one.\n"+
+				"If trying to use your own web UI resources, use ui_config.dir or the -ui-dir flag.\n"+
+				"The web UI is included in the binary so use ui_config.enabled or the -ui flag to enable it"))
 	}
 	if rt.DNSUDPAnswerLimit < 0 {
-		return fmt.Errorf("dns_config.udp_answer_limit cannot be %d. Must be greater than or equal to zero", rt.DNSUDPAnswerLimit)
+		errs = multierror.Append(errs, fmt.Errorf("dns_config.udp_answer_limit cannot be %d. Must be greater than or equal to zero", rt.DNSUDPAnswerLimit))
 	}
 	if rt.DNSARecordLimit < 0 {
-		return fmt.Errorf("dns_config.a_record_limit cannot be %d. Must be greater than or equal to zero", rt.DNSARecordLimit)
+		errs = multierror.Append(errs, fmt.Errorf("dns_config.a_record_limit cannot be %d. Must be greater than or equal to zero", rt.DNSARecordLimit))
+	}
+	if rt.MDNSAdvertiseEnabled && rt.ServerMode && !rt.MDNSAdvertiseAllowServer {
+		errs = multierror.Append(errs, fmt.Errorf("mdns_advertise is intended for client agents and is refused in server mode. Set mdns_advertise.allow_server to enable it anyway"))
+	}
+	if rt.XDSMaxConcurrentStreams < 0 {
+		errs = multierror.Append(errs, fmt.Errorf("xds.max_concurrent_streams cannot be %d. Must be greater than or equal to zero", rt.XDSMaxConcurrentStreams))
+	}
+	if rt.XDSNackBackoff < 0 {
+		errs = multierror.Append(errs, fmt.Errorf("xds.nack_backoff cannot be %s. Must be greater than or equal to zero", rt.XDSNackBackoff))
+	}
+	if rt.LoggingSlowQueryThreshold < 0 {
+		errs = multierror.Append(errs, fmt.Errorf("logging.slow_query_threshold cannot be %s. Must be greater than or equal to zero", rt.LoggingSlowQueryThreshold))
+	}
+	if rt.UsageReportingEnabled {
+		if rt.UsageReportingEndpoint == "" {
+			errs = multierror.Append(errs, fmt.Errorf("usage_reporting.endpoint must be set when usage_reporting is enabled"))
+		} else if u, err := url.Parse(rt.UsageReportingEndpoint); err != nil || u.Host == "" {
+			errs = multierror.Append(errs, fmt.Errorf("usage_reporting.endpoint is not a valid URL: %q", rt.UsageReportingEndpoint))
+		}
+		if rt.UsageReportingInterval < time.Minute {
+			errs = multierror.Append(errs, fmt.Errorf("usage_reporting.interval cannot be less than 1m, was: %s", rt.UsageReportingInterval))
+		}
+	}
+	if rt.MaintenanceScheduleCron != "" {
+		if _, err := lib.ParseCron(rt.MaintenanceScheduleCron); err != nil {
+			errs = multierror.Append(errs, fmt.Errorf("maintenance_schedule.cron is not a valid cron expression: %s", err))
+		}
+		if rt.MaintenanceScheduleDuration <= 0 {
+			errs = multierror.Append(errs, fmt.Errorf("maintenance_schedule.duration must be greater than zero"))
+		}
+	} else if rt.MaintenanceScheduleDuration != 0 || rt.MaintenanceScheduleReason != "" {
+		errs = multierror.Append(errs, fmt.Errorf("maintenance_schedule.cron must be set when a maintenance schedule is configured"))
+	}
+	if rt.RuntimeGOMAXPROCS < 0 {
+		errs = multierror.Append(errs, fmt.Errorf("runtime.gomaxprocs cannot be %d. Must be greater than or equal to zero", rt.RuntimeGOMAXPROCS))
+	}
+	if rt.RuntimeGOGC < -1 {
+		errs = multierror.Append(errs, fmt.Errorf("runtime.gogc cannot be %d. Must be -1 to disable garbage collection or greater than or equal to zero", rt.RuntimeGOGC))
+	}
+	if rt.RuntimeMemoryLimit < 0 {
+		errs = multierror.Append(errs, fmt.Errorf("runtime.memory_limit cannot be %d. Must be greater than or equal to zero", rt.RuntimeMemoryLimit))
+	}
+	if rt.RuntimeMemoryLimit > 0 {
+		if cg := cgroupMemoryLimit(); cg > 0 {
+			if rt.RuntimeMemoryLimit > cg {
+				errs = multierror.Append(errs, fmt.Errorf("runtime.memory_limit (%d) exceeds the cgroup memory limit (%d)", rt.RuntimeMemoryLimit, cg))
+			}
+			if rt.RuntimeMemoryLimit*10 > cg*9 {
+				b.warnc("memory-limit-headroom",
+					"runtime.memory_limit %d is within 10%% of the cgroup memory limit %d: leave headroom for non-heap memory to avoid the kernel OOM killer",
+					rt.RuntimeMemoryLimit, cg)
+			}
+		}
+	}
+	if rt.Telemetry.EnableDeprecatedConfigMetrics && !rt.Telemetry.FilterDefault {
+		name := rt.Telemetry.MetricsPrefix + ".config.deprecated"
+		allowed := false
+		for _, p := range rt.Telemetry.AllowedPrefixes {
+			if strings.HasPrefix(name, p) {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			b.warnc("deprecated-config-metrics-filtered",
+				"telemetry.enable_deprecated_config_metrics is set but telemetry.prefix_filter does not allow %q, so the metric will be filtered out", name)
+		}
 	}
 	if err := structs.ValidateNodeMetadata(rt.NodeMeta, false); err != nil {
-		return fmt.Errorf("node_meta invalid: %v", err)
+		errs = multierror.Append(errs, fmt.Errorf("node_meta invalid: %v", err))
 	}
 	if rt.EncryptKey != "" {
 		if _, err := decodeBytes(rt.EncryptKey); err != nil {
-			return fmt.Errorf("encrypt has invalid key: %s", err)
+			errs = multierror.Append(errs, fmt.Errorf("encrypt has invalid key: %s", err))
 		}
 	}
 
 	if rt.ConnectMeshGatewayWANFederationEnabled && !rt.ServerMode {
-		return fmt.Errorf("'connect.enable_mesh_gateway_wan_federation = true' requires 'server = true'")
+		errs = multierror.Append(errs, fmt.Errorf("'connect.enable_mesh_gateway_wan_federation = true' requires 'server = true'"))
 	}
 	if rt.ConnectMeshGatewayWANFederationEnabled && strings.ContainsAny(rt.NodeName, "/") {
-		return fmt.Errorf("'connect.enable_mesh_gateway_wan_federation = true' requires that 'node_name' not contain '/' characters")
+		errs = multierror.Append(errs, fmt.Errorf("'connect.enable_mesh_gateway_wan_federation = true' requires that 'node_name' not contain '/' characters"))
 	}
 	if rt.ConnectMeshGatewayWANFederationEnabled {
 		if len(rt.StartJoinAddrsWAN) > 0 {
-			return fmt.Errorf("'start_join_wan' is incompatible with 'connect.enable_mesh_gateway_wan_federation = true'")
+			errs = multierror.Append(errs, fmt.Errorf("'start_join_wan' is incompatible with 'connect.enable_mesh_gateway_wan_federation = true'"))
 		}
 		if len(rt.RetryJoinWAN) > 0 {
-			return fmt.Errorf("'retry_join_wan' is incompatible with 'connect.enable_mesh_gateway_wan_federation = true'")
+			errs = multierror.Append(errs, fmt.Errorf("'retry_join_wan' is incompatible with 'connect.enable_mesh_gateway_wan_federation = true'"))
 		}
 	}
 	if len(rt.PrimaryGateways) > 0 {
 		if !rt.ServerMode {
-			return fmt.Errorf("'primary_gateways' requires 'server = true'")
+			errs = multierror.Append(errs, fmt.Errorf("'primary_gateways' requires 'server = true'"))
 		}
 		if rt.PrimaryDatacenter == rt.Datacenter {
-			return fmt.Errorf("'primary_gateways' should only be configured in a secondary datacenter")
+			errs = multierror.Append(errs, fmt.Errorf("'primary_gateways' should only be configured in a secondary datacenter"))
 		}
 	}
 
+	// A relative servers_file is resolved against the data directory during
+	// Build, so only an absolute path can remain when no data_dir is set.
+	if rt.ServersFile != "" && !filepath.IsAbs(rt.ServersFile) {
+		errs = multierror.Append(errs, fmt.Errorf(
+			"servers_file %q must be an absolute path or relative to a configured data_dir", rt.ServersFile))
+	}
+
 	// Check the data dir for signs of an un-migrated Consul 0.5.x or older
 	// server. Consul refuses to start if this is present to protect a server
 	// with existing data from starting on a fresh data set.
@@ -1323,14 +2369,15 @@ func (b *Builder) Validate(rt RuntimeConfig) error {
 		mdbPath := filepath.Join(rt.DataDir, "mdb")
 		if _, err := os.Stat(mdbPath); !os.IsNotExist(err) {
 			if os.IsPermission(err) {
-				return fmt.Errorf(
+				errs = multierror.Append(errs, fmt.Errorf(
 					"CRITICAL: Permission denied for data folder at %q!\n"+
 						"Consul will refuse to boot without access to this directory.\n"+
-						"Please correct permissions and try starting again.", mdbPath)
+						"Please correct permissions and try starting again.", mdbPath))
+			} else {
+				errs = multierror.Append(errs, fmt.Errorf("CRITICAL: Deprecated data folder found at %q!\n"+
+					"Consul will refuse to boot with this directory present.\n"+
+					"See https://www.consul.io/docs/upgrade-specific.html for more information.", mdbPath))
 			}
-			return fmt.Errorf("CRITICAL: Deprecated data folder found at %q!\n"+
-				"Consul will refuse to boot with this directory present.\n"+
-				"See https://www.consul.io/docs/upgrade-specific.html for more information.", mdbPath)
 		}
 	}
 
@@ -1338,34 +2385,77 @@ func (b *Builder) Validate(rt RuntimeConfig) error {
 	if err := addrsUnique(inuse, "DNS", rt.DNSAddrs); err != nil {
 		// cannot happen since this is the first address
 		// we leave this for consistency
-		return err
+		errs = multierror.Append(errs, err)
+	}
+	if err := addrsUnique(inuse, "DNS TLS", rt.DNSTLSAddrs); err != nil {
+		errs = multierror.Append(errs, err)
+	}
+	if rt.DNSTLSPort > 0 && (rt.DNSTLSCertFile == "" || rt.DNSTLSKeyFile == "") {
+		errs = multierror.Append(errs, fmt.Errorf("ports.dns_tls requires dns_config.tls.cert_file and dns_config.tls.key_file"))
+	}
+	if rt.DNSDOHPath != "" {
+		if !strings.HasPrefix(rt.DNSDOHPath, "/") {
+			errs = multierror.Append(errs, fmt.Errorf("dns_config.doh_path must begin with a '/'. received: %q", rt.DNSDOHPath))
+		} else if strings.HasPrefix(rt.DNSDOHPath, "/v1/") {
+			errs = multierror.Append(errs, fmt.Errorf("dns_config.doh_path cannot be under the /v1/ API prefix. received: %q", rt.DNSDOHPath))
+		}
+		if len(rt.HTTPSAddrs) == 0 {
+			b.warn("dns_config.doh_path is set but no HTTPS listener is configured; DNS over HTTPS requires TLS for use by browsers and public resolvers")
+		}
 	}
 	if err := addrsUnique(inuse, "HTTP", rt.HTTPAddrs); err != nil {
-		return err
+		errs = multierror.Append(errs, err)
 	}
 	if err := addrsUnique(inuse, "HTTPS", rt.HTTPSAddrs); err != nil {
-		return err
+		errs = multierror.Append(errs, err)
+	}
+	if err := addrsUnique(inuse, "gRPC TLS", rt.GRPCTLSAddrs); err != nil {
+		errs = multierror.Append(errs, err)
 	}
 	if err := addrUnique(inuse, "RPC Advertise", rt.RPCAdvertiseAddr); err != nil {
-		return err
+		errs = multierror.Append(errs, err)
 	}
 	if err := addrUnique(inuse, "Serf Advertise LAN", rt.SerfAdvertiseAddrLAN); err != nil {
-		return err
+		errs = multierror.Append(errs, err)
 	}
 	// Validate serf WAN advertise address only when its set
 	if rt.SerfAdvertiseAddrWAN != nil {
 		if err := addrUnique(inuse, "Serf Advertise WAN", rt.SerfAdvertiseAddrWAN); err != nil {
-			return err
+			errs = multierror.Append(errs, err)
 		}
 	}
 	if b.err != nil {
-		return b.err
+		errs = multierror.Append(errs, b.err)
 	}
 
 	// Check for errors in the service definitions
 	for _, s := range rt.Services {
 		if err := s.Validate(); err != nil {
-			return fmt.Errorf("service %q: %s", s.Name, err)
+			errs = multierror.Append(errs, fmt.Errorf("service %q: %s", s.Name, err))
+		}
+		// service addresses may contain go-sockaddr templates which are
+		// resolved when the service is registered so that multi-homed
+		// hosts pick the per-service interface. Catch syntax errors now.
+		if strings.Contains(s.Address, "{{") {
+			if err := validateTemplate(s.Address); err != nil {
+				errs = multierror.Append(errs, fmt.Errorf("service %q: address template is invalid: %s", s.Name, err))
+			}
+		}
+		// config defined services must not claim ports the operator
+		// reserved through limits.reserved_ports or one of the agent's
+		// own listener ports.
+		if rt.IsReservedPort(s.Port) {
+			errs = multierror.Append(errs, fmt.Errorf("service %q: port %d is in a reserved port range", s.Name, s.Port))
+		}
+		if s.Connect != nil && s.Connect.SidecarService != nil && rt.IsReservedPort(s.Connect.SidecarService.Port) {
+			errs = multierror.Append(errs, fmt.Errorf("service %q: sidecar_service port %d is in a reserved port range", s.Name, s.Connect.SidecarService.Port))
+		}
+		if s.Proxy != nil {
+			for _, p := range s.Proxy.Expose.Paths {
+				if rt.IsReservedPort(p.ListenerPort) {
+					errs = multierror.Append(errs, fmt.Errorf("service %q: expose path %q listener port %d is in a reserved port range", s.Name, p.Path, p.ListenerPort))
+				}
+			}
 		}
 	}
 
@@ -1377,33 +2467,33 @@ func (b *Builder) Validate(rt RuntimeConfig) error {
 		structs.AWSCAProvider:    true,
 	}
 	if _, ok := validCAProviders[rt.ConnectCAProvider]; !ok {
-		return fmt.Errorf("%s is not a valid CA provider", rt.ConnectCAProvider)
+		errs = multierror.Append(errs, fmt.Errorf("%s is not a valid CA provider", rt.ConnectCAProvider))
 	} else {
 		switch rt.ConnectCAProvider {
 		case structs.ConsulCAProvider:
 			if _, err := ca.ParseConsulCAConfig(rt.ConnectCAConfig); err != nil {
-				return err
+				errs = multierror.Append(errs, err)
 			}
 		case structs.VaultCAProvider:
 			if _, err := ca.ParseVaultCAConfig(rt.ConnectCAConfig); err != nil {
-				return err
+				errs = multierror.Append(errs, err)
 			}
 		case structs.AWSCAProvider:
 			if _, err := ca.ParseAWSCAConfig(rt.ConnectCAConfig); err != nil {
-				return err
+				errs = multierror.Append(errs, err)
 			}
 		}
 	}
 
 	if rt.ServerMode && rt.AutoEncryptTLS {
-		return fmt.Errorf("auto_encrypt.tls can only be used on a client.")
+		errs = multierror.Append(errs, fmt.Errorf("auto_encrypt.tls can only be used on a client."))
 	}
 	if !rt.ServerMode && rt.AutoEncryptAllowTLS {
-		return fmt.Errorf("auto_encrypt.allow_tls can only be used on a server.")
+		errs = multierror.Append(errs, fmt.Errorf("auto_encrypt.allow_tls can only be used on a server."))
 	}
 
 	if rt.ServerMode && rt.AdvertiseReconnectTimeout != 0 {
-		return fmt.Errorf("advertise_reconnect_timeout can only be used on a client")
+		errs = multierror.Append(errs, fmt.Errorf("advertise_reconnect_timeout can only be used on a client"))
 	}
 
 	// ----------------------------------------------------------------
@@ -1411,46 +2501,96 @@ func (b *Builder) Validate(rt RuntimeConfig) error {
 	//
 
 	if rt.ServerMode && !rt.DevMode && !rt.Bootstrap && rt.BootstrapExpect == 2 {
-		b.warn(`bootstrap_expect = 2: A cluster with 2 servers will provide no failure tolerance. See https://www.consul.io/docs/internals/consensus.html#deployment-table`)
+		b.warnc("bootstrap-expect", `bootstrap_expect = 2: A cluster with 2 servers will provide no failure tolerance. See https://www.consul.io/docs/internals/consensus.html#deployment-table`)
 	}
 
 	if rt.ServerMode && !rt.Bootstrap && rt.BootstrapExpect > 2 && rt.BootstrapExpect%2 == 0 {
-		b.warn(`bootstrap_expect is even number: A cluster with an even number of servers does not achieve optimum fault tolerance. See https://www.consul.io/docs/internals/consensus.html#deployment-table`)
+		b.warnc("bootstrap-expect", `bootstrap_expect is even number: A cluster with an even number of servers does not achieve optimum fault tolerance. See https://www.consul.io/docs/internals/consensus.html#deployment-table`)
 	}
 
 	if rt.ServerMode && rt.Bootstrap && rt.BootstrapExpect == 0 {
-		b.warn(`bootstrap = true: do not enable unless necessary`)
+		b.warnc("bootstrap", `bootstrap = true: do not enable unless necessary`)
 	}
 
 	if rt.ServerMode && !rt.DevMode && !rt.Bootstrap && rt.BootstrapExpect > 1 {
-		b.warn("bootstrap_expect > 0: expecting %d servers", rt.BootstrapExpect)
+		b.warnc("bootstrap-expect", "bootstrap_expect > 0: expecting %d servers", rt.BootstrapExpect)
 	}
 
 	if rt.AutoEncryptAllowTLS {
 		if !rt.VerifyIncoming && !rt.VerifyIncomingRPC {
-			b.warn("if auto_encrypt.allow_tls is turned on, either verify_incoming or verify_incoming_rpc should be enabled. It is necessary to turn it off during a migration to TLS, but it should definitely be turned on afterwards.")
+			b.warnc("auto-encrypt-verify-incoming", "if auto_encrypt.allow_tls is turned on, either verify_incoming or verify_incoming_rpc should be enabled. It is necessary to turn it off during a migration to TLS, but it should definitely be turned on afterwards.")
+		}
+	}
+
+	if rt.ServerMode && rt.StartDrained {
+		b.warnc("start-drained", "start_drained is intended for client agents: a drained server still participates in Raft and serves RPCs")
+	}
+
+	if len(rt.VerifyIncomingHTTPSExemptCIDRs) > 0 {
+		if !rt.VerifyIncoming && !rt.VerifyIncomingHTTPS {
+			errs = multierror.Append(errs, fmt.Errorf(
+				"verify_incoming_https_exempt_cidrs requires verify_incoming or verify_incoming_https"))
+		}
+		for _, cidr := range rt.VerifyIncomingHTTPSExemptCIDRs {
+			if ones, bits := cidr.Mask.Size(); ones == 0 && bits != 0 {
+				errs = multierror.Append(errs, fmt.Errorf(
+					"verify_incoming_https_exempt_cidrs: %s would exempt every client and disable verify_incoming_https entirely", cidr))
+				continue
+			}
+			b.warnc("verify-incoming-https-exempt", "HTTPS clients from %s are exempt from client certificate verification", cidr)
+		}
+	}
+
+	for _, k := range rt.DNSNodeMetaTXTKeys {
+		if _, ok := rt.NodeMeta[k]; !ok {
+			b.warnc("dns-node-meta-txt-keys", "dns_config.node_meta_txt_keys: key %q is not present in node_meta", k)
 		}
 	}
 
 	if err := checkLimitsFromMaxConnsPerClient(rt.HTTPMaxConnsPerClient); err != nil {
-		return err
+		errs = multierror.Append(errs, err)
+	}
+
+	if rt.CAFile != "" && rt.CAFileContents != "" {
+		errs = multierror.Append(errs, fmt.Errorf("ca_file and ca_file_contents are mutually exclusive"))
+	}
+	if rt.CertFile != "" && rt.CertFileContents != "" {
+		errs = multierror.Append(errs, fmt.Errorf("cert_file and cert_file_contents are mutually exclusive"))
+	}
+	if rt.KeyFile != "" && rt.KeyFileContents != "" {
+		errs = multierror.Append(errs, fmt.Errorf("key_file and key_file_contents are mutually exclusive"))
 	}
 
 	if rt.AutoConfig.Enabled && rt.AutoEncryptTLS {
-		return fmt.Errorf("both auto_encrypt.tls and auto_config.enabled cannot be set to true.")
+		errs = multierror.Append(errs, fmt.Errorf("both auto_encrypt.tls and auto_config.enabled cannot be set to true."))
 	}
 
 	if err := b.validateAutoConfig(rt); err != nil {
-		return err
+		errs = multierror.Append(errs, err)
 	}
 
 	if err := validateRemoteScriptsChecks(rt); err != nil {
 		// TODO: make this an error in a future version
-		b.warn(err.Error())
+		b.warnc("remote-script-checks", err.Error())
+	}
+
+	if err := b.validateEnterpriseConfig(rt); err != nil {
+		errs = multierror.Append(errs, err)
+	}
+
+	// Entries that explicitly name a code are checked against the known
+	// codes so a typo does not silently suppress nothing. Bare entries
+	// may be literal warning messages and cannot be validated.
+	for _, e := range b.suppressWarnings {
+		if strings.HasPrefix(e, "code:") {
+			if code := strings.TrimPrefix(e, "code:"); !knownWarningCode(code) {
+				errs = multierror.Append(errs, fmt.Errorf("suppress_warnings: unknown warning code %q", code))
+			}
+		}
 	}
 
-	err := b.validateEnterpriseConfig(rt)
-	return err
+	b.applyWarningSuppressions()
+	return errs.ErrorOrNil()
 }
 
 // addrUnique checks if the given address is already in use for another
@@ -1493,7 +2633,55 @@ func splitSlicesAndValues(c Config) (slices, values Config) {
 }
 
 func (b *Builder) warn(msg string, args ...interface{}) {
-	b.Warnings = append(b.Warnings, fmt.Sprintf(msg, args...))
+	b.warnc("", msg, args...)
+}
+
+// warnc records a warning together with a stable code that operators can
+// list in suppress_warnings to silence it.
+func (b *Builder) warnc(code string, msg string, args ...interface{}) {
+	b.warning(Warning{Code: code, Message: fmt.Sprintf(msg, args...)})
+}
+
+// warning records a warning with all of its structured fields. Call sites
+// that know the config source or key the warning refers to use this instead
+// of warnc so that the fields are populated.
+func (b *Builder) warning(w Warning) {
+	b.Warnings = append(b.Warnings, w)
+}
+
+// applyWarningSuppressions drops warnings listed in suppress_warnings.
+// Entries in the "code:<code>" form and bare entries that are known codes
+// suppress by warning code; any other entry suppresses warnings whose
+// message matches it exactly, which keeps uncoded warnings suppressible
+// at the cost of breaking when the wording changes. It runs at the end of
+// both Build and Validate since both emit warnings.
+func (b *Builder) applyWarningSuppressions() {
+	if len(b.suppressWarnings) == 0 {
+		return
+	}
+	codes := make(map[string]bool, len(b.suppressWarnings))
+	messages := make(map[string]bool)
+	for _, e := range b.suppressWarnings {
+		switch {
+		case strings.HasPrefix(e, "code:"):
+			codes[strings.TrimPrefix(e, "code:")] = true
+		case knownWarningCode(e):
+			codes[e] = true
+		default:
+			messages[e] = true
+		}
+	}
+	var warnings []Warning
+	for _, w := range b.Warnings {
+		if w.Code != "" && codes[w.Code] {
+			continue
+		}
+		if messages[w.Message] {
+			continue
+		}
+		warnings = append(warnings, w)
+	}
+	b.Warnings = warnings
 }
 
 func (b *Builder) checkVal(v *CheckDefinition) *structs.CheckDefinition {
@@ -1518,6 +2706,7 @@ func (b *Builder) checkVal(v *CheckDefinition) *structs.CheckDefinition {
 		TCP:                            b.stringVal(v.TCP),
 		Interval:                       b.durationVal(fmt.Sprintf("check[%s].interval", id), v.Interval),
 		DockerContainerID:              b.stringVal(v.DockerContainerID),
+		CRIContainerID:                 b.stringVal(v.CRIContainerID),
 		Shell:                          b.stringVal(v.Shell),
 		GRPC:                           b.stringVal(v.GRPC),
 		GRPCUseTLS:                     b.boolVal(v.GRPCUseTLS),
@@ -1534,6 +2723,216 @@ func (b *Builder) checkVal(v *CheckDefinition) *structs.CheckDefinition {
 	}
 }
 
+// expandBindAddrs resolves the bind_addrs entries to ip addresses. An
+// entry containing a '/' is a CIDR and expands to all interface
+// addresses within that network; every other entry must be a single ip
+// address. Duplicates are dropped so that overlapping entries do not
+// bind the same address twice.
+func expandBindAddrs(entries []string) ([]*net.IPAddr, error) {
+	var addrs []*net.IPAddr
+	seen := map[string]bool{}
+	add := func(ip net.IP) {
+		if !seen[ip.String()] {
+			seen[ip.String()] = true
+			addrs = append(addrs, &net.IPAddr{IP: ip})
+		}
+	}
+	for _, e := range entries {
+		if strings.Contains(e, "/") {
+			_, network, err := net.ParseCIDR(e)
+			if err != nil {
+				return nil, fmt.Errorf("bind_addrs: invalid network %q: %s", e, err)
+			}
+			ifaceAddrs, err := net.InterfaceAddrs()
+			if err != nil {
+				return nil, fmt.Errorf("bind_addrs: %s", err)
+			}
+			n := len(addrs)
+			for _, a := range ifaceAddrs {
+				if ipnet, ok := a.(*net.IPNet); ok && network.Contains(ipnet.IP) {
+					add(ipnet.IP)
+				}
+			}
+			if len(addrs) == n {
+				return nil, fmt.Errorf("bind_addrs: no interface address in %s", network)
+			}
+			continue
+		}
+		ip := net.ParseIP(e)
+		if ip == nil {
+			return nil, fmt.Errorf("bind_addrs: invalid address %q", e)
+		}
+		if ipaddr.IsAny(ip) {
+			return nil, fmt.Errorf("bind_addrs cannot contain 0.0.0.0, :: or [::]")
+		}
+		add(ip)
+	}
+	return addrs, nil
+}
+
+// interfaceAddr returns the address of the named network interface in
+// the preferred address family, falling back to the other family when
+// the interface has no address of the preferred one. IPv6 link-local
+// addresses are skipped since they are not usable without a zone.
+func interfaceAddr(name, preference string) (string, error) {
+	iface, err := net.InterfaceByName(name)
+	if err != nil {
+		return "", err
+	}
+	addrs, err := iface.Addrs()
+	if err != nil {
+		return "", err
+	}
+	var v4, v6 []string
+	for _, a := range addrs {
+		ipnet, ok := a.(*net.IPNet)
+		if !ok {
+			continue
+		}
+		switch {
+		case ipnet.IP.To4() != nil:
+			v4 = append(v4, ipnet.IP.String())
+		case !ipnet.IP.IsLinkLocalUnicast():
+			v6 = append(v6, ipnet.IP.String())
+		}
+	}
+	first, second := v4, v6
+	if preference == "ipv6" {
+		first, second = v6, v4
+	}
+	switch {
+	case len(first) > 0:
+		return first[0], nil
+	case len(second) > 0:
+		return second[0], nil
+	default:
+		return "", fmt.Errorf("interface %q has no usable addresses", name)
+	}
+}
+
+// validateAdvertiseSelection checks that the advertise_addr_selection
+// rule has one of the supported forms. Interface and network existence
+// are only checked when the rule is actually applied.
+func validateAdvertiseSelection(rule string) error {
+	switch {
+	case rule == "error" || rule == "first":
+		return nil
+	case strings.HasPrefix(rule, "interface:"):
+		if strings.TrimPrefix(rule, "interface:") == "" {
+			return fmt.Errorf("advertise_addr_selection: interface name cannot be empty")
+		}
+		return nil
+	case strings.HasPrefix(rule, "cidr:"):
+		if _, _, err := net.ParseCIDR(strings.TrimPrefix(rule, "cidr:")); err != nil {
+			return fmt.Errorf("advertise_addr_selection: invalid network %q: %s", strings.TrimPrefix(rule, "cidr:"), err)
+		}
+		return nil
+	default:
+		return fmt.Errorf("advertise_addr_selection cannot be %q. Must be \"error\", \"first\", \"interface:<name>\" or \"cidr:<net>\"", rule)
+	}
+}
+
+// selectAdvertiseAddr applies the advertise_addr_selection rule to the
+// detected candidate addresses. With the default "error" rule multiple
+// candidates refuse to start, as before the rule existed.
+func selectAdvertiseAddr(addrtyp string, addrs []*net.IPAddr, rule string) (*net.IPAddr, error) {
+	switch {
+	case rule == "first":
+		return addrs[0], nil
+
+	case strings.HasPrefix(rule, "interface:"):
+		name := strings.TrimPrefix(rule, "interface:")
+		iface, err := net.InterfaceByName(name)
+		if err != nil {
+			return nil, fmt.Errorf("advertise_addr_selection: interface %q: %s", name, err)
+		}
+		ifaceAddrs, err := iface.Addrs()
+		if err != nil {
+			return nil, fmt.Errorf("advertise_addr_selection: interface %q: %s", name, err)
+		}
+		for _, a := range addrs {
+			for _, ia := range ifaceAddrs {
+				var ip net.IP
+				switch v := ia.(type) {
+				case *net.IPAddr:
+					ip = v.IP
+				case *net.IPNet:
+					ip = v.IP
+				}
+				if ip != nil && ip.Equal(a.IP) {
+					return a, nil
+				}
+			}
+		}
+		return nil, fmt.Errorf("advertise_addr_selection: no detected %s address on interface %q", addrtyp, name)
+
+	case strings.HasPrefix(rule, "cidr:"):
+		_, network, err := net.ParseCIDR(strings.TrimPrefix(rule, "cidr:"))
+		if err != nil {
+			return nil, fmt.Errorf("advertise_addr_selection: invalid network %q: %s", strings.TrimPrefix(rule, "cidr:"), err)
+		}
+		for _, a := range addrs {
+			if network.Contains(a.IP) {
+				return a, nil
+			}
+		}
+		return nil, fmt.Errorf("advertise_addr_selection: no detected %s address in %s", addrtyp, network)
+
+	default:
+		return nil, fmt.Errorf("Multiple %s addresses found. Please configure one with 'bind' and/or 'advertise'.", addrtyp)
+	}
+}
+
+// portRangeVals parses a list of inclusive port ranges of the form
+// "port" or "min-max". Invalid entries are recorded on b.err.
+func (b *Builder) portRangeVals(name string, v []string) []PortRange {
+	var ranges []PortRange
+	for _, s := range v {
+		lo, hi := s, s
+		if i := strings.Index(s, "-"); i >= 0 {
+			lo, hi = s[:i], s[i+1:]
+		}
+		from, err1 := strconv.Atoi(strings.TrimSpace(lo))
+		to, err2 := strconv.Atoi(strings.TrimSpace(hi))
+		if err1 != nil || err2 != nil || from < 1 || to > 65535 || from > to {
+			b.err = multierror.Append(b.err, fmt.Errorf("%s: invalid port range %q", name, s))
+			continue
+		}
+		ranges = append(ranges, PortRange{From: from, To: to})
+	}
+	return ranges
+}
+
+// validateTaggedAddresses checks the tagged addresses of a service
+// definition for ports outside the valid range, names that collide after
+// case folding and invalid health modes. Violations are recorded on
+// b.err and name the config source the service came from.
+func (b *Builder) validateTaggedAddresses(svc *structs.ServiceDefinition, source string) {
+	names := make([]string, 0, len(svc.TaggedAddresses))
+	for name := range svc.TaggedAddresses {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	seen := make(map[string]string, len(names))
+	for _, name := range names {
+		addr := svc.TaggedAddresses[name]
+		if addr.Port < 0 || addr.Port > 65535 {
+			b.err = multierror.Append(b.err, fmt.Errorf("service %q: tagged address %q port cannot be %d. Must be in the range 0 to 65535 (in %s)", svc.Name, name, addr.Port, source))
+		}
+		if prev, ok := seen[strings.ToLower(name)]; ok {
+			b.err = multierror.Append(b.err, fmt.Errorf("service %q: tagged addresses %q and %q are duplicates (in %s)", svc.Name, prev, name, source))
+		} else {
+			seen[strings.ToLower(name)] = name
+		}
+		switch addr.Health {
+		case "", "inherit", "independent":
+		default:
+			b.err = multierror.Append(b.err, fmt.Errorf("service %q: tagged address %q health cannot be %q. Must be \"inherit\" or \"independent\" (in %s)", svc.Name, name, addr.Health, source))
+		}
+	}
+}
+
 func (b *Builder) svcTaggedAddresses(v map[string]ServiceAddress) map[string]structs.ServiceAddress {
 	if len(v) <= 0 {
 		return nil
@@ -1548,12 +2947,80 @@ func (b *Builder) svcTaggedAddresses(v map[string]ServiceAddress) map[string]str
 		if addrConf.Port != nil {
 			addr.Port = *addrConf.Port
 		}
+		if addrConf.Health != nil {
+			addr.Health = *addrConf.Health
+		}
 
 		svcAddrs[addrName] = addr
 	}
 	return svcAddrs
 }
 
+// taggedAddressesVal normalizes the node tagged addresses. An entry is
+// either a plain address string or, like service tagged addresses, an
+// object with an address and an optional port. Entries with a port are
+// published to the catalog as a joined "address:port" value.
+func (b *Builder) taggedAddressesVal(v map[string]interface{}) map[string]string {
+	out := make(map[string]string, len(v))
+	for name, raw := range v {
+		if s, ok := raw.(string); ok {
+			out[name] = s
+			continue
+		}
+
+		m := taggedAddressObject(raw)
+		if m == nil {
+			b.err = multierror.Append(b.err, fmt.Errorf(
+				"tagged_addresses.%s must be an address string or an object with address and port", name))
+			continue
+		}
+		address, _ := m["address"].(string)
+		if address == "" {
+			b.err = multierror.Append(b.err, fmt.Errorf("tagged_addresses.%s.address cannot be empty", name))
+			continue
+		}
+
+		switch port := m["port"].(type) {
+		case nil:
+			out[name] = address
+		case int:
+			out[name] = b.taggedAddressPort(name, address, port)
+		case float64:
+			out[name] = b.taggedAddressPort(name, address, int(port))
+		default:
+			b.err = multierror.Append(b.err, fmt.Errorf("tagged_addresses.%s.port must be an integer", name))
+		}
+	}
+	return out
+}
+
+func (b *Builder) taggedAddressPort(name, address string, port int) string {
+	if port < 1 || port > 65535 {
+		b.err = multierror.Append(b.err, fmt.Errorf("tagged_addresses.%s.port must be in the range 1-65535, got %d", name, port))
+		return ""
+	}
+	return ipaddr.FormatAddressPort(address, port)
+}
+
+// taggedAddressObject unwraps the decoded form of an object-valued tagged
+// address entry. HCL wraps nested blocks in a slice while JSON decodes
+// them to a plain map.
+func taggedAddressObject(raw interface{}) map[string]interface{} {
+	switch x := raw.(type) {
+	case map[string]interface{}:
+		return x
+	case []map[string]interface{}:
+		if len(x) == 1 {
+			return x[0]
+		}
+	case []interface{}:
+		if len(x) == 1 {
+			return taggedAddressObject(x[0])
+		}
+	}
+	return nil
+}
+
 func (b *Builder) serviceVal(v *ServiceDefinition) *structs.ServiceDefinition {
 	if v == nil {
 		return nil
@@ -1652,7 +3119,7 @@ func (b *Builder) upstreamsVal(v []Upstream) structs.Upstreams {
 			Datacenter:           b.stringVal(u.Datacenter),
 			LocalBindAddress:     b.stringVal(u.LocalBindAddress),
 			LocalBindPort:        b.intVal(u.LocalBindPort),
-			Config:               u.Config,
+			Config:               b.applyUpstreamDefaults(u.Config),
 			MeshGateway:          b.meshGatewayConfVal(u.MeshGateway),
 		}
 		if ups[i].DestinationType == "" {
@@ -1662,6 +3129,82 @@ func (b *Builder) upstreamsVal(v []Upstream) structs.Upstreams {
 	return ups
 }
 
+// upstreamDefaultsVal converts connect.upstream_defaults into the opaque
+// upstream config keys understood by the xds package.
+func (b *Builder) upstreamDefaultsVal(v *UpstreamDefaults) map[string]interface{} {
+	if v == nil {
+		return nil
+	}
+
+	defaults := make(map[string]interface{})
+	if v.ConnectTimeoutMS != nil {
+		if *v.ConnectTimeoutMS <= 0 {
+			b.err = multierror.Append(b.err, fmt.Errorf(
+				"connect.upstream_defaults.connect_timeout_ms must be positive, got: %d", *v.ConnectTimeoutMS))
+		}
+		defaults["connect_timeout_ms"] = *v.ConnectTimeoutMS
+	}
+	if v.Protocol != nil {
+		switch *v.Protocol {
+		case "tcp", "http", "http2", "grpc":
+		default:
+			b.err = multierror.Append(b.err, fmt.Errorf(
+				"connect.upstream_defaults.protocol must be one of 'tcp', 'http', 'http2' or 'grpc', got: %q", *v.Protocol))
+		}
+		defaults["protocol"] = *v.Protocol
+	}
+	if v.MaxConnections != nil {
+		if *v.MaxConnections <= 0 {
+			b.err = multierror.Append(b.err, fmt.Errorf(
+				"connect.upstream_defaults.max_connections must be positive, got: %d", *v.MaxConnections))
+		}
+		defaults["limits"] = map[string]interface{}{
+			"max_connections": *v.MaxConnections,
+		}
+	}
+	if v.PassiveHealthCheck != nil {
+		phc := make(map[string]interface{})
+		if v.PassiveHealthCheck.Interval != nil {
+			phc["interval"] = b.durationVal(
+				"connect.upstream_defaults.passive_health_check.interval",
+				v.PassiveHealthCheck.Interval).String()
+		}
+		if v.PassiveHealthCheck.MaxFailures != nil {
+			if *v.PassiveHealthCheck.MaxFailures < 0 {
+				b.err = multierror.Append(b.err, fmt.Errorf(
+					"connect.upstream_defaults.passive_health_check.max_failures cannot be negative, got: %d",
+					*v.PassiveHealthCheck.MaxFailures))
+			}
+			phc["max_failures"] = *v.PassiveHealthCheck.MaxFailures
+		}
+		if len(phc) > 0 {
+			defaults["passive_health_check"] = phc
+		}
+	}
+
+	if len(defaults) == 0 {
+		return nil
+	}
+	return defaults
+}
+
+// applyUpstreamDefaults returns the upstream config with the agent-wide
+// connect.upstream_defaults filled in for top-level keys the upstream does
+// not set itself.
+func (b *Builder) applyUpstreamDefaults(config map[string]interface{}) map[string]interface{} {
+	if len(b.upstreamDefaults) == 0 {
+		return config
+	}
+	merged := make(map[string]interface{}, len(b.upstreamDefaults)+len(config))
+	for k, v := range b.upstreamDefaults {
+		merged[k] = v
+	}
+	for k, v := range config {
+		merged[k] = v
+	}
+	return merged
+}
+
 func (b *Builder) meshGatewayConfVal(mgConf *MeshGatewayConfig) structs.MeshGatewayConfig {
 	cfg := structs.MeshGatewayConfig{Mode: structs.MeshGatewayModeDefault}
 	if mgConf == nil || mgConf.Mode == nil {
@@ -1729,6 +3272,35 @@ func (b *Builder) serviceConnectVal(v *ServiceConnect) *structs.ServiceConnect {
 	}
 }
 
+// unixSocketPermissionsVal resolves the effective unix socket ownership and
+// mode for one listener by applying the per-listener overrides on top of the
+// agent-wide unix_sockets values.
+func (b *Builder) unixSocketPermissionsVal(global UnixSocket, override UnixSocketPermissionsRaw) UnixSocketPermissions {
+	perms := UnixSocketPermissions{
+		Group: b.stringVal(global.Group),
+		Mode:  b.stringVal(global.Mode),
+		User:  b.stringVal(global.User),
+	}
+	if override.Group != nil {
+		perms.Group = *override.Group
+	}
+	if override.Mode != nil {
+		perms.Mode = *override.Mode
+	}
+	if override.User != nil {
+		perms.User = *override.User
+	}
+	return perms
+}
+
+func (b *Builder) listenerOptionsVal(name string, v ListenerOptionsBlock) ListenerSocketOptions {
+	return ListenerSocketOptions{
+		SOReusePort:          b.boolVal(v.SOReusePort),
+		TCPKeepaliveInterval: b.durationVal(name+".tcp_keepalive_interval", v.TCPKeepaliveInterval),
+		Backlog:              b.intVal(v.Backlog),
+	}
+}
+
 func (b *Builder) uiConfigVal(v RawUIConfig) UIConfig {
 	return UIConfig{
 		Enabled:                    b.boolVal(v.Enabled),
@@ -1808,6 +3380,17 @@ func (b *Builder) uintValWithDefault(v *uint, defaultVal uint) uint {
 	return *v
 }
 
+func (b *Builder) int64ValWithDefault(v *int64, defaultVal int64) int64 {
+	if v == nil {
+		return defaultVal
+	}
+	return *v
+}
+
+func (b *Builder) int64Val(v *int64) int64 {
+	return b.int64ValWithDefault(v, 0)
+}
+
 func (b *Builder) uint64ValWithDefault(v *uint64, defaultVal uint64) uint64 {
 	if v == nil {
 		return defaultVal
@@ -1829,6 +3412,39 @@ func (b *Builder) portVal(name string, v *int) int {
 	return *v
 }
 
+// ephemeralPortVal behaves like portVal except that an explicit port of 0
+// asks the operating system for a free port. The chosen port is recorded
+// in b.ephemeralPorts so it can be written to the data dir and reported
+// through the self endpoint. Test harnesses and CI environments use this
+// to avoid port collisions.
+func (b *Builder) ephemeralPortVal(name string, v *int) int {
+	if v != nil && *v == 0 {
+		port, err := freePort()
+		if err != nil {
+			b.err = multierror.Append(b.err, fmt.Errorf("%s: cannot allocate an ephemeral port: %s", name, err))
+			return -1
+		}
+		if b.ephemeralPorts == nil {
+			b.ephemeralPorts = map[string]int{}
+		}
+		b.ephemeralPorts[strings.TrimPrefix(name, "ports.")] = port
+		return port
+	}
+	return b.portVal(name, v)
+}
+
+// freePort asks the kernel for an unused TCP port and releases it again.
+// The port is not reserved, so there is a small window in which another
+// process could claim it before the agent binds its listener.
+func freePort() (int, error) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return 0, err
+	}
+	defer l.Close()
+	return l.Addr().(*net.TCPAddr).Port, nil
+}
+
 func (b *Builder) stringValWithDefault(v *string, defaultVal string) string {
 	if v == nil {
 		return defaultVal
@@ -1850,6 +3466,28 @@ func stringVal(v *string) string {
 	return *v
 }
 
+// fileFuncRe matches values of the form file("<path>").
+var fileFuncRe = regexp.MustCompile(`^file\("([^"]*)"\)$`)
+
+// fileContentsVal returns the value of a *_contents config key. A value of
+// the form file("<path>") is resolved by reading path so that the contents
+// can be kept in a separate file while still being inlined when the config
+// is loaded. Relative paths are resolved against the working directory of
+// the agent.
+func (b *Builder) fileContentsVal(name string, v *string) string {
+	s := b.stringVal(v)
+	m := fileFuncRe.FindStringSubmatch(s)
+	if m == nil {
+		return s
+	}
+	data, err := ioutil.ReadFile(m[1])
+	if err != nil {
+		b.err = multierror.Append(b.err, fmt.Errorf("%s: failed to read %s: %s", name, m[1], err))
+		return ""
+	}
+	return string(data)
+}
+
 func (b *Builder) float64ValWithDefault(v *float64, defaultVal float64) float64 {
 	if v == nil {
 		return defaultVal
@@ -1878,6 +3516,166 @@ func (b *Builder) cidrsVal(name string, v []string) (nets []*net.IPNet) {
 	return
 }
 
+// headerNameRe matches the token characters RFC 7230 allows in an HTTP
+// header field name.
+var headerNameRe = regexp.MustCompile("^[A-Za-z0-9!#$%&'*+.^_`|~-]+$")
+
+// requestIDHeaderVal validates that http_config.request_id_header is a legal
+// HTTP header field name.
+func (b *Builder) requestIDHeaderVal(v *string) string {
+	header := b.stringVal(v)
+	if header != "" && !headerNameRe.MatchString(header) {
+		b.err = multierror.Append(b.err, fmt.Errorf(
+			"http_config.request_id_header is not a valid HTTP header name: %q", header))
+	}
+	return header
+}
+
+// blockEndpointRulesVal validates the structured block_endpoint_rules and
+// converts them to their runtime form. The path regex must compile and the
+// response code must be a client or server error code. Rules without a name
+// get one derived from their position so the per-rule metric has a label.
+func (b *Builder) blockEndpointRulesVal(v []BlockEndpointRule) []RuntimeBlockEndpointRule {
+	var rules []RuntimeBlockEndpointRule
+	for i, r := range v {
+		name := b.stringVal(r.Name)
+		if name == "" {
+			name = fmt.Sprintf("rule-%d", i)
+		}
+		pathRegex := b.stringVal(r.PathRegex)
+		if pathRegex == "" {
+			b.err = multierror.Append(b.err, fmt.Errorf(
+				"http_config.block_endpoint_rules[%d]: path_regex cannot be empty", i))
+		} else if _, err := regexp.Compile(pathRegex); err != nil {
+			b.err = multierror.Append(b.err, fmt.Errorf(
+				"http_config.block_endpoint_rules[%d]: invalid path_regex: %s", i, err))
+		}
+		code := b.intValWithDefault(r.ResponseCode, http.StatusForbidden)
+		if code < 400 || code > 599 {
+			b.err = multierror.Append(b.err, fmt.Errorf(
+				"http_config.block_endpoint_rules[%d]: response_code must be an HTTP error status code, was: %d", i, code))
+		}
+		var methods []string
+		for _, m := range r.Methods {
+			methods = append(methods, strings.ToUpper(m))
+		}
+		msg := b.stringVal(r.ResponseMessage)
+		if msg == "" {
+			msg = "Endpoint is blocked by agent configuration"
+		}
+		rules = append(rules, RuntimeBlockEndpointRule{
+			Name:            name,
+			PathRegex:       pathRegex,
+			Methods:         methods,
+			ResponseCode:    code,
+			ResponseMessage: msg,
+		})
+	}
+	return rules
+}
+
+// legacyEndpoints are the deprecated HTTP endpoints that can be switched
+// off with http_config.disable_legacy_endpoints. The acl entries are the
+// pre-1.4 endpoints operating on legacy tokens and the check entries are
+// the aliases that predate /v1/agent/check/update.
+var legacyEndpoints = []string{
+	"/v1/acl/clone",
+	"/v1/acl/create",
+	"/v1/acl/destroy",
+	"/v1/acl/info",
+	"/v1/acl/list",
+	"/v1/acl/update",
+	"/v1/agent/check/fail",
+	"/v1/agent/check/pass",
+	"/v1/agent/check/warn",
+}
+
+// disableLegacyEndpointsVal validates that every entry names a known
+// legacy endpoint so a typo cannot silently leave an endpoint enabled.
+func (b *Builder) disableLegacyEndpointsVal(v []string) []string {
+	for _, e := range v {
+		known := false
+		for _, l := range legacyEndpoints {
+			if e == l {
+				known = true
+				break
+			}
+		}
+		if !known {
+			b.err = multierror.Append(b.err, fmt.Errorf(
+				"http_config.disable_legacy_endpoints: unknown legacy endpoint %q. Known endpoints: %s",
+				e, strings.Join(legacyEndpoints, ", ")))
+		}
+	}
+	return v
+}
+
+// kvQuotasVal validates the limits.kv_quota entries and converts them to
+// their runtime form. Every entry needs a prefix and at least one limit,
+// and prefixes must not overlap since a key below two quotas would make
+// enforcement ambiguous.
+func (b *Builder) kvQuotasVal(v []KVQuota) []consul.KVQuota {
+	var quotas []consul.KVQuota
+	for i, q := range v {
+		prefix := b.stringVal(q.Prefix)
+		if prefix == "" {
+			b.err = multierror.Append(b.err, fmt.Errorf(
+				"limits.kv_quota[%d]: prefix cannot be empty", i))
+		}
+		maxKeys := b.intVal(q.MaxKeys)
+		maxBytes := b.intVal(q.MaxBytes)
+		if maxKeys < 0 || maxBytes < 0 {
+			b.err = multierror.Append(b.err, fmt.Errorf(
+				"limits.kv_quota[%d]: max_keys and max_bytes cannot be negative", i))
+		}
+		if maxKeys == 0 && maxBytes == 0 {
+			b.err = multierror.Append(b.err, fmt.Errorf(
+				"limits.kv_quota[%d]: at least one of max_keys and max_bytes must be set", i))
+		}
+		quotas = append(quotas, consul.KVQuota{
+			Prefix:   prefix,
+			MaxKeys:  maxKeys,
+			MaxBytes: int64(maxBytes),
+		})
+	}
+	for i, a := range quotas {
+		for _, c := range quotas[i+1:] {
+			if strings.HasPrefix(a.Prefix, c.Prefix) || strings.HasPrefix(c.Prefix, a.Prefix) {
+				b.err = multierror.Append(b.err, fmt.Errorf(
+					"limits.kv_quota: prefix %q overlaps with prefix %q", a.Prefix, c.Prefix))
+			}
+		}
+	}
+	return quotas
+}
+
+// tracingVal validates the tracing block and converts it to its runtime
+// form. The sampling ratio must fall within [0, 1] and the OTLP endpoint,
+// when set, must be a host:port pair or a URL.
+func (b *Builder) tracingVal(v Tracing) lib.TracingConfig {
+	cfg := lib.TracingConfig{
+		OTLPEndpoint:  b.stringVal(v.OTLPEndpoint),
+		SamplingRatio: b.float64ValWithDefault(v.SamplingRatio, 1),
+		ServiceName:   b.stringValWithDefault(v.ServiceName, "consul"),
+	}
+	if cfg.SamplingRatio < 0 || cfg.SamplingRatio > 1 {
+		b.err = multierror.Append(b.err, fmt.Errorf(
+			"tracing.sampling_ratio must be between 0 and 1, was: %v", cfg.SamplingRatio))
+	}
+	if cfg.OTLPEndpoint != "" {
+		if strings.Contains(cfg.OTLPEndpoint, "://") {
+			if u, err := url.Parse(cfg.OTLPEndpoint); err != nil || u.Host == "" {
+				b.err = multierror.Append(b.err, fmt.Errorf(
+					"tracing.otlp_endpoint is not a valid URL: %q", cfg.OTLPEndpoint))
+			}
+		} else if _, _, err := net.SplitHostPort(cfg.OTLPEndpoint); err != nil {
+			b.err = multierror.Append(b.err, fmt.Errorf(
+				"tracing.otlp_endpoint must be a host:port or URL, was: %q", cfg.OTLPEndpoint))
+		}
+	}
+	return cfg
+}
+
 func (b *Builder) tlsCipherSuites(name string, v *string) []uint16 {
 	if v == nil {
 		return nil
@@ -1904,10 +3702,49 @@ func (b *Builder) nodeName(v *string) string {
 			return ""
 		}
 		nodeName = name
+	} else {
+		name, err := parseTemplate(nodeName)
+		if err != nil {
+			b.err = multierror.Append(b.err, fmt.Errorf("node_name: error parsing template %q: %s", nodeName, err))
+			return ""
+		}
+		nodeName = name
 	}
 	return strings.TrimSpace(nodeName)
 }
 
+// dnsTagAllowlistVal validates the dns_config.tag_allowlist patterns. An
+// entry is an exact tag or a prefix pattern with a trailing '*'.
+func (b *Builder) dnsTagAllowlistVal(patterns []string) []string {
+	for _, p := range patterns {
+		if p == "" {
+			b.err = multierror.Append(b.err, fmt.Errorf("dns_config.tag_allowlist: pattern cannot be empty"))
+			continue
+		}
+		if i := strings.Index(p, "*"); i >= 0 && i != len(p)-1 {
+			b.err = multierror.Append(b.err, fmt.Errorf("dns_config.tag_allowlist: invalid pattern %q: '*' is only supported as a suffix", p))
+		}
+	}
+	return patterns
+}
+
+// nodeMetaVal expands templated values in the node_meta map.
+func (b *Builder) nodeMetaVal(m map[string]string) map[string]string {
+	if m == nil {
+		return nil
+	}
+	out := make(map[string]string, len(m))
+	for k, v := range m {
+		x, err := parseTemplate(v)
+		if err != nil {
+			b.err = multierror.Append(b.err, fmt.Errorf("node_meta: error parsing template %q: %s", v, err))
+			continue
+		}
+		out[k] = x
+	}
+	return out
+}
+
 // expandAddrs expands the go-sockaddr template in s and returns the
 // result as a list of *net.IPAddr and *net.UnixAddr.
 func (b *Builder) expandAddrs(name string, s *string) []net.Addr {
@@ -1915,7 +3752,7 @@ func (b *Builder) expandAddrs(name string, s *string) []net.Addr {
 		return nil
 	}
 
-	x, err := template.Parse(*s)
+	x, err := parseTemplate(*s)
 	if err != nil {
 		b.err = multierror.Append(b.err, fmt.Errorf("%s: error parsing %q: %s", name, *s, err))
 		return nil
@@ -1954,7 +3791,7 @@ func (b *Builder) expandOptionalAddrs(name string, s *string) []string {
 		return nil
 	}
 
-	x, err := template.Parse(*s)
+	x, err := parseTemplate(*s)
 	if err != nil {
 		b.err = multierror.Append(b.err, fmt.Errorf("%s: error parsing %q: %s", name, *s, err))
 		return nil
@@ -2136,7 +3973,7 @@ func (b *Builder) autoConfigVal(raw AutoConfigRaw) AutoConfig {
 	// default the IntroToken to the env variable if specified.
 	if envToken := os.Getenv("CONSUL_INTRO_TOKEN"); envToken != "" {
 		if val.IntroToken != "" {
-			b.warn("Both auto_config.intro_token and the CONSUL_INTRO_TOKEN environment variable are set. Using the value from the environment variable")
+			b.warnc("auto-config-intro-token", "Both auto_config.intro_token and the CONSUL_INTRO_TOKEN environment variable are set. Using the value from the environment variable")
 		}
 
 		val.IntroToken = envToken
@@ -2149,7 +3986,7 @@ func (b *Builder) autoConfigVal(raw AutoConfigRaw) AutoConfig {
 	for _, i := range raw.IPSANs {
 		ip := net.ParseIP(i)
 		if ip == nil {
-			b.warn(fmt.Sprintf("Cannot parse ip %q from auto_config.ip_sans", i))
+			b.warnc("auto-config-ip-san", fmt.Sprintf("Cannot parse ip %q from auto_config.ip_sans", i))
 			continue
 		}
 		val.IPSANs = append(val.IPSANs, ip)
@@ -2219,7 +4056,7 @@ func (b *Builder) validateAutoConfig(rt RuntimeConfig) error {
 
 	// When both are set we will prefer the given value over the file.
 	if autoconf.IntroToken != "" && autoconf.IntroTokenFile != "" {
-		b.warn("Both an intro token and intro token file are set. The intro token will be used instead of the file")
+		b.warnc("auto-config-intro-token", "Both an intro token and intro token file are set. The intro token will be used instead of the file")
 	} else if autoconf.IntroToken == "" && autoconf.IntroTokenFile == "" {
 		return fmt.Errorf("One of auto_config.intro_token, auto_config.intro_token_file or the CONSUL_INTRO_TOKEN environment variable must be set to enable auto_config")
 	}
@@ -2292,6 +4129,30 @@ func (b *Builder) validateAutoConfigAuthorizer(rt RuntimeConfig) error {
 	return nil
 }
 
+// warnBootstrapIntentions emits warnings for service-intentions entries in
+// config_entries.bootstrap that are valid but almost certainly not what the
+// operator intended. The entry has already been normalized so source types
+// are defaulted and sources are sorted by precedence.
+func (b *Builder) warnBootstrapIntentions(e *structs.ServiceIntentionsConfigEntry) {
+	// An exact source always takes precedence over a wildcard source so an
+	// exact source repeating the wildcard's action is a no-op. Warn so that
+	// config files don't accumulate dead intentions.
+	for _, wild := range e.Sources {
+		if wild.Name != structs.WildcardSpecifier || wild.Action == "" {
+			continue
+		}
+		for _, src := range e.Sources {
+			if src.Name == structs.WildcardSpecifier || src.Action == "" {
+				continue
+			}
+			if src.Action == wild.Action {
+				b.warnc("service-intentions-redundant-source", "service-intentions %q: source %q is redundant, the wildcard source already specifies action %q",
+					e.Name, src.SourceServiceName().String(), wild.Action)
+			}
+		}
+	}
+}
+
 // decodeBytes returns the encryption key decoded.
 func decodeBytes(key string) ([]byte, error) {
 	return base64.StdEncoding.DecodeString(key)
@@ -2364,3 +4225,90 @@ func validateAbsoluteURLPath(p string) error {
 
 	return nil
 }
+
+// OutboundProxyCredentials reads the file referenced by
+// outbound_proxy.credentials_file and returns the proxy user info it
+// holds. The file must contain a single "username:password" line. The
+// caller re-reads the file when the proxy is configured so credentials
+// never live in the main configuration.
+func OutboundProxyCredentials(path string) (*url.Userinfo, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("outbound_proxy.credentials_file: %s", err)
+	}
+	creds := strings.TrimSpace(string(data))
+	parts := strings.SplitN(creds, ":", 2)
+	if len(parts) != 2 || parts[0] == "" {
+		return nil, fmt.Errorf("outbound_proxy.credentials_file must contain a single username:password line")
+	}
+	return url.UserPassword(parts[0], parts[1]), nil
+}
+
+// validateRecursor checks the scheme-prefixed recursor forms at build time
+// so a typo fails config validation instead of agent start. tls:// entries
+// carry the optional TLS server name behind a # fragment and https://
+// entries are DNS-over-HTTPS endpoints. Plain host:port entries keep being
+// validated when the DNS server is created.
+func validateRecursor(addr string) error {
+	switch {
+	case strings.HasPrefix(addr, "tls://"):
+		rest := strings.TrimPrefix(addr, "tls://")
+		if i := strings.Index(rest, "#"); i >= 0 {
+			if rest[i+1:] == "" {
+				return fmt.Errorf("tls recursor server name cannot be empty")
+			}
+			rest = rest[:i]
+		}
+		if rest == "" {
+			return fmt.Errorf("tls recursor requires a host")
+		}
+	case strings.HasPrefix(addr, "https://"):
+		u, err := url.Parse(addr)
+		if err != nil {
+			return err
+		}
+		if u.Host == "" {
+			return fmt.Errorf("https recursor requires a host")
+		}
+	case strings.Contains(addr, "://"):
+		return fmt.Errorf("recursor scheme must be tls or https")
+	}
+	return nil
+}
+
+// validateListenerBinds briefly binds every configured DNS, HTTP, HTTPS
+// and gRPC address and closes the probe socket again so address conflicts
+// — including ports held by other processes — surface during validation
+// instead of after the agent claims to start. The cluster ports are
+// deliberately left out: they may legitimately be unavailable while a
+// previous agent is still draining. Only used when validate_listeners is
+// set.
+func validateListenerBinds(rt RuntimeConfig) error {
+	var errs error
+	probe := func(name string, addrs []net.Addr) {
+		for _, addr := range addrs {
+			switch x := addr.(type) {
+			case *net.TCPAddr:
+				l, err := net.Listen("tcp", x.String())
+				if err != nil {
+					errs = multierror.Append(errs, fmt.Errorf("validate_listeners: %s address %s is not bindable: %s", name, x.String(), err))
+					continue
+				}
+				l.Close()
+			case *net.UDPAddr:
+				c, err := net.ListenPacket("udp", x.String())
+				if err != nil {
+					errs = multierror.Append(errs, fmt.Errorf("validate_listeners: %s address %s is not bindable: %s", name, x.String(), err))
+					continue
+				}
+				c.Close()
+			}
+		}
+	}
+	probe("dns", rt.DNSAddrs)
+	probe("http", rt.HTTPAddrs)
+	probe("https", rt.HTTPSAddrs)
+	probe("grpc", rt.GRPCAddrs)
+	probe("grpc_tls", rt.GRPCTLSAddrs)
+	return errs
+}
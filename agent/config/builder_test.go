@@ -1,6 +1,12 @@
 package config
 
 import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
 	"fmt"
 	"io/ioutil"
 	"net"
@@ -62,6 +68,31 @@ func TestShouldParseFile(t *testing.T) {
 	}
 }
 
+func TestInterfaceAddr(t *testing.T) {
+	ifaces, err := net.Interfaces()
+	require.NoError(t, err)
+
+	var lo *net.Interface
+	for i, iface := range ifaces {
+		if iface.Flags&net.FlagLoopback != 0 {
+			lo = &ifaces[i]
+			break
+		}
+	}
+	if lo == nil {
+		t.Skip("no loopback interface found")
+	}
+
+	addr, err := interfaceAddr(lo.Name, "ipv4")
+	require.NoError(t, err)
+	ip := net.ParseIP(addr)
+	require.NotNil(t, ip)
+	require.True(t, ip.IsLoopback())
+
+	_, err = interfaceAddr("no-such-interface", "ipv4")
+	require.Error(t, err)
+}
+
 func TestNewBuilder_PopulatesSourcesFromConfigFiles(t *testing.T) {
 	paths := setupConfigFiles(t)
 
@@ -95,6 +126,432 @@ func TestNewBuilder_PopulatesSourcesFromConfigFiles_WithConfigFormat(t *testing.
 	require.Equal(t, expected, b.Sources)
 }
 
+func TestNewBuilder_PopulatesSourcesFromStdin(t *testing.T) {
+	b, err := NewBuilder(BuilderOpts{
+		ConfigFiles:  []string{"-"},
+		ConfigFormat: "hcl",
+		stdin:        strings.NewReader(`node_name = "hobbiton"`),
+	})
+	require.NoError(t, err)
+
+	expected := []Source{
+		FileSource{Name: "stdin", Format: "hcl", Data: `node_name = "hobbiton"`},
+	}
+	require.Equal(t, expected, b.Sources)
+}
+
+func TestNewBuilder_StdinRequiresConfigFormat(t *testing.T) {
+	_, err := NewBuilder(BuilderOpts{
+		ConfigFiles: []string{"-"},
+		stdin:       strings.NewReader(`{}`),
+	})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "-config-format")
+}
+
+func TestNewBuilder_VersionMetadata(t *testing.T) {
+	devMode := true
+	b, err := NewBuilder(BuilderOpts{
+		DevMode: &devMode,
+		VersionMetadata: &VersionMetadata{
+			Revision:          "abcdef12",
+			VersionPrerelease: "distro1",
+		},
+	})
+	require.NoError(t, err)
+	patchBuilderShims(b)
+
+	rt, err := b.BuildAndValidate()
+	require.NoError(t, err)
+	require.Equal(t, "abcdef12", rt.Revision)
+	require.Equal(t, "distro1", rt.VersionPrerelease)
+}
+
+func TestNewBuilder_VersionMetadataConflict(t *testing.T) {
+	devMode := true
+	_, err := NewBuilder(BuilderOpts{
+		DevMode: &devMode,
+		VersionMetadata: &VersionMetadata{
+			Version: "0.0.1-not-this-build",
+		},
+	})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "conflicts with the compiled version")
+}
+
+func TestNewBuilder_ExpandsIncludes(t *testing.T) {
+	path, err := ioutil.TempDir("", t.Name())
+	require.NoError(t, err)
+	t.Cleanup(func() { os.RemoveAll(path) })
+
+	require.NoError(t, os.Mkdir(filepath.Join(path, "shared"), 0755))
+
+	files := map[string]string{
+		"main.hcl":        `include = ["shared/*.hcl"]` + "\n" + `node_name = "hobbiton"`,
+		"shared/a.hcl":    `datacenter = "dc2"`,
+		"shared/b.hcl":    `include = ["../extra.json"]`,
+		"extra.json":      `{"log_level": "DEBUG"}`,
+		"shared/skip.txt": `ignored`,
+	}
+	for name, data := range files {
+		require.NoError(t, ioutil.WriteFile(filepath.Join(path, name), []byte(data), 0644))
+	}
+
+	b, err := NewBuilder(BuilderOpts{ConfigFiles: []string{filepath.Join(path, "main.hcl")}})
+	require.NoError(t, err)
+
+	expected := []Source{
+		FileSource{Name: filepath.Join(path, "main.hcl"), Format: "hcl", Data: files["main.hcl"]},
+		FileSource{Name: filepath.Join(path, "shared", "a.hcl"), Format: "hcl", Data: files["shared/a.hcl"]},
+		FileSource{Name: filepath.Join(path, "shared", "b.hcl"), Format: "hcl", Data: files["shared/b.hcl"]},
+		FileSource{Name: filepath.Join(path, "extra.json"), Format: "json", Data: files["extra.json"]},
+	}
+	require.Equal(t, expected, b.Sources)
+}
+
+func TestNewBuilder_IncludeCycleDetection(t *testing.T) {
+	path, err := ioutil.TempDir("", t.Name())
+	require.NoError(t, err)
+	t.Cleanup(func() { os.RemoveAll(path) })
+
+	files := map[string]string{
+		"a.hcl": `include = ["b.hcl"]`,
+		"b.hcl": `include = ["a.hcl"]`,
+	}
+	for name, data := range files {
+		require.NoError(t, ioutil.WriteFile(filepath.Join(path, name), []byte(data), 0644))
+	}
+
+	_, err = NewBuilder(BuilderOpts{ConfigFiles: []string{filepath.Join(path, "a.hcl")}})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "include cycle detected")
+	require.Contains(t, err.Error(), filepath.Join(path, "a.hcl"))
+}
+
+func TestNewBuilder_ConfigDirFollowSymlink(t *testing.T) {
+	path, err := ioutil.TempDir("", t.Name())
+	require.NoError(t, err)
+	t.Cleanup(func() { os.RemoveAll(path) })
+
+	for gen, data := range map[string]string{
+		"gen-1": `node_name = "hobbiton"`,
+		"gen-2": `node_name = "bree"`,
+	} {
+		require.NoError(t, os.Mkdir(filepath.Join(path, gen), 0755))
+		require.NoError(t, ioutil.WriteFile(filepath.Join(path, gen, "a.hcl"), []byte(data), 0644))
+	}
+
+	current := filepath.Join(path, "current")
+	require.NoError(t, os.Symlink(filepath.Join(path, "gen-1"), current))
+
+	opts := BuilderOpts{
+		ConfigFiles: []string{current},
+		Config:      Config{ConfigDirFollowSymlink: pBool(true)},
+	}
+	b, err := NewBuilder(opts)
+	require.NoError(t, err)
+	expected := []Source{
+		FileSource{Name: filepath.Join(current, "a.hcl"), Format: "hcl", Data: `node_name = "hobbiton"`},
+	}
+	require.Equal(t, expected, b.Sources)
+
+	// swap the symlink to the next generation and load again, like a reload
+	// would, and check that the new content comes back under the same name
+	require.NoError(t, os.Remove(current))
+	require.NoError(t, os.Symlink(filepath.Join(path, "gen-2"), current))
+
+	b, err = NewBuilder(opts)
+	require.NoError(t, err)
+	expected = []Source{
+		FileSource{Name: filepath.Join(current, "a.hcl"), Format: "hcl", Data: `node_name = "bree"`},
+	}
+	require.Equal(t, expected, b.Sources)
+}
+
+func TestNewBuilder_DecryptsEncryptedConfigFiles(t *testing.T) {
+	path, err := ioutil.TempDir("", t.Name())
+	require.NoError(t, err)
+	t.Cleanup(func() { os.RemoveAll(path) })
+
+	key := make([]byte, 32)
+	_, err = rand.Read(key)
+	require.NoError(t, err)
+	keyFile := filepath.Join(path, "config.key")
+	require.NoError(t, ioutil.WriteFile(keyFile, []byte(base64.StdEncoding.EncodeToString(key)), 0600))
+
+	plain := `node_name = "hobbiton"`
+	data, err := encryptConfig([]byte(plain), key)
+	require.NoError(t, err)
+	cfgFile := filepath.Join(path, "a.hcl.age")
+	require.NoError(t, ioutil.WriteFile(cfgFile, data, 0644))
+
+	b, err := NewBuilder(BuilderOpts{
+		ConfigFiles:      []string{cfgFile},
+		ConfigDecryptKey: keyFile,
+	})
+	require.NoError(t, err)
+	expected := []Source{
+		FileSource{Name: cfgFile, Format: "hcl", Data: plain},
+	}
+	require.Equal(t, expected, b.Sources)
+}
+
+func TestNewBuilder_EncryptedConfigFileRequiresKey(t *testing.T) {
+	path, err := ioutil.TempDir("", t.Name())
+	require.NoError(t, err)
+	t.Cleanup(func() { os.RemoveAll(path) })
+
+	cfgFile := filepath.Join(path, "a.hcl.age")
+	require.NoError(t, ioutil.WriteFile(cfgFile, []byte("garbage"), 0644))
+
+	_, err = NewBuilder(BuilderOpts{ConfigFiles: []string{cfgFile}})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "-config-decrypt-key is not set")
+}
+
+func TestNewBuilder_EncryptedConfigFileWrongKey(t *testing.T) {
+	path, err := ioutil.TempDir("", t.Name())
+	require.NoError(t, err)
+	t.Cleanup(func() { os.RemoveAll(path) })
+
+	key := make([]byte, 32)
+	_, err = rand.Read(key)
+	require.NoError(t, err)
+	data, err := encryptConfig([]byte(`{"node_name": "hobbiton"}`), key)
+	require.NoError(t, err)
+	cfgFile := filepath.Join(path, "a.json.age")
+	require.NoError(t, ioutil.WriteFile(cfgFile, data, 0644))
+
+	wrongKey := make([]byte, 32)
+	keyFile := filepath.Join(path, "config.key")
+	require.NoError(t, ioutil.WriteFile(keyFile, []byte(base64.StdEncoding.EncodeToString(wrongKey)), 0600))
+
+	_, err = NewBuilder(BuilderOpts{
+		ConfigFiles:      []string{cfgFile},
+		ConfigDecryptKey: keyFile,
+	})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "failed to decrypt")
+}
+
+func TestNewBuilder_ConfigDirPriority(t *testing.T) {
+	path, err := ioutil.TempDir("", t.Name())
+	require.NoError(t, err)
+	t.Cleanup(func() { os.RemoveAll(path) })
+
+	files := map[string]string{
+		"a.hcl": `priority = 20` + "\n" + `node_name = "bree"`,
+		"b.hcl": `node_name = "hobbiton"`,
+		"c.hcl": `priority = 10` + "\n" + `node_name = "rivendell"`,
+		"d.hcl": `datacenter = "dc2"`,
+	}
+	for name, data := range files {
+		require.NoError(t, ioutil.WriteFile(filepath.Join(path, name), []byte(data), 0644))
+	}
+
+	b, err := NewBuilder(BuilderOpts{ConfigFiles: []string{path}})
+	require.NoError(t, err)
+
+	// files without a priority default to 0 and keep their alphabetical
+	// order, prioritized files are merged after them in ascending order
+	expected := []Source{
+		FileSource{Name: filepath.Join(path, "b.hcl"), Format: "hcl", Data: files["b.hcl"]},
+		FileSource{Name: filepath.Join(path, "d.hcl"), Format: "hcl", Data: files["d.hcl"]},
+		FileSource{Name: filepath.Join(path, "c.hcl"), Format: "hcl", Data: files["c.hcl"]},
+		FileSource{Name: filepath.Join(path, "a.hcl"), Format: "hcl", Data: files["a.hcl"]},
+	}
+	require.Equal(t, expected, b.Sources)
+}
+
+func TestNewBuilder_PopulatesSourcesFromTarBundle(t *testing.T) {
+	path, err := ioutil.TempDir("", t.Name())
+	require.NoError(t, err)
+	t.Cleanup(func() { os.RemoveAll(path) })
+
+	files := map[string]string{
+		"b.hcl":    `node_name = "hobbiton"`,
+		"a.json":   `{"datacenter": "dc2"}`,
+		"skip.txt": `ignored`,
+	}
+
+	bundle := filepath.Join(path, "bundle.tgz")
+	f, err := os.Create(bundle)
+	require.NoError(t, err)
+	gz := gzip.NewWriter(f)
+	tw := tar.NewWriter(gz)
+	for name, data := range files {
+		require.NoError(t, tw.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(data))}))
+		_, err = tw.Write([]byte(data))
+		require.NoError(t, err)
+	}
+	require.NoError(t, tw.Close())
+	require.NoError(t, gz.Close())
+	require.NoError(t, f.Close())
+
+	b, err := NewBuilder(BuilderOpts{ConfigFiles: []string{bundle}})
+	require.NoError(t, err)
+
+	expected := []Source{
+		FileSource{Name: bundle + ":a.json", Format: "json", Data: files["a.json"]},
+		FileSource{Name: bundle + ":b.hcl", Format: "hcl", Data: files["b.hcl"]},
+	}
+	require.Equal(t, expected, b.Sources)
+	require.Len(t, b.Warnings, 1)
+	require.Contains(t, b.Warnings[0].String(), "skip.txt")
+}
+
+func TestNewBuilder_PopulatesSourcesFromZipBundle(t *testing.T) {
+	path, err := ioutil.TempDir("", t.Name())
+	require.NoError(t, err)
+	t.Cleanup(func() { os.RemoveAll(path) })
+
+	bundle := filepath.Join(path, "bundle.zip")
+	f, err := os.Create(bundle)
+	require.NoError(t, err)
+	zw := zip.NewWriter(f)
+	w, err := zw.Create("conf/a.hcl")
+	require.NoError(t, err)
+	_, err = w.Write([]byte(`node_name = "hobbiton"`))
+	require.NoError(t, err)
+	require.NoError(t, zw.Close())
+	require.NoError(t, f.Close())
+
+	b, err := NewBuilder(BuilderOpts{ConfigFiles: []string{bundle}})
+	require.NoError(t, err)
+
+	expected := []Source{
+		FileSource{Name: bundle + ":conf/a.hcl", Format: "hcl", Data: `node_name = "hobbiton"`},
+	}
+	require.Equal(t, expected, b.Sources)
+}
+
+func TestBuilder_FileContentsVal(t *testing.T) {
+	path, err := ioutil.TempDir("", t.Name())
+	require.NoError(t, err)
+	t.Cleanup(func() { os.RemoveAll(path) })
+
+	pem := "-----BEGIN CERTIFICATE-----\nnot-a-real-cert\n-----END CERTIFICATE-----\n"
+	caFile := filepath.Join(path, "ca.pem")
+	require.NoError(t, ioutil.WriteFile(caFile, []byte(pem), 0644))
+
+	devMode := true
+	b, err := NewBuilder(BuilderOpts{
+		DevMode: &devMode,
+		HCL:     []string{`ca_file_contents = "file(\"` + caFile + `\")"`},
+	})
+	require.NoError(t, err)
+	patchBuilderShims(b)
+
+	rt, err := b.BuildAndValidate()
+	require.NoError(t, err)
+	require.Equal(t, pem, rt.CAFileContents)
+}
+
+func TestBuilder_FileContentsValMissingFile(t *testing.T) {
+	devMode := true
+	b, err := NewBuilder(BuilderOpts{
+		DevMode: &devMode,
+		HCL:     []string{`ca_file_contents = "file(\"/no/such/file.pem\")"`},
+	})
+	require.NoError(t, err)
+	patchBuilderShims(b)
+
+	_, err = b.BuildAndValidate()
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "ca_file_contents: failed to read /no/such/file.pem")
+}
+
+// setupSignedConfigDir writes a config directory with two config files, a
+// base64 encoded ed25519 public key and a detached signature over the config
+// file contents so that config verification succeeds.
+func setupSignedConfigDir(t *testing.T) (confDir, keyFile, sigFile string) {
+	t.Helper()
+	path, err := ioutil.TempDir("", t.Name())
+	require.NoError(t, err)
+	t.Cleanup(func() { os.RemoveAll(path) })
+
+	confDir = filepath.Join(path, "conf")
+	require.NoError(t, os.Mkdir(confDir, 0755))
+
+	files := map[string]string{
+		"a.hcl":  `node_name = "hobbiton"`,
+		"b.json": `{"datacenter": "dc2"}`,
+	}
+	var signed []byte
+	for _, name := range []string{"a.hcl", "b.json"} {
+		require.NoError(t, ioutil.WriteFile(filepath.Join(confDir, name), []byte(files[name]), 0644))
+		signed = append(signed, []byte(name+"\n"+files[name]+"\n")...)
+	}
+
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	keyFile = filepath.Join(path, "config.pub")
+	require.NoError(t, ioutil.WriteFile(keyFile, []byte(base64.StdEncoding.EncodeToString(pub)), 0644))
+
+	sigFile = filepath.Join(path, "config.sig")
+	sig := ed25519.Sign(priv, signed)
+	require.NoError(t, ioutil.WriteFile(sigFile, []byte(base64.StdEncoding.EncodeToString(sig)), 0644))
+	return confDir, keyFile, sigFile
+}
+
+func TestNewBuilder_ConfigVerification(t *testing.T) {
+	confDir, keyFile, sigFile := setupSignedConfigDir(t)
+
+	b, err := NewBuilder(BuilderOpts{
+		ConfigFiles: []string{confDir},
+		Config: Config{ConfigVerification: ConfigVerification{
+			PublicKeyFile: &keyFile,
+			SignatureFile: &sigFile,
+			Required:      pBool(true),
+		}},
+	})
+	require.NoError(t, err)
+	require.Len(t, b.Sources, 2)
+}
+
+func TestNewBuilder_ConfigVerificationTamperedFile(t *testing.T) {
+	confDir, keyFile, sigFile := setupSignedConfigDir(t)
+	err := ioutil.WriteFile(filepath.Join(confDir, "a.hcl"), []byte(`node_name = "mordor"`), 0644)
+	require.NoError(t, err)
+
+	_, err = NewBuilder(BuilderOpts{
+		ConfigFiles: []string{confDir},
+		Config: Config{ConfigVerification: ConfigVerification{
+			PublicKeyFile: &keyFile,
+			SignatureFile: &sigFile,
+		}},
+	})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "does not match the config file contents")
+}
+
+func TestNewBuilder_ConfigVerificationMissingSignature(t *testing.T) {
+	confDir, keyFile, sigFile := setupSignedConfigDir(t)
+	require.NoError(t, os.Remove(sigFile))
+
+	cv := ConfigVerification{
+		PublicKeyFile: &keyFile,
+		SignatureFile: &sigFile,
+		Required:      pBool(true),
+	}
+	_, err := NewBuilder(BuilderOpts{
+		ConfigFiles: []string{confDir},
+		Config:      Config{ConfigVerification: cv},
+	})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "failed to read config_verification.signature_file")
+
+	cv.Required = pBool(false)
+	b, err := NewBuilder(BuilderOpts{
+		ConfigFiles: []string{confDir},
+		Config:      Config{ConfigVerification: cv},
+	})
+	require.NoError(t, err)
+	require.Len(t, b.Warnings, 1)
+	require.Contains(t, b.Warnings[0].String(), "skipping config verification")
+}
+
 // TODO: this would be much nicer with gotest.tools/fs
 func setupConfigFiles(t *testing.T) []string {
 	t.Helper()
@@ -124,6 +581,28 @@ func setupConfigFiles(t *testing.T) []string {
 	}
 }
 
+func TestBuilder_Validate_AggregatesErrors(t *testing.T) {
+	b, err := NewBuilder(BuilderOpts{})
+	require.NoError(t, err)
+	patchBuilderShims(b)
+	b.Sources = append(b.Sources, FileSource{
+		Name:   "invalid.hcl",
+		Format: "hcl",
+		Data: `
+			data_dir = "dir"
+			bootstrap_expect = -1
+			dns_config { udp_answer_limit = -5 }
+			xds { max_concurrent_streams = -1 }
+		`,
+	})
+
+	_, err = b.BuildAndValidate()
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "bootstrap_expect cannot be -1")
+	require.Contains(t, err.Error(), "dns_config.udp_answer_limit cannot be -5")
+	require.Contains(t, err.Error(), "xds.max_concurrent_streams cannot be -1")
+}
+
 func TestBuilder_BuildAndValidate_NodeName(t *testing.T) {
 	type testCase struct {
 		name         string
@@ -143,7 +622,7 @@ func TestBuilder_BuildAndValidate_NodeName(t *testing.T) {
 		_, err = b.BuildAndValidate()
 		require.NoError(t, err)
 		require.Len(t, b.Warnings, 1)
-		require.Contains(t, b.Warnings[0], tc.expectedWarn)
+		require.Contains(t, b.Warnings[0].String(), tc.expectedWarn)
 	}
 
 	var testCases = []testCase{
@@ -182,3 +661,46 @@ func patchBuilderShims(b *Builder) {
 		return []*net.IPAddr{ipAddr("dead:beef::1")}, nil
 	}
 }
+
+func TestValidateListenerBinds(t *testing.T) {
+	tl, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer tl.Close()
+	ul, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer ul.Close()
+
+	rt := RuntimeConfig{
+		ValidateListeners: true,
+		DNSAddrs:          []net.Addr{ul.LocalAddr()},
+		HTTPAddrs:         []net.Addr{tl.Addr()},
+	}
+
+	err = validateListenerBinds(rt)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), fmt.Sprintf("http address %s is not bindable", tl.Addr()))
+	require.Contains(t, err.Error(), fmt.Sprintf("dns address %s is not bindable", ul.LocalAddr()))
+
+	tl.Close()
+	ul.Close()
+	require.NoError(t, validateListenerBinds(rt))
+}
+
+func TestBuilder_EphemeralPorts(t *testing.T) {
+	devMode := true
+	cfg, _, err := Load(BuilderOpts{DevMode: &devMode}, FileSource{
+		Name:   "test",
+		Format: "hcl",
+		Data:   `ports { http = 0 grpc = 0 }`,
+	})
+	require.NoError(t, err)
+
+	require.True(t, cfg.HTTPPort > 0, "expected an allocated http port, got %d", cfg.HTTPPort)
+	require.True(t, cfg.GRPCPort > 0, "expected an allocated grpc port, got %d", cfg.GRPCPort)
+	require.Equal(t, map[string]int{"http": cfg.HTTPPort, "grpc": cfg.GRPCPort}, cfg.EphemeralPorts)
+
+	require.Len(t, cfg.HTTPAddrs, 1)
+	tcp, ok := cfg.HTTPAddrs[0].(*net.TCPAddr)
+	require.True(t, ok)
+	require.Equal(t, cfg.HTTPPort, tcp.Port)
+}
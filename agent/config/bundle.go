@@ -0,0 +1,139 @@
+package config
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+)
+
+// isBundle returns true if path points at a config bundle archive that
+// sourcesFromBundle knows how to read.
+func isBundle(path string) bool {
+	switch {
+	case strings.HasSuffix(path, ".tar"),
+		strings.HasSuffix(path, ".tar.gz"),
+		strings.HasSuffix(path, ".tgz"),
+		strings.HasSuffix(path, ".zip"):
+		return true
+	}
+	return false
+}
+
+// sourcesFromBundle reads config files out of a tar or zip archive without
+// extracting it to disk. The contained .hcl and .json files are loaded in
+// sorted order by their path inside the archive so a bundle behaves like a
+// config directory that is distributed as a single file. Other files are
+// skipped with a warning, directories are not special: every file entry is
+// considered regardless of how deeply it is nested.
+func (b *Builder) sourcesFromBundle(path string, format string) ([]Source, error) {
+	files, err := readBundle(path)
+	if err != nil {
+		return nil, fmt.Errorf("config: failed to read bundle %s: %s", path, err)
+	}
+
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var sources []Source
+	for _, name := range names {
+		// qualify the name with the bundle path so that parse errors and
+		// warnings point at both the archive and the file within it
+		fp := path + ":" + name
+		if !shouldParseFile(name, format) {
+			b.warning(Warning{
+				Code:    "skipped-file",
+				Source:  fp,
+				Message: fmt.Sprintf("skipping file %v, extension must be .hcl or .json, or config format must be set", fp),
+			})
+			continue
+		}
+		srcFormat := format
+		if srcFormat == "" {
+			srcFormat = formatFromFileExtension(name)
+		}
+		sources = append(sources, FileSource{Name: fp, Data: files[name], Format: srcFormat})
+	}
+	return sources, nil
+}
+
+// readBundle returns the content of every file in the archive keyed by its
+// path inside the archive.
+func readBundle(path string) (map[string]string, error) {
+	if strings.HasSuffix(path, ".zip") {
+		return readZipBundle(path)
+	}
+	return readTarBundle(path)
+}
+
+func readTarBundle(path string) (map[string]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var r io.Reader = f
+	if !strings.HasSuffix(path, ".tar") {
+		gz, err := gzip.NewReader(f)
+		if err != nil {
+			return nil, err
+		}
+		defer gz.Close()
+		r = gz
+	}
+
+	files := map[string]string{}
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return files, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+		data, err := ioutil.ReadAll(tr)
+		if err != nil {
+			return nil, err
+		}
+		files[hdr.Name] = string(data)
+	}
+}
+
+func readZipBundle(path string) (map[string]string, error) {
+	zr, err := zip.OpenReader(path)
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+
+	files := map[string]string{}
+	for _, zf := range zr.File {
+		if zf.FileInfo().IsDir() {
+			continue
+		}
+		rc, err := zf.Open()
+		if err != nil {
+			return nil, err
+		}
+		data, err := ioutil.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			return nil, err
+		}
+		files[zf.Name] = string(data)
+	}
+	return files, nil
+}
@@ -0,0 +1,151 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl"
+)
+
+// schemaVersions lists the config schema versions the compatibility check
+// knows about, oldest first. A schema version is the minor Consul release
+// that introduced it.
+var schemaVersions = []string{"1.8", "1.9", "1.10"}
+
+// keysIntroduced records which config keys each schema version added. An
+// agent running an older version refuses config files that set any of these
+// keys, so the list is what CheckCompat reports against. Nested keys use
+// dotted notation. When a new config key is added to Config it must be
+// recorded here under the version that ships it.
+var keysIntroduced = map[string][]string{
+	"1.9": {
+		"cache",
+		"ui_config",
+	},
+	"1.10": {
+		"auto_reload_config",
+		"ca_file_contents",
+		"cert_file_contents",
+		"config_verification",
+		"connect.upstream_defaults",
+		"include",
+		"key_file_contents",
+		"mdns_advertise",
+		"suppress_warnings",
+		"xds",
+	},
+}
+
+// CompatIssue reports a config key that an agent running an older config
+// schema version would reject.
+type CompatIssue struct {
+	// Source is the config file that sets the key.
+	Source string `json:"source"`
+
+	// Key is the offending config key in dotted notation.
+	Key string `json:"key"`
+
+	// Version is the oldest schema version that accepts the key.
+	Version string `json:"version"`
+}
+
+// CheckCompat parses the config files and directories in paths exactly as
+// the agent would and reports every key that an agent running the given
+// schema version would reject. It exists so that a single config tree can
+// be verified against the oldest agent version in a mixed fleet before it
+// is rolled out. A nil result means the corpus is compatible.
+func CheckCompat(paths []string, format, version string) ([]CompatIssue, error) {
+	idx := -1
+	for i, v := range schemaVersions {
+		if v == version {
+			idx = i
+		}
+	}
+	if idx < 0 {
+		return nil, fmt.Errorf("unknown schema version %q. Known versions: %s",
+			version, strings.Join(schemaVersions, ", "))
+	}
+
+	// Collect the keys the given version does not understand yet.
+	newer := map[string]string{}
+	for _, v := range schemaVersions[idx+1:] {
+		for _, k := range keysIntroduced[v] {
+			newer[k] = v
+		}
+	}
+
+	b, err := NewBuilder(BuilderOpts{ConfigFiles: paths, ConfigFormat: format})
+	if err != nil {
+		return nil, err
+	}
+
+	var issues []CompatIssue
+	for _, src := range b.Sources {
+		fs, ok := src.(FileSource)
+		if !ok {
+			continue
+		}
+		keys, err := rawKeys(fs)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing %s: %s", fs.Name, err)
+		}
+		for k, v := range newer {
+			if keys[k] {
+				issues = append(issues, CompatIssue{Source: fs.Name, Key: k, Version: v})
+			}
+		}
+	}
+	sort.Slice(issues, func(i, j int) bool {
+		if issues[i].Source != issues[j].Source {
+			return issues[i].Source < issues[j].Source
+		}
+		return issues[i].Key < issues[j].Key
+	})
+	return issues, nil
+}
+
+// rawKeys returns the set of dotted key paths the file sets, without
+// interpreting them against the Config struct.
+func rawKeys(f FileSource) (map[string]bool, error) {
+	var raw map[string]interface{}
+	var err error
+	switch f.Format {
+	case "json":
+		err = json.Unmarshal([]byte(f.Data), &raw)
+	case "hcl":
+		err = hcl.Decode(&raw, f.Data)
+	default:
+		err = fmt.Errorf("invalid format: %s", f.Format)
+	}
+	if err != nil {
+		return nil, err
+	}
+	keys := map[string]bool{}
+	flattenKeys("", raw, keys)
+	return keys, nil
+}
+
+func flattenKeys(prefix string, v interface{}, keys map[string]bool) {
+	switch x := v.(type) {
+	case map[string]interface{}:
+		for k, v := range x {
+			path := k
+			if prefix != "" {
+				path = prefix + "." + k
+			}
+			keys[path] = true
+			flattenKeys(path, v, keys)
+		}
+	case []map[string]interface{}:
+		// HCL decodes blocks as a list of maps.
+		for _, m := range x {
+			flattenKeys(prefix, m, keys)
+		}
+	case []interface{}:
+		for _, e := range x {
+			flattenKeys(prefix, e, keys)
+		}
+	}
+}
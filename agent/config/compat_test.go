@@ -0,0 +1,51 @@
+package config
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/hashicorp/consul/sdk/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckCompat(t *testing.T) {
+	dir := testutil.TempDir(t, "compat")
+
+	err := ioutil.WriteFile(filepath.Join(dir, "a.hcl"), []byte(`
+		node_name = "compat"
+		ui_config {
+			enabled = true
+		}
+	`), 0644)
+	require.NoError(t, err)
+	err = ioutil.WriteFile(filepath.Join(dir, "b.json"), []byte(`
+		{"auto_reload_config": true, "connect": {"upstream_defaults": {"connect_timeout_ms": 5000}}}
+	`), 0644)
+	require.NoError(t, err)
+
+	issues, err := CheckCompat([]string{dir}, "", "1.8")
+	require.NoError(t, err)
+	require.Equal(t, []CompatIssue{
+		{Source: filepath.Join(dir, "a.hcl"), Key: "ui_config", Version: "1.9"},
+		{Source: filepath.Join(dir, "b.json"), Key: "auto_reload_config", Version: "1.10"},
+		{Source: filepath.Join(dir, "b.json"), Key: "connect.upstream_defaults", Version: "1.10"},
+	}, issues)
+
+	issues, err = CheckCompat([]string{dir}, "", "1.9")
+	require.NoError(t, err)
+	require.Equal(t, []CompatIssue{
+		{Source: filepath.Join(dir, "b.json"), Key: "auto_reload_config", Version: "1.10"},
+		{Source: filepath.Join(dir, "b.json"), Key: "connect.upstream_defaults", Version: "1.10"},
+	}, issues)
+
+	issues, err = CheckCompat([]string{dir}, "", "1.10")
+	require.NoError(t, err)
+	require.Nil(t, issues)
+}
+
+func TestCheckCompat_UnknownVersion(t *testing.T) {
+	_, err := CheckCompat(nil, "", "0.7")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), `unknown schema version "0.7"`)
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 
 	"github.com/hashicorp/hcl"
+	hclparser "github.com/hashicorp/hcl/hcl/parser"
 	"github.com/mitchellh/mapstructure"
 
 	"github.com/hashicorp/consul/lib/decode"
@@ -51,7 +52,7 @@ func (f FileSource) Parse() (Config, mapstructure.Metadata, error) {
 		err = fmt.Errorf("invalid format: %s", f.Format)
 	}
 	if err != nil {
-		return Config{}, md, err
+		return Config{}, md, withPos(f.Data, err)
 	}
 
 	var c Config
@@ -78,6 +79,54 @@ func (f FileSource) Parse() (Config, mapstructure.Metadata, error) {
 	return c, md, nil
 }
 
+// Parse decodes a single configuration document in "json" or "hcl"
+// format into a Config struct with the exact parsing semantics the agent
+// uses, including key translation and HCL block weakening. It is meant
+// for external tools such as config linters and admission webhooks that
+// want to validate documents without driving the full Builder. An empty
+// document yields a zero Config.
+func Parse(data []byte, format string) (Config, error) {
+	c, _, err := FileSource{Name: "Parse", Format: format, Data: string(data)}.Parse()
+	if err == ErrNoData {
+		return Config{}, nil
+	}
+	return c, err
+}
+
+// withPos prefixes parse errors that carry position metadata with the
+// 1-based line and column in the source data so that operators with large
+// config dirs can find the offending spot from the error alone.
+func withPos(data string, err error) error {
+	switch t := err.(type) {
+	case *json.SyntaxError:
+		line, col := linecol(data, int(t.Offset))
+		return fmt.Errorf("%d:%d: %w", line, col, err)
+	case *json.UnmarshalTypeError:
+		line, col := linecol(data, int(t.Offset))
+		return fmt.Errorf("%d:%d: %w", line, col, err)
+	case *hclparser.PosError:
+		return fmt.Errorf("%d:%d: %s", t.Pos.Line, t.Pos.Column, t.Err)
+	}
+	return err
+}
+
+// linecol converts a byte offset into a 1-based line and column.
+func linecol(data string, offset int) (line, col int) {
+	line, col = 1, 1
+	if offset > len(data) {
+		offset = len(data)
+	}
+	for _, r := range data[:offset] {
+		if r == '\n' {
+			line++
+			col = 1
+		} else {
+			col++
+		}
+	}
+	return line, col
+}
+
 // LiteralSource implements Source and returns an existing Config struct.
 type LiteralSource struct {
 	Name   string
@@ -129,114 +178,154 @@ type Config struct {
 	// DEPRECATED (ACL-Legacy-Compat) - moved into the "acl.tokens" stanza
 	ACLTTL *string `json:"acl_ttl,omitempty" hcl:"acl_ttl" mapstructure:"acl_ttl"`
 	// DEPRECATED (ACL-Legacy-Compat) - moved into the "acl.tokens" stanza
-	ACLToken                         *string             `json:"acl_token,omitempty" hcl:"acl_token" mapstructure:"acl_token"`
-	ACL                              ACL                 `json:"acl,omitempty" hcl:"acl" mapstructure:"acl"`
-	Addresses                        Addresses           `json:"addresses,omitempty" hcl:"addresses" mapstructure:"addresses"`
-	AdvertiseAddrLAN                 *string             `json:"advertise_addr,omitempty" hcl:"advertise_addr" mapstructure:"advertise_addr"`
-	AdvertiseAddrLANIPv4             *string             `json:"advertise_addr_ipv4,omitempty" hcl:"advertise_addr_ipv4" mapstructure:"advertise_addr_ipv4"`
-	AdvertiseAddrLANIPv6             *string             `json:"advertise_addr_ipv6,omitempty" hcl:"advertise_addr_ipv6" mapstructure:"advertise_addr_ipv6"`
-	AdvertiseAddrWAN                 *string             `json:"advertise_addr_wan,omitempty" hcl:"advertise_addr_wan" mapstructure:"advertise_addr_wan"`
-	AdvertiseAddrWANIPv4             *string             `json:"advertise_addr_wan_ipv4,omitempty" hcl:"advertise_addr_wan_ipv4" mapstructure:"advertise_addr_wan_ipv4"`
-	AdvertiseAddrWANIPv6             *string             `json:"advertise_addr_wan_ipv6,omitempty" hcl:"advertise_addr_wan_ipv6" mapstructure:"advertise_addr_ipv6"`
-	AdvertiseReconnectTimeout        *string             `json:"advertise_reconnect_timeout,omitempty" hcl:"advertise_reconnect_timeout" mapstructure:"advertise_reconnect_timeout"`
-	AutoConfig                       AutoConfigRaw       `json:"auto_config,omitempty" hcl:"auto_config" mapstructure:"auto_config"`
-	Autopilot                        Autopilot           `json:"autopilot,omitempty" hcl:"autopilot" mapstructure:"autopilot"`
-	BindAddr                         *string             `json:"bind_addr,omitempty" hcl:"bind_addr" mapstructure:"bind_addr"`
-	Bootstrap                        *bool               `json:"bootstrap,omitempty" hcl:"bootstrap" mapstructure:"bootstrap"`
-	BootstrapExpect                  *int                `json:"bootstrap_expect,omitempty" hcl:"bootstrap_expect" mapstructure:"bootstrap_expect"`
-	Cache                            Cache               `json:"cache,omitempty" hcl:"cache" mapstructure:"cache"`
-	CAFile                           *string             `json:"ca_file,omitempty" hcl:"ca_file" mapstructure:"ca_file"`
-	CAPath                           *string             `json:"ca_path,omitempty" hcl:"ca_path" mapstructure:"ca_path"`
-	CertFile                         *string             `json:"cert_file,omitempty" hcl:"cert_file" mapstructure:"cert_file"`
-	Check                            *CheckDefinition    `json:"check,omitempty" hcl:"check" mapstructure:"check"` // needs to be a pointer to avoid partial merges
-	CheckOutputMaxSize               *int                `json:"check_output_max_size,omitempty" hcl:"check_output_max_size" mapstructure:"check_output_max_size"`
-	CheckUpdateInterval              *string             `json:"check_update_interval,omitempty" hcl:"check_update_interval" mapstructure:"check_update_interval"`
-	Checks                           []CheckDefinition   `json:"checks,omitempty" hcl:"checks" mapstructure:"checks"`
-	ClientAddr                       *string             `json:"client_addr,omitempty" hcl:"client_addr" mapstructure:"client_addr"`
-	ConfigEntries                    ConfigEntries       `json:"config_entries,omitempty" hcl:"config_entries" mapstructure:"config_entries"`
-	AutoEncrypt                      AutoEncrypt         `json:"auto_encrypt,omitempty" hcl:"auto_encrypt" mapstructure:"auto_encrypt"`
-	Connect                          Connect             `json:"connect,omitempty" hcl:"connect" mapstructure:"connect"`
-	DNS                              DNS                 `json:"dns_config,omitempty" hcl:"dns_config" mapstructure:"dns_config"`
-	DNSDomain                        *string             `json:"domain,omitempty" hcl:"domain" mapstructure:"domain"`
-	DNSAltDomain                     *string             `json:"alt_domain,omitempty" hcl:"alt_domain" mapstructure:"alt_domain"`
-	DNSRecursors                     []string            `json:"recursors,omitempty" hcl:"recursors" mapstructure:"recursors"`
-	DataDir                          *string             `json:"data_dir,omitempty" hcl:"data_dir" mapstructure:"data_dir"`
-	Datacenter                       *string             `json:"datacenter,omitempty" hcl:"datacenter" mapstructure:"datacenter"`
-	DefaultQueryTime                 *string             `json:"default_query_time,omitempty" hcl:"default_query_time" mapstructure:"default_query_time"`
-	DisableAnonymousSignature        *bool               `json:"disable_anonymous_signature,omitempty" hcl:"disable_anonymous_signature" mapstructure:"disable_anonymous_signature"`
-	DisableCoordinates               *bool               `json:"disable_coordinates,omitempty" hcl:"disable_coordinates" mapstructure:"disable_coordinates"`
-	DisableHostNodeID                *bool               `json:"disable_host_node_id,omitempty" hcl:"disable_host_node_id" mapstructure:"disable_host_node_id"`
-	DisableHTTPUnprintableCharFilter *bool               `json:"disable_http_unprintable_char_filter,omitempty" hcl:"disable_http_unprintable_char_filter" mapstructure:"disable_http_unprintable_char_filter"`
-	DisableKeyringFile               *bool               `json:"disable_keyring_file,omitempty" hcl:"disable_keyring_file" mapstructure:"disable_keyring_file"`
-	DisableRemoteExec                *bool               `json:"disable_remote_exec,omitempty" hcl:"disable_remote_exec" mapstructure:"disable_remote_exec"`
-	DisableUpdateCheck               *bool               `json:"disable_update_check,omitempty" hcl:"disable_update_check" mapstructure:"disable_update_check"`
-	DiscardCheckOutput               *bool               `json:"discard_check_output" hcl:"discard_check_output" mapstructure:"discard_check_output"`
-	DiscoveryMaxStale                *string             `json:"discovery_max_stale" hcl:"discovery_max_stale" mapstructure:"discovery_max_stale"`
-	EnableACLReplication             *bool               `json:"enable_acl_replication,omitempty" hcl:"enable_acl_replication" mapstructure:"enable_acl_replication"`
-	EnableAgentTLSForChecks          *bool               `json:"enable_agent_tls_for_checks,omitempty" hcl:"enable_agent_tls_for_checks" mapstructure:"enable_agent_tls_for_checks"`
-	EnableCentralServiceConfig       *bool               `json:"enable_central_service_config,omitempty" hcl:"enable_central_service_config" mapstructure:"enable_central_service_config"`
-	EnableDebug                      *bool               `json:"enable_debug,omitempty" hcl:"enable_debug" mapstructure:"enable_debug"`
-	EnableScriptChecks               *bool               `json:"enable_script_checks,omitempty" hcl:"enable_script_checks" mapstructure:"enable_script_checks"`
-	EnableLocalScriptChecks          *bool               `json:"enable_local_script_checks,omitempty" hcl:"enable_local_script_checks" mapstructure:"enable_local_script_checks"`
-	EnableSyslog                     *bool               `json:"enable_syslog,omitempty" hcl:"enable_syslog" mapstructure:"enable_syslog"`
-	EncryptKey                       *string             `json:"encrypt,omitempty" hcl:"encrypt" mapstructure:"encrypt"`
-	EncryptVerifyIncoming            *bool               `json:"encrypt_verify_incoming,omitempty" hcl:"encrypt_verify_incoming" mapstructure:"encrypt_verify_incoming"`
-	EncryptVerifyOutgoing            *bool               `json:"encrypt_verify_outgoing,omitempty" hcl:"encrypt_verify_outgoing" mapstructure:"encrypt_verify_outgoing"`
-	GossipLAN                        GossipLANConfig     `json:"gossip_lan,omitempty" hcl:"gossip_lan" mapstructure:"gossip_lan"`
-	GossipWAN                        GossipWANConfig     `json:"gossip_wan,omitempty" hcl:"gossip_wan" mapstructure:"gossip_wan"`
-	HTTPConfig                       HTTPConfig          `json:"http_config,omitempty" hcl:"http_config" mapstructure:"http_config"`
-	KeyFile                          *string             `json:"key_file,omitempty" hcl:"key_file" mapstructure:"key_file"`
-	LeaveOnTerm                      *bool               `json:"leave_on_terminate,omitempty" hcl:"leave_on_terminate" mapstructure:"leave_on_terminate"`
-	Limits                           Limits              `json:"limits,omitempty" hcl:"limits" mapstructure:"limits"`
-	LogLevel                         *string             `json:"log_level,omitempty" hcl:"log_level" mapstructure:"log_level"`
-	LogJSON                          *bool               `json:"log_json,omitempty" hcl:"log_json" mapstructure:"log_json"`
-	LogFile                          *string             `json:"log_file,omitempty" hcl:"log_file" mapstructure:"log_file"`
-	LogRotateDuration                *string             `json:"log_rotate_duration,omitempty" hcl:"log_rotate_duration" mapstructure:"log_rotate_duration"`
-	LogRotateBytes                   *int                `json:"log_rotate_bytes,omitempty" hcl:"log_rotate_bytes" mapstructure:"log_rotate_bytes"`
-	LogRotateMaxFiles                *int                `json:"log_rotate_max_files,omitempty" hcl:"log_rotate_max_files" mapstructure:"log_rotate_max_files"`
-	MaxQueryTime                     *string             `json:"max_query_time,omitempty" hcl:"max_query_time" mapstructure:"max_query_time"`
-	NodeID                           *string             `json:"node_id,omitempty" hcl:"node_id" mapstructure:"node_id"`
-	NodeMeta                         map[string]string   `json:"node_meta,omitempty" hcl:"node_meta" mapstructure:"node_meta"`
-	NodeName                         *string             `json:"node_name,omitempty" hcl:"node_name" mapstructure:"node_name"`
-	Performance                      Performance         `json:"performance,omitempty" hcl:"performance" mapstructure:"performance"`
-	PidFile                          *string             `json:"pid_file,omitempty" hcl:"pid_file" mapstructure:"pid_file"`
-	Ports                            Ports               `json:"ports,omitempty" hcl:"ports" mapstructure:"ports"`
-	PrimaryDatacenter                *string             `json:"primary_datacenter,omitempty" hcl:"primary_datacenter" mapstructure:"primary_datacenter"`
-	PrimaryGateways                  []string            `json:"primary_gateways" hcl:"primary_gateways" mapstructure:"primary_gateways"`
-	PrimaryGatewaysInterval          *string             `json:"primary_gateways_interval,omitempty" hcl:"primary_gateways_interval" mapstructure:"primary_gateways_interval"`
-	RPCProtocol                      *int                `json:"protocol,omitempty" hcl:"protocol" mapstructure:"protocol"`
-	RaftProtocol                     *int                `json:"raft_protocol,omitempty" hcl:"raft_protocol" mapstructure:"raft_protocol"`
-	RaftSnapshotThreshold            *int                `json:"raft_snapshot_threshold,omitempty" hcl:"raft_snapshot_threshold" mapstructure:"raft_snapshot_threshold"`
-	RaftSnapshotInterval             *string             `json:"raft_snapshot_interval,omitempty" hcl:"raft_snapshot_interval" mapstructure:"raft_snapshot_interval"`
-	RaftTrailingLogs                 *int                `json:"raft_trailing_logs,omitempty" hcl:"raft_trailing_logs" mapstructure:"raft_trailing_logs"`
-	ReconnectTimeoutLAN              *string             `json:"reconnect_timeout,omitempty" hcl:"reconnect_timeout" mapstructure:"reconnect_timeout"`
-	ReconnectTimeoutWAN              *string             `json:"reconnect_timeout_wan,omitempty" hcl:"reconnect_timeout_wan" mapstructure:"reconnect_timeout_wan"`
-	RejoinAfterLeave                 *bool               `json:"rejoin_after_leave,omitempty" hcl:"rejoin_after_leave" mapstructure:"rejoin_after_leave"`
-	RetryJoinIntervalLAN             *string             `json:"retry_interval,omitempty" hcl:"retry_interval" mapstructure:"retry_interval"`
-	RetryJoinIntervalWAN             *string             `json:"retry_interval_wan,omitempty" hcl:"retry_interval_wan" mapstructure:"retry_interval_wan"`
-	RetryJoinLAN                     []string            `json:"retry_join,omitempty" hcl:"retry_join" mapstructure:"retry_join"`
-	RetryJoinMaxAttemptsLAN          *int                `json:"retry_max,omitempty" hcl:"retry_max" mapstructure:"retry_max"`
-	RetryJoinMaxAttemptsWAN          *int                `json:"retry_max_wan,omitempty" hcl:"retry_max_wan" mapstructure:"retry_max_wan"`
-	RetryJoinWAN                     []string            `json:"retry_join_wan,omitempty" hcl:"retry_join_wan" mapstructure:"retry_join_wan"`
-	SerfAllowedCIDRsLAN              []string            `json:"serf_lan_allowed_cidrs,omitempty" hcl:"serf_lan_allowed_cidrs" mapstructure:"serf_lan_allowed_cidrs"`
-	SerfAllowedCIDRsWAN              []string            `json:"serf_wan_allowed_cidrs,omitempty" hcl:"serf_wan_allowed_cidrs" mapstructure:"serf_wan_allowed_cidrs"`
-	SerfBindAddrLAN                  *string             `json:"serf_lan,omitempty" hcl:"serf_lan" mapstructure:"serf_lan"`
-	SerfBindAddrWAN                  *string             `json:"serf_wan,omitempty" hcl:"serf_wan" mapstructure:"serf_wan"`
-	ServerMode                       *bool               `json:"server,omitempty" hcl:"server" mapstructure:"server"`
-	ServerName                       *string             `json:"server_name,omitempty" hcl:"server_name" mapstructure:"server_name"`
-	Service                          *ServiceDefinition  `json:"service,omitempty" hcl:"service" mapstructure:"service"`
-	Services                         []ServiceDefinition `json:"services,omitempty" hcl:"services" mapstructure:"services"`
-	SessionTTLMin                    *string             `json:"session_ttl_min,omitempty" hcl:"session_ttl_min" mapstructure:"session_ttl_min"`
-	SkipLeaveOnInt                   *bool               `json:"skip_leave_on_interrupt,omitempty" hcl:"skip_leave_on_interrupt" mapstructure:"skip_leave_on_interrupt"`
-	StartJoinAddrsLAN                []string            `json:"start_join,omitempty" hcl:"start_join" mapstructure:"start_join"`
-	StartJoinAddrsWAN                []string            `json:"start_join_wan,omitempty" hcl:"start_join_wan" mapstructure:"start_join_wan"`
-	SyslogFacility                   *string             `json:"syslog_facility,omitempty" hcl:"syslog_facility" mapstructure:"syslog_facility"`
-	TLSCipherSuites                  *string             `json:"tls_cipher_suites,omitempty" hcl:"tls_cipher_suites" mapstructure:"tls_cipher_suites"`
-	TLSMinVersion                    *string             `json:"tls_min_version,omitempty" hcl:"tls_min_version" mapstructure:"tls_min_version"`
-	TLSPreferServerCipherSuites      *bool               `json:"tls_prefer_server_cipher_suites,omitempty" hcl:"tls_prefer_server_cipher_suites" mapstructure:"tls_prefer_server_cipher_suites"`
-	TaggedAddresses                  map[string]string   `json:"tagged_addresses,omitempty" hcl:"tagged_addresses" mapstructure:"tagged_addresses"`
-	Telemetry                        Telemetry           `json:"telemetry,omitempty" hcl:"telemetry" mapstructure:"telemetry"`
-	TranslateWANAddrs                *bool               `json:"translate_wan_addrs,omitempty" hcl:"translate_wan_addrs" mapstructure:"translate_wan_addrs"`
+	ACLToken                         *string                    `json:"acl_token,omitempty" hcl:"acl_token" mapstructure:"acl_token"`
+	ACL                              ACL                        `json:"acl,omitempty" hcl:"acl" mapstructure:"acl"`
+	Addresses                        Addresses                  `json:"addresses,omitempty" hcl:"addresses" mapstructure:"addresses"`
+	AdvertiseAddrLAN                 *string                    `json:"advertise_addr,omitempty" hcl:"advertise_addr" mapstructure:"advertise_addr"`
+	AdvertiseAddrLANIPv4             *string                    `json:"advertise_addr_ipv4,omitempty" hcl:"advertise_addr_ipv4" mapstructure:"advertise_addr_ipv4"`
+	AdvertiseAddrLANIPv6             *string                    `json:"advertise_addr_ipv6,omitempty" hcl:"advertise_addr_ipv6" mapstructure:"advertise_addr_ipv6"`
+	AdvertiseAddrWAN                 *string                    `json:"advertise_addr_wan,omitempty" hcl:"advertise_addr_wan" mapstructure:"advertise_addr_wan"`
+	AdvertiseAddrWANIPv4             *string                    `json:"advertise_addr_wan_ipv4,omitempty" hcl:"advertise_addr_wan_ipv4" mapstructure:"advertise_addr_wan_ipv4"`
+	AdvertiseAddrWANIPv6             *string                    `json:"advertise_addr_wan_ipv6,omitempty" hcl:"advertise_addr_wan_ipv6" mapstructure:"advertise_addr_ipv6"`
+	AdvertiseAddrSelection           *string                    `json:"advertise_addr_selection,omitempty" hcl:"advertise_addr_selection" mapstructure:"advertise_addr_selection"`
+	AdvertiseInterface               *string                    `json:"advertise_interface,omitempty" hcl:"advertise_interface" mapstructure:"advertise_interface"`
+	AdvertiseReconnectTimeout        *string                    `json:"advertise_reconnect_timeout,omitempty" hcl:"advertise_reconnect_timeout" mapstructure:"advertise_reconnect_timeout"`
+	AutoConfig                       AutoConfigRaw              `json:"auto_config,omitempty" hcl:"auto_config" mapstructure:"auto_config"`
+	AutoReloadConfig                 *bool                      `json:"auto_reload_config,omitempty" hcl:"auto_reload_config" mapstructure:"auto_reload_config"`
+	Autopilot                        Autopilot                  `json:"autopilot,omitempty" hcl:"autopilot" mapstructure:"autopilot"`
+	BindAddr                         *string                    `json:"bind_addr,omitempty" hcl:"bind_addr" mapstructure:"bind_addr"`
+	BindAddrs                        []string                   `json:"bind_addrs,omitempty" hcl:"bind_addrs" mapstructure:"bind_addrs"`
+	BindInterface                    *string                    `json:"bind_interface,omitempty" hcl:"bind_interface" mapstructure:"bind_interface"`
+	Bootstrap                        *bool                      `json:"bootstrap,omitempty" hcl:"bootstrap" mapstructure:"bootstrap"`
+	BootstrapExpect                  *int                       `json:"bootstrap_expect,omitempty" hcl:"bootstrap_expect" mapstructure:"bootstrap_expect"`
+	Cache                            Cache                      `json:"cache,omitempty" hcl:"cache" mapstructure:"cache"`
+	CAFile                           *string                    `json:"ca_file,omitempty" hcl:"ca_file" mapstructure:"ca_file"`
+	CAFileContents                   *string                    `json:"ca_file_contents,omitempty" hcl:"ca_file_contents" mapstructure:"ca_file_contents"`
+	CAPath                           *string                    `json:"ca_path,omitempty" hcl:"ca_path" mapstructure:"ca_path"`
+	CertFile                         *string                    `json:"cert_file,omitempty" hcl:"cert_file" mapstructure:"cert_file"`
+	CertFileContents                 *string                    `json:"cert_file_contents,omitempty" hcl:"cert_file_contents" mapstructure:"cert_file_contents"`
+	Check                            *CheckDefinition           `json:"check,omitempty" hcl:"check" mapstructure:"check"` // needs to be a pointer to avoid partial merges
+	CheckExecLimits                  CheckExecLimits            `json:"check_exec_limits,omitempty" hcl:"check_exec_limits" mapstructure:"check_exec_limits"`
+	CheckOutputMaxSize               *int                       `json:"check_output_max_size,omitempty" hcl:"check_output_max_size" mapstructure:"check_output_max_size"`
+	CheckUpdateInterval              *string                    `json:"check_update_interval,omitempty" hcl:"check_update_interval" mapstructure:"check_update_interval"`
+	Checks                           []CheckDefinition          `json:"checks,omitempty" hcl:"checks" mapstructure:"checks"`
+	ClientAddr                       *string                    `json:"client_addr,omitempty" hcl:"client_addr" mapstructure:"client_addr"`
+	ClientAddrExclude                []string                   `json:"client_addr_exclude,omitempty" hcl:"client_addr_exclude" mapstructure:"client_addr_exclude"`
+	ConfigDirFollowSymlink           *bool                      `json:"config_dir_follow_symlink,omitempty" hcl:"config_dir_follow_symlink" mapstructure:"config_dir_follow_symlink"`
+	ConfigEntries                    ConfigEntries              `json:"config_entries,omitempty" hcl:"config_entries" mapstructure:"config_entries"`
+	ConfigVerification               ConfigVerification         `json:"config_verification,omitempty" hcl:"config_verification" mapstructure:"config_verification"`
+	AutoEncrypt                      AutoEncrypt                `json:"auto_encrypt,omitempty" hcl:"auto_encrypt" mapstructure:"auto_encrypt"`
+	Connect                          Connect                    `json:"connect,omitempty" hcl:"connect" mapstructure:"connect"`
+	CRIEndpoint                      *string                    `json:"cri_endpoint,omitempty" hcl:"cri_endpoint" mapstructure:"cri_endpoint"`
+	DNS                              DNS                        `json:"dns_config,omitempty" hcl:"dns_config" mapstructure:"dns_config"`
+	DNSDomain                        *string                    `json:"domain,omitempty" hcl:"domain" mapstructure:"domain"`
+	DNSAltDomain                     *string                    `json:"alt_domain,omitempty" hcl:"alt_domain" mapstructure:"alt_domain"`
+	DNSRecursors                     []string                   `json:"recursors,omitempty" hcl:"recursors" mapstructure:"recursors"`
+	DataDir                          *string                    `json:"data_dir,omitempty" hcl:"data_dir" mapstructure:"data_dir"`
+	Datacenter                       *string                    `json:"datacenter,omitempty" hcl:"datacenter" mapstructure:"datacenter"`
+	DatacenterAliases                map[string]string          `json:"datacenter_aliases,omitempty" hcl:"datacenter_aliases" mapstructure:"datacenter_aliases"`
+	DeprecationPolicy                *string                    `json:"deprecation_policy,omitempty" hcl:"deprecation_policy" mapstructure:"deprecation_policy"`
+	DefaultQueryTime                 *string                    `json:"default_query_time,omitempty" hcl:"default_query_time" mapstructure:"default_query_time"`
+	DisableAnonymousSignature        *bool                      `json:"disable_anonymous_signature,omitempty" hcl:"disable_anonymous_signature" mapstructure:"disable_anonymous_signature"`
+	DisableCoordinates               *bool                      `json:"disable_coordinates,omitempty" hcl:"disable_coordinates" mapstructure:"disable_coordinates"`
+	DisableHostNodeID                *bool                      `json:"disable_host_node_id,omitempty" hcl:"disable_host_node_id" mapstructure:"disable_host_node_id"`
+	DisableHTTPUnprintableCharFilter *bool                      `json:"disable_http_unprintable_char_filter,omitempty" hcl:"disable_http_unprintable_char_filter" mapstructure:"disable_http_unprintable_char_filter"`
+	DisableKeyringFile               *bool                      `json:"disable_keyring_file,omitempty" hcl:"disable_keyring_file" mapstructure:"disable_keyring_file"`
+	DisableRemoteExec                *bool                      `json:"disable_remote_exec,omitempty" hcl:"disable_remote_exec" mapstructure:"disable_remote_exec"`
+	DisableUpdateCheck               *bool                      `json:"disable_update_check,omitempty" hcl:"disable_update_check" mapstructure:"disable_update_check"`
+	DiscardCheckOutput               *bool                      `json:"discard_check_output" hcl:"discard_check_output" mapstructure:"discard_check_output"`
+	DiscoveryMaxResults              DiscoveryMaxResults        `json:"discovery_max_results,omitempty" hcl:"discovery_max_results" mapstructure:"discovery_max_results"`
+	DiscoveryMaxStale                *string                    `json:"discovery_max_stale" hcl:"discovery_max_stale" mapstructure:"discovery_max_stale"`
+	DockerEndpoint                   DockerEndpoint             `json:"docker_endpoint,omitempty" hcl:"docker_endpoint" mapstructure:"docker_endpoint"`
+	EnableACLReplication             *bool                      `json:"enable_acl_replication,omitempty" hcl:"enable_acl_replication" mapstructure:"enable_acl_replication"`
+	EnableAgentTLSForChecks          *bool                      `json:"enable_agent_tls_for_checks,omitempty" hcl:"enable_agent_tls_for_checks" mapstructure:"enable_agent_tls_for_checks"`
+	EnableCentralServiceConfig       *bool                      `json:"enable_central_service_config,omitempty" hcl:"enable_central_service_config" mapstructure:"enable_central_service_config"`
+	EnableDebug                      *bool                      `json:"enable_debug,omitempty" hcl:"enable_debug" mapstructure:"enable_debug"`
+	EnableScriptChecks               *bool                      `json:"enable_script_checks,omitempty" hcl:"enable_script_checks" mapstructure:"enable_script_checks"`
+	EnableLocalScriptChecks          *bool                      `json:"enable_local_script_checks,omitempty" hcl:"enable_local_script_checks" mapstructure:"enable_local_script_checks"`
+	EnableSyslog                     *bool                      `json:"enable_syslog,omitempty" hcl:"enable_syslog" mapstructure:"enable_syslog"`
+	EncryptKey                       *string                    `json:"encrypt,omitempty" hcl:"encrypt" mapstructure:"encrypt"`
+	EncryptVerifyIncoming            *bool                      `json:"encrypt_verify_incoming,omitempty" hcl:"encrypt_verify_incoming" mapstructure:"encrypt_verify_incoming"`
+	EncryptVerifyOutgoing            *bool                      `json:"encrypt_verify_outgoing,omitempty" hcl:"encrypt_verify_outgoing" mapstructure:"encrypt_verify_outgoing"`
+	ExposeAutoConfigStatus           *bool                      `json:"expose_auto_config_status,omitempty" hcl:"expose_auto_config_status" mapstructure:"expose_auto_config_status"`
+	GossipLAN                        GossipLANConfig            `json:"gossip_lan,omitempty" hcl:"gossip_lan" mapstructure:"gossip_lan"`
+	GossipWAN                        GossipWANConfig            `json:"gossip_wan,omitempty" hcl:"gossip_wan" mapstructure:"gossip_wan"`
+	GRPC                             GRPCConfig                 `json:"grpc,omitempty" hcl:"grpc" mapstructure:"grpc"`
+	HTTPConfig                       HTTPConfig                 `json:"http_config,omitempty" hcl:"http_config" mapstructure:"http_config"`
+	Include                          []string                   `json:"include,omitempty" hcl:"include" mapstructure:"include"`
+	InterfaceAddrPreference          *string                    `json:"interface_addr_preference,omitempty" hcl:"interface_addr_preference" mapstructure:"interface_addr_preference"`
+	KeyFile                          *string                    `json:"key_file,omitempty" hcl:"key_file" mapstructure:"key_file"`
+	KeyFileContents                  *string                    `json:"key_file_contents,omitempty" hcl:"key_file_contents" mapstructure:"key_file_contents"`
+	LeaveOnTerm                      *bool                      `json:"leave_on_terminate,omitempty" hcl:"leave_on_terminate" mapstructure:"leave_on_terminate"`
+	Limits                           Limits                     `json:"limits,omitempty" hcl:"limits" mapstructure:"limits"`
+	ListenerOptions                  ListenerOptions            `json:"listener_options,omitempty" hcl:"listener_options" mapstructure:"listener_options"`
+	LogLevel                         *string                    `json:"log_level,omitempty" hcl:"log_level" mapstructure:"log_level"`
+	LogJSON                          *bool                      `json:"log_json,omitempty" hcl:"log_json" mapstructure:"log_json"`
+	LogFile                          *string                    `json:"log_file,omitempty" hcl:"log_file" mapstructure:"log_file"`
+	LogRotateDuration                *string                    `json:"log_rotate_duration,omitempty" hcl:"log_rotate_duration" mapstructure:"log_rotate_duration"`
+	LogRotateBytes                   *int                       `json:"log_rotate_bytes,omitempty" hcl:"log_rotate_bytes" mapstructure:"log_rotate_bytes"`
+	LogRotateMaxFiles                *int                       `json:"log_rotate_max_files,omitempty" hcl:"log_rotate_max_files" mapstructure:"log_rotate_max_files"`
+	Logging                          Logging                    `json:"logging,omitempty" hcl:"logging" mapstructure:"logging"`
+	MaintenanceSchedule              MaintenanceSchedule        `json:"maintenance_schedule,omitempty" hcl:"maintenance_schedule" mapstructure:"maintenance_schedule"`
+	MaxQueryTime                     *string                    `json:"max_query_time,omitempty" hcl:"max_query_time" mapstructure:"max_query_time"`
+	MDNSAdvertise                    MDNSAdvertise              `json:"mdns_advertise,omitempty" hcl:"mdns_advertise" mapstructure:"mdns_advertise"`
+	MergeStrategy                    MergeStrategy              `json:"merge_strategy,omitempty" hcl:"merge_strategy" mapstructure:"merge_strategy"`
+	NodeID                           *string                    `json:"node_id,omitempty" hcl:"node_id" mapstructure:"node_id"`
+	NodeMeta                         map[string]string          `json:"node_meta,omitempty" hcl:"node_meta" mapstructure:"node_meta"`
+	NodeName                         *string                    `json:"node_name,omitempty" hcl:"node_name" mapstructure:"node_name"`
+	OutboundProxy                    OutboundProxy              `json:"outbound_proxy,omitempty" hcl:"outbound_proxy" mapstructure:"outbound_proxy"`
+	Performance                      Performance                `json:"performance,omitempty" hcl:"performance" mapstructure:"performance"`
+	PidFile                          *string                    `json:"pid_file,omitempty" hcl:"pid_file" mapstructure:"pid_file"`
+	Ports                            Ports                      `json:"ports,omitempty" hcl:"ports" mapstructure:"ports"`
+	Priority                         *int                       `json:"priority,omitempty" hcl:"priority" mapstructure:"priority"`
+	PrimaryDatacenter                *string                    `json:"primary_datacenter,omitempty" hcl:"primary_datacenter" mapstructure:"primary_datacenter"`
+	PrimaryDatacenterMigration       PrimaryDatacenterMigration `json:"primary_datacenter_migration,omitempty" hcl:"primary_datacenter_migration" mapstructure:"primary_datacenter_migration"`
+	PrimaryGateways                  []string                   `json:"primary_gateways" hcl:"primary_gateways" mapstructure:"primary_gateways"`
+	PrimaryGatewaysInterval          *string                    `json:"primary_gateways_interval,omitempty" hcl:"primary_gateways_interval" mapstructure:"primary_gateways_interval"`
+	RPCProtocol                      *int                       `json:"protocol,omitempty" hcl:"protocol" mapstructure:"protocol"`
+	RaftProtocol                     *int                       `json:"raft_protocol,omitempty" hcl:"raft_protocol" mapstructure:"raft_protocol"`
+	RaftSnapshotThreshold            *int                       `json:"raft_snapshot_threshold,omitempty" hcl:"raft_snapshot_threshold" mapstructure:"raft_snapshot_threshold"`
+	RaftSnapshotInterval             *string                    `json:"raft_snapshot_interval,omitempty" hcl:"raft_snapshot_interval" mapstructure:"raft_snapshot_interval"`
+	RaftTrailingLogs                 *int                       `json:"raft_trailing_logs,omitempty" hcl:"raft_trailing_logs" mapstructure:"raft_trailing_logs"`
+	RaftTrailingLogsSafetyFactor     *float64                   `json:"raft_trailing_logs_safety_factor,omitempty" hcl:"raft_trailing_logs_safety_factor" mapstructure:"raft_trailing_logs_safety_factor"`
+	RaftTuningProfile                *string                    `json:"raft_tuning_profile,omitempty" hcl:"raft_tuning_profile" mapstructure:"raft_tuning_profile"`
+	ReconnectTimeoutLAN              *string                    `json:"reconnect_timeout,omitempty" hcl:"reconnect_timeout" mapstructure:"reconnect_timeout"`
+	ReconnectTimeoutWAN              *string                    `json:"reconnect_timeout_wan,omitempty" hcl:"reconnect_timeout_wan" mapstructure:"reconnect_timeout_wan"`
+	RejoinAfterLeave                 *bool                      `json:"rejoin_after_leave,omitempty" hcl:"rejoin_after_leave" mapstructure:"rejoin_after_leave"`
+	RetryJoinIntervalLAN             *string                    `json:"retry_interval,omitempty" hcl:"retry_interval" mapstructure:"retry_interval"`
+	RetryJoinIntervalWAN             *string                    `json:"retry_interval_wan,omitempty" hcl:"retry_interval_wan" mapstructure:"retry_interval_wan"`
+	RetryJoinLAN                     []string                   `json:"retry_join,omitempty" hcl:"retry_join" mapstructure:"retry_join"`
+	RetryJoinMaxAttemptsLAN          *int                       `json:"retry_max,omitempty" hcl:"retry_max" mapstructure:"retry_max"`
+	RetryJoinMaxAttemptsWAN          *int                       `json:"retry_max_wan,omitempty" hcl:"retry_max_wan" mapstructure:"retry_max_wan"`
+	RetryJoinWAN                     []string                   `json:"retry_join_wan,omitempty" hcl:"retry_join_wan" mapstructure:"retry_join_wan"`
+	Runtime                          Runtime                    `json:"runtime,omitempty" hcl:"runtime" mapstructure:"runtime"`
+	Sanitize                         SanitizeConfig             `json:"sanitize,omitempty" hcl:"sanitize" mapstructure:"sanitize"`
+	SerfAllowedCIDRsLAN              []string                   `json:"serf_lan_allowed_cidrs,omitempty" hcl:"serf_lan_allowed_cidrs" mapstructure:"serf_lan_allowed_cidrs"`
+	SerfAllowedCIDRsWAN              []string                   `json:"serf_wan_allowed_cidrs,omitempty" hcl:"serf_wan_allowed_cidrs" mapstructure:"serf_wan_allowed_cidrs"`
+	SerfBindAddrLAN                  *string                    `json:"serf_lan,omitempty" hcl:"serf_lan" mapstructure:"serf_lan"`
+	SerfBindAddrWAN                  *string                    `json:"serf_wan,omitempty" hcl:"serf_wan" mapstructure:"serf_wan"`
+	ServerMode                       *bool                      `json:"server,omitempty" hcl:"server" mapstructure:"server"`
+	ServerName                       *string                    `json:"server_name,omitempty" hcl:"server_name" mapstructure:"server_name"`
+	ServersFile                      *string                    `json:"servers_file,omitempty" hcl:"servers_file" mapstructure:"servers_file"`
+	Service                          *ServiceDefinition         `json:"service,omitempty" hcl:"service" mapstructure:"service"`
+	Services                         []ServiceDefinition        `json:"services,omitempty" hcl:"services" mapstructure:"services"`
+	SessionTTLMin                    *string                    `json:"session_ttl_min,omitempty" hcl:"session_ttl_min" mapstructure:"session_ttl_min"`
+	SkipLeaveOnInt                   *bool                      `json:"skip_leave_on_interrupt,omitempty" hcl:"skip_leave_on_interrupt" mapstructure:"skip_leave_on_interrupt"`
+	StartDrained                     *bool                      `json:"start_drained,omitempty" hcl:"start_drained" mapstructure:"start_drained"`
+	StartJoinAddrsLAN                []string                   `json:"start_join,omitempty" hcl:"start_join" mapstructure:"start_join"`
+	StrictMode                       *bool                      `json:"strict_mode,omitempty" hcl:"strict_mode" mapstructure:"strict_mode"`
+	SuppressWarnings                 []string                   `json:"suppress_warnings,omitempty" hcl:"suppress_warnings" mapstructure:"suppress_warnings"`
+	StartJoinAddrsWAN                []string                   `json:"start_join_wan,omitempty" hcl:"start_join_wan" mapstructure:"start_join_wan"`
+	SyslogFacility                   *string                    `json:"syslog_facility,omitempty" hcl:"syslog_facility" mapstructure:"syslog_facility"`
+	TLS                              TLS                        `json:"tls,omitempty" hcl:"tls" mapstructure:"tls"`
+	TLSBootstrapMode                 *string                    `json:"tls_bootstrap_mode,omitempty" hcl:"tls_bootstrap_mode" mapstructure:"tls_bootstrap_mode"`
+	TLSCipherSuites                  *string                    `json:"tls_cipher_suites,omitempty" hcl:"tls_cipher_suites" mapstructure:"tls_cipher_suites"`
+	TLSMinVersion                    *string                    `json:"tls_min_version,omitempty" hcl:"tls_min_version" mapstructure:"tls_min_version"`
+	TLSPreferServerCipherSuites      *bool                      `json:"tls_prefer_server_cipher_suites,omitempty" hcl:"tls_prefer_server_cipher_suites" mapstructure:"tls_prefer_server_cipher_suites"`
+	TaggedAddresses                  map[string]interface{}     `json:"tagged_addresses,omitempty" hcl:"tagged_addresses" mapstructure:"tagged_addresses"`
+	Telemetry                        Telemetry                  `json:"telemetry,omitempty" hcl:"telemetry" mapstructure:"telemetry"`
+	Tracing                          Tracing                    `json:"tracing,omitempty" hcl:"tracing" mapstructure:"tracing"`
+	TranslateWANAddrs                *bool                      `json:"translate_wan_addrs,omitempty" hcl:"translate_wan_addrs" mapstructure:"translate_wan_addrs"`
 
 	// DEPRECATED (ui-config) - moved to the ui_config stanza
 	UI *bool `json:"ui,omitempty" hcl:"ui" mapstructure:"ui"`
@@ -246,13 +335,19 @@ type Config struct {
 	UIDir    *string     `json:"ui_dir,omitempty" hcl:"ui_dir" mapstructure:"ui_dir"`
 	UIConfig RawUIConfig `json:"ui_config,omitempty" hcl:"ui_config" mapstructure:"ui_config"`
 
-	UnixSocket           UnixSocket               `json:"unix_sockets,omitempty" hcl:"unix_sockets" mapstructure:"unix_sockets"`
-	VerifyIncoming       *bool                    `json:"verify_incoming,omitempty" hcl:"verify_incoming" mapstructure:"verify_incoming"`
-	VerifyIncomingHTTPS  *bool                    `json:"verify_incoming_https,omitempty" hcl:"verify_incoming_https" mapstructure:"verify_incoming_https"`
-	VerifyIncomingRPC    *bool                    `json:"verify_incoming_rpc,omitempty" hcl:"verify_incoming_rpc" mapstructure:"verify_incoming_rpc"`
-	VerifyOutgoing       *bool                    `json:"verify_outgoing,omitempty" hcl:"verify_outgoing" mapstructure:"verify_outgoing"`
-	VerifyServerHostname *bool                    `json:"verify_server_hostname,omitempty" hcl:"verify_server_hostname" mapstructure:"verify_server_hostname"`
-	Watches              []map[string]interface{} `json:"watches,omitempty" hcl:"watches" mapstructure:"watches"`
+	UnixSocket                     UnixSocket               `json:"unix_sockets,omitempty" hcl:"unix_sockets" mapstructure:"unix_sockets"`
+	UnknownKeys                    *string                  `json:"unknown_keys,omitempty" hcl:"unknown_keys" mapstructure:"unknown_keys"`
+	UsageReporting                 UsageReporting           `json:"usage_reporting,omitempty" hcl:"usage_reporting" mapstructure:"usage_reporting"`
+	UseSocketActivation            *bool                    `json:"use_socket_activation,omitempty" hcl:"use_socket_activation" mapstructure:"use_socket_activation"`
+	ValidateListeners              *bool                    `json:"validate_listeners,omitempty" hcl:"validate_listeners" mapstructure:"validate_listeners"`
+	VerifyIncoming                 *bool                    `json:"verify_incoming,omitempty" hcl:"verify_incoming" mapstructure:"verify_incoming"`
+	VerifyIncomingHTTPS            *bool                    `json:"verify_incoming_https,omitempty" hcl:"verify_incoming_https" mapstructure:"verify_incoming_https"`
+	VerifyIncomingHTTPSExemptCIDRs []string                 `json:"verify_incoming_https_exempt_cidrs,omitempty" hcl:"verify_incoming_https_exempt_cidrs" mapstructure:"verify_incoming_https_exempt_cidrs"`
+	VerifyIncomingRPC              *bool                    `json:"verify_incoming_rpc,omitempty" hcl:"verify_incoming_rpc" mapstructure:"verify_incoming_rpc"`
+	VerifyOutgoing                 *bool                    `json:"verify_outgoing,omitempty" hcl:"verify_outgoing" mapstructure:"verify_outgoing"`
+	VerifyServerHostname           *bool                    `json:"verify_server_hostname,omitempty" hcl:"verify_server_hostname" mapstructure:"verify_server_hostname"`
+	Watches                        []map[string]interface{} `json:"watches,omitempty" hcl:"watches" mapstructure:"watches"`
+	XDS                            XDS                      `json:"xds,omitempty" hcl:"xds" mapstructure:"xds"`
 
 	RPC RPC `mapstructure:"rpc"`
 
@@ -309,6 +404,10 @@ type GossipWANConfig struct {
 	RetransmitMult *int    `json:"retransmit_mult,omitempty" hcl:"retransmit_mult" mapstructure:"retransmit_mult"`
 }
 
+type GRPCConfig struct {
+	EnableConfigService *bool `json:"enable_config_service,omitempty" hcl:"enable_config_service" mapstructure:"enable_config_service"`
+}
+
 type Consul struct {
 	Coordinate struct {
 		UpdateBatchSize  *int    `json:"update_batch_size,omitempty" hcl:"update_batch_size" mapstructure:"update_batch_size"`
@@ -328,10 +427,11 @@ type Consul struct {
 }
 
 type Addresses struct {
-	DNS   *string `json:"dns,omitempty" hcl:"dns" mapstructure:"dns"`
-	HTTP  *string `json:"http,omitempty" hcl:"http" mapstructure:"http"`
-	HTTPS *string `json:"https,omitempty" hcl:"https" mapstructure:"https"`
-	GRPC  *string `json:"grpc,omitempty" hcl:"grpc" mapstructure:"grpc"`
+	DNS     *string `json:"dns,omitempty" hcl:"dns" mapstructure:"dns"`
+	HTTP    *string `json:"http,omitempty" hcl:"http" mapstructure:"http"`
+	HTTPS   *string `json:"https,omitempty" hcl:"https" mapstructure:"https"`
+	GRPC    *string `json:"grpc,omitempty" hcl:"grpc" mapstructure:"grpc"`
+	GRPCTLS *string `json:"grpc_tls,omitempty" hcl:"grpc_tls" mapstructure:"grpc_tls"`
 }
 
 type AdvertiseAddrsConfig struct {
@@ -364,6 +464,7 @@ type ServiceWeights struct {
 type ServiceAddress struct {
 	Address *string `json:"address,omitempty" hcl:"address" mapstructure:"address"`
 	Port    *int    `json:"port,omitempty" hcl:"port" mapstructure:"port"`
+	Health  *string `json:"health,omitempty" hcl:"health" mapstructure:"health"`
 }
 
 type ServiceDefinition struct {
@@ -402,6 +503,7 @@ type CheckDefinition struct {
 	TCP                            *string             `json:"tcp,omitempty" hcl:"tcp" mapstructure:"tcp"`
 	Interval                       *string             `json:"interval,omitempty" hcl:"interval" mapstructure:"interval"`
 	DockerContainerID              *string             `json:"docker_container_id,omitempty" hcl:"docker_container_id" mapstructure:"docker_container_id" alias:"dockercontainerid"`
+	CRIContainerID                 *string             `json:"cri_container_id,omitempty" hcl:"cri_container_id" mapstructure:"cri_container_id" alias:"cricontainerid"`
 	Shell                          *string             `json:"shell,omitempty" hcl:"shell" mapstructure:"shell"`
 	GRPC                           *string             `json:"grpc,omitempty" hcl:"grpc" mapstructure:"grpc"`
 	GRPCUseTLS                     *bool               `json:"grpc_use_tls,omitempty" hcl:"grpc_use_tls" mapstructure:"grpc_use_tls"`
@@ -570,12 +672,71 @@ type Connect struct {
 	CAConfig                        map[string]interface{} `json:"ca_config,omitempty" hcl:"ca_config" mapstructure:"ca_config"`
 	MeshGatewayWANFederationEnabled *bool                  `json:"enable_mesh_gateway_wan_federation" hcl:"enable_mesh_gateway_wan_federation" mapstructure:"enable_mesh_gateway_wan_federation"`
 
+	// UpstreamDefaults is merged into the opaque Config of every upstream of
+	// proxies registered with this agent so that common envoy settings do not
+	// have to be repeated in every upstream block. Keys an upstream sets
+	// itself always win over the defaults.
+	UpstreamDefaults *UpstreamDefaults `json:"upstream_defaults,omitempty" hcl:"upstream_defaults" mapstructure:"upstream_defaults"`
+
 	// TestCALeafRootChangeSpread controls how long after a CA roots change before new leaft certs will be generated.
 	// This is only tuned in tests, generally set to 1ns to make tests deterministic with when to expect updated leaf
 	// certs by. This configuration is not exposed to users (not documented, and agent/config/default.go will override it)
 	TestCALeafRootChangeSpread *string `json:"test_ca_leaf_root_change_spread,omitempty" hcl:"test_ca_leaf_root_change_spread" mapstructure:"test_ca_leaf_root_change_spread"`
 }
 
+// UpstreamDefaults holds the agent-wide default envoy settings for upstreams
+// of locally defined proxies. The fields mirror the keys of the opaque
+// upstream config understood by the xds package.
+type UpstreamDefaults struct {
+	ConnectTimeoutMS   *int                       `json:"connect_timeout_ms,omitempty" hcl:"connect_timeout_ms" mapstructure:"connect_timeout_ms"`
+	Protocol           *string                    `json:"protocol,omitempty" hcl:"protocol" mapstructure:"protocol"`
+	MaxConnections     *int                       `json:"max_connections,omitempty" hcl:"max_connections" mapstructure:"max_connections"`
+	PassiveHealthCheck *PassiveHealthCheckDefault `json:"passive_health_check,omitempty" hcl:"passive_health_check" mapstructure:"passive_health_check"`
+}
+
+// PassiveHealthCheckDefault is the passive health check part of the agent-wide
+// upstream defaults.
+type PassiveHealthCheckDefault struct {
+	Interval    *string `json:"interval,omitempty" hcl:"interval" mapstructure:"interval"`
+	MaxFailures *int    `json:"max_failures,omitempty" hcl:"max_failures" mapstructure:"max_failures"`
+}
+
+// MDNSAdvertise makes the agent advertise its own HTTP API over mDNS so that
+// dev and edge deployments can discover the agent itself without prior
+// configuration. Server agents refuse to enable it unless allow_server is
+// set since multicast advertisement is rarely appropriate in a datacenter.
+type MDNSAdvertise struct {
+	// Enabled turns the advertisement on.
+	Enabled *bool `json:"enabled,omitempty" hcl:"enabled" mapstructure:"enabled"`
+
+	// Service is the mDNS instance name to advertise under. Defaults to the
+	// node name.
+	Service *string `json:"service,omitempty" hcl:"service" mapstructure:"service"`
+
+	// Interface is the name of the network interface to bind the multicast
+	// listener to. The system default multicast interface is used when empty.
+	Interface *string `json:"interface,omitempty" hcl:"interface" mapstructure:"interface"`
+
+	// AllowServer opts a server agent in to mDNS advertisement.
+	AllowServer *bool `json:"allow_server,omitempty" hcl:"allow_server" mapstructure:"allow_server"`
+}
+
+// XDS tunes the xDS server that serves Envoy proxy configuration over the
+// gRPC port.
+type XDS struct {
+	// MaxConcurrentStreams limits how many xDS streams the server accepts at
+	// the same time. Zero means no limit.
+	MaxConcurrentStreams *int `json:"max_concurrent_streams,omitempty" hcl:"max_concurrent_streams" mapstructure:"max_concurrent_streams"`
+
+	// NackBackoff is how long the server waits before re-sending a resource
+	// that a proxy rejected with a NACK.
+	NackBackoff *string `json:"nack_backoff,omitempty" hcl:"nack_backoff" mapstructure:"nack_backoff"`
+
+	// EnableDelta switches proxies that support it to the incremental (delta)
+	// xDS protocol instead of full state-of-the-world updates.
+	EnableDelta *bool `json:"enable_delta,omitempty" hcl:"enable_delta" mapstructure:"enable_delta"`
+}
+
 // SOA is the configuration of SOA for DNS
 type SOA struct {
 	Refresh *uint32 `json:"refresh,omitempty" hcl:"refresh" mapstructure:"refresh"`
@@ -588,28 +749,102 @@ type DNS struct {
 	AllowStale         *bool             `json:"allow_stale,omitempty" hcl:"allow_stale" mapstructure:"allow_stale"`
 	ARecordLimit       *int              `json:"a_record_limit,omitempty" hcl:"a_record_limit" mapstructure:"a_record_limit"`
 	DisableCompression *bool             `json:"disable_compression,omitempty" hcl:"disable_compression" mapstructure:"disable_compression"`
+	DOHPath            *string           `json:"doh_path,omitempty" hcl:"doh_path" mapstructure:"doh_path"`
 	EnableTruncate     *bool             `json:"enable_truncate,omitempty" hcl:"enable_truncate" mapstructure:"enable_truncate"`
 	MaxStale           *string           `json:"max_stale,omitempty" hcl:"max_stale" mapstructure:"max_stale"`
 	NodeTTL            *string           `json:"node_ttl,omitempty" hcl:"node_ttl" mapstructure:"node_ttl"`
 	OnlyPassing        *bool             `json:"only_passing,omitempty" hcl:"only_passing" mapstructure:"only_passing"`
 	RecursorTimeout    *string           `json:"recursor_timeout,omitempty" hcl:"recursor_timeout" mapstructure:"recursor_timeout"`
 	ServiceTTL         map[string]string `json:"service_ttl,omitempty" hcl:"service_ttl" mapstructure:"service_ttl"`
+	TagAllowlist       []string          `json:"tag_allowlist,omitempty" hcl:"tag_allowlist" mapstructure:"tag_allowlist"`
 	UDPAnswerLimit     *int              `json:"udp_answer_limit,omitempty" hcl:"udp_answer_limit" mapstructure:"udp_answer_limit"`
 	NodeMetaTXT        *bool             `json:"enable_additional_node_meta_txt,omitempty" hcl:"enable_additional_node_meta_txt" mapstructure:"enable_additional_node_meta_txt"`
+	NodeMetaTXTKeys    []string          `json:"node_meta_txt_keys,omitempty" hcl:"node_meta_txt_keys" mapstructure:"node_meta_txt_keys"`
 	SOA                *SOA              `json:"soa,omitempty" hcl:"soa" mapstructure:"soa"`
 	UseCache           *bool             `json:"use_cache,omitempty" hcl:"use_cache" mapstructure:"use_cache"`
 	CacheMaxAge        *string           `json:"cache_max_age,omitempty" hcl:"cache_max_age" mapstructure:"cache_max_age"`
 
 	// Enterprise Only
 	PreferNamespace *bool `json:"prefer_namespace,omitempty" hcl:"prefer_namespace" mapstructure:"prefer_namespace"`
+
+	TLS DNSTLS `json:"tls,omitempty" hcl:"tls" mapstructure:"tls"`
+}
+
+// DNSTLS configures the certificate used by the DNS-over-TLS listener.
+type DNSTLS struct {
+	CertFile *string `json:"cert_file,omitempty" hcl:"cert_file" mapstructure:"cert_file"`
+	KeyFile  *string `json:"key_file,omitempty" hcl:"key_file" mapstructure:"key_file"`
 }
 
 type HTTPConfig struct {
-	BlockEndpoints     []string          `json:"block_endpoints,omitempty" hcl:"block_endpoints" mapstructure:"block_endpoints"`
-	AllowWriteHTTPFrom []string          `json:"allow_write_http_from,omitempty" hcl:"allow_write_http_from" mapstructure:"allow_write_http_from"`
-	ResponseHeaders    map[string]string `json:"response_headers,omitempty" hcl:"response_headers" mapstructure:"response_headers"`
-	UseCache           *bool             `json:"use_cache,omitempty" hcl:"use_cache" mapstructure:"use_cache"`
-	MaxHeaderBytes     *int              `json:"max_header_bytes,omitempty" hcl:"max_header_bytes" mapstructure:"max_header_bytes"`
+	BlockEndpoints         []string            `json:"block_endpoints,omitempty" hcl:"block_endpoints" mapstructure:"block_endpoints"`
+	BlockEndpointRules     []BlockEndpointRule `json:"block_endpoint_rules,omitempty" hcl:"block_endpoint_rules" mapstructure:"block_endpoint_rules"`
+	DisableLegacyEndpoints []string            `json:"disable_legacy_endpoints,omitempty" hcl:"disable_legacy_endpoints" mapstructure:"disable_legacy_endpoints"`
+	AllowWriteHTTPFrom     []string            `json:"allow_write_http_from,omitempty" hcl:"allow_write_http_from" mapstructure:"allow_write_http_from"`
+	RequestIDHeader        *string             `json:"request_id_header,omitempty" hcl:"request_id_header" mapstructure:"request_id_header"`
+	ResponseHeaders        map[string]string   `json:"response_headers,omitempty" hcl:"response_headers" mapstructure:"response_headers"`
+	UseCache               *bool               `json:"use_cache,omitempty" hcl:"use_cache" mapstructure:"use_cache"`
+	MaxHeaderBytes         *int                `json:"max_header_bytes,omitempty" hcl:"max_header_bytes" mapstructure:"max_header_bytes"`
+	ProxyProtocol          ProxyProtocol       `json:"proxy_protocol,omitempty" hcl:"proxy_protocol" mapstructure:"proxy_protocol"`
+}
+
+// ProxyProtocol configures PROXY protocol support on the HTTP and HTTPS
+// listeners so agents behind an L4 load balancer can recover the real client
+// address for allow_write_http_from enforcement and request logging.
+type ProxyProtocol struct {
+	Enabled        *bool    `json:"enabled,omitempty" hcl:"enabled" mapstructure:"enabled"`
+	AllowedSources []string `json:"allowed_sources,omitempty" hcl:"allowed_sources" mapstructure:"allowed_sources"`
+}
+
+// BlockEndpointRule is a structured alternative to the plain path prefixes
+// of block_endpoints. A rule blocks a request when the path matches
+// path_regex and, if methods is set, the request method is listed.
+type BlockEndpointRule struct {
+	Name            *string  `json:"name,omitempty" hcl:"name" mapstructure:"name"`
+	PathRegex       *string  `json:"path_regex,omitempty" hcl:"path_regex" mapstructure:"path_regex"`
+	Methods         []string `json:"methods,omitempty" hcl:"methods" mapstructure:"methods"`
+	ResponseCode    *int     `json:"response_code,omitempty" hcl:"response_code" mapstructure:"response_code"`
+	ResponseMessage *string  `json:"response_message,omitempty" hcl:"response_message" mapstructure:"response_message"`
+}
+
+// SanitizeConfig controls the redaction the agent applies when it renders
+// its configuration, e.g. in /v1/agent/self.
+type SanitizeConfig struct {
+	RedactKeys []string `json:"redact_keys,omitempty" hcl:"redact_keys" mapstructure:"redact_keys"`
+	RedactNone *bool    `json:"redact_none,omitempty" hcl:"redact_none" mapstructure:"redact_none"`
+}
+
+// MergeStrategy controls how list values from the config source it appears
+// in are merged over earlier sources. List keys named in replace have their
+// previously merged value replaced by this source's value instead of
+// appended to, which is otherwise impossible for keys like retry_join.
+type MergeStrategy struct {
+	Replace []string `json:"replace,omitempty" hcl:"replace" mapstructure:"replace"`
+}
+
+// Logging groups logging settings beyond the flat log_* keys. The
+// slow query threshold applies to HTTP requests, blocking queries and DNS
+// lookups served by the agent.
+type Logging struct {
+	SlowQueryThreshold *string `json:"slow_query_threshold,omitempty" hcl:"slow_query_threshold" mapstructure:"slow_query_threshold"`
+}
+
+// MaintenanceSchedule configures a recurring maintenance window during which
+// the node automatically enters maintenance mode, e.g. for patch windows.
+type MaintenanceSchedule struct {
+	Cron     *string `json:"cron,omitempty" hcl:"cron" mapstructure:"cron"`
+	Duration *string `json:"duration,omitempty" hcl:"duration" mapstructure:"duration"`
+	Reason   *string `json:"reason,omitempty" hcl:"reason" mapstructure:"reason"`
+}
+
+// PrimaryDatacenterMigration describes a controlled rename of the
+// primary datacenter. Before the cutover time the old name is the
+// effective primary, afterwards the new one, and the retired name keeps
+// resolving through an implicit datacenter alias.
+type PrimaryDatacenterMigration struct {
+	Old         *string `json:"old,omitempty" hcl:"old" mapstructure:"old"`
+	New         *string `json:"new,omitempty" hcl:"new" mapstructure:"new"`
+	CutoverTime *string `json:"cutover_time,omitempty" hcl:"cutover_time" mapstructure:"cutover_time"`
 }
 
 type Performance struct {
@@ -618,6 +853,52 @@ type Performance struct {
 	RPCHoldTimeout *string `json:"rpc_hold_timeout" hcl:"rpc_hold_timeout" mapstructure:"rpc_hold_timeout"`
 }
 
+// TLS is the tls { } stanza. It groups the TLS settings per listener with
+// inheritance from the defaults block, so the HTTPS API and the server RPC
+// listener can use different CAs and verification policies. Settings not
+// present in a listener block fall back to defaults, which in turn falls
+// back to the flat top-level cert and verify fields.
+type TLS struct {
+	Defaults    TLSProtocolConfig `json:"defaults,omitempty" hcl:"defaults" mapstructure:"defaults"`
+	HTTPS       TLSProtocolConfig `json:"https,omitempty" hcl:"https" mapstructure:"https"`
+	InternalRPC TLSProtocolConfig `json:"internal_rpc,omitempty" hcl:"internal_rpc" mapstructure:"internal_rpc"`
+	GRPC        TLSProtocolConfig `json:"grpc,omitempty" hcl:"grpc" mapstructure:"grpc"`
+	WAN         TLSProtocolConfig `json:"wan,omitempty" hcl:"wan" mapstructure:"wan"`
+
+	// Profiles are named TLS settings that the https and grpc blocks can
+	// reference via their profile field, and that agent checks and watches
+	// reference via checks_profile and watches_profile. The builder rejects
+	// references to names that are not defined here.
+	Profiles map[string]TLSProtocolConfig `json:"profiles,omitempty" hcl:"profiles" mapstructure:"profiles"`
+
+	ChecksProfile  *string `json:"checks_profile,omitempty" hcl:"checks_profile" mapstructure:"checks_profile"`
+	WatchesProfile *string `json:"watches_profile,omitempty" hcl:"watches_profile" mapstructure:"watches_profile"`
+}
+
+// TLSProtocolConfig is a single block inside the tls { } stanza. Not every
+// field is valid in every block; the builder rejects settings that only
+// make sense for the defaults or internal_rpc blocks elsewhere.
+type TLSProtocolConfig struct {
+	CAFile               *string `json:"ca_file,omitempty" hcl:"ca_file" mapstructure:"ca_file"`
+	CAPath               *string `json:"ca_path,omitempty" hcl:"ca_path" mapstructure:"ca_path"`
+	CertFile             *string `json:"cert_file,omitempty" hcl:"cert_file" mapstructure:"cert_file"`
+	KeyFile              *string `json:"key_file,omitempty" hcl:"key_file" mapstructure:"key_file"`
+	TLSCipherSuites      *string `json:"tls_cipher_suites,omitempty" hcl:"tls_cipher_suites" mapstructure:"tls_cipher_suites"`
+	TLSMinVersion        *string `json:"tls_min_version,omitempty" hcl:"tls_min_version" mapstructure:"tls_min_version"`
+	VerifyIncoming       *bool   `json:"verify_incoming,omitempty" hcl:"verify_incoming" mapstructure:"verify_incoming"`
+	VerifyOutgoing       *bool   `json:"verify_outgoing,omitempty" hcl:"verify_outgoing" mapstructure:"verify_outgoing"`
+	VerifyServerHostname *bool   `json:"verify_server_hostname,omitempty" hcl:"verify_server_hostname" mapstructure:"verify_server_hostname"`
+	Profile              *string `json:"profile,omitempty" hcl:"profile" mapstructure:"profile"`
+}
+
+// Tracing is the distributed tracing configuration for the agent. Traces
+// are exported to the configured OpenTelemetry collector.
+type Tracing struct {
+	OTLPEndpoint  *string  `json:"otlp_endpoint,omitempty" hcl:"otlp_endpoint" mapstructure:"otlp_endpoint"`
+	SamplingRatio *float64 `json:"sampling_ratio,omitempty" hcl:"sampling_ratio" mapstructure:"sampling_ratio"`
+	ServiceName   *string  `json:"service_name,omitempty" hcl:"service_name" mapstructure:"service_name"`
+}
+
 type Telemetry struct {
 	CirconusAPIApp                     *string  `json:"circonus_api_app,omitempty" hcl:"circonus_api_app" mapstructure:"circonus_api_app"`
 	CirconusAPIToken                   *string  `json:"circonus_api_token,omitempty" hcl:"circonus_api_token" mapstructure:"circonus_api_token"`
@@ -636,6 +917,7 @@ type Telemetry struct {
 	DisableHostname                    *bool    `json:"disable_hostname,omitempty" hcl:"disable_hostname" mapstructure:"disable_hostname"`
 	DogstatsdAddr                      *string  `json:"dogstatsd_addr,omitempty" hcl:"dogstatsd_addr" mapstructure:"dogstatsd_addr"`
 	DogstatsdTags                      []string `json:"dogstatsd_tags,omitempty" hcl:"dogstatsd_tags" mapstructure:"dogstatsd_tags"`
+	EnableDeprecatedConfigMetrics      *bool    `json:"enable_deprecated_config_metrics,omitempty" hcl:"enable_deprecated_config_metrics" mapstructure:"enable_deprecated_config_metrics"`
 	FilterDefault                      *bool    `json:"filter_default,omitempty" hcl:"filter_default" mapstructure:"filter_default"`
 	PrefixFilter                       []string `json:"prefix_filter,omitempty" hcl:"prefix_filter" mapstructure:"prefix_filter"`
 	MetricsPrefix                      *string  `json:"metrics_prefix,omitempty" hcl:"metrics_prefix" mapstructure:"metrics_prefix"`
@@ -646,12 +928,16 @@ type Telemetry struct {
 
 type Ports struct {
 	DNS            *int `json:"dns,omitempty" hcl:"dns" mapstructure:"dns"`
+	DNSTCP         *int `json:"dns_tcp,omitempty" hcl:"dns_tcp" mapstructure:"dns_tcp"`
+	DNSUDP         *int `json:"dns_udp,omitempty" hcl:"dns_udp" mapstructure:"dns_udp"`
+	DNSTLS         *int `json:"dns_tls,omitempty" hcl:"dns_tls" mapstructure:"dns_tls"`
 	HTTP           *int `json:"http,omitempty" hcl:"http" mapstructure:"http"`
 	HTTPS          *int `json:"https,omitempty" hcl:"https" mapstructure:"https"`
 	SerfLAN        *int `json:"serf_lan,omitempty" hcl:"serf_lan" mapstructure:"serf_lan"`
 	SerfWAN        *int `json:"serf_wan,omitempty" hcl:"serf_wan" mapstructure:"serf_wan"`
 	Server         *int `json:"server,omitempty" hcl:"server" mapstructure:"server"`
 	GRPC           *int `json:"grpc,omitempty" hcl:"grpc" mapstructure:"grpc"`
+	GRPCTLS        *int `json:"grpc_tls,omitempty" hcl:"grpc_tls" mapstructure:"grpc_tls"`
 	ProxyMinPort   *int `json:"proxy_min_port,omitempty" hcl:"proxy_min_port" mapstructure:"proxy_min_port"`
 	ProxyMaxPort   *int `json:"proxy_max_port,omitempty" hcl:"proxy_max_port" mapstructure:"proxy_max_port"`
 	SidecarMinPort *int `json:"sidecar_min_port,omitempty" hcl:"sidecar_min_port" mapstructure:"sidecar_min_port"`
@@ -660,21 +946,123 @@ type Ports struct {
 	ExposeMaxPort  *int `json:"expose_max_port,omitempty" hcl:"expose_max_port" mapstructure:"expose_max_port"`
 }
 
+// CheckExecLimits bounds the resources script check processes may use so
+// that a heavy check cannot starve the agent. Unset values apply no limit.
+type CheckExecLimits struct {
+	MaxConcurrent *int   `json:"max_concurrent,omitempty" hcl:"max_concurrent" mapstructure:"max_concurrent"`
+	CPUSeconds    *int   `json:"cpu_seconds,omitempty" hcl:"cpu_seconds" mapstructure:"cpu_seconds"`
+	MemoryBytes   *int64 `json:"memory_bytes,omitempty" hcl:"memory_bytes" mapstructure:"memory_bytes"`
+	Nice          *int   `json:"nice,omitempty" hcl:"nice" mapstructure:"nice"`
+}
+
+// DockerEndpoint configures how the agent reaches the Docker daemon for
+// docker container checks, for hosts running rootless or remote Docker.
+// The host falls back to the DOCKER_HOST environment variable and then
+// the default socket. TLS material may only be set for tcp hosts.
+type DockerEndpoint struct {
+	Host          *string `json:"host,omitempty" hcl:"host" mapstructure:"host"`
+	CAFile        *string `json:"ca_file,omitempty" hcl:"ca_file" mapstructure:"ca_file"`
+	CertFile      *string `json:"cert_file,omitempty" hcl:"cert_file" mapstructure:"cert_file"`
+	KeyFile       *string `json:"key_file,omitempty" hcl:"key_file" mapstructure:"key_file"`
+	TLSSkipVerify *bool   `json:"tls_skip_verify,omitempty" hcl:"tls_skip_verify" mapstructure:"tls_skip_verify"`
+}
+
+// Runtime tunes the Go runtime of the agent process so that resource
+// tuning lives with the rest of the agent configuration rather than in
+// environment variables in unit files. Unset values leave the runtime
+// defaults and environment variables in place.
+type Runtime struct {
+	GOMAXPROCS  *int   `json:"gomaxprocs,omitempty" hcl:"gomaxprocs" mapstructure:"gomaxprocs"`
+	GOGC        *int   `json:"gogc,omitempty" hcl:"gogc" mapstructure:"gogc"`
+	MemoryLimit *int64 `json:"memory_limit,omitempty" hcl:"memory_limit" mapstructure:"memory_limit"`
+}
+
+// UsageReporting is the opt-in reporting of enabled major features to an
+// operator controlled endpoint, used by platform teams to plan upgrades
+// across many clusters. Nothing is reported unless enabled is set.
+type UsageReporting struct {
+	Enabled  *bool   `json:"enabled,omitempty" hcl:"enabled" mapstructure:"enabled"`
+	Endpoint *string `json:"endpoint,omitempty" hcl:"endpoint" mapstructure:"endpoint"`
+	Interval *string `json:"interval,omitempty" hcl:"interval" mapstructure:"interval"`
+}
+
 type UnixSocket struct {
+	Group *string                  `json:"group,omitempty" hcl:"group" mapstructure:"group"`
+	Mode  *string                  `json:"mode,omitempty" hcl:"mode" mapstructure:"mode"`
+	User  *string                  `json:"user,omitempty" hcl:"user" mapstructure:"user"`
+	GRPC  UnixSocketPermissionsRaw `json:"grpc,omitempty" hcl:"grpc" mapstructure:"grpc"`
+	HTTP  UnixSocketPermissionsRaw `json:"http,omitempty" hcl:"http" mapstructure:"http"`
+	HTTPS UnixSocketPermissionsRaw `json:"https,omitempty" hcl:"https" mapstructure:"https"`
+}
+
+// UnixSocketPermissionsRaw overrides the agent-wide unix socket ownership and
+// mode for the sockets of a single listener.
+type UnixSocketPermissionsRaw struct {
 	Group *string `json:"group,omitempty" hcl:"group" mapstructure:"group"`
 	Mode  *string `json:"mode,omitempty" hcl:"mode" mapstructure:"mode"`
 	User  *string `json:"user,omitempty" hcl:"user" mapstructure:"user"`
 }
 
 type Limits struct {
-	HTTPMaxConnsPerClient *int     `json:"http_max_conns_per_client,omitempty" hcl:"http_max_conns_per_client" mapstructure:"http_max_conns_per_client"`
-	HTTPSHandshakeTimeout *string  `json:"https_handshake_timeout,omitempty" hcl:"https_handshake_timeout" mapstructure:"https_handshake_timeout"`
-	RPCHandshakeTimeout   *string  `json:"rpc_handshake_timeout,omitempty" hcl:"rpc_handshake_timeout" mapstructure:"rpc_handshake_timeout"`
-	RPCMaxBurst           *int     `json:"rpc_max_burst,omitempty" hcl:"rpc_max_burst" mapstructure:"rpc_max_burst"`
-	RPCMaxConnsPerClient  *int     `json:"rpc_max_conns_per_client,omitempty" hcl:"rpc_max_conns_per_client" mapstructure:"rpc_max_conns_per_client"`
-	RPCRate               *float64 `json:"rpc_rate,omitempty" hcl:"rpc_rate" mapstructure:"rpc_rate"`
-	KVMaxValueSize        *uint64  `json:"kv_max_value_size,omitempty" hcl:"kv_max_value_size" mapstructure:"kv_max_value_size"`
-	TxnMaxReqLen          *uint64  `json:"txn_max_req_len,omitempty" hcl:"txn_max_req_len" mapstructure:"txn_max_req_len"`
+	HTTPMaxConnsPerClient *int      `json:"http_max_conns_per_client,omitempty" hcl:"http_max_conns_per_client" mapstructure:"http_max_conns_per_client"`
+	HTTPSHandshakeTimeout *string   `json:"https_handshake_timeout,omitempty" hcl:"https_handshake_timeout" mapstructure:"https_handshake_timeout"`
+	RPCHandshakeTimeout   *string   `json:"rpc_handshake_timeout,omitempty" hcl:"rpc_handshake_timeout" mapstructure:"rpc_handshake_timeout"`
+	RPCMaxBurst           *int      `json:"rpc_max_burst,omitempty" hcl:"rpc_max_burst" mapstructure:"rpc_max_burst"`
+	RPCMaxConnsPerClient  *int      `json:"rpc_max_conns_per_client,omitempty" hcl:"rpc_max_conns_per_client" mapstructure:"rpc_max_conns_per_client"`
+	RPCRate               *float64  `json:"rpc_rate,omitempty" hcl:"rpc_rate" mapstructure:"rpc_rate"`
+	KVMaxValueSize        *uint64   `json:"kv_max_value_size,omitempty" hcl:"kv_max_value_size" mapstructure:"kv_max_value_size"`
+	KVQuota               []KVQuota `json:"kv_quota,omitempty" hcl:"kv_quota" mapstructure:"kv_quota"`
+	ReservedPorts         []string  `json:"reserved_ports,omitempty" hcl:"reserved_ports" mapstructure:"reserved_ports"`
+	TxnMaxReqLen          *uint64   `json:"txn_max_req_len,omitempty" hcl:"txn_max_req_len" mapstructure:"txn_max_req_len"`
+}
+
+// DiscoveryMaxResults caps how many service instances a single discovery
+// query may return, per protocol. It protects agents from ballooning
+// answers when a service has tens of thousands of instances. Zero means
+// unlimited. Results are truncated after shuffling and filtering, so
+// clients still see a spread of instances.
+type DiscoveryMaxResults struct {
+	DNS  *int `json:"dns,omitempty" hcl:"dns" mapstructure:"dns"`
+	HTTP *int `json:"http,omitempty" hcl:"http" mapstructure:"http"`
+}
+
+// OutboundProxy routes the agent's own outbound HTTP traffic — retry-join
+// cloud discovery, the update check, OIDC discovery and the Vault CA
+// provider — through an HTTP CONNECT or SOCKS5 proxy for agents in
+// egress-restricted networks. Credentials are kept out of the main
+// configuration by referencing a separate file holding a single
+// "username:password" line.
+type OutboundProxy struct {
+	URL             *string  `json:"url,omitempty" hcl:"url" mapstructure:"url"`
+	NoProxy         []string `json:"no_proxy,omitempty" hcl:"no_proxy" mapstructure:"no_proxy"`
+	CredentialsFile *string  `json:"credentials_file,omitempty" hcl:"credentials_file" mapstructure:"credentials_file"`
+}
+
+// ListenerOptions tunes low-level socket behavior per listener type. Each
+// block only affects the listeners of the named type.
+type ListenerOptions struct {
+	DNS   ListenerOptionsBlock `json:"dns,omitempty" hcl:"dns" mapstructure:"dns"`
+	GRPC  ListenerOptionsBlock `json:"grpc,omitempty" hcl:"grpc" mapstructure:"grpc"`
+	HTTP  ListenerOptionsBlock `json:"http,omitempty" hcl:"http" mapstructure:"http"`
+	HTTPS ListenerOptionsBlock `json:"https,omitempty" hcl:"https" mapstructure:"https"`
+}
+
+// ListenerOptionsBlock holds the socket options for one listener type.
+// so_reuseport and backlog are applied when the socket is bound and have no
+// effect on Windows. tcp_keepalive_interval adjusts the keep-alive period
+// set on accepted TCP connections.
+type ListenerOptionsBlock struct {
+	SOReusePort          *bool   `json:"so_reuseport,omitempty" hcl:"so_reuseport" mapstructure:"so_reuseport"`
+	TCPKeepaliveInterval *string `json:"tcp_keepalive_interval,omitempty" hcl:"tcp_keepalive_interval" mapstructure:"tcp_keepalive_interval"`
+	Backlog              *int    `json:"backlog,omitempty" hcl:"backlog" mapstructure:"backlog"`
+}
+
+// KVQuota caps KV usage under a key prefix. Writes that would push the
+// prefix over max_keys keys or max_bytes total value bytes are rejected.
+type KVQuota struct {
+	Prefix   *string `json:"prefix,omitempty" hcl:"prefix" mapstructure:"prefix"`
+	MaxKeys  *int    `json:"max_keys,omitempty" hcl:"max_keys" mapstructure:"max_keys"`
+	MaxBytes *int    `json:"max_bytes,omitempty" hcl:"max_bytes" mapstructure:"max_bytes"`
 }
 
 type Segment struct {
@@ -728,6 +1116,25 @@ type ConfigEntries struct {
 	Bootstrap []map[string]interface{} `json:"bootstrap,omitempty" hcl:"bootstrap" mapstructure:"bootstrap"`
 }
 
+// ConfigVerification configures verification of a detached ed25519 signature
+// over the config files before they are applied. Since the config files are
+// exactly what the signature protects, this block is only honored from the
+// command line or from the embedding application and is ignored when it
+// appears in a config file.
+type ConfigVerification struct {
+	// PublicKeyFile is the path to a file containing the base64 encoded
+	// ed25519 public key that signatures are verified against.
+	PublicKeyFile *string `json:"public_key_file,omitempty" hcl:"public_key_file" mapstructure:"public_key_file"`
+
+	// SignatureFile is the path to a file containing the base64 encoded
+	// detached signature over the config file contents.
+	SignatureFile *string `json:"signature_file,omitempty" hcl:"signature_file" mapstructure:"signature_file"`
+
+	// Required refuses to start when no signature file is present instead of
+	// skipping verification with a warning.
+	Required *bool `json:"required,omitempty" hcl:"required" mapstructure:"required"`
+}
+
 // Audit allows us to enable and define destinations for auditing
 type Audit struct {
 	Enabled *bool                `json:"enabled,omitempty" hcl:"enabled" mapstructure:"enabled"`
@@ -0,0 +1,85 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileSource_ParseErrorPosition(t *testing.T) {
+	tests := []struct {
+		desc   string
+		format string
+		data   string
+		want   string
+	}{
+		{
+			desc:   "json syntax error",
+			format: "json",
+			data:   "{\n  \"node_name\": \"a\"\n  \"datacenter\": \"dc1\"\n}",
+			want:   "3:4: ",
+		},
+		{
+			desc:   "hcl syntax error",
+			format: "hcl",
+			data:   "node_name = \"a\"\nports {\n",
+			want:   "3:2: ",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			_, _, err := FileSource{Name: "a." + tt.format, Format: tt.format, Data: tt.data}.Parse()
+			require.Error(t, err)
+			require.Contains(t, err.Error(), tt.want)
+		})
+	}
+}
+
+func TestLinecol(t *testing.T) {
+	data := "ab\ncd\ne"
+	tests := []struct {
+		offset    int
+		line, col int
+	}{
+		{0, 1, 1},
+		{2, 1, 3},
+		{3, 2, 1},
+		{5, 2, 3},
+		{6, 3, 1},
+		{100, 3, 2},
+	}
+	for _, tt := range tests {
+		line, col := linecol(data, tt.offset)
+		require.Equal(t, tt.line, line, "line for offset %d", tt.offset)
+		require.Equal(t, tt.col, col, "col for offset %d", tt.offset)
+	}
+}
+
+func TestParse(t *testing.T) {
+	c, err := Parse([]byte(`{"node_name": "hobbiton", "ports": {"http": 18500}}`), "json")
+	require.NoError(t, err)
+	require.Equal(t, "hobbiton", *c.NodeName)
+	require.Equal(t, 18500, *c.Ports.HTTP)
+
+	c, err = Parse([]byte(`node_name = "bree"`), "hcl")
+	require.NoError(t, err)
+	require.Equal(t, "bree", *c.NodeName)
+
+	// An empty document is not an error.
+	c, err = Parse(nil, "hcl")
+	require.NoError(t, err)
+	require.Equal(t, Config{}, c)
+
+	_, err = Parse([]byte(`node_name = "a"`), "yaml")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "invalid format")
+}
+
+func TestDefault(t *testing.T) {
+	rt, err := Default()
+	require.NoError(t, err)
+	require.Equal(t, 8500, rt.HTTPPort)
+	require.Equal(t, 8600, rt.DNSPort)
+	require.Equal(t, 8300, rt.ServerPort)
+	require.False(t, rt.ServerMode)
+}
@@ -0,0 +1,85 @@
+package config
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"io/ioutil"
+	"strings"
+)
+
+// Config files with an .hcl.age or .json.age extension are stored encrypted
+// so that gossip keys and tokens can live in version control safely. An
+// encrypted file holds the base64 encoding of a random 12 byte nonce followed
+// by the AES-256-GCM ciphertext of the plain config file, sealed with the
+// 256-bit key referenced via -config-decrypt-key.
+
+// isEncryptedFile determines whether the file at path holds an encrypted
+// config file.
+func isEncryptedFile(path string) bool {
+	return strings.HasSuffix(path, ".age")
+}
+
+// loadDecryptKey reads the base64 encoded 256-bit decryption key from the
+// file at path.
+func loadDecryptKey(path string) ([]byte, error) {
+	keyData, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("config: failed to read -config-decrypt-key: %s", err)
+	}
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(keyData)))
+	if err != nil {
+		return nil, fmt.Errorf("config: -config-decrypt-key %s does not contain a base64 encoded key: %s", path, err)
+	}
+	if len(key) != 32 {
+		return nil, fmt.Errorf("config: -config-decrypt-key %s must contain a 32 byte key, got %d bytes", path, len(key))
+	}
+	return key, nil
+}
+
+// decryptConfig decrypts the contents of an encrypted config file.
+func decryptConfig(data, key []byte) ([]byte, error) {
+	raw, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(data)))
+	if err != nil {
+		return nil, fmt.Errorf("not base64 encoded: %s", err)
+	}
+	aead, err := newConfigAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(raw) < aead.NonceSize() {
+		return nil, fmt.Errorf("ciphertext too short")
+	}
+	nonce, ciphertext := raw[:aead.NonceSize()], raw[aead.NonceSize():]
+	plain, err := aead.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return nil, fmt.Errorf("decryption failed: %s", err)
+	}
+	return plain, nil
+}
+
+// encryptConfig produces the encrypted file contents for a plain config
+// file. It is the inverse of decryptConfig and exists for tests and external
+// tooling that prepares encrypted config files.
+func encryptConfig(plain, key []byte) ([]byte, error) {
+	aead, err := newConfigAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	raw := aead.Seal(nonce, nonce, plain, nil)
+	return []byte(base64.StdEncoding.EncodeToString(raw)), nil
+}
+
+func newConfigAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -1,7 +1,10 @@
 package config
 
 import (
+	"fmt"
+	"sort"
 	"strconv"
+	"strings"
 
 	"github.com/hashicorp/raft"
 
@@ -109,6 +112,7 @@ func DefaultSource() Source {
 			http = 8500
 			https = -1
 			grpc = -1
+			grpc_tls = -1
 			serf_lan = ` + strconv.Itoa(consul.DefaultLANSerfPort) + `
 			serf_wan = ` + strconv.Itoa(consul.DefaultWANSerfPort) + `
 			server = ` + strconv.Itoa(consul.DefaultRPCPort) + `
@@ -173,6 +177,84 @@ func DevSource() Source {
 	}
 }
 
+// profiles are the tuning presets selectable with -profile. Each preset is
+// merged in the head after the default configuration so user supplied
+// configuration still overrides it.
+var profiles = map[string]string{
+	// server-small trades throughput for a smaller resource footprint on
+	// modest hardware.
+	"server-small": `
+		performance = {
+			raft_multiplier = 5
+		}
+		limits = {
+			http_max_conns_per_client = 100
+			rpc_max_conns_per_client = 50
+		}
+		cache = {
+			entry_fetch_rate = 50
+			entry_fetch_max_burst = 5
+		}
+	`,
+	// server-large assumes dedicated hardware and a fast network and tunes
+	// for latency and connection volume.
+	"server-large": `
+		performance = {
+			raft_multiplier = 1
+		}
+		limits = {
+			http_max_conns_per_client = 400
+			rpc_max_conns_per_client = 200
+		}
+		cache = {
+			entry_fetch_max_burst = 10
+		}
+		gossip_lan = {
+			gossip_interval = "100ms"
+			probe_interval = "500ms"
+		}
+	`,
+	// dev-connect enables Connect and the UI for local service mesh
+	// development without the rest of dev mode.
+	"dev-connect": `
+		connect = {
+			enabled = true
+		}
+		ui_config {
+			enabled = true
+		}
+		ports = {
+			grpc = 8502
+		}
+	`,
+}
+
+// ProfileNames returns the names of the available tuning presets in
+// alphabetical order.
+func ProfileNames() []string {
+	var names []string
+	for name := range profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ProfileSource returns the additional default configuration for the given
+// tuning preset. This should be merged in the head after the default
+// configuration.
+func ProfileSource(profile string) (Source, error) {
+	data, ok := profiles[profile]
+	if !ok {
+		return nil, fmt.Errorf("config: -profile must be one of %s", strings.Join(ProfileNames(), ", "))
+	}
+	return FileSource{
+		Name:   "profile:" + profile,
+		Format: "hcl",
+		Data:   data,
+	}, nil
+}
+
 // NonUserSource contains the values the user cannot configure.
 // This needs to be merged in the tail.
 // TODO: return a LiteralSource (no decoding) instead of a FileSource
@@ -225,6 +307,37 @@ func defaultVersionSource() Source {
 	return versionSource(version.GitCommit, version.Version, version.VersionPrerelease)
 }
 
+// VersionMetadata are overrides for the version parameters that embedding
+// distributions can supply via BuilderOpts to tag agents with their own
+// build or channel info. Empty fields keep the compiled value.
+type VersionMetadata struct {
+	Revision          string
+	Version           string
+	VersionPrerelease string
+}
+
+// versionMetadataSource creates a config source from the overrides. It is
+// merged in the tail after defaultVersionSource so the overrides win.
+// Downstream builds may tag but not impersonate another core version, so a
+// version that conflicts with the compiled one is rejected.
+func versionMetadataSource(md VersionMetadata) (Source, error) {
+	if md.Version != "" && md.Version != version.Version {
+		return nil, fmt.Errorf("config: version metadata version %q conflicts with the compiled version %q",
+			md.Version, version.Version)
+	}
+	cfg := Config{}
+	if md.Revision != "" {
+		cfg.Revision = &md.Revision
+	}
+	if md.Version != "" {
+		cfg.Version = &md.Version
+	}
+	if md.VersionPrerelease != "" {
+		cfg.VersionPrerelease = &md.VersionPrerelease
+	}
+	return LiteralSource{Name: "version-metadata", Config: cfg}, nil
+}
+
 // DefaultConsulSource returns the default configuration for the consul agent.
 // This should be merged in the tail since these values are not user configurable.
 // TODO: return a LiteralSource (no decoding) instead of a FileSource
@@ -281,3 +394,16 @@ func DefaultRuntimeConfig(hcl string) *RuntimeConfig {
 	}
 	return &rt
 }
+
+// Default returns the runtime configuration an agent ends up with when no
+// user configuration is supplied at all. Unlike DefaultRuntimeConfig it
+// returns errors instead of panicking and skips validation, since the
+// defaults alone do not contain a data dir. External tools use it to
+// compare user documents against Consul's exact default values.
+func Default() (RuntimeConfig, error) {
+	b, err := NewBuilder(BuilderOpts{})
+	if err != nil {
+		return RuntimeConfig{}, err
+	}
+	return b.Build()
+}
@@ -0,0 +1,109 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/go-version"
+)
+
+// DeprecatedKey describes a config key that still works but is slated for
+// removal.
+type DeprecatedKey struct {
+	// Name is the config key as it appears in config files.
+	Name string
+
+	// RemovedIn is the Consul version the key will be removed in. It is
+	// appended to the deprecation warning and used by deprecation_policy to
+	// refuse configs that would break on an upcoming upgrade.
+	RemovedIn string
+
+	// ReplacedBy is the key that supersedes this one, if any.
+	ReplacedBy string
+}
+
+// deprecatedKeys maps config keys that still work but are slated for removal
+// to their removal version and replacement.
+var deprecatedKeys = map[string]DeprecatedKey{
+	"acl_enforce_version_8": {RemovedIn: "1.10.0"},
+	"acl_datacenter":        {RemovedIn: "1.10.0", ReplacedBy: "primary_datacenter"},
+	"ui":                    {RemovedIn: "1.10.0", ReplacedBy: "ui_config.enabled"},
+	"ui_dir":                {RemovedIn: "1.10.0", ReplacedBy: "ui_config.dir"},
+	"ui_content_path":       {RemovedIn: "1.10.0", ReplacedBy: "ui_config.content_path"},
+}
+
+// DeprecatedKeys returns the deprecated config keys sorted by name.
+func DeprecatedKeys() []DeprecatedKey {
+	keys := make([]DeprecatedKey, 0, len(deprecatedKeys))
+	for name, k := range deprecatedKeys {
+		k.Name = name
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].Name < keys[j].Name })
+	return keys
+}
+
+// warnDeprecated records a deprecation warning for key, extended with the
+// version the key will be removed in. The key is also remembered so that
+// deprecation_policy = "error-after:<version>" can turn it into an error.
+func (b *Builder) warnDeprecated(key string, msg string, args ...interface{}) {
+	if dk, ok := deprecatedKeys[key]; ok {
+		msg += fmt.Sprintf(" This key will be removed in Consul %s.", dk.RemovedIn)
+		b.deprecatedInUse = append(b.deprecatedInUse, key)
+	}
+	b.warning(Warning{
+		Code:    "deprecated-key:" + key,
+		Key:     key,
+		Message: fmt.Sprintf(msg, args...),
+	})
+}
+
+// deprecatedKeysVal returns the deprecated keys the configuration used,
+// sorted and de-duplicated, for the DeprecatedKeysInUse runtime field.
+func (b *Builder) deprecatedKeysVal() []string {
+	if len(b.deprecatedInUse) == 0 {
+		return nil
+	}
+	seen := make(map[string]bool, len(b.deprecatedInUse))
+	var keys []string
+	for _, k := range b.deprecatedInUse {
+		if !seen[k] {
+			seen[k] = true
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// checkDeprecationPolicy enforces deprecation_policy against the deprecated
+// keys that were actually used. With "warn" (the default) nothing happens
+// beyond the warnings. With "error-after:<version>" every used key whose
+// removal version is less than or equal to <version> becomes an error so
+// that configs are cleaned up before upgrading past the removal version.
+func (b *Builder) checkDeprecationPolicy(policy string) error {
+	switch {
+	case policy == "" || policy == "warn":
+		return nil
+	case strings.HasPrefix(policy, "error-after:"):
+		target, err := version.NewVersion(strings.TrimPrefix(policy, "error-after:"))
+		if err != nil {
+			return fmt.Errorf("deprecation_policy has invalid version %q: %s",
+				strings.TrimPrefix(policy, "error-after:"), err)
+		}
+		for _, key := range b.deprecatedInUse {
+			removeIn, err := version.NewVersion(deprecatedKeys[key].RemovedIn)
+			if err != nil {
+				continue
+			}
+			if removeIn.LessThanOrEqual(target) {
+				return fmt.Errorf("deprecation_policy: config key %q will be removed in Consul %s and must be cleaned up before upgrading to %s",
+					key, deprecatedKeys[key].RemovedIn, target)
+			}
+		}
+		return nil
+	default:
+		return fmt.Errorf("deprecation_policy must be 'warn' or 'error-after:<version>', got: %q", policy)
+	}
+}
@@ -0,0 +1,54 @@
+package config
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+)
+
+// fingerprint computes a stable hash over the merged, pre-validation
+// Config. Keys that may hold secret material are dropped before hashing so
+// that agents differing only in credentials fingerprint identically and
+// the hash leaks nothing about their values. The config is rendered
+// through JSON, which sorts map keys and therefore yields the same digest
+// for the same configuration on every run.
+func fingerprint(c Config) (string, error) {
+	b, err := json.Marshal(c)
+	if err != nil {
+		return "", err
+	}
+	var m map[string]interface{}
+	if err := json.Unmarshal(b, &m); err != nil {
+		return "", err
+	}
+	scrubSecrets(m)
+	b, err = json.Marshal(m)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// scrubSecrets removes every key that isSecret considers sensitive,
+// recursing into nested objects and arrays.
+func scrubSecrets(m map[string]interface{}) {
+	for k, v := range m {
+		if isSecret(k) {
+			delete(m, k)
+			continue
+		}
+		scrubVal(v)
+	}
+}
+
+func scrubVal(v interface{}) {
+	switch x := v.(type) {
+	case map[string]interface{}:
+		scrubSecrets(x)
+	case []interface{}:
+		for _, e := range x {
+			scrubVal(e)
+		}
+	}
+}
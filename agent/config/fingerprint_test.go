@@ -0,0 +1,39 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func buildWithSource(t *testing.T, data string) RuntimeConfig {
+	t.Helper()
+	b, err := NewBuilder(BuilderOpts{})
+	require.NoError(t, err)
+	b.Sources = append(b.Sources, FileSource{
+		Name:   "fingerprint.hcl",
+		Format: "hcl",
+		Data:   data,
+	})
+	rt, err := b.Build()
+	require.NoError(t, err)
+	return rt
+}
+
+func TestFingerprint(t *testing.T) {
+	a := buildWithSource(t, `node_name = "a"`)
+	require.Len(t, a.Hash, 64)
+
+	// the same configuration fingerprints identically across runs
+	require.Equal(t, a.Hash, buildWithSource(t, `node_name = "a"`).Hash)
+
+	// a non-secret change alters the fingerprint
+	require.NotEqual(t, a.Hash, buildWithSource(t, `node_name = "b"`).Hash)
+
+	// secret material is excluded from the hash
+	withToken := buildWithSource(t, `
+		node_name = "a"
+		acl { tokens { agent = "d94009d6-46ac-4674-932e-661f3d3298b3" } }
+	`)
+	require.Equal(t, a.Hash, withToken.Hash)
+}
@@ -3,7 +3,9 @@ package config
 import (
 	"flag"
 	"fmt"
+	"io"
 	"net"
+	"os"
 	"time"
 )
 
@@ -13,6 +15,10 @@ type BuilderOpts struct {
 	// in a config file.
 	Config Config
 
+	// ConfigDecryptKey is the path to a key file used to decrypt config
+	// files with an .hcl.age or .json.age extension.
+	ConfigDecryptKey string
+
 	// ConfigFiles contains the list of config files and directories
 	// that should be read.
 	ConfigFiles []string
@@ -28,16 +34,42 @@ type BuilderOpts struct {
 	// HCL contains an arbitrary config in hcl format.
 	HCL []string
 
+	// JSON contains an arbitrary config in json format.
+	JSON []string
+
+	// Profile selects a tuning preset that is merged in the head after the
+	// default configuration. This cannot be set from a config file.
+	Profile string
+
+	// VersionMetadata overrides the compiled version parameters so that
+	// embedding distributions can tag agents with their own build or channel
+	// info which is surfaced through the agent/self endpoint. This cannot be
+	// set from a config file.
+	VersionMetadata *VersionMetadata
+
 	// hostname is a shim for testing, allowing tests to specify a replacement
 	// for os.Hostname.
 	hostname func() (string, error)
 
+	// stdin is a shim for testing, allowing tests to specify a replacement
+	// for os.Stdin when '-config-file=-' is used.
+	stdin io.Reader
+
 	// getPrivateIPv4 and getPublicIPv6 are shims for testing, allowing tests to
 	// specify a replacement for ipaddr.GetPrivateIPv4 and ipaddr.GetPublicIPv6.
 	getPrivateIPv4 func() ([]*net.IPAddr, error)
 	getPublicIPv6  func() ([]*net.IPAddr, error)
 }
 
+// stdinInput returns the reader used when '-config-file=-' is specified,
+// which is os.Stdin unless a test has replaced it.
+func (f *BuilderOpts) stdinInput() io.Reader {
+	if f.stdin != nil {
+		return f.stdin
+	}
+	return os.Stdin
+}
+
 // AddFlags adds the command line flags for the agent.
 func AddFlags(fs *flag.FlagSet, f *BuilderOpts) {
 	add := func(p interface{}, name, help string) {
@@ -68,9 +100,14 @@ func AddFlags(fs *flag.FlagSet, f *BuilderOpts) {
 	add(&f.Config.BootstrapExpect, "bootstrap-expect", "Sets server to expect bootstrap mode.")
 	add(&f.Config.ClientAddr, "client", "Sets the address to bind for client access. This includes RPC, DNS, HTTP, HTTPS and gRPC (if configured).")
 	add(&f.Config.CheckOutputMaxSize, "check_output_max_size", "Sets the maximum output size for checks on this agent")
+	fs.StringVar(&f.ConfigDecryptKey, "config-decrypt-key", "", "Path to a file with a base64 encoded 256-bit key used to decrypt config files with an '.hcl.age' or '.json.age' extension.")
 	add(&f.ConfigFiles, "config-dir", "Path to a directory to read configuration files from. This will read every file ending in '.json' as configuration in this directory in alphabetical order. Can be specified multiple times.")
-	add(&f.ConfigFiles, "config-file", "Path to a file in JSON or HCL format with a matching file extension. Can be specified multiple times.")
+	add(&f.Config.ConfigDirFollowSymlink, "config-dir-follow-symlink", "Re-resolves symlinked config paths every time the config is loaded or reloaded so a new generation directory can be activated atomically by swapping the symlink.")
+	add(&f.ConfigFiles, "config-file", "Path to a file in JSON or HCL format with a matching file extension. Append ':hcl' or ':json' to force the format for that file. Use '-' to read a single config document from stdin, which requires -config-format. Can be specified multiple times.")
 	fs.StringVar(&f.ConfigFormat, "config-format", "", "Config files are in this format irrespective of their extension. Must be 'hcl' or 'json'")
+	add(&f.Config.ConfigVerification.PublicKeyFile, "config-verify-key-file", "Path to a file with a base64 encoded ed25519 public key used to verify a detached signature over the config files before they are applied.")
+	add(&f.Config.ConfigVerification.Required, "config-verify-required", "Refuses to start when -config-verify-key-file is set and the signature file does not exist.")
+	add(&f.Config.ConfigVerification.SignatureFile, "config-verify-signature-file", "Path to a file with a base64 encoded detached ed25519 signature over the config file contents.")
 	add(&f.Config.DataDir, "data-dir", "Path to a data directory to store agent state.")
 	add(&f.Config.Datacenter, "datacenter", "Datacenter of the agent.")
 	add(&f.Config.DefaultQueryTime, "default-query-time", "the amount of time a blocking query will wait before Consul will force a response. This value can be overridden by the 'wait' query parameter.")
@@ -102,6 +139,7 @@ func AddFlags(fs *flag.FlagSet, f *BuilderOpts) {
 	add(&f.Config.ReadReplica, "non-voting-server", "(Enterprise-only) DEPRECATED: -read-replica should be used instead")
 	add(&f.Config.ReadReplica, "read-replica", "(Enterprise-only) This flag is used to make the server not participate in the Raft quorum, and have it only receive the data replication stream. This can be used to add read scalability to a cluster in cases where a high volume of reads to servers are needed.")
 	add(&f.Config.PidFile, "pid-file", "Path to file to store agent PID.")
+	fs.StringVar(&f.Profile, "profile", "", "Tuning preset to apply. Must be one of 'dev-connect', 'server-large' or 'server-small'. Values from config files and other flags override the preset.")
 	add(&f.Config.RPCProtocol, "protocol", "Sets the protocol version. Defaults to latest.")
 	add(&f.Config.RaftProtocol, "raft-protocol", "Sets the Raft protocol version. Defaults to latest.")
 	add(&f.Config.DNSRecursors, "recursor", "Address of an upstream DNS server. Can be specified multiple times.")
@@ -121,9 +159,11 @@ func AddFlags(fs *flag.FlagSet, f *BuilderOpts) {
 	add(&f.Config.SerfBindAddrWAN, "serf-wan-bind", "Address to bind Serf WAN listeners to.")
 	add(&f.Config.Ports.SerfWAN, "serf-wan-port", "Sets the Serf WAN port to listen on.")
 	add(&f.Config.ServerMode, "server", "Switches agent to server mode.")
+	add(&f.Config.StrictMode, "strict", "Treats config warnings such as deprecated keys as errors.")
 	add(&f.Config.EnableSyslog, "syslog", "Enables logging to syslog.")
 	add(&f.Config.UIConfig.Enabled, "ui", "Enables the built-in static web UI server.")
 	add(&f.Config.UIConfig.ContentPath, "ui-content-path", "Sets the external UI path to a string. Defaults to: /ui/ ")
 	add(&f.Config.UIConfig.Dir, "ui-dir", "Path to directory containing the web UI resources.")
 	add(&f.HCL, "hcl", "hcl config fragment. Can be specified multiple times.")
+	add(&f.JSON, "json", "json config fragment. Can be specified multiple times.")
 }
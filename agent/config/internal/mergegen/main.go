@@ -0,0 +1,121 @@
+// mergegen generates typed merge functions for the config.Config struct
+// and every struct type reachable from it. The generated code replaces a
+// reflect-based merge that showed up in startup profiles and reported
+// unsupported field types only when a config was actually merged. With
+// the generator any field type that cannot be merged fails the build of
+// the generated file and names the offending field.
+//
+// Run via go:generate from the config package:
+//
+//	go run ./internal/mergegen -o merge_gen.go
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/format"
+	"io/ioutil"
+	"log"
+	"reflect"
+	"strings"
+
+	"github.com/hashicorp/consul/agent/config"
+)
+
+const header = `// Code generated by internal/mergegen. DO NOT EDIT.
+
+// The functions below merge two config file structures field by field
+// according to the rules documented on Merge: pointers are overridden,
+// slices are appended, maps are overlaid and structs are merged
+// recursively. Regenerate with 'go generate ./agent/config' after adding
+// fields to Config or any struct reachable from it.
+
+package config
+`
+
+func main() {
+	out := flag.String("o", "merge_gen.go", "output file")
+	flag.Parse()
+
+	g := &generator{done: map[reflect.Type]bool{}}
+	g.walk(reflect.TypeOf(config.Config{}))
+
+	var buf bytes.Buffer
+	buf.WriteString(header)
+	for _, f := range g.funcs {
+		buf.WriteString("\n")
+		buf.WriteString(f)
+	}
+
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		log.Fatalf("formatting generated code: %s", err)
+	}
+	if err := ioutil.WriteFile(*out, src, 0644); err != nil {
+		log.Fatalf("writing %s: %s", *out, err)
+	}
+}
+
+type generator struct {
+	done  map[reflect.Type]bool
+	funcs []string
+}
+
+// walk generates the merge function for the struct type t and recurses
+// into struct typed fields. The functions are appended in the order the
+// types are first seen so the output is deterministic.
+func (g *generator) walk(t reflect.Type) {
+	if g.done[t] {
+		return
+	}
+	g.done[t] = true
+
+	if t.PkgPath() != reflect.TypeOf(config.Config{}).PkgPath() {
+		log.Fatalf("cannot generate merge code for %s: type is not declared in the config package", t)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "func merge%s(a, b %s) %s {\n", t.Name(), t.Name(), t.Name())
+	g.fields(&b, t, "")
+	b.WriteString("return a\n}\n")
+	g.funcs = append(g.funcs, b.String())
+}
+
+// fields emits the merge statements for every field of the struct type
+// t. Fields of anonymous struct types cannot be passed to a named merge
+// function and are merged inline through the dotted prefix instead.
+func (g *generator) fields(b *strings.Builder, t reflect.Type, prefix string) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name := prefix + f.Name
+		switch f.Type.Kind() {
+		case reflect.Ptr:
+			fmt.Fprintf(b, "if b.%s != nil {\na.%s = b.%s\n}\n", name, name, name)
+
+		case reflect.Slice:
+			fmt.Fprintf(b, "a.%s = append(a.%s, b.%s...)\n", name, name, name)
+
+		case reflect.Map:
+			fmt.Fprintf(b, "switch {\ncase a.%s == nil:\na.%s = b.%s\ncase b.%s == nil:\ndefault:\nm := make(%s, len(a.%s)+len(b.%s))\nfor k, v := range a.%s {\nm[k] = v\n}\nfor k, v := range b.%s {\nm[k] = v\n}\na.%s = m\n}\n",
+				name, name, name, name, localType(f.Type), name, name, name, name, name)
+
+		case reflect.Struct:
+			if f.Type.Name() == "" {
+				g.fields(b, f.Type, name+".")
+				continue
+			}
+			g.walk(f.Type)
+			fmt.Fprintf(b, "a.%s = merge%s(a.%s, b.%s)\n", name, f.Type.Name(), name, name)
+
+		default:
+			log.Fatalf("config.%s.%s: unsupported type %s: only pointer, slice, map and struct fields can be merged", t.Name(), name, f.Type)
+		}
+	}
+}
+
+// localType renders t as it is written inside the config package, i.e.
+// without the package qualifier for types declared there.
+func localType(t reflect.Type) string {
+	return strings.ReplaceAll(t.String(), "config.", "")
+}
@@ -0,0 +1,9 @@
+// +build !linux
+
+package config
+
+// cgroupMemoryLimit returns 0 on platforms without cgroups so that
+// runtime.memory_limit is not checked against a container limit.
+func cgroupMemoryLimit() int64 {
+	return 0
+}
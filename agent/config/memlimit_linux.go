@@ -0,0 +1,43 @@
+// +build linux
+
+package config
+
+import (
+	"io/ioutil"
+	"strconv"
+	"strings"
+)
+
+// cgroupMemoryLimitPaths are the files holding the memory limit of the
+// cgroup the process runs in, for cgroup v2 and v1 respectively. They are
+// variables so tests can point them at fixtures.
+var cgroupMemoryLimitPaths = []string{
+	"/sys/fs/cgroup/memory.max",
+	"/sys/fs/cgroup/memory/memory.limit_in_bytes",
+}
+
+// cgroupMemoryLimit returns the memory limit in bytes of the cgroup the
+// process runs in, or 0 when there is no limit or it cannot be determined.
+func cgroupMemoryLimit() int64 {
+	for _, path := range cgroupMemoryLimitPaths {
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		s := strings.TrimSpace(string(data))
+		if s == "max" {
+			return 0
+		}
+		n, err := strconv.ParseInt(s, 10, 64)
+		if err != nil || n <= 0 {
+			continue
+		}
+		// cgroup v1 reports a page-aligned maximum value instead of an
+		// error when no limit is configured
+		if n >= int64(1)<<60 {
+			return 0
+		}
+		return n
+	}
+	return 0
+}
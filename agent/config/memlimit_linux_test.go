@@ -0,0 +1,90 @@
+// +build linux
+
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCgroupMemoryLimit(t *testing.T) {
+	dir, err := ioutil.TempDir("", t.Name())
+	require.NoError(t, err)
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		require.NoError(t, ioutil.WriteFile(path, []byte(content), 0644))
+		return path
+	}
+
+	restore := cgroupMemoryLimitPaths
+	t.Cleanup(func() { cgroupMemoryLimitPaths = restore })
+
+	tests := []struct {
+		desc  string
+		paths []string
+		want  int64
+	}{
+		{"no files", []string{filepath.Join(dir, "nope")}, 0},
+		{"v2 no limit", []string{write("memory.max", "max\n")}, 0},
+		{"v2 limit", []string{write("memory.max.set", "1073741824\n")}, 1073741824},
+		{"v1 no limit", []string{write("memory.limit_in_bytes", "9223372036854771712\n")}, 0},
+		{"v1 limit", []string{write("memory.limit_in_bytes.set", "536870912\n")}, 536870912},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			cgroupMemoryLimitPaths = tt.paths
+			require.Equal(t, tt.want, cgroupMemoryLimit())
+		})
+	}
+}
+
+func TestValidate_MemoryLimitAgainstCgroup(t *testing.T) {
+	dir, err := ioutil.TempDir("", t.Name())
+	require.NoError(t, err)
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	limitFile := filepath.Join(dir, "memory.max")
+	require.NoError(t, ioutil.WriteFile(limitFile, []byte("1073741824\n"), 0644))
+
+	restore := cgroupMemoryLimitPaths
+	t.Cleanup(func() { cgroupMemoryLimitPaths = restore })
+	cgroupMemoryLimitPaths = []string{limitFile}
+
+	build := func(t *testing.T, memoryLimit string) (*Builder, error) {
+		b, err := NewBuilder(BuilderOpts{})
+		require.NoError(t, err)
+		b.Sources = append(b.Sources, FileSource{
+			Name:   "memlimit.hcl",
+			Format: "hcl",
+			Data:   `data_dir = "` + dir + `" runtime { memory_limit = ` + memoryLimit + ` }`,
+		})
+		_, err = b.BuildAndValidate()
+		return b, err
+	}
+
+	t.Run("exceeds cgroup limit", func(t *testing.T) {
+		_, err := build(t, "2147483648")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "runtime.memory_limit (2147483648) exceeds the cgroup memory limit (1073741824)")
+	})
+
+	t.Run("within 10% of cgroup limit", func(t *testing.T) {
+		b, err := build(t, "1000000000")
+		require.NoError(t, err)
+		require.Contains(t, strings.Join(b.WarningStrings(), "\n"),
+			"runtime.memory_limit 1000000000 is within 10% of the cgroup memory limit 1073741824")
+	})
+
+	t.Run("enough headroom", func(t *testing.T) {
+		b, err := build(t, "536870912")
+		require.NoError(t, err)
+		require.NotContains(t, strings.Join(b.WarningStrings(), "\n"), "cgroup memory limit")
+	})
+}
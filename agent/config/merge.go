@@ -3,71 +3,83 @@ package config
 import (
 	"fmt"
 	"reflect"
+	"strings"
 )
 
-// Merge recursively combines a set of config file structures into a single structure
+//go:generate go run ./internal/mergegen -o merge_gen.go
+
+// Merge combines a set of config file structures into a single structure
 // according to the following rules:
 //
-// * only values of type struct, slice, map and pointer to simple types are allowed. Other types panic.
+// * only values of type struct, slice, map and pointer to simple types are allowed. Other types fail the generator.
 // * when merging two structs the result is the recursive merge of all fields according to the rules below
 // * when merging two slices the result is the second slice appended to the first
 // * when merging two maps the result is the second map overlaid on the first
 // * when merging two pointer values the result is the second value if it is not nil, otherwise the first
+//
+// The field by field merge code lives in merge_gen.go and is generated
+// from the Config struct. It must be regenerated whenever a field is
+// added to Config or to one of the struct types it contains.
 func Merge(files ...Config) Config {
 	var a Config
 	for _, b := range files {
-		a = merge(a, b).(Config)
+		a = mergeConfig(a, b)
 	}
 	return a
 }
 
-func merge(a, b interface{}) interface{} {
-	return mergeValue(reflect.ValueOf(a), reflect.ValueOf(b)).Interface()
-}
-
-func mergeValue(a, b reflect.Value) reflect.Value {
-	switch a.Kind() {
-	case reflect.Map:
-		// dont bother allocating a new map to aggregate keys in when either one
-		// or both of the maps to merge is the zero value - nil
-		if a.IsZero() {
-			return b
-		} else if b.IsZero() {
-			return a
+// applyReplaceStrategy applies the merge_strategy stanza of the next config
+// source before it is merged: every list key named in merge_strategy.replace
+// has its previously merged value cleared so that the subsequent Merge keeps
+// only the values from the new source instead of appending to the old ones.
+func applyReplaceStrategy(acc *Config, next Config) error {
+	for _, key := range next.MergeStrategy.Replace {
+		f, err := sliceFieldByKey(reflect.ValueOf(acc).Elem(), key)
+		if err != nil {
+			return err
+		}
+		if f.IsValid() {
+			f.Set(reflect.Zero(f.Type()))
 		}
+	}
+	return nil
+}
 
-		r := reflect.MakeMap(a.Type())
-		for _, k := range a.MapKeys() {
-			v := a.MapIndex(k)
-			r.SetMapIndex(k, v)
+// sliceFieldByKey resolves a dotted config key like "telemetry.prefix_filter"
+// to the slice field it names, using the hcl struct tags. It returns an
+// invalid value without error when an intermediate block is nil since there
+// is nothing to clear in that case.
+func sliceFieldByKey(v reflect.Value, key string) (reflect.Value, error) {
+	parts := strings.Split(key, ".")
+	for i, part := range parts {
+		if v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				return reflect.Value{}, nil
+			}
+			v = v.Elem()
 		}
-		for _, k := range b.MapKeys() {
-			v := b.MapIndex(k)
-			r.SetMapIndex(k, v)
+		if v.Kind() != reflect.Struct {
+			return reflect.Value{}, fmt.Errorf("merge_strategy.replace: unknown config key %q", key)
 		}
-		return r
-
-	case reflect.Ptr:
-		if !b.IsNil() {
-			return b
+		f, ok := fieldByHCLTag(v, part)
+		if !ok {
+			return reflect.Value{}, fmt.Errorf("merge_strategy.replace: unknown config key %q", key)
 		}
-		return a
-
-	case reflect.Slice:
-		if !a.IsValid() {
-			a = reflect.Zero(a.Type())
+		v = f
+		if i == len(parts)-1 && v.Kind() != reflect.Slice {
+			return reflect.Value{}, fmt.Errorf("merge_strategy.replace: config key %q is not a list", key)
 		}
-		return reflect.AppendSlice(a, b)
+	}
+	return v, nil
+}
 
-	case reflect.Struct:
-		r := reflect.New(a.Type()) // &struct{}
-		for i := 0; i < a.NumField(); i++ {
-			v := mergeValue(a.Field(i), b.Field(i))
-			r.Elem().Field(i).Set(v)
+func fieldByHCLTag(v reflect.Value, name string) (reflect.Value, bool) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		tag := strings.SplitN(t.Field(i).Tag.Get("hcl"), ",", 2)[0]
+		if tag == name {
+			return v.Field(i), true
 		}
-		return r.Elem() // *struct
-
-	default:
-		panic(fmt.Sprintf("unsupported element type: %v", a.Type()))
 	}
+	return reflect.Value{}, false
 }
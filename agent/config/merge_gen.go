@@ -0,0 +1,1488 @@
+// Code generated by internal/mergegen. DO NOT EDIT.
+
+// The functions below merge two config file structures field by field
+// according to the rules documented on Merge: pointers are overridden,
+// slices are appended, maps are overlaid and structs are merged
+// recursively. Regenerate with 'go generate ./agent/config' after adding
+// fields to Config or any struct reachable from it.
+
+package config
+
+func mergeTokens(a, b Tokens) Tokens {
+	if b.Master != nil {
+		a.Master = b.Master
+	}
+	if b.Replication != nil {
+		a.Replication = b.Replication
+	}
+	if b.AgentMaster != nil {
+		a.AgentMaster = b.AgentMaster
+	}
+	if b.Default != nil {
+		a.Default = b.Default
+	}
+	if b.Agent != nil {
+		a.Agent = b.Agent
+	}
+	a.ManagedServiceProvider = append(a.ManagedServiceProvider, b.ManagedServiceProvider...)
+	return a
+}
+
+func mergeACL(a, b ACL) ACL {
+	if b.Enabled != nil {
+		a.Enabled = b.Enabled
+	}
+	if b.TokenReplication != nil {
+		a.TokenReplication = b.TokenReplication
+	}
+	if b.PolicyTTL != nil {
+		a.PolicyTTL = b.PolicyTTL
+	}
+	if b.RoleTTL != nil {
+		a.RoleTTL = b.RoleTTL
+	}
+	if b.TokenTTL != nil {
+		a.TokenTTL = b.TokenTTL
+	}
+	if b.DownPolicy != nil {
+		a.DownPolicy = b.DownPolicy
+	}
+	if b.DefaultPolicy != nil {
+		a.DefaultPolicy = b.DefaultPolicy
+	}
+	if b.EnableKeyListPolicy != nil {
+		a.EnableKeyListPolicy = b.EnableKeyListPolicy
+	}
+	a.Tokens = mergeTokens(a.Tokens, b.Tokens)
+	if b.DisabledTTL != nil {
+		a.DisabledTTL = b.DisabledTTL
+	}
+	if b.EnableTokenPersistence != nil {
+		a.EnableTokenPersistence = b.EnableTokenPersistence
+	}
+	if b.MSPDisableBootstrap != nil {
+		a.MSPDisableBootstrap = b.MSPDisableBootstrap
+	}
+	return a
+}
+
+func mergeAddresses(a, b Addresses) Addresses {
+	if b.DNS != nil {
+		a.DNS = b.DNS
+	}
+	if b.HTTP != nil {
+		a.HTTP = b.HTTP
+	}
+	if b.HTTPS != nil {
+		a.HTTPS = b.HTTPS
+	}
+	if b.GRPC != nil {
+		a.GRPC = b.GRPC
+	}
+	if b.GRPCTLS != nil {
+		a.GRPCTLS = b.GRPCTLS
+	}
+	return a
+}
+
+func mergeAutoConfigAuthorizerRaw(a, b AutoConfigAuthorizerRaw) AutoConfigAuthorizerRaw {
+	a.ClaimAssertions = append(a.ClaimAssertions, b.ClaimAssertions...)
+	if b.AllowReuse != nil {
+		a.AllowReuse = b.AllowReuse
+	}
+	a.JWTSupportedAlgs = append(a.JWTSupportedAlgs, b.JWTSupportedAlgs...)
+	a.BoundAudiences = append(a.BoundAudiences, b.BoundAudiences...)
+	switch {
+	case a.ClaimMappings == nil:
+		a.ClaimMappings = b.ClaimMappings
+	case b.ClaimMappings == nil:
+	default:
+		m := make(map[string]string, len(a.ClaimMappings)+len(b.ClaimMappings))
+		for k, v := range a.ClaimMappings {
+			m[k] = v
+		}
+		for k, v := range b.ClaimMappings {
+			m[k] = v
+		}
+		a.ClaimMappings = m
+	}
+	switch {
+	case a.ListClaimMappings == nil:
+		a.ListClaimMappings = b.ListClaimMappings
+	case b.ListClaimMappings == nil:
+	default:
+		m := make(map[string]string, len(a.ListClaimMappings)+len(b.ListClaimMappings))
+		for k, v := range a.ListClaimMappings {
+			m[k] = v
+		}
+		for k, v := range b.ListClaimMappings {
+			m[k] = v
+		}
+		a.ListClaimMappings = m
+	}
+	if b.OIDCDiscoveryURL != nil {
+		a.OIDCDiscoveryURL = b.OIDCDiscoveryURL
+	}
+	if b.OIDCDiscoveryCACert != nil {
+		a.OIDCDiscoveryCACert = b.OIDCDiscoveryCACert
+	}
+	if b.JWKSURL != nil {
+		a.JWKSURL = b.JWKSURL
+	}
+	if b.JWKSCACert != nil {
+		a.JWKSCACert = b.JWKSCACert
+	}
+	a.JWTValidationPubKeys = append(a.JWTValidationPubKeys, b.JWTValidationPubKeys...)
+	if b.BoundIssuer != nil {
+		a.BoundIssuer = b.BoundIssuer
+	}
+	if b.ExpirationLeeway != nil {
+		a.ExpirationLeeway = b.ExpirationLeeway
+	}
+	if b.NotBeforeLeeway != nil {
+		a.NotBeforeLeeway = b.NotBeforeLeeway
+	}
+	if b.ClockSkewLeeway != nil {
+		a.ClockSkewLeeway = b.ClockSkewLeeway
+	}
+	return a
+}
+
+func mergeAutoConfigAuthorizationRaw(a, b AutoConfigAuthorizationRaw) AutoConfigAuthorizationRaw {
+	if b.Enabled != nil {
+		a.Enabled = b.Enabled
+	}
+	a.Static = mergeAutoConfigAuthorizerRaw(a.Static, b.Static)
+	return a
+}
+
+func mergeAutoConfigRaw(a, b AutoConfigRaw) AutoConfigRaw {
+	if b.Enabled != nil {
+		a.Enabled = b.Enabled
+	}
+	if b.IntroToken != nil {
+		a.IntroToken = b.IntroToken
+	}
+	if b.IntroTokenFile != nil {
+		a.IntroTokenFile = b.IntroTokenFile
+	}
+	a.ServerAddresses = append(a.ServerAddresses, b.ServerAddresses...)
+	a.DNSSANs = append(a.DNSSANs, b.DNSSANs...)
+	a.IPSANs = append(a.IPSANs, b.IPSANs...)
+	a.Authorization = mergeAutoConfigAuthorizationRaw(a.Authorization, b.Authorization)
+	return a
+}
+
+func mergeAutopilot(a, b Autopilot) Autopilot {
+	if b.CleanupDeadServers != nil {
+		a.CleanupDeadServers = b.CleanupDeadServers
+	}
+	if b.LastContactThreshold != nil {
+		a.LastContactThreshold = b.LastContactThreshold
+	}
+	if b.MaxTrailingLogs != nil {
+		a.MaxTrailingLogs = b.MaxTrailingLogs
+	}
+	if b.MinQuorum != nil {
+		a.MinQuorum = b.MinQuorum
+	}
+	if b.ServerStabilizationTime != nil {
+		a.ServerStabilizationTime = b.ServerStabilizationTime
+	}
+	if b.DisableUpgradeMigration != nil {
+		a.DisableUpgradeMigration = b.DisableUpgradeMigration
+	}
+	if b.RedundancyZoneTag != nil {
+		a.RedundancyZoneTag = b.RedundancyZoneTag
+	}
+	if b.UpgradeVersionTag != nil {
+		a.UpgradeVersionTag = b.UpgradeVersionTag
+	}
+	return a
+}
+
+func mergeCache(a, b Cache) Cache {
+	if b.EntryFetchMaxBurst != nil {
+		a.EntryFetchMaxBurst = b.EntryFetchMaxBurst
+	}
+	if b.EntryFetchRate != nil {
+		a.EntryFetchRate = b.EntryFetchRate
+	}
+	return a
+}
+
+func mergeCheckExecLimits(a, b CheckExecLimits) CheckExecLimits {
+	if b.MaxConcurrent != nil {
+		a.MaxConcurrent = b.MaxConcurrent
+	}
+	if b.CPUSeconds != nil {
+		a.CPUSeconds = b.CPUSeconds
+	}
+	if b.MemoryBytes != nil {
+		a.MemoryBytes = b.MemoryBytes
+	}
+	if b.Nice != nil {
+		a.Nice = b.Nice
+	}
+	return a
+}
+
+func mergeConfigEntries(a, b ConfigEntries) ConfigEntries {
+	a.Bootstrap = append(a.Bootstrap, b.Bootstrap...)
+	return a
+}
+
+func mergeConfigVerification(a, b ConfigVerification) ConfigVerification {
+	if b.PublicKeyFile != nil {
+		a.PublicKeyFile = b.PublicKeyFile
+	}
+	if b.SignatureFile != nil {
+		a.SignatureFile = b.SignatureFile
+	}
+	if b.Required != nil {
+		a.Required = b.Required
+	}
+	return a
+}
+
+func mergeAutoEncrypt(a, b AutoEncrypt) AutoEncrypt {
+	if b.TLS != nil {
+		a.TLS = b.TLS
+	}
+	a.DNSSAN = append(a.DNSSAN, b.DNSSAN...)
+	a.IPSAN = append(a.IPSAN, b.IPSAN...)
+	if b.AllowTLS != nil {
+		a.AllowTLS = b.AllowTLS
+	}
+	return a
+}
+
+func mergeConnect(a, b Connect) Connect {
+	if b.Enabled != nil {
+		a.Enabled = b.Enabled
+	}
+	if b.CAProvider != nil {
+		a.CAProvider = b.CAProvider
+	}
+	switch {
+	case a.CAConfig == nil:
+		a.CAConfig = b.CAConfig
+	case b.CAConfig == nil:
+	default:
+		m := make(map[string]interface{}, len(a.CAConfig)+len(b.CAConfig))
+		for k, v := range a.CAConfig {
+			m[k] = v
+		}
+		for k, v := range b.CAConfig {
+			m[k] = v
+		}
+		a.CAConfig = m
+	}
+	if b.MeshGatewayWANFederationEnabled != nil {
+		a.MeshGatewayWANFederationEnabled = b.MeshGatewayWANFederationEnabled
+	}
+	if b.UpstreamDefaults != nil {
+		a.UpstreamDefaults = b.UpstreamDefaults
+	}
+	if b.TestCALeafRootChangeSpread != nil {
+		a.TestCALeafRootChangeSpread = b.TestCALeafRootChangeSpread
+	}
+	return a
+}
+
+func mergeDNSTLS(a, b DNSTLS) DNSTLS {
+	if b.CertFile != nil {
+		a.CertFile = b.CertFile
+	}
+	if b.KeyFile != nil {
+		a.KeyFile = b.KeyFile
+	}
+	return a
+}
+
+func mergeDNS(a, b DNS) DNS {
+	if b.AllowStale != nil {
+		a.AllowStale = b.AllowStale
+	}
+	if b.ARecordLimit != nil {
+		a.ARecordLimit = b.ARecordLimit
+	}
+	if b.DisableCompression != nil {
+		a.DisableCompression = b.DisableCompression
+	}
+	if b.DOHPath != nil {
+		a.DOHPath = b.DOHPath
+	}
+	if b.EnableTruncate != nil {
+		a.EnableTruncate = b.EnableTruncate
+	}
+	if b.MaxStale != nil {
+		a.MaxStale = b.MaxStale
+	}
+	if b.NodeTTL != nil {
+		a.NodeTTL = b.NodeTTL
+	}
+	if b.OnlyPassing != nil {
+		a.OnlyPassing = b.OnlyPassing
+	}
+	if b.RecursorTimeout != nil {
+		a.RecursorTimeout = b.RecursorTimeout
+	}
+	switch {
+	case a.ServiceTTL == nil:
+		a.ServiceTTL = b.ServiceTTL
+	case b.ServiceTTL == nil:
+	default:
+		m := make(map[string]string, len(a.ServiceTTL)+len(b.ServiceTTL))
+		for k, v := range a.ServiceTTL {
+			m[k] = v
+		}
+		for k, v := range b.ServiceTTL {
+			m[k] = v
+		}
+		a.ServiceTTL = m
+	}
+	a.TagAllowlist = append(a.TagAllowlist, b.TagAllowlist...)
+	if b.UDPAnswerLimit != nil {
+		a.UDPAnswerLimit = b.UDPAnswerLimit
+	}
+	if b.NodeMetaTXT != nil {
+		a.NodeMetaTXT = b.NodeMetaTXT
+	}
+	a.NodeMetaTXTKeys = append(a.NodeMetaTXTKeys, b.NodeMetaTXTKeys...)
+	if b.SOA != nil {
+		a.SOA = b.SOA
+	}
+	if b.UseCache != nil {
+		a.UseCache = b.UseCache
+	}
+	if b.CacheMaxAge != nil {
+		a.CacheMaxAge = b.CacheMaxAge
+	}
+	if b.PreferNamespace != nil {
+		a.PreferNamespace = b.PreferNamespace
+	}
+	a.TLS = mergeDNSTLS(a.TLS, b.TLS)
+	return a
+}
+
+func mergeDiscoveryMaxResults(a, b DiscoveryMaxResults) DiscoveryMaxResults {
+	if b.DNS != nil {
+		a.DNS = b.DNS
+	}
+	if b.HTTP != nil {
+		a.HTTP = b.HTTP
+	}
+	return a
+}
+
+func mergeDockerEndpoint(a, b DockerEndpoint) DockerEndpoint {
+	if b.Host != nil {
+		a.Host = b.Host
+	}
+	if b.CAFile != nil {
+		a.CAFile = b.CAFile
+	}
+	if b.CertFile != nil {
+		a.CertFile = b.CertFile
+	}
+	if b.KeyFile != nil {
+		a.KeyFile = b.KeyFile
+	}
+	if b.TLSSkipVerify != nil {
+		a.TLSSkipVerify = b.TLSSkipVerify
+	}
+	return a
+}
+
+func mergeGossipLANConfig(a, b GossipLANConfig) GossipLANConfig {
+	if b.GossipNodes != nil {
+		a.GossipNodes = b.GossipNodes
+	}
+	if b.GossipInterval != nil {
+		a.GossipInterval = b.GossipInterval
+	}
+	if b.ProbeInterval != nil {
+		a.ProbeInterval = b.ProbeInterval
+	}
+	if b.ProbeTimeout != nil {
+		a.ProbeTimeout = b.ProbeTimeout
+	}
+	if b.SuspicionMult != nil {
+		a.SuspicionMult = b.SuspicionMult
+	}
+	if b.RetransmitMult != nil {
+		a.RetransmitMult = b.RetransmitMult
+	}
+	return a
+}
+
+func mergeGossipWANConfig(a, b GossipWANConfig) GossipWANConfig {
+	if b.GossipNodes != nil {
+		a.GossipNodes = b.GossipNodes
+	}
+	if b.GossipInterval != nil {
+		a.GossipInterval = b.GossipInterval
+	}
+	if b.ProbeInterval != nil {
+		a.ProbeInterval = b.ProbeInterval
+	}
+	if b.ProbeTimeout != nil {
+		a.ProbeTimeout = b.ProbeTimeout
+	}
+	if b.SuspicionMult != nil {
+		a.SuspicionMult = b.SuspicionMult
+	}
+	if b.RetransmitMult != nil {
+		a.RetransmitMult = b.RetransmitMult
+	}
+	return a
+}
+
+func mergeGRPCConfig(a, b GRPCConfig) GRPCConfig {
+	if b.EnableConfigService != nil {
+		a.EnableConfigService = b.EnableConfigService
+	}
+	return a
+}
+
+func mergeProxyProtocol(a, b ProxyProtocol) ProxyProtocol {
+	if b.Enabled != nil {
+		a.Enabled = b.Enabled
+	}
+	a.AllowedSources = append(a.AllowedSources, b.AllowedSources...)
+	return a
+}
+
+func mergeHTTPConfig(a, b HTTPConfig) HTTPConfig {
+	a.BlockEndpoints = append(a.BlockEndpoints, b.BlockEndpoints...)
+	a.BlockEndpointRules = append(a.BlockEndpointRules, b.BlockEndpointRules...)
+	a.DisableLegacyEndpoints = append(a.DisableLegacyEndpoints, b.DisableLegacyEndpoints...)
+	a.AllowWriteHTTPFrom = append(a.AllowWriteHTTPFrom, b.AllowWriteHTTPFrom...)
+	if b.RequestIDHeader != nil {
+		a.RequestIDHeader = b.RequestIDHeader
+	}
+	switch {
+	case a.ResponseHeaders == nil:
+		a.ResponseHeaders = b.ResponseHeaders
+	case b.ResponseHeaders == nil:
+	default:
+		m := make(map[string]string, len(a.ResponseHeaders)+len(b.ResponseHeaders))
+		for k, v := range a.ResponseHeaders {
+			m[k] = v
+		}
+		for k, v := range b.ResponseHeaders {
+			m[k] = v
+		}
+		a.ResponseHeaders = m
+	}
+	if b.UseCache != nil {
+		a.UseCache = b.UseCache
+	}
+	if b.MaxHeaderBytes != nil {
+		a.MaxHeaderBytes = b.MaxHeaderBytes
+	}
+	a.ProxyProtocol = mergeProxyProtocol(a.ProxyProtocol, b.ProxyProtocol)
+	return a
+}
+
+func mergeLimits(a, b Limits) Limits {
+	if b.HTTPMaxConnsPerClient != nil {
+		a.HTTPMaxConnsPerClient = b.HTTPMaxConnsPerClient
+	}
+	if b.HTTPSHandshakeTimeout != nil {
+		a.HTTPSHandshakeTimeout = b.HTTPSHandshakeTimeout
+	}
+	if b.RPCHandshakeTimeout != nil {
+		a.RPCHandshakeTimeout = b.RPCHandshakeTimeout
+	}
+	if b.RPCMaxBurst != nil {
+		a.RPCMaxBurst = b.RPCMaxBurst
+	}
+	if b.RPCMaxConnsPerClient != nil {
+		a.RPCMaxConnsPerClient = b.RPCMaxConnsPerClient
+	}
+	if b.RPCRate != nil {
+		a.RPCRate = b.RPCRate
+	}
+	if b.KVMaxValueSize != nil {
+		a.KVMaxValueSize = b.KVMaxValueSize
+	}
+	a.KVQuota = append(a.KVQuota, b.KVQuota...)
+	a.ReservedPorts = append(a.ReservedPorts, b.ReservedPorts...)
+	if b.TxnMaxReqLen != nil {
+		a.TxnMaxReqLen = b.TxnMaxReqLen
+	}
+	return a
+}
+
+func mergeListenerOptionsBlock(a, b ListenerOptionsBlock) ListenerOptionsBlock {
+	if b.SOReusePort != nil {
+		a.SOReusePort = b.SOReusePort
+	}
+	if b.TCPKeepaliveInterval != nil {
+		a.TCPKeepaliveInterval = b.TCPKeepaliveInterval
+	}
+	if b.Backlog != nil {
+		a.Backlog = b.Backlog
+	}
+	return a
+}
+
+func mergeListenerOptions(a, b ListenerOptions) ListenerOptions {
+	a.DNS = mergeListenerOptionsBlock(a.DNS, b.DNS)
+	a.GRPC = mergeListenerOptionsBlock(a.GRPC, b.GRPC)
+	a.HTTP = mergeListenerOptionsBlock(a.HTTP, b.HTTP)
+	a.HTTPS = mergeListenerOptionsBlock(a.HTTPS, b.HTTPS)
+	return a
+}
+
+func mergeLogging(a, b Logging) Logging {
+	if b.SlowQueryThreshold != nil {
+		a.SlowQueryThreshold = b.SlowQueryThreshold
+	}
+	return a
+}
+
+func mergeMaintenanceSchedule(a, b MaintenanceSchedule) MaintenanceSchedule {
+	if b.Cron != nil {
+		a.Cron = b.Cron
+	}
+	if b.Duration != nil {
+		a.Duration = b.Duration
+	}
+	if b.Reason != nil {
+		a.Reason = b.Reason
+	}
+	return a
+}
+
+func mergeMDNSAdvertise(a, b MDNSAdvertise) MDNSAdvertise {
+	if b.Enabled != nil {
+		a.Enabled = b.Enabled
+	}
+	if b.Service != nil {
+		a.Service = b.Service
+	}
+	if b.Interface != nil {
+		a.Interface = b.Interface
+	}
+	if b.AllowServer != nil {
+		a.AllowServer = b.AllowServer
+	}
+	return a
+}
+
+func mergeMergeStrategy(a, b MergeStrategy) MergeStrategy {
+	a.Replace = append(a.Replace, b.Replace...)
+	return a
+}
+
+func mergeOutboundProxy(a, b OutboundProxy) OutboundProxy {
+	if b.URL != nil {
+		a.URL = b.URL
+	}
+	a.NoProxy = append(a.NoProxy, b.NoProxy...)
+	if b.CredentialsFile != nil {
+		a.CredentialsFile = b.CredentialsFile
+	}
+	return a
+}
+
+func mergePerformance(a, b Performance) Performance {
+	if b.LeaveDrainTime != nil {
+		a.LeaveDrainTime = b.LeaveDrainTime
+	}
+	if b.RaftMultiplier != nil {
+		a.RaftMultiplier = b.RaftMultiplier
+	}
+	if b.RPCHoldTimeout != nil {
+		a.RPCHoldTimeout = b.RPCHoldTimeout
+	}
+	return a
+}
+
+func mergePorts(a, b Ports) Ports {
+	if b.DNS != nil {
+		a.DNS = b.DNS
+	}
+	if b.DNSTCP != nil {
+		a.DNSTCP = b.DNSTCP
+	}
+	if b.DNSUDP != nil {
+		a.DNSUDP = b.DNSUDP
+	}
+	if b.DNSTLS != nil {
+		a.DNSTLS = b.DNSTLS
+	}
+	if b.HTTP != nil {
+		a.HTTP = b.HTTP
+	}
+	if b.HTTPS != nil {
+		a.HTTPS = b.HTTPS
+	}
+	if b.SerfLAN != nil {
+		a.SerfLAN = b.SerfLAN
+	}
+	if b.SerfWAN != nil {
+		a.SerfWAN = b.SerfWAN
+	}
+	if b.Server != nil {
+		a.Server = b.Server
+	}
+	if b.GRPC != nil {
+		a.GRPC = b.GRPC
+	}
+	if b.GRPCTLS != nil {
+		a.GRPCTLS = b.GRPCTLS
+	}
+	if b.ProxyMinPort != nil {
+		a.ProxyMinPort = b.ProxyMinPort
+	}
+	if b.ProxyMaxPort != nil {
+		a.ProxyMaxPort = b.ProxyMaxPort
+	}
+	if b.SidecarMinPort != nil {
+		a.SidecarMinPort = b.SidecarMinPort
+	}
+	if b.SidecarMaxPort != nil {
+		a.SidecarMaxPort = b.SidecarMaxPort
+	}
+	if b.ExposeMinPort != nil {
+		a.ExposeMinPort = b.ExposeMinPort
+	}
+	if b.ExposeMaxPort != nil {
+		a.ExposeMaxPort = b.ExposeMaxPort
+	}
+	return a
+}
+
+func mergePrimaryDatacenterMigration(a, b PrimaryDatacenterMigration) PrimaryDatacenterMigration {
+	if b.Old != nil {
+		a.Old = b.Old
+	}
+	if b.New != nil {
+		a.New = b.New
+	}
+	if b.CutoverTime != nil {
+		a.CutoverTime = b.CutoverTime
+	}
+	return a
+}
+
+func mergeRuntime(a, b Runtime) Runtime {
+	if b.GOMAXPROCS != nil {
+		a.GOMAXPROCS = b.GOMAXPROCS
+	}
+	if b.GOGC != nil {
+		a.GOGC = b.GOGC
+	}
+	if b.MemoryLimit != nil {
+		a.MemoryLimit = b.MemoryLimit
+	}
+	return a
+}
+
+func mergeSanitizeConfig(a, b SanitizeConfig) SanitizeConfig {
+	a.RedactKeys = append(a.RedactKeys, b.RedactKeys...)
+	if b.RedactNone != nil {
+		a.RedactNone = b.RedactNone
+	}
+	return a
+}
+
+func mergeTLSProtocolConfig(a, b TLSProtocolConfig) TLSProtocolConfig {
+	if b.CAFile != nil {
+		a.CAFile = b.CAFile
+	}
+	if b.CAPath != nil {
+		a.CAPath = b.CAPath
+	}
+	if b.CertFile != nil {
+		a.CertFile = b.CertFile
+	}
+	if b.KeyFile != nil {
+		a.KeyFile = b.KeyFile
+	}
+	if b.TLSCipherSuites != nil {
+		a.TLSCipherSuites = b.TLSCipherSuites
+	}
+	if b.TLSMinVersion != nil {
+		a.TLSMinVersion = b.TLSMinVersion
+	}
+	if b.VerifyIncoming != nil {
+		a.VerifyIncoming = b.VerifyIncoming
+	}
+	if b.VerifyOutgoing != nil {
+		a.VerifyOutgoing = b.VerifyOutgoing
+	}
+	if b.VerifyServerHostname != nil {
+		a.VerifyServerHostname = b.VerifyServerHostname
+	}
+	if b.Profile != nil {
+		a.Profile = b.Profile
+	}
+	return a
+}
+
+func mergeTLS(a, b TLS) TLS {
+	a.Defaults = mergeTLSProtocolConfig(a.Defaults, b.Defaults)
+	a.HTTPS = mergeTLSProtocolConfig(a.HTTPS, b.HTTPS)
+	a.InternalRPC = mergeTLSProtocolConfig(a.InternalRPC, b.InternalRPC)
+	a.GRPC = mergeTLSProtocolConfig(a.GRPC, b.GRPC)
+	a.WAN = mergeTLSProtocolConfig(a.WAN, b.WAN)
+	switch {
+	case a.Profiles == nil:
+		a.Profiles = b.Profiles
+	case b.Profiles == nil:
+	default:
+		m := make(map[string]TLSProtocolConfig, len(a.Profiles)+len(b.Profiles))
+		for k, v := range a.Profiles {
+			m[k] = v
+		}
+		for k, v := range b.Profiles {
+			m[k] = v
+		}
+		a.Profiles = m
+	}
+	if b.ChecksProfile != nil {
+		a.ChecksProfile = b.ChecksProfile
+	}
+	if b.WatchesProfile != nil {
+		a.WatchesProfile = b.WatchesProfile
+	}
+	return a
+}
+
+func mergeTelemetry(a, b Telemetry) Telemetry {
+	if b.CirconusAPIApp != nil {
+		a.CirconusAPIApp = b.CirconusAPIApp
+	}
+	if b.CirconusAPIToken != nil {
+		a.CirconusAPIToken = b.CirconusAPIToken
+	}
+	if b.CirconusAPIURL != nil {
+		a.CirconusAPIURL = b.CirconusAPIURL
+	}
+	if b.CirconusBrokerID != nil {
+		a.CirconusBrokerID = b.CirconusBrokerID
+	}
+	if b.CirconusBrokerSelectTag != nil {
+		a.CirconusBrokerSelectTag = b.CirconusBrokerSelectTag
+	}
+	if b.CirconusCheckDisplayName != nil {
+		a.CirconusCheckDisplayName = b.CirconusCheckDisplayName
+	}
+	if b.CirconusCheckForceMetricActivation != nil {
+		a.CirconusCheckForceMetricActivation = b.CirconusCheckForceMetricActivation
+	}
+	if b.CirconusCheckID != nil {
+		a.CirconusCheckID = b.CirconusCheckID
+	}
+	if b.CirconusCheckInstanceID != nil {
+		a.CirconusCheckInstanceID = b.CirconusCheckInstanceID
+	}
+	if b.CirconusCheckSearchTag != nil {
+		a.CirconusCheckSearchTag = b.CirconusCheckSearchTag
+	}
+	if b.CirconusCheckTags != nil {
+		a.CirconusCheckTags = b.CirconusCheckTags
+	}
+	if b.CirconusSubmissionInterval != nil {
+		a.CirconusSubmissionInterval = b.CirconusSubmissionInterval
+	}
+	if b.CirconusSubmissionURL != nil {
+		a.CirconusSubmissionURL = b.CirconusSubmissionURL
+	}
+	if b.DisableCompatOneNine != nil {
+		a.DisableCompatOneNine = b.DisableCompatOneNine
+	}
+	if b.DisableHostname != nil {
+		a.DisableHostname = b.DisableHostname
+	}
+	if b.DogstatsdAddr != nil {
+		a.DogstatsdAddr = b.DogstatsdAddr
+	}
+	a.DogstatsdTags = append(a.DogstatsdTags, b.DogstatsdTags...)
+	if b.EnableDeprecatedConfigMetrics != nil {
+		a.EnableDeprecatedConfigMetrics = b.EnableDeprecatedConfigMetrics
+	}
+	if b.FilterDefault != nil {
+		a.FilterDefault = b.FilterDefault
+	}
+	a.PrefixFilter = append(a.PrefixFilter, b.PrefixFilter...)
+	if b.MetricsPrefix != nil {
+		a.MetricsPrefix = b.MetricsPrefix
+	}
+	if b.PrometheusRetentionTime != nil {
+		a.PrometheusRetentionTime = b.PrometheusRetentionTime
+	}
+	if b.StatsdAddr != nil {
+		a.StatsdAddr = b.StatsdAddr
+	}
+	if b.StatsiteAddr != nil {
+		a.StatsiteAddr = b.StatsiteAddr
+	}
+	return a
+}
+
+func mergeTracing(a, b Tracing) Tracing {
+	if b.OTLPEndpoint != nil {
+		a.OTLPEndpoint = b.OTLPEndpoint
+	}
+	if b.SamplingRatio != nil {
+		a.SamplingRatio = b.SamplingRatio
+	}
+	if b.ServiceName != nil {
+		a.ServiceName = b.ServiceName
+	}
+	return a
+}
+
+func mergeRawUIMetricsProxy(a, b RawUIMetricsProxy) RawUIMetricsProxy {
+	if b.BaseURL != nil {
+		a.BaseURL = b.BaseURL
+	}
+	a.AddHeaders = append(a.AddHeaders, b.AddHeaders...)
+	a.PathAllowlist = append(a.PathAllowlist, b.PathAllowlist...)
+	return a
+}
+
+func mergeRawUIConfig(a, b RawUIConfig) RawUIConfig {
+	if b.Enabled != nil {
+		a.Enabled = b.Enabled
+	}
+	if b.Dir != nil {
+		a.Dir = b.Dir
+	}
+	if b.ContentPath != nil {
+		a.ContentPath = b.ContentPath
+	}
+	if b.MetricsProvider != nil {
+		a.MetricsProvider = b.MetricsProvider
+	}
+	a.MetricsProviderFiles = append(a.MetricsProviderFiles, b.MetricsProviderFiles...)
+	if b.MetricsProviderOptionsJSON != nil {
+		a.MetricsProviderOptionsJSON = b.MetricsProviderOptionsJSON
+	}
+	a.MetricsProxy = mergeRawUIMetricsProxy(a.MetricsProxy, b.MetricsProxy)
+	switch {
+	case a.DashboardURLTemplates == nil:
+		a.DashboardURLTemplates = b.DashboardURLTemplates
+	case b.DashboardURLTemplates == nil:
+	default:
+		m := make(map[string]string, len(a.DashboardURLTemplates)+len(b.DashboardURLTemplates))
+		for k, v := range a.DashboardURLTemplates {
+			m[k] = v
+		}
+		for k, v := range b.DashboardURLTemplates {
+			m[k] = v
+		}
+		a.DashboardURLTemplates = m
+	}
+	return a
+}
+
+func mergeUnixSocketPermissionsRaw(a, b UnixSocketPermissionsRaw) UnixSocketPermissionsRaw {
+	if b.Group != nil {
+		a.Group = b.Group
+	}
+	if b.Mode != nil {
+		a.Mode = b.Mode
+	}
+	if b.User != nil {
+		a.User = b.User
+	}
+	return a
+}
+
+func mergeUnixSocket(a, b UnixSocket) UnixSocket {
+	if b.Group != nil {
+		a.Group = b.Group
+	}
+	if b.Mode != nil {
+		a.Mode = b.Mode
+	}
+	if b.User != nil {
+		a.User = b.User
+	}
+	a.GRPC = mergeUnixSocketPermissionsRaw(a.GRPC, b.GRPC)
+	a.HTTP = mergeUnixSocketPermissionsRaw(a.HTTP, b.HTTP)
+	a.HTTPS = mergeUnixSocketPermissionsRaw(a.HTTPS, b.HTTPS)
+	return a
+}
+
+func mergeUsageReporting(a, b UsageReporting) UsageReporting {
+	if b.Enabled != nil {
+		a.Enabled = b.Enabled
+	}
+	if b.Endpoint != nil {
+		a.Endpoint = b.Endpoint
+	}
+	if b.Interval != nil {
+		a.Interval = b.Interval
+	}
+	return a
+}
+
+func mergeXDS(a, b XDS) XDS {
+	if b.MaxConcurrentStreams != nil {
+		a.MaxConcurrentStreams = b.MaxConcurrentStreams
+	}
+	if b.NackBackoff != nil {
+		a.NackBackoff = b.NackBackoff
+	}
+	if b.EnableDelta != nil {
+		a.EnableDelta = b.EnableDelta
+	}
+	return a
+}
+
+func mergeRPC(a, b RPC) RPC {
+	if b.EnableStreaming != nil {
+		a.EnableStreaming = b.EnableStreaming
+	}
+	return a
+}
+
+func mergeConsul(a, b Consul) Consul {
+	if b.Coordinate.UpdateBatchSize != nil {
+		a.Coordinate.UpdateBatchSize = b.Coordinate.UpdateBatchSize
+	}
+	if b.Coordinate.UpdateMaxBatches != nil {
+		a.Coordinate.UpdateMaxBatches = b.Coordinate.UpdateMaxBatches
+	}
+	if b.Coordinate.UpdatePeriod != nil {
+		a.Coordinate.UpdatePeriod = b.Coordinate.UpdatePeriod
+	}
+	if b.Raft.ElectionTimeout != nil {
+		a.Raft.ElectionTimeout = b.Raft.ElectionTimeout
+	}
+	if b.Raft.HeartbeatTimeout != nil {
+		a.Raft.HeartbeatTimeout = b.Raft.HeartbeatTimeout
+	}
+	if b.Raft.LeaderLeaseTimeout != nil {
+		a.Raft.LeaderLeaseTimeout = b.Raft.LeaderLeaseTimeout
+	}
+	if b.Server.HealthInterval != nil {
+		a.Server.HealthInterval = b.Server.HealthInterval
+	}
+	return a
+}
+
+func mergeConfig(a, b Config) Config {
+	if b.ACLAgentMasterToken != nil {
+		a.ACLAgentMasterToken = b.ACLAgentMasterToken
+	}
+	if b.ACLAgentToken != nil {
+		a.ACLAgentToken = b.ACLAgentToken
+	}
+	if b.ACLDatacenter != nil {
+		a.ACLDatacenter = b.ACLDatacenter
+	}
+	if b.ACLDefaultPolicy != nil {
+		a.ACLDefaultPolicy = b.ACLDefaultPolicy
+	}
+	if b.ACLDownPolicy != nil {
+		a.ACLDownPolicy = b.ACLDownPolicy
+	}
+	if b.ACLEnableKeyListPolicy != nil {
+		a.ACLEnableKeyListPolicy = b.ACLEnableKeyListPolicy
+	}
+	if b.ACLMasterToken != nil {
+		a.ACLMasterToken = b.ACLMasterToken
+	}
+	if b.ACLReplicationToken != nil {
+		a.ACLReplicationToken = b.ACLReplicationToken
+	}
+	if b.ACLTTL != nil {
+		a.ACLTTL = b.ACLTTL
+	}
+	if b.ACLToken != nil {
+		a.ACLToken = b.ACLToken
+	}
+	a.ACL = mergeACL(a.ACL, b.ACL)
+	a.Addresses = mergeAddresses(a.Addresses, b.Addresses)
+	if b.AdvertiseAddrLAN != nil {
+		a.AdvertiseAddrLAN = b.AdvertiseAddrLAN
+	}
+	if b.AdvertiseAddrLANIPv4 != nil {
+		a.AdvertiseAddrLANIPv4 = b.AdvertiseAddrLANIPv4
+	}
+	if b.AdvertiseAddrLANIPv6 != nil {
+		a.AdvertiseAddrLANIPv6 = b.AdvertiseAddrLANIPv6
+	}
+	if b.AdvertiseAddrWAN != nil {
+		a.AdvertiseAddrWAN = b.AdvertiseAddrWAN
+	}
+	if b.AdvertiseAddrWANIPv4 != nil {
+		a.AdvertiseAddrWANIPv4 = b.AdvertiseAddrWANIPv4
+	}
+	if b.AdvertiseAddrWANIPv6 != nil {
+		a.AdvertiseAddrWANIPv6 = b.AdvertiseAddrWANIPv6
+	}
+	if b.AdvertiseAddrSelection != nil {
+		a.AdvertiseAddrSelection = b.AdvertiseAddrSelection
+	}
+	if b.AdvertiseInterface != nil {
+		a.AdvertiseInterface = b.AdvertiseInterface
+	}
+	if b.AdvertiseReconnectTimeout != nil {
+		a.AdvertiseReconnectTimeout = b.AdvertiseReconnectTimeout
+	}
+	a.AutoConfig = mergeAutoConfigRaw(a.AutoConfig, b.AutoConfig)
+	if b.AutoReloadConfig != nil {
+		a.AutoReloadConfig = b.AutoReloadConfig
+	}
+	a.Autopilot = mergeAutopilot(a.Autopilot, b.Autopilot)
+	if b.BindAddr != nil {
+		a.BindAddr = b.BindAddr
+	}
+	a.BindAddrs = append(a.BindAddrs, b.BindAddrs...)
+	if b.BindInterface != nil {
+		a.BindInterface = b.BindInterface
+	}
+	if b.Bootstrap != nil {
+		a.Bootstrap = b.Bootstrap
+	}
+	if b.BootstrapExpect != nil {
+		a.BootstrapExpect = b.BootstrapExpect
+	}
+	a.Cache = mergeCache(a.Cache, b.Cache)
+	if b.CAFile != nil {
+		a.CAFile = b.CAFile
+	}
+	if b.CAFileContents != nil {
+		a.CAFileContents = b.CAFileContents
+	}
+	if b.CAPath != nil {
+		a.CAPath = b.CAPath
+	}
+	if b.CertFile != nil {
+		a.CertFile = b.CertFile
+	}
+	if b.CertFileContents != nil {
+		a.CertFileContents = b.CertFileContents
+	}
+	if b.Check != nil {
+		a.Check = b.Check
+	}
+	a.CheckExecLimits = mergeCheckExecLimits(a.CheckExecLimits, b.CheckExecLimits)
+	if b.CheckOutputMaxSize != nil {
+		a.CheckOutputMaxSize = b.CheckOutputMaxSize
+	}
+	if b.CheckUpdateInterval != nil {
+		a.CheckUpdateInterval = b.CheckUpdateInterval
+	}
+	a.Checks = append(a.Checks, b.Checks...)
+	if b.ClientAddr != nil {
+		a.ClientAddr = b.ClientAddr
+	}
+	a.ClientAddrExclude = append(a.ClientAddrExclude, b.ClientAddrExclude...)
+	if b.ConfigDirFollowSymlink != nil {
+		a.ConfigDirFollowSymlink = b.ConfigDirFollowSymlink
+	}
+	a.ConfigEntries = mergeConfigEntries(a.ConfigEntries, b.ConfigEntries)
+	a.ConfigVerification = mergeConfigVerification(a.ConfigVerification, b.ConfigVerification)
+	a.AutoEncrypt = mergeAutoEncrypt(a.AutoEncrypt, b.AutoEncrypt)
+	a.Connect = mergeConnect(a.Connect, b.Connect)
+	if b.CRIEndpoint != nil {
+		a.CRIEndpoint = b.CRIEndpoint
+	}
+	a.DNS = mergeDNS(a.DNS, b.DNS)
+	if b.DNSDomain != nil {
+		a.DNSDomain = b.DNSDomain
+	}
+	if b.DNSAltDomain != nil {
+		a.DNSAltDomain = b.DNSAltDomain
+	}
+	a.DNSRecursors = append(a.DNSRecursors, b.DNSRecursors...)
+	if b.DataDir != nil {
+		a.DataDir = b.DataDir
+	}
+	if b.Datacenter != nil {
+		a.Datacenter = b.Datacenter
+	}
+	switch {
+	case a.DatacenterAliases == nil:
+		a.DatacenterAliases = b.DatacenterAliases
+	case b.DatacenterAliases == nil:
+	default:
+		m := make(map[string]string, len(a.DatacenterAliases)+len(b.DatacenterAliases))
+		for k, v := range a.DatacenterAliases {
+			m[k] = v
+		}
+		for k, v := range b.DatacenterAliases {
+			m[k] = v
+		}
+		a.DatacenterAliases = m
+	}
+	if b.DeprecationPolicy != nil {
+		a.DeprecationPolicy = b.DeprecationPolicy
+	}
+	if b.DefaultQueryTime != nil {
+		a.DefaultQueryTime = b.DefaultQueryTime
+	}
+	if b.DisableAnonymousSignature != nil {
+		a.DisableAnonymousSignature = b.DisableAnonymousSignature
+	}
+	if b.DisableCoordinates != nil {
+		a.DisableCoordinates = b.DisableCoordinates
+	}
+	if b.DisableHostNodeID != nil {
+		a.DisableHostNodeID = b.DisableHostNodeID
+	}
+	if b.DisableHTTPUnprintableCharFilter != nil {
+		a.DisableHTTPUnprintableCharFilter = b.DisableHTTPUnprintableCharFilter
+	}
+	if b.DisableKeyringFile != nil {
+		a.DisableKeyringFile = b.DisableKeyringFile
+	}
+	if b.DisableRemoteExec != nil {
+		a.DisableRemoteExec = b.DisableRemoteExec
+	}
+	if b.DisableUpdateCheck != nil {
+		a.DisableUpdateCheck = b.DisableUpdateCheck
+	}
+	if b.DiscardCheckOutput != nil {
+		a.DiscardCheckOutput = b.DiscardCheckOutput
+	}
+	a.DiscoveryMaxResults = mergeDiscoveryMaxResults(a.DiscoveryMaxResults, b.DiscoveryMaxResults)
+	if b.DiscoveryMaxStale != nil {
+		a.DiscoveryMaxStale = b.DiscoveryMaxStale
+	}
+	a.DockerEndpoint = mergeDockerEndpoint(a.DockerEndpoint, b.DockerEndpoint)
+	if b.EnableACLReplication != nil {
+		a.EnableACLReplication = b.EnableACLReplication
+	}
+	if b.EnableAgentTLSForChecks != nil {
+		a.EnableAgentTLSForChecks = b.EnableAgentTLSForChecks
+	}
+	if b.EnableCentralServiceConfig != nil {
+		a.EnableCentralServiceConfig = b.EnableCentralServiceConfig
+	}
+	if b.EnableDebug != nil {
+		a.EnableDebug = b.EnableDebug
+	}
+	if b.EnableScriptChecks != nil {
+		a.EnableScriptChecks = b.EnableScriptChecks
+	}
+	if b.EnableLocalScriptChecks != nil {
+		a.EnableLocalScriptChecks = b.EnableLocalScriptChecks
+	}
+	if b.EnableSyslog != nil {
+		a.EnableSyslog = b.EnableSyslog
+	}
+	if b.EncryptKey != nil {
+		a.EncryptKey = b.EncryptKey
+	}
+	if b.EncryptVerifyIncoming != nil {
+		a.EncryptVerifyIncoming = b.EncryptVerifyIncoming
+	}
+	if b.EncryptVerifyOutgoing != nil {
+		a.EncryptVerifyOutgoing = b.EncryptVerifyOutgoing
+	}
+	if b.ExposeAutoConfigStatus != nil {
+		a.ExposeAutoConfigStatus = b.ExposeAutoConfigStatus
+	}
+	a.GossipLAN = mergeGossipLANConfig(a.GossipLAN, b.GossipLAN)
+	a.GossipWAN = mergeGossipWANConfig(a.GossipWAN, b.GossipWAN)
+	a.GRPC = mergeGRPCConfig(a.GRPC, b.GRPC)
+	a.HTTPConfig = mergeHTTPConfig(a.HTTPConfig, b.HTTPConfig)
+	a.Include = append(a.Include, b.Include...)
+	if b.InterfaceAddrPreference != nil {
+		a.InterfaceAddrPreference = b.InterfaceAddrPreference
+	}
+	if b.KeyFile != nil {
+		a.KeyFile = b.KeyFile
+	}
+	if b.KeyFileContents != nil {
+		a.KeyFileContents = b.KeyFileContents
+	}
+	if b.LeaveOnTerm != nil {
+		a.LeaveOnTerm = b.LeaveOnTerm
+	}
+	a.Limits = mergeLimits(a.Limits, b.Limits)
+	a.ListenerOptions = mergeListenerOptions(a.ListenerOptions, b.ListenerOptions)
+	if b.LogLevel != nil {
+		a.LogLevel = b.LogLevel
+	}
+	if b.LogJSON != nil {
+		a.LogJSON = b.LogJSON
+	}
+	if b.LogFile != nil {
+		a.LogFile = b.LogFile
+	}
+	if b.LogRotateDuration != nil {
+		a.LogRotateDuration = b.LogRotateDuration
+	}
+	if b.LogRotateBytes != nil {
+		a.LogRotateBytes = b.LogRotateBytes
+	}
+	if b.LogRotateMaxFiles != nil {
+		a.LogRotateMaxFiles = b.LogRotateMaxFiles
+	}
+	a.Logging = mergeLogging(a.Logging, b.Logging)
+	a.MaintenanceSchedule = mergeMaintenanceSchedule(a.MaintenanceSchedule, b.MaintenanceSchedule)
+	if b.MaxQueryTime != nil {
+		a.MaxQueryTime = b.MaxQueryTime
+	}
+	a.MDNSAdvertise = mergeMDNSAdvertise(a.MDNSAdvertise, b.MDNSAdvertise)
+	a.MergeStrategy = mergeMergeStrategy(a.MergeStrategy, b.MergeStrategy)
+	if b.NodeID != nil {
+		a.NodeID = b.NodeID
+	}
+	switch {
+	case a.NodeMeta == nil:
+		a.NodeMeta = b.NodeMeta
+	case b.NodeMeta == nil:
+	default:
+		m := make(map[string]string, len(a.NodeMeta)+len(b.NodeMeta))
+		for k, v := range a.NodeMeta {
+			m[k] = v
+		}
+		for k, v := range b.NodeMeta {
+			m[k] = v
+		}
+		a.NodeMeta = m
+	}
+	if b.NodeName != nil {
+		a.NodeName = b.NodeName
+	}
+	a.OutboundProxy = mergeOutboundProxy(a.OutboundProxy, b.OutboundProxy)
+	a.Performance = mergePerformance(a.Performance, b.Performance)
+	if b.PidFile != nil {
+		a.PidFile = b.PidFile
+	}
+	a.Ports = mergePorts(a.Ports, b.Ports)
+	if b.Priority != nil {
+		a.Priority = b.Priority
+	}
+	if b.PrimaryDatacenter != nil {
+		a.PrimaryDatacenter = b.PrimaryDatacenter
+	}
+	a.PrimaryDatacenterMigration = mergePrimaryDatacenterMigration(a.PrimaryDatacenterMigration, b.PrimaryDatacenterMigration)
+	a.PrimaryGateways = append(a.PrimaryGateways, b.PrimaryGateways...)
+	if b.PrimaryGatewaysInterval != nil {
+		a.PrimaryGatewaysInterval = b.PrimaryGatewaysInterval
+	}
+	if b.RPCProtocol != nil {
+		a.RPCProtocol = b.RPCProtocol
+	}
+	if b.RaftProtocol != nil {
+		a.RaftProtocol = b.RaftProtocol
+	}
+	if b.RaftSnapshotThreshold != nil {
+		a.RaftSnapshotThreshold = b.RaftSnapshotThreshold
+	}
+	if b.RaftSnapshotInterval != nil {
+		a.RaftSnapshotInterval = b.RaftSnapshotInterval
+	}
+	if b.RaftTrailingLogs != nil {
+		a.RaftTrailingLogs = b.RaftTrailingLogs
+	}
+	if b.RaftTrailingLogsSafetyFactor != nil {
+		a.RaftTrailingLogsSafetyFactor = b.RaftTrailingLogsSafetyFactor
+	}
+	if b.RaftTuningProfile != nil {
+		a.RaftTuningProfile = b.RaftTuningProfile
+	}
+	if b.ReconnectTimeoutLAN != nil {
+		a.ReconnectTimeoutLAN = b.ReconnectTimeoutLAN
+	}
+	if b.ReconnectTimeoutWAN != nil {
+		a.ReconnectTimeoutWAN = b.ReconnectTimeoutWAN
+	}
+	if b.RejoinAfterLeave != nil {
+		a.RejoinAfterLeave = b.RejoinAfterLeave
+	}
+	if b.RetryJoinIntervalLAN != nil {
+		a.RetryJoinIntervalLAN = b.RetryJoinIntervalLAN
+	}
+	if b.RetryJoinIntervalWAN != nil {
+		a.RetryJoinIntervalWAN = b.RetryJoinIntervalWAN
+	}
+	a.RetryJoinLAN = append(a.RetryJoinLAN, b.RetryJoinLAN...)
+	if b.RetryJoinMaxAttemptsLAN != nil {
+		a.RetryJoinMaxAttemptsLAN = b.RetryJoinMaxAttemptsLAN
+	}
+	if b.RetryJoinMaxAttemptsWAN != nil {
+		a.RetryJoinMaxAttemptsWAN = b.RetryJoinMaxAttemptsWAN
+	}
+	a.RetryJoinWAN = append(a.RetryJoinWAN, b.RetryJoinWAN...)
+	a.Runtime = mergeRuntime(a.Runtime, b.Runtime)
+	a.Sanitize = mergeSanitizeConfig(a.Sanitize, b.Sanitize)
+	a.SerfAllowedCIDRsLAN = append(a.SerfAllowedCIDRsLAN, b.SerfAllowedCIDRsLAN...)
+	a.SerfAllowedCIDRsWAN = append(a.SerfAllowedCIDRsWAN, b.SerfAllowedCIDRsWAN...)
+	if b.SerfBindAddrLAN != nil {
+		a.SerfBindAddrLAN = b.SerfBindAddrLAN
+	}
+	if b.SerfBindAddrWAN != nil {
+		a.SerfBindAddrWAN = b.SerfBindAddrWAN
+	}
+	if b.ServerMode != nil {
+		a.ServerMode = b.ServerMode
+	}
+	if b.ServerName != nil {
+		a.ServerName = b.ServerName
+	}
+	if b.ServersFile != nil {
+		a.ServersFile = b.ServersFile
+	}
+	if b.Service != nil {
+		a.Service = b.Service
+	}
+	a.Services = append(a.Services, b.Services...)
+	if b.SessionTTLMin != nil {
+		a.SessionTTLMin = b.SessionTTLMin
+	}
+	if b.SkipLeaveOnInt != nil {
+		a.SkipLeaveOnInt = b.SkipLeaveOnInt
+	}
+	if b.StartDrained != nil {
+		a.StartDrained = b.StartDrained
+	}
+	a.StartJoinAddrsLAN = append(a.StartJoinAddrsLAN, b.StartJoinAddrsLAN...)
+	if b.StrictMode != nil {
+		a.StrictMode = b.StrictMode
+	}
+	a.SuppressWarnings = append(a.SuppressWarnings, b.SuppressWarnings...)
+	a.StartJoinAddrsWAN = append(a.StartJoinAddrsWAN, b.StartJoinAddrsWAN...)
+	if b.SyslogFacility != nil {
+		a.SyslogFacility = b.SyslogFacility
+	}
+	a.TLS = mergeTLS(a.TLS, b.TLS)
+	if b.TLSBootstrapMode != nil {
+		a.TLSBootstrapMode = b.TLSBootstrapMode
+	}
+	if b.TLSCipherSuites != nil {
+		a.TLSCipherSuites = b.TLSCipherSuites
+	}
+	if b.TLSMinVersion != nil {
+		a.TLSMinVersion = b.TLSMinVersion
+	}
+	if b.TLSPreferServerCipherSuites != nil {
+		a.TLSPreferServerCipherSuites = b.TLSPreferServerCipherSuites
+	}
+	switch {
+	case a.TaggedAddresses == nil:
+		a.TaggedAddresses = b.TaggedAddresses
+	case b.TaggedAddresses == nil:
+	default:
+		m := make(map[string]interface{}, len(a.TaggedAddresses)+len(b.TaggedAddresses))
+		for k, v := range a.TaggedAddresses {
+			m[k] = v
+		}
+		for k, v := range b.TaggedAddresses {
+			m[k] = v
+		}
+		a.TaggedAddresses = m
+	}
+	a.Telemetry = mergeTelemetry(a.Telemetry, b.Telemetry)
+	a.Tracing = mergeTracing(a.Tracing, b.Tracing)
+	if b.TranslateWANAddrs != nil {
+		a.TranslateWANAddrs = b.TranslateWANAddrs
+	}
+	if b.UI != nil {
+		a.UI = b.UI
+	}
+	if b.UIContentPath != nil {
+		a.UIContentPath = b.UIContentPath
+	}
+	if b.UIDir != nil {
+		a.UIDir = b.UIDir
+	}
+	a.UIConfig = mergeRawUIConfig(a.UIConfig, b.UIConfig)
+	a.UnixSocket = mergeUnixSocket(a.UnixSocket, b.UnixSocket)
+	if b.UnknownKeys != nil {
+		a.UnknownKeys = b.UnknownKeys
+	}
+	a.UsageReporting = mergeUsageReporting(a.UsageReporting, b.UsageReporting)
+	if b.UseSocketActivation != nil {
+		a.UseSocketActivation = b.UseSocketActivation
+	}
+	if b.ValidateListeners != nil {
+		a.ValidateListeners = b.ValidateListeners
+	}
+	if b.VerifyIncoming != nil {
+		a.VerifyIncoming = b.VerifyIncoming
+	}
+	if b.VerifyIncomingHTTPS != nil {
+		a.VerifyIncomingHTTPS = b.VerifyIncomingHTTPS
+	}
+	a.VerifyIncomingHTTPSExemptCIDRs = append(a.VerifyIncomingHTTPSExemptCIDRs, b.VerifyIncomingHTTPSExemptCIDRs...)
+	if b.VerifyIncomingRPC != nil {
+		a.VerifyIncomingRPC = b.VerifyIncomingRPC
+	}
+	if b.VerifyOutgoing != nil {
+		a.VerifyOutgoing = b.VerifyOutgoing
+	}
+	if b.VerifyServerHostname != nil {
+		a.VerifyServerHostname = b.VerifyServerHostname
+	}
+	a.Watches = append(a.Watches, b.Watches...)
+	a.XDS = mergeXDS(a.XDS, b.XDS)
+	a.RPC = mergeRPC(a.RPC, b.RPC)
+	if b.UseStreamingBackend != nil {
+		a.UseStreamingBackend = b.UseStreamingBackend
+	}
+	switch {
+	case a.SnapshotAgent == nil:
+		a.SnapshotAgent = b.SnapshotAgent
+	case b.SnapshotAgent == nil:
+	default:
+		m := make(map[string]interface{}, len(a.SnapshotAgent)+len(b.SnapshotAgent))
+		for k, v := range a.SnapshotAgent {
+			m[k] = v
+		}
+		for k, v := range b.SnapshotAgent {
+			m[k] = v
+		}
+		a.SnapshotAgent = m
+	}
+	if b.ACLDisabledTTL != nil {
+		a.ACLDisabledTTL = b.ACLDisabledTTL
+	}
+	if b.AEInterval != nil {
+		a.AEInterval = b.AEInterval
+	}
+	if b.CheckDeregisterIntervalMin != nil {
+		a.CheckDeregisterIntervalMin = b.CheckDeregisterIntervalMin
+	}
+	if b.CheckReapInterval != nil {
+		a.CheckReapInterval = b.CheckReapInterval
+	}
+	a.Consul = mergeConsul(a.Consul, b.Consul)
+	if b.Revision != nil {
+		a.Revision = b.Revision
+	}
+	if b.SegmentLimit != nil {
+		a.SegmentLimit = b.SegmentLimit
+	}
+	if b.SegmentNameLimit != nil {
+		a.SegmentNameLimit = b.SegmentNameLimit
+	}
+	if b.SyncCoordinateIntervalMin != nil {
+		a.SyncCoordinateIntervalMin = b.SyncCoordinateIntervalMin
+	}
+	if b.SyncCoordinateRateTarget != nil {
+		a.SyncCoordinateRateTarget = b.SyncCoordinateRateTarget
+	}
+	if b.Version != nil {
+		a.Version = b.Version
+	}
+	if b.VersionPrerelease != nil {
+		a.VersionPrerelease = b.VersionPrerelease
+	}
+	if b.Audit != nil {
+		a.Audit = b.Audit
+	}
+	if b.ReadReplica != nil {
+		a.ReadReplica = b.ReadReplica
+	}
+	if b.SegmentName != nil {
+		a.SegmentName = b.SegmentName
+	}
+	a.Segments = append(a.Segments, b.Segments...)
+	return a
+}
@@ -0,0 +1,110 @@
+package config
+
+// This file is the supported entry point for projects that embed Consul's
+// configuration machinery. Embedders should build a RuntimeConfig through
+// LoadWithOptions and the typed Source constructors below instead of
+// assembling a Builder by hand, so that they are insulated from changes to
+// the Builder internals.
+
+// LoadOption alters how LoadWithOptions assembles the configuration
+// sources before building.
+type LoadOption func(*loadOptions)
+
+type loadOptions struct {
+	opts      BuilderOpts
+	head      []Source
+	overrides []Source
+}
+
+// WithConfigFile adds a config file or directory to be read, in the same
+// way the -config-file and -config-dir command line flags do.
+func WithConfigFile(path string) LoadOption {
+	return func(l *loadOptions) {
+		l.opts.ConfigFiles = append(l.opts.ConfigFiles, path)
+	}
+}
+
+// WithConfigFormat forces all config files to be interpreted as the given
+// format ("json" or "hcl") independent of their extension.
+func WithConfigFormat(format string) LoadOption {
+	return func(l *loadOptions) {
+		l.opts.ConfigFormat = format
+	}
+}
+
+// WithProfile applies a tuning preset, as the -profile command line flag
+// does. See ProfileNames for the available presets.
+func WithProfile(name string) LoadOption {
+	return func(l *loadOptions) {
+		l.opts.Profile = name
+	}
+}
+
+// WithDevMode starts the agent configuration in development mode, as the
+// -dev command line flag does.
+func WithDevMode() LoadOption {
+	return func(l *loadOptions) {
+		devMode := true
+		l.opts.DevMode = &devMode
+	}
+}
+
+// WithVersionMetadata overrides the compiled version parameters so that
+// embedding distributions can tag agents with their own build info.
+func WithVersionMetadata(md VersionMetadata) LoadOption {
+	return func(l *loadOptions) {
+		l.opts.VersionMetadata = &md
+	}
+}
+
+// WithSource injects a source that is merged after the defaults but before
+// any user supplied configuration, so users can override its values.
+func WithSource(src Source) LoadOption {
+	return func(l *loadOptions) {
+		l.head = append(l.head, src)
+	}
+}
+
+// WithOverride injects a source that is merged after every other source
+// and therefore takes precedence over user supplied configuration.
+func WithOverride(src Source) LoadOption {
+	return func(l *loadOptions) {
+		l.overrides = append(l.overrides, src)
+	}
+}
+
+// NewFileSource returns a Source that parses configuration text in the
+// given format ("json" or "hcl"). The name identifies the source in error
+// messages and warnings.
+func NewFileSource(name, format, data string) Source {
+	return FileSource{Name: name, Format: format, Data: data}
+}
+
+// NewLiteralSource returns a Source that supplies an existing typed Config
+// struct rather than HCL or JSON text.
+func NewLiteralSource(name string, cfg Config) Source {
+	return LiteralSource{Name: name, Config: cfg}
+}
+
+// LoadWithOptions constructs and validates a RuntimeConfig from the given
+// options. It returns the runtime configuration together with any warnings
+// produced while building it.
+func LoadWithOptions(options ...LoadOption) (*RuntimeConfig, []string, error) {
+	var l loadOptions
+	for _, opt := range options {
+		opt(&l)
+	}
+
+	b, err := NewBuilder(l.opts)
+	if err != nil {
+		return nil, nil, err
+	}
+	b.Head = append(b.Head, l.head...)
+	b.Tail = append(b.Tail, l.overrides...)
+
+	rt, err := b.BuildAndValidate()
+	if err != nil {
+		return nil, nil, err
+	}
+	return &rt, b.WarningStrings(), nil
+}
@@ -0,0 +1,40 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadWithOptions(t *testing.T) {
+	rt, warns, err := LoadWithOptions(
+		WithDevMode(),
+		WithSource(NewLiteralSource("embedder", Config{
+			NodeName: pString("defaulted"),
+			Ports:    Ports{HTTP: pInt(8501)},
+		})),
+	)
+	require.NoError(t, err)
+	require.Empty(t, warns)
+	require.Equal(t, "defaulted", rt.NodeName)
+	require.Equal(t, 8501, rt.HTTPPort)
+}
+
+func TestLoadWithOptions_UserConfigWinsOverSource(t *testing.T) {
+	rt, _, err := LoadWithOptions(
+		WithDevMode(),
+		WithSource(NewLiteralSource("embedder", Config{NodeName: pString("defaulted")})),
+		WithOverride(NewFileSource("override.hcl", "hcl", `node_name = "final"`)),
+	)
+	require.NoError(t, err)
+	require.Equal(t, "final", rt.NodeName)
+}
+
+func TestLoadWithOptions_VersionMetadata(t *testing.T) {
+	rt, _, err := LoadWithOptions(
+		WithDevMode(),
+		WithVersionMetadata(VersionMetadata{Revision: "deadbeef"}),
+	)
+	require.NoError(t, err)
+	require.Equal(t, "deadbeef", rt.Revision)
+}
@@ -0,0 +1,71 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+)
+
+// BuildWithProvenance is Build but additionally records, for every config
+// key that was set, which config sources contributed to the final value in
+// merge order. Pointer valued keys take the value of the last source that
+// sets them while slice and map values combine all listed sources. The
+// result is returned and also stored in the Provenance field of the runtime
+// configuration so that Sanitized() includes it.
+func (b *Builder) BuildWithProvenance() (RuntimeConfig, map[string][]string, error) {
+	b.provenance = map[string][]string{}
+	rt, err := b.Build()
+	if err != nil {
+		return RuntimeConfig{}, nil, err
+	}
+	rt.Provenance = b.provenance
+	return rt, b.provenance, nil
+}
+
+// recordProvenance adds source to the provenance of every config key the
+// given parsed config sets. It is a no-op unless Build was invoked through
+// BuildWithProvenance.
+func (b *Builder) recordProvenance(source string, c Config) {
+	if b.provenance == nil {
+		return
+	}
+	keys := map[string]bool{}
+	configKeys("", reflect.ValueOf(c), keys)
+	for k := range keys {
+		b.provenance[k] = append(b.provenance[k], source)
+	}
+}
+
+// configKeys collects the dotted key paths of all values the config sets.
+// Key names come from the hcl struct tags so they match what the user wrote
+// in the config file.
+func configKeys(prefix string, v reflect.Value, keys map[string]bool) {
+	typ := v.Type()
+	for i := 0; i < typ.NumField(); i++ {
+		f, fv := typ.Field(i), v.Field(i)
+		name := strings.SplitN(f.Tag.Get("hcl"), ",", 2)[0]
+		if name == "" {
+			name = f.Name
+		}
+		path := name
+		if prefix != "" {
+			path = prefix + "." + name
+		}
+		switch fv.Kind() {
+		case reflect.Ptr:
+			if fv.IsNil() {
+				continue
+			}
+			if fv.Elem().Kind() == reflect.Struct {
+				configKeys(path, fv.Elem(), keys)
+				continue
+			}
+			keys[path] = true
+		case reflect.Struct:
+			configKeys(path, fv, keys)
+		case reflect.Slice, reflect.Map:
+			if fv.Len() > 0 {
+				keys[path] = true
+			}
+		}
+	}
+}
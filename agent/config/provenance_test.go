@@ -0,0 +1,47 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildWithProvenance(t *testing.T) {
+	b, err := NewBuilder(BuilderOpts{})
+	require.NoError(t, err)
+	b.Sources = append(b.Sources,
+		FileSource{
+			Name:   "a.hcl",
+			Format: "hcl",
+			Data: `
+				node_name = "a"
+				ports { http = 8501 }
+			`,
+		},
+		FileSource{
+			Name:   "b.hcl",
+			Format: "hcl",
+			Data:   `node_name = "b"`,
+		},
+	)
+
+	rt, prov, err := b.BuildWithProvenance()
+	require.NoError(t, err)
+
+	require.Equal(t, "b", rt.NodeName)
+	require.Equal(t, []string{"a.hcl", "b.hcl"}, prov["node_name"])
+	require.Equal(t, []string{"default", "a.hcl"}, prov["ports.http"])
+	require.Equal(t, prov, rt.Provenance)
+
+	sanitized := rt.Sanitized()["Provenance"].(map[string]interface{})
+	require.Equal(t, []interface{}{"a.hcl", "b.hcl"}, sanitized["node_name"])
+}
+
+func TestBuild_NoProvenance(t *testing.T) {
+	b, err := NewBuilder(BuilderOpts{})
+	require.NoError(t, err)
+
+	rt, err := b.Build()
+	require.NoError(t, err)
+	require.Nil(t, rt.Provenance)
+}
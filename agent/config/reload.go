@@ -0,0 +1,101 @@
+package config
+
+import "reflect"
+
+// reloadableFields is the set of RuntimeConfig fields the agent applies
+// live when its configuration is reloaded via SIGHUP or the agent reload
+// endpoint. Fields not listed here keep their original value until the agent
+// is restarted, so the agent warns when a reload changes one of them. Keep
+// this list in sync with Agent.reloadConfigInternal.
+var reloadableFields = map[string]bool{
+	// services, checks and node metadata
+	"Checks":             true,
+	"Services":           true,
+	"NodeMeta":           true,
+	"DiscardCheckOutput": true,
+
+	// ACL tokens
+	"ACLTokens": true,
+
+	// TLS settings applied through tlsConfigurator.Update
+	"AutoEncryptTLS":              true,
+	"CAFile":                      true,
+	"CAPath":                      true,
+	"CertFile":                    true,
+	"EnableAgentTLSForChecks":     true,
+	"KeyFile":                     true,
+	"ServerName":                  true,
+	"TLSCipherSuites":             true,
+	"TLSMinVersion":               true,
+	"TLSPreferServerCipherSuites": true,
+	"VerifyIncoming":              true,
+	"VerifyIncomingHTTPS":         true,
+	"VerifyIncomingRPC":           true,
+	"VerifyOutgoing":              true,
+	"VerifyServerHostname":        true,
+
+	// watches
+	"Watches": true,
+
+	// limits
+	"HTTPMaxConnsPerClient": true,
+	"RPCMaxBurst":           true,
+	"RPCRateLimit":          true,
+
+	// DNS settings applied through DNSServer.ReloadConfig
+	"DNSARecordLimit":       true,
+	"DNSAllowStale":         true,
+	"DNSCacheMaxAge":        true,
+	"DNSDisableCompression": true,
+	"DNSEnableTruncate":     true,
+	"DNSMaxStale":           true,
+	"DNSNodeMetaTXT":        true,
+	"DNSNodeMetaTXTKeys":    true,
+	"DNSNodeTTL":            true,
+	"DNSOnlyPassing":        true,
+	"DNSRecursorTimeout":    true,
+	"DNSRecursors":          true,
+	"DNSSOA":                true,
+	"DNSServiceTTL":         true,
+	"DNSTagAllowlist":       true,
+	"DNSUDPAnswerLimit":     true,
+	"DNSUseCache":           true,
+
+	// bootstrap_expect may be lowered (never raised) on a running server
+	// through Server.ReloadBootstrapExpect
+	"BootstrapExpect": true,
+
+	// miscellaneous
+	"Cache":                     true,
+	"ConfigEntryBootstrap":      true,
+	"Logging":                   true,
+	"LoggingSlowQueryThreshold": true,
+	"Telemetry":                 true,
+	"UIConfig":                  true,
+
+	// derived values that are recomputed on every load and therefore do
+	// not represent an ignored operator change
+	"DeprecatedKeysInUse": true,
+	"Hash":                true,
+	"Provenance":          true,
+}
+
+// NonReloadableChanges compares two runtime configurations and returns the
+// names of the fields that changed but are not applied by a reload. The
+// agent uses this to warn operators that the changed values are ignored
+// until the agent is restarted.
+func NonReloadableChanges(old, new *RuntimeConfig) []string {
+	var changed []string
+	t := reflect.TypeOf(*old)
+	ov, nv := reflect.ValueOf(*old), reflect.ValueOf(*new)
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if reloadableFields[f.Name] {
+			continue
+		}
+		if !reflect.DeepEqual(ov.Field(i).Interface(), nv.Field(i).Interface()) {
+			changed = append(changed, f.Name)
+		}
+	}
+	return changed
+}
@@ -0,0 +1,24 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNonReloadableChanges(t *testing.T) {
+	var oldCfg, newCfg RuntimeConfig
+	require.Empty(t, NonReloadableChanges(&oldCfg, &newCfg))
+
+	// reloadable fields do not show up
+	newCfg.RPCRateLimit = 100
+	newCfg.HTTPMaxConnsPerClient = 100
+	newCfg.Logging.LogLevel = "DEBUG"
+	newCfg.Telemetry.AllowedPrefixes = []string{"consul.rpc"}
+	require.Empty(t, NonReloadableChanges(&oldCfg, &newCfg))
+
+	// non-reloadable fields do
+	newCfg.Datacenter = "dc2"
+	newCfg.NodeName = "hobbiton"
+	require.Equal(t, []string{"Datacenter", "NodeName"}, NonReloadableChanges(&oldCfg, &newCfg))
+}
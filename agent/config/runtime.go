@@ -1,13 +1,18 @@
 package config
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
 	"net"
 	"reflect"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/hashicorp/go-uuid"
+	hclprinter "github.com/hashicorp/hcl/hcl/printer"
+	jsonparser "github.com/hashicorp/hcl/json/parser"
 	"golang.org/x/time/rate"
 
 	"github.com/hashicorp/consul/agent/cache"
@@ -21,6 +26,27 @@ import (
 	"github.com/hashicorp/consul/types"
 )
 
+// RuntimeBlockEndpointRule is the runtime form of a structured
+// http_config.block_endpoint_rules entry. The path regex is validated when
+// the configuration is built.
+type RuntimeBlockEndpointRule struct {
+	Name            string
+	PathRegex       string
+	Methods         []string
+	ResponseCode    int // 403 by default
+	ResponseMessage string
+}
+
+// ListenerSocketOptions are the resolved socket options for one listener
+// type. SOReusePort and Backlog are applied when the socket is bound;
+// TCPKeepaliveInterval overrides the keep-alive period set on accepted TCP
+// connections. Zero values leave the operating system defaults in place.
+type ListenerSocketOptions struct {
+	SOReusePort          bool
+	TCPKeepaliveInterval time.Duration
+	Backlog              int
+}
+
 type RuntimeSOAConfig struct {
 	Refresh uint32 // 3600 by default
 	Retry   uint32 // 600
@@ -45,6 +71,26 @@ type RuntimeConfig struct {
 	Version                    string
 	VersionPrerelease          string
 
+	// Provenance records for every config key that was set which config
+	// sources contributed to the final value, in merge order. It is only
+	// populated by Builder.BuildWithProvenance and is empty otherwise.
+	// (not user configurable)
+	Provenance map[string][]string
+
+	// Hash is a stable fingerprint of the merged, pre-validation Config
+	// with secret values excluded. Agents running the same configuration
+	// report the same hash regardless of credentials, so fleet tooling can
+	// detect configuration drift without diffing full payloads.
+	// (not user configurable)
+	Hash string
+
+	// DeprecatedKeysInUse are the deprecated config keys with a scheduled
+	// removal version that this configuration actually used, sorted and
+	// de-duplicated. The agent exports a labeled gauge for each of them when
+	// telemetry.enable_deprecated_config_metrics is set.
+	// (not user configurable)
+	DeprecatedKeysInUse []string
+
 	// consul config
 	ConsulCoordinateUpdateMaxBatches int
 	ConsulCoordinateUpdateBatchSize  int
@@ -225,6 +271,13 @@ type RuntimeConfig struct {
 	// hcl: dns_config { disable_compression = (true|false) }
 	DNSDisableCompression bool
 
+	// DNSDOHPath is the HTTP path under which DNS-over-HTTPS (RFC 8484)
+	// queries are served on the HTTP(S) listeners. Empty disables the
+	// endpoint.
+	//
+	// hcl: dns_config { doh_path = string }
+	DNSDOHPath string
+
 	// DNSDomain is the DNS domain for the records. Should end with a dot.
 	// Defaults to "consul."
 	//
@@ -299,6 +352,20 @@ type RuntimeConfig struct {
 	// request (query type = TXT). If unset this will default to true
 	DNSNodeMetaTXT bool
 
+	// DNSNodeMetaTXTKeys restricts which node_meta keys are synthesized
+	// into TXT records when DNSNodeMetaTXT is enabled. When empty all node
+	// metadata is exposed.
+	//
+	// hcl: dns_config { node_meta_txt_keys = []string }
+	DNSNodeMetaTXTKeys []string
+
+	// DNSTagAllowlist restricts which service tags are resolvable as
+	// tag.name.service.consul. Entries are exact tags or prefix patterns
+	// with a trailing '*'. When empty all tags are resolvable.
+	//
+	// hcl: dns_config { tag_allowlist = []string }
+	DNSTagAllowlist []string
+
 	// DNSRecursors can be set to allow the DNS servers to recursively
 	// resolve non-consul domains.
 	//
@@ -328,6 +395,29 @@ type RuntimeConfig struct {
 	// hcl: http_config { block_endpoints = []string }
 	HTTPBlockEndpoints []string
 
+	// HTTPBlockEndpointRules is a list of structured rules to block
+	// requests in the HTTP API by path regex and, optionally, method.
+	// Each rule controls the response code and message it returns.
+	//
+	// hcl: http_config { block_endpoint_rules = []RuntimeBlockEndpointRule }
+	HTTPBlockEndpointRules []RuntimeBlockEndpointRule
+
+	// HTTPDisableLegacyEndpoints is a list of deprecated endpoint path
+	// prefixes the operator has switched off. Entries are validated
+	// against the known legacy endpoints and any request to one of them
+	// gets a 403 response.
+	//
+	// hcl: http_config { disable_legacy_endpoints = []string }
+	HTTPDisableLegacyEndpoints []string
+
+	// HTTPRequestIDHeader names the HTTP header carrying a request id for
+	// cross-system tracing. When set the agent accepts an incoming id or
+	// generates one, echoes it on the response and attaches it to the log
+	// entries for the request. Empty disables request id handling.
+	//
+	// hcl: http_config { request_id_header = string }
+	HTTPRequestIDHeader string
+
 	// AllowWriteHTTPFrom restricts the agent write endpoints to the given
 	// networks. Any request to a protected endpoint that is not mactched
 	// by one of these networks will get a 403 response.
@@ -344,6 +434,13 @@ type RuntimeConfig struct {
 	// Embed Telemetry Config
 	Telemetry lib.TelemetryConfig
 
+	// Tracing is the distributed tracing configuration consulted by the
+	// HTTP and RPC layers. Tracing is disabled unless an OTLP endpoint is
+	// configured.
+	//
+	// hcl: tracing { otlp_endpoint = string sampling_ratio = float64 service_name = string }
+	Tracing lib.TracingConfig
+
 	// Datacenter is the datacenter this node is in. Defaults to "dc1".
 	//
 	// Datacenter is exposed via /v1/agent/self from here and
@@ -354,6 +451,14 @@ type RuntimeConfig struct {
 	// flag: -datacenter string
 	Datacenter string
 
+	// DatacenterAliases maps alternative datacenter names onto the
+	// datacenter they stand for. DNS and HTTP queries naming an alias are
+	// served from the aliased datacenter, which keeps legacy clients
+	// working through a datacenter rename migration.
+	//
+	// hcl: datacenter_aliases = map[string]string
+	DatacenterAliases map[string]string
+
 	// Defines the maximum stale value for discovery path. Defaults to "0s".
 	// Discovery paths are /v1/heath/ paths
 	//
@@ -362,6 +467,52 @@ type RuntimeConfig struct {
 	// hcl: discovery_max_stale = "duration"
 	DiscoveryMaxStale time.Duration
 
+	// DiscoveryMaxResultsDNS caps how many service instances a single DNS
+	// service or prepared query lookup may return. The cap is applied
+	// after shuffling so clients still see a spread of instances. Zero
+	// means unlimited.
+	//
+	// hcl: discovery_max_results { dns = int }
+	DiscoveryMaxResultsDNS int
+
+	// DiscoveryMaxResultsHTTP caps how many service instances a single
+	// HTTP discovery query (/v1/health/service, /v1/catalog/service and
+	// their connect variants) may return. Zero means unlimited.
+	//
+	// hcl: discovery_max_results { http = int }
+	DiscoveryMaxResultsHTTP int
+
+	// DockerEndpointHost is the Docker daemon address used for docker
+	// container checks. It takes precedence over the DOCKER_HOST
+	// environment variable. Defaults to the platform default socket.
+	//
+	// hcl: docker_endpoint { host = string }
+	DockerEndpointHost string
+
+	// DockerEndpointCAFile is the path to a CA certificate used to verify
+	// a tcp Docker endpoint.
+	//
+	// hcl: docker_endpoint { ca_file = string }
+	DockerEndpointCAFile string
+
+	// DockerEndpointCertFile is the path to a client certificate presented
+	// to a tcp Docker endpoint. Requires key_file.
+	//
+	// hcl: docker_endpoint { cert_file = string }
+	DockerEndpointCertFile string
+
+	// DockerEndpointKeyFile is the path to the client certificate key for
+	// a tcp Docker endpoint. Requires cert_file.
+	//
+	// hcl: docker_endpoint { key_file = string }
+	DockerEndpointKeyFile string
+
+	// DockerEndpointTLSSkipVerify disables verification of the Docker
+	// endpoint certificate.
+	//
+	// hcl: docker_endpoint { tls_skip_verify = (true|false) }
+	DockerEndpointTLSSkipVerify bool
+
 	// Node name is the name we use to advertise. Defaults to hostname.
 	//
 	// NodeName is exposed via /v1/agent/self from here and
@@ -380,6 +531,16 @@ type RuntimeConfig struct {
 	// hcl: advertise_addr = string
 	AdvertiseAddrLAN *net.IPAddr
 
+	// AdvertiseAddrSelection is the rule applied when advertise address
+	// detection finds more than one candidate address. "error" (the
+	// default) refuses to start, "first" picks the first detected
+	// address, "interface:<name>" picks the address on the named
+	// interface and "cidr:<net>" picks the address contained in the
+	// network.
+	//
+	// hcl: advertise_addr_selection = string
+	AdvertiseAddrSelection string
+
 	// AdvertiseAddrWAN is the address we use for advertising our Serf, and
 	// Consul RPC IP. The address can be specified as an ip address or as a
 	// go-sockaddr template which resolves to a single ip address. If not
@@ -400,6 +561,16 @@ type RuntimeConfig struct {
 	// flag: -bind string
 	BindAddr *net.IPAddr
 
+	// BindAddrs is the full list of addresses the RPC and Serf listeners
+	// bind to on multi-homed hosts. Each entry of bind_addrs is either an
+	// ip address or a CIDR which is expanded to all interface addresses
+	// within that network. When set, BindAddr is the first entry of the
+	// list and advertise detection is skipped since the advertise address
+	// defaults to that first entry. Empty unless bind_addrs is configured.
+	//
+	// hcl: bind_addrs = []string
+	BindAddrs []*net.IPAddr
+
 	// Bootstrap is used to bring up the first Consul server, and
 	// permits that node to elect itself leader
 	//
@@ -426,6 +597,14 @@ type RuntimeConfig struct {
 	// hcl: ca_file = string
 	CAFile string
 
+	// CAFileContents contains PEM encoded certificate authorities directly.
+	// It is used instead of CAFile so that a config can be fully
+	// self-contained. A value of the form file("<path>") is resolved by the
+	// builder when the config is loaded.
+	//
+	// hcl: ca_file_contents = string
+	CAFileContents string
+
 	// CAPath is a path to a directory of certificate authority files. This is
 	// used with VerifyIncoming or VerifyOutgoing to verify the TLS connection.
 	//
@@ -438,6 +617,39 @@ type RuntimeConfig struct {
 	// hcl: cert_file = string
 	CertFile string
 
+	// CertFileContents contains the PEM encoded TLS certificate directly.
+	// It is used instead of CertFile so that a config can be fully
+	// self-contained. A value of the form file("<path>") is resolved by the
+	// builder when the config is loaded.
+	//
+	// hcl: cert_file_contents = string
+	CertFileContents string
+
+	// CheckExecLimitsMaxConcurrent bounds how many script checks may run
+	// at the same time. Additional checks wait for a free slot before
+	// running. Zero applies no limit.
+	//
+	// hcl: check_exec_limits { max_concurrent = int }
+	CheckExecLimitsMaxConcurrent int
+
+	// CheckExecLimitsCPUSeconds is a per-check CPU time rlimit in seconds
+	// applied to script check processes. Zero applies no limit.
+	//
+	// hcl: check_exec_limits { cpu_seconds = int }
+	CheckExecLimitsCPUSeconds int
+
+	// CheckExecLimitsMemoryBytes is a per-check address space rlimit in
+	// bytes applied to script check processes. Zero applies no limit.
+	//
+	// hcl: check_exec_limits { memory_bytes = int }
+	CheckExecLimitsMemoryBytes int64
+
+	// CheckExecLimitsNice is the scheduling priority script check
+	// processes run with. Zero leaves the priority unchanged.
+	//
+	// hcl: check_exec_limits { nice = int }
+	CheckExecLimitsNice int
+
 	// CheckUpdateInterval controls the interval on which the output of a health check
 	// is updated if there is no change to the state. For example, a check in a steady
 	// state may run every 5 second generating a unique output (timestamp, etc), forcing
@@ -497,6 +709,14 @@ type RuntimeConfig struct {
 	// flag: -client string
 	ClientAddrs []*net.IPAddr
 
+	// ClientAddrExclude contains the networks removed from the interface
+	// set produced by the client_addr templates. It lets "all interfaces
+	// except the metadata and VIP networks" be expressed without template
+	// logic.
+	//
+	// hcl: client_addr_exclude = []string
+	ClientAddrExclude []*net.IPNet
+
 	// ConfigEntryBootstrap contains a list of ConfigEntries to ensure are created
 	// If entries of the same Kind/Name exist already these will not update them.
 	ConfigEntryBootstrap []structs.ConfigEntry
@@ -521,6 +741,22 @@ type RuntimeConfig struct {
 	// process including how servers can authorize requests.
 	AutoConfig AutoConfig
 
+	// ExposeAutoConfigStatus opts the agent into including a sanitized summary
+	// of its auto_config/auto_encrypt state (intro token expiry, certificate
+	// validity window, authorizer auth method) in the /v1/agent/self response.
+	// The set of fields considered safe to expose is defined by
+	// AutoConfigStatus; no secret material is ever included.
+	//
+	// hcl: expose_auto_config_status = (true|false)
+	ExposeAutoConfigStatus bool
+
+	// AutoReloadConfig opts the agent into watching the config files and
+	// certificate files it loaded and reloading the configuration
+	// automatically when they change, as if a SIGHUP had been received.
+	//
+	// hcl: auto_reload_config = (true|false)
+	AutoReloadConfig bool
+
 	// ConnectEnabled opts the agent into connect. It should be set on all clients
 	// and servers in a cluster for correct connect operation.
 	ConnectEnabled bool
@@ -560,10 +796,24 @@ type RuntimeConfig struct {
 	// deterministic again.
 	ConnectTestCALeafRootChangeSpread time.Duration
 
+	// CRIEndpoint is the path of the unix socket of the local CRI
+	// runtime service (containerd, CRI-O) used for checks that exec
+	// inside a container with cri_container_id. Defaults to the
+	// containerd socket when unset and only needs to exist when such a
+	// check is configured.
+	//
+	// hcl: cri_endpoint = string
+	CRIEndpoint string
+
 	// DNSAddrs contains the list of TCP and UDP addresses the DNS server will
 	// bind to. If the DNS endpoint is disabled (ports.dns <= 0) the list is
 	// empty.
 	//
+	// The TCP and UDP listeners can be configured independently through
+	// ports.dns_tcp and ports.dns_udp, which fall back to ports.dns.
+	// Disabling one of them (<= 0) drops the entries of that protocol
+	// from the list.
+	//
 	// The ip addresses are taken from 'addresses.dns' which should contain a
 	// space separated list of ip addresses and/or go-sockaddr templates.
 	//
@@ -575,6 +825,27 @@ type RuntimeConfig struct {
 	// hcl: client_addr = string addresses { dns = string } ports { dns = int }
 	DNSAddrs []net.Addr
 
+	// DNSTLSAddrs contains the list of TCP addresses the DNS-over-TLS
+	// (RFC 7858) listener binds to. The addresses are derived like
+	// DNSAddrs. Empty unless ports.dns_tls is set.
+	//
+	// hcl: client_addr = string addresses { dns = string } ports { dns_tls = int }
+	DNSTLSAddrs []net.Addr
+
+	// DNSTLSCertFile and DNSTLSKeyFile name the certificate and key
+	// presented by the DNS-over-TLS listener. Both are required when
+	// ports.dns_tls is set.
+	//
+	// hcl: dns_config { tls { cert_file = string key_file = string } }
+	DNSTLSCertFile string
+	DNSTLSKeyFile  string
+
+	// DNSTLSPort is the port the DNS-over-TLS listener listens on.
+	// Disabled by default.
+	//
+	// hcl: ports { dns_tls = int }
+	DNSTLSPort int
+
 	// DNSPort is the port the DNS server listens on. The default is 8600.
 	// Setting this to a value <= 0 disables the endpoint.
 	//
@@ -714,6 +985,23 @@ type RuntimeConfig struct {
 	// hcl: encrypt_verify_outgoing = (true|false)
 	EncryptVerifyOutgoing bool
 
+	// EphemeralPorts records the ports that were picked by the operating
+	// system for listeners configured with an explicit port of 0, keyed
+	// by the ports.* name. The agent writes them to an ephemeral-ports.json
+	// file in the data dir on start so test harnesses can discover them.
+	// It is nil when no port was allocated.
+	//
+	// hcl: ports { http = 0 }
+	EphemeralPorts map[string]int
+
+	// GRPCEnableConfigService enables the agent config gRPC service which
+	// streams the sanitized runtime configuration and an update event on
+	// every reload. Sidecar controllers use it to react to agent config
+	// changes without polling /v1/agent/self.
+	//
+	// hcl: grpc { enable_config_service = (true|false) }
+	GRPCEnableConfigService bool
+
 	// GRPCPort is the port the gRPC server listens on. Currently this only
 	// exposes the xDS and ext_authz APIs for Envoy and it is disabled by default.
 	//
@@ -736,6 +1024,20 @@ type RuntimeConfig struct {
 	// hcl: client_addr = string addresses { grpc = string } ports { grpc = int }
 	GRPCAddrs []net.Addr
 
+	// GRPCTLSPort is the port a second, TLS gRPC listener binds to. It allows
+	// xDS traffic to be served plaintext on ports.grpc (e.g. for local Envoy
+	// sidecars) while remote proxies connect over TLS. Disabled by default.
+	//
+	// hcl: ports { grpc_tls = int }
+	GRPCTLSPort int
+
+	// GRPCTLSAddrs contains the list of TCP addresses and UNIX sockets the
+	// TLS gRPC server will bind to. If the listener is disabled
+	// (ports.grpc_tls <= 0) the list is empty.
+	//
+	// hcl: client_addr = string addresses { grpc_tls = string } ports { grpc_tls = int }
+	GRPCTLSAddrs []net.Addr
+
 	// HTTPAddrs contains the list of TCP addresses and UNIX sockets the HTTP
 	// server will bind to. If the HTTP endpoint is disabled (ports.http <= 0)
 	// the list is empty.
@@ -787,6 +1089,22 @@ type RuntimeConfig struct {
 	// If zero, or negative, http.DefaultMaxHeaderBytes is used.
 	HTTPMaxHeaderBytes int
 
+	// HTTPProxyProtocolEnabled makes the HTTP and HTTPS listeners parse a
+	// leading PROXY protocol (version 1) header on each connection so that
+	// agents behind an L4 load balancer see the real client address for
+	// allow_write_http_from enforcement and request logging.
+	//
+	// hcl: http_config { proxy_protocol { enabled = (true|false) } }
+	HTTPProxyProtocolEnabled bool
+
+	// HTTPProxyProtocolAllowedSources restricts which remote addresses are
+	// trusted to send a PROXY protocol header. Connections from other
+	// sources are served unchanged so a stray header cannot spoof the
+	// client address. When empty every source is trusted.
+	//
+	// hcl: http_config { proxy_protocol { allowed_sources = []string } }
+	HTTPProxyProtocolAllowedSources []*net.IPNet
+
 	// HTTPSHandshakeTimeout is the time allowed for HTTPS client to complete the
 	// TLS handshake and send first bytes of the request.
 	//
@@ -806,12 +1124,27 @@ type RuntimeConfig struct {
 	// hcl: key_file = string
 	KeyFile string
 
+	// KeyFileContents contains the PEM encoded TLS key directly. It is used
+	// instead of KeyFile so that a config can be fully self-contained. A
+	// value of the form file("<path>") is resolved by the builder when the
+	// config is loaded.
+	//
+	// hcl: key_file_contents = string
+	KeyFileContents string
+
 	// KVMaxValueSize controls the max allowed value size. If not set defaults
 	// to raft's suggested max value size.
 	//
 	// hcl: limits { kv_max_value_size = uint64 }
 	KVMaxValueSize uint64
 
+	// KVQuotas caps KV usage under key prefixes. Writes that would push a
+	// prefix over its key count or byte limit are rejected by the servers.
+	// Prefixes must not overlap.
+	//
+	// hcl: limits { kv_quota = [{ prefix = string max_keys = int max_bytes = int }] }
+	KVQuotas []consul.KVQuota
+
 	// LeaveDrainTime is used to wait after a server has left the LAN Serf
 	// pool for RPCs to drain and new requests to be sent to other servers.
 	//
@@ -824,9 +1157,57 @@ type RuntimeConfig struct {
 	// hcl: leave_on_terminate = (true|false)
 	LeaveOnTerm bool
 
+	// ListenerOptionsDNS tunes socket options for the DNS listeners.
+	// tcp_keepalive_interval is accepted but has no effect for DNS.
+	//
+	// hcl: listener_options { dns { so_reuseport = (true|false) backlog = int } }
+	ListenerOptionsDNS ListenerSocketOptions
+
+	// ListenerOptionsGRPC tunes socket options for the gRPC listeners.
+	//
+	// hcl: listener_options { grpc { so_reuseport = (true|false) tcp_keepalive_interval = "duration" backlog = int } }
+	ListenerOptionsGRPC ListenerSocketOptions
+
+	// ListenerOptionsHTTP tunes socket options for the HTTP listeners.
+	//
+	// hcl: listener_options { http { so_reuseport = (true|false) tcp_keepalive_interval = "duration" backlog = int } }
+	ListenerOptionsHTTP ListenerSocketOptions
+
+	// ListenerOptionsHTTPS tunes socket options for the HTTPS listeners.
+	//
+	// hcl: listener_options { https { so_reuseport = (true|false) tcp_keepalive_interval = "duration" backlog = int } }
+	ListenerOptionsHTTPS ListenerSocketOptions
+
 	// Logging configuration used to initialize agent logging.
 	Logging logging.Config
 
+	// LoggingSlowQueryThreshold is the duration above which served HTTP
+	// requests, blocking queries and DNS lookups are logged as slow with
+	// labels identifying the query. Zero disables slow query logging.
+	// (reloadable)
+	//
+	// hcl: logging { slow_query_threshold = "duration" }
+	LoggingSlowQueryThreshold time.Duration
+
+	// MaintenanceScheduleCron is a five-field cron expression describing when
+	// a recurring maintenance window opens. When it is empty no maintenance
+	// schedule is active.
+	//
+	// hcl: maintenance_schedule { cron = string }
+	MaintenanceScheduleCron string
+
+	// MaintenanceScheduleDuration is how long the node stays in maintenance
+	// mode after a scheduled window opens.
+	//
+	// hcl: maintenance_schedule { duration = "duration" }
+	MaintenanceScheduleDuration time.Duration
+
+	// MaintenanceScheduleReason is the reason recorded on the maintenance
+	// check during a scheduled window.
+	//
+	// hcl: maintenance_schedule { reason = string }
+	MaintenanceScheduleReason string
+
 	// MaxQueryTime is the maximum amount of time a blocking query can wait
 	// before Consul will force a response. Consul applies jitter to the wait
 	// time. The jittered time will be capped to MaxQueryTime.
@@ -835,6 +1216,31 @@ type RuntimeConfig struct {
 	// flags: -max-query-time string
 	MaxQueryTime time.Duration
 
+	// MDNSAdvertiseAllowServer opts a server agent in to mDNS advertisement
+	// which is otherwise refused in server mode.
+	//
+	// hcl: mdns_advertise { allow_server = (true|false) }
+	MDNSAdvertiseAllowServer bool
+
+	// MDNSAdvertiseEnabled makes the agent advertise its own HTTP API over
+	// mDNS for zero-config discovery of the agent on the local network.
+	//
+	// hcl: mdns_advertise { enabled = (true|false) }
+	MDNSAdvertiseEnabled bool
+
+	// MDNSAdvertiseInterface is the name of the network interface to bind the
+	// mDNS multicast listener to. Empty means the system default multicast
+	// interface.
+	//
+	// hcl: mdns_advertise { interface = string }
+	MDNSAdvertiseInterface string
+
+	// MDNSAdvertiseService is the mDNS instance name to advertise under.
+	// Defaults to the node name.
+	//
+	// hcl: mdns_advertise { service = string }
+	MDNSAdvertiseService string
+
 	// Node ID is a unique ID for this node across space and time. Defaults
 	// to a randomly-generated ID that persists in the data-dir.
 	//
@@ -853,6 +1259,14 @@ type RuntimeConfig struct {
 	// flag: -node-meta "key:value" -node-meta "key:value" ...
 	NodeMeta map[string]string
 
+	// ReservedPorts are inclusive port ranges that config defined
+	// services, sidecar proxies and exposed check paths must not claim.
+	// The agent's own listener ports are always treated as reserved in
+	// addition to the configured ranges. See also: IsReservedPort.
+	//
+	// hcl: limits { reserved_ports = ["min-max", ...] }
+	ReservedPorts []PortRange
+
 	// ReadReplica is whether this server will act as a non-voting member
 	// of the cluster to help provide read scalability. (Enterprise-only)
 	//
@@ -860,6 +1274,29 @@ type RuntimeConfig struct {
 	// flag: -non-voting-server
 	ReadReplica bool
 
+	// OutboundProxyURL is the URL of an HTTP CONNECT or SOCKS5 proxy the
+	// agent's own outbound HTTP traffic is routed through: retry-join
+	// cloud discovery, the update check, OIDC discovery and the Vault CA
+	// provider. The URL must not embed credentials; use
+	// outbound_proxy.credentials_file for those.
+	//
+	// hcl: outbound_proxy { url = string }
+	OutboundProxyURL string
+
+	// OutboundProxyNoProxy lists hosts, domains or CIDRs which bypass the
+	// outbound proxy. It is exported as the NO_PROXY environment variable.
+	//
+	// hcl: outbound_proxy { no_proxy = []string }
+	OutboundProxyNoProxy []string
+
+	// OutboundProxyCredentialsFile is the path of a file holding a single
+	// "username:password" line used to authenticate to the outbound
+	// proxy. The file is read again whenever the proxy is configured so
+	// credentials never appear in the main configuration.
+	//
+	// hcl: outbound_proxy { credentials_file = string }
+	OutboundProxyCredentialsFile string
+
 	// PidFile is the file to store our PID in.
 	//
 	// hcl: pid_file = string
@@ -872,6 +1309,17 @@ type RuntimeConfig struct {
 	// hcl: primary_datacenter = string
 	PrimaryDatacenter string
 
+	// PrimaryDatacenterMigrationOld/New/CutoverTime describe a controlled
+	// rename of the primary datacenter. Before the cutover time the old
+	// name is the effective primary, afterwards the new one, and the
+	// retired name resolves through an implicit datacenter alias so peers
+	// can keep using either name during the migration.
+	//
+	// hcl: primary_datacenter_migration { old = string new = string cutover_time = string }
+	PrimaryDatacenterMigrationOld         string
+	PrimaryDatacenterMigrationNew         string
+	PrimaryDatacenterMigrationCutoverTime time.Time
+
 	// PrimaryGateways is a list of addresses and/or go-discover expressions to
 	// discovery the mesh gateways in the primary datacenter. See
 	// https://www.consul.io/docs/agent/options.html#cloud-auto-joining for
@@ -945,6 +1393,24 @@ type RuntimeConfig struct {
 
 	RPCConfig consul.RPCConfig
 
+	// UseSocketActivation makes the agent inherit pre-bound sockets from
+	// the service manager (systemd's LISTEN_FDS protocol) for the HTTP,
+	// HTTPS, DNS and gRPC listeners whose addresses match an inherited
+	// socket. This allows binding privileged ports such as 53 without
+	// running the agent as root. Addresses without a matching socket are
+	// bound by the agent as usual.
+	//
+	// hcl: use_socket_activation = (true|false)
+	UseSocketActivation bool
+
+	// ValidateListeners makes Validate briefly bind every configured DNS,
+	// HTTP, HTTPS and gRPC address so port conflicts — including addresses
+	// held by other processes — are reported before the agent claims to
+	// start successfully. The probe sockets are closed again immediately.
+	//
+	// hcl: validate_listeners = (true|false)
+	ValidateListeners bool
+
 	// UseStreamingBackend enables streaming as a replacement for agent/cache
 	// in the client agent for endpoints which support streaming.
 	UseStreamingBackend bool
@@ -982,6 +1448,23 @@ type RuntimeConfig struct {
 	// hcl: raft_trailing_logs = int
 	RaftTrailingLogs int
 
+	// RaftTrailingLogsSafetyFactor sets the minimum ratio of raft_trailing_logs
+	// to raft_snapshot_threshold. If both settings are configured explicitly,
+	// raft_trailing_logs must be at least raft_snapshot_threshold multiplied by
+	// this factor, otherwise followers risk falling permanently behind between
+	// snapshots. Defaults to 1.25.
+	//
+	// hcl: raft_trailing_logs_safety_factor = float64
+	RaftTrailingLogsSafetyFactor float64
+
+	// RaftTuningProfile selects a preset of mutually consistent values for the
+	// raft_* settings. Valid values are 'default', 'write-heavy' and
+	// 'low-memory'. Explicitly configured raft_* settings take precedence over
+	// the profile.
+	//
+	// hcl: raft_tuning_profile = string
+	RaftTuningProfile string
+
 	// ReconnectTimeoutLAN specifies the amount of time to wait to reconnect with
 	// another agent before deciding it's permanently gone. This can be used to
 	// control the time it takes to reap failed nodes from the cluster.
@@ -1060,6 +1543,42 @@ type RuntimeConfig struct {
 	// flag: -retry-join-wan string -retry-join-wan string
 	RetryJoinWAN []string
 
+	// RuntimeGOGC sets the garbage collection target percentage for the
+	// agent process. -1 disables the garbage collector. Zero leaves the Go
+	// runtime default (or the GOGC environment variable) in place.
+	//
+	// hcl: runtime { gogc = int }
+	RuntimeGOGC int
+
+	// RuntimeGOMAXPROCS limits the number of CPUs executing simultaneously
+	// in the agent process. Zero leaves the Go runtime default (or the
+	// GOMAXPROCS environment variable) in place.
+	//
+	// hcl: runtime { gomaxprocs = int }
+	RuntimeGOMAXPROCS int
+
+	// RuntimeMemoryLimit sets a soft memory limit in bytes for the agent
+	// process. Zero leaves the Go runtime default (or the GOMEMLIMIT
+	// environment variable) in place.
+	//
+	// hcl: runtime { memory_limit = int }
+	RuntimeMemoryLimit int64
+
+	// SanitizeRedactKeys are additional field names that Sanitized()
+	// redacts on top of the built-in key/token/secret rules. Names are
+	// matched case-insensitively against the field names of the sanitized
+	// output.
+	//
+	// hcl: sanitize { redact_keys = []string }
+	SanitizeRedactKeys []string
+
+	// SanitizeRedactNone disables all redaction in Sanitized() so that
+	// secrets appear in clear text. This is an explicit opt-in for
+	// debugging in air-gapped environments.
+	//
+	// hcl: sanitize { redact_none = (true|false) }
+	SanitizeRedactNone bool
+
 	// SegmentName is the network segment for this client to join.
 	// (Enterprise-only)
 	//
@@ -1324,6 +1843,16 @@ type RuntimeConfig struct {
 	// hcl: ports { server = int }
 	ServerPort int
 
+	// ServersFile is the path of a file the agent keeps updated with the
+	// RPC addresses of the known servers. Its content is used to rejoin
+	// the cluster at startup, so clients can reconnect after long outages
+	// even when the persisted gossip state has been lost. The file may be
+	// seeded by the operator before the first start. A relative path is
+	// resolved below the data directory.
+	//
+	// hcl: servers_file = string
+	ServersFile string
+
 	// Services contains the provided service definitions:
 	//
 	// hcl: services = [
@@ -1353,6 +1882,14 @@ type RuntimeConfig struct {
 	// hcl: skip_leave_on_interrupt = (true|false)
 	SkipLeaveOnInt bool
 
+	// StartDrained controls whether the agent places its node into
+	// maintenance mode on startup. The node stays drained until it is
+	// explicitly cleared via `consul maint -disable` or the API, which is
+	// useful for pre-warm provisioning.
+	//
+	// hcl: start_drained = (true|false)
+	StartDrained bool
+
 	// StartJoinAddrsLAN is a list of addresses to attempt to join -lan when the
 	// agent starts. If Serf is unable to communicate with any of these
 	// addresses, then the agent will error and exit.
@@ -1369,6 +1906,23 @@ type RuntimeConfig struct {
 	// flag: -join-wan string -join-wan string
 	StartJoinAddrsWAN []string
 
+	// StrictMode promotes config warnings (deprecated keys, skipped files,
+	// security warnings) to errors so that clean configs can be enforced
+	// before the offending keys are removed.
+	//
+	// hcl: strict_mode = (true|false)
+	// flag: -strict
+	StrictMode bool
+
+	// TLSBootstrapMode is an umbrella over the auto_config and auto_encrypt
+	// sub-blocks so a typical agent only needs a single key to pick how its
+	// TLS material is provisioned. One of "manual", "auto_config" or
+	// "auto_encrypt". Explicitly configured legacy keys win over this and
+	// produce a migration warning.
+	//
+	// hcl: tls_bootstrap_mode = string
+	TLSBootstrapMode string
+
 	// TLSCipherSuites is used to specify the list of supported ciphersuites.
 	//
 	// The values should be a list of the following values:
@@ -1391,6 +1945,51 @@ type RuntimeConfig struct {
 	// hcl: tls_cipher_suites = []string
 	TLSCipherSuites []uint16
 
+	// TLSChecks contains TLS settings applied on top of the agent-wide
+	// configuration when agent checks call HTTPS endpoints and
+	// enable_agent_tls_for_checks is set. It is resolved from the named
+	// profile referenced by tls.checks_profile.
+	//
+	// hcl: tls { checks_profile = string }
+	TLSChecks tlsutil.ProtocolConfig
+
+	// TLSGRPC contains TLS settings that apply only to the gRPC (xDS)
+	// listeners, resolved from the tls { grpc { ... } } block. Zero values
+	// fall back to the agent-wide TLS configuration.
+	//
+	// hcl: tls { grpc { ... } }
+	TLSGRPC tlsutil.ProtocolConfig
+
+	// TLSHTTPS contains TLS settings that apply only to the HTTPS API
+	// listeners, resolved from the tls { https { ... } } block. Zero values
+	// fall back to the agent-wide TLS configuration.
+	//
+	// hcl: tls { https { ... } }
+	TLSHTTPS tlsutil.ProtocolConfig
+
+	// TLSInternalRPC contains TLS settings that apply only to the server
+	// RPC listener and outgoing server RPC connections, resolved from the
+	// tls { internal_rpc { ... } } block. Zero values fall back to the
+	// agent-wide TLS configuration.
+	//
+	// hcl: tls { internal_rpc { ... } }
+	TLSInternalRPC tlsutil.ProtocolConfig
+
+	// TLSWAN contains TLS settings that apply only to cross-datacenter
+	// connections, resolved from the tls { wan { ... } } block. It allows
+	// the WAN trust domain to be backed by a different PKI than the LAN.
+	// Zero values fall back to the agent-wide TLS configuration.
+	//
+	// hcl: tls { wan { ... } }
+	TLSWAN tlsutil.ProtocolConfig
+
+	// TLSWatches overrides the client TLS material watch plans use when
+	// they call back into the agent over HTTPS. It is resolved from the
+	// named profile referenced by tls.watches_profile.
+	//
+	// hcl: tls { watches_profile = string }
+	TLSWatches tlsutil.ProtocolConfig
+
 	// TLSMinVersion is used to set the minimum TLS version used for TLS
 	// connections. Should be either "tls10", "tls11", "tls12" or "tls13".
 	// Defaults to tls12.
@@ -1458,6 +2057,45 @@ type RuntimeConfig struct {
 	// hcl: unix_sockets { user = string }
 	UnixSocketUser string
 
+	// UnixSocketGRPC, UnixSocketHTTP and UnixSocketHTTPS contain the resolved
+	// ownership and mode for the sockets of a single listener. Each field
+	// starts from the agent-wide unix_sockets values and is then overridden by
+	// the matching sub-stanza, so the HTTP socket can for example be
+	// group-readable while the gRPC socket stays locked down.
+	//
+	// hcl: unix_sockets { grpc|http|https { user = string, group = string, mode = string } }
+	UnixSocketGRPC  UnixSocketPermissions
+	UnixSocketHTTP  UnixSocketPermissions
+	UnixSocketHTTPS UnixSocketPermissions
+
+	// UnknownKeys controls how config keys that are not understood by
+	// this agent are handled. "error" refuses to start, "warn" records a
+	// warning for each unknown key and "ignore" silently drops them. The
+	// default is "error". Relaxing this lets rolling deploys ship config
+	// for newer agents without crash-looping older ones.
+	//
+	// hcl: unknown_keys = "error|warn|ignore"
+	UnknownKeys string
+
+	// UsageReportingEnabled opts the agent in to periodically reporting
+	// which major features are enabled to UsageReportingEndpoint. Nothing
+	// is reported when it is false.
+	//
+	// hcl: usage_reporting { enabled = (true|false) }
+	UsageReportingEnabled bool
+
+	// UsageReportingEndpoint is the URL the feature usage reports are
+	// POSTed to. Required when usage reporting is enabled.
+	//
+	// hcl: usage_reporting { endpoint = string }
+	UsageReportingEndpoint string
+
+	// UsageReportingInterval is how often a usage report is sent. It must
+	// be at least one minute and defaults to one hour.
+	//
+	// hcl: usage_reporting { interval = "duration" }
+	UsageReportingInterval time.Duration
+
 	// VerifyIncoming is used to verify the authenticity of incoming
 	// connections. This means that TCP requests are forbidden, only allowing
 	// for TLS. TLS connections must match a provided certificate authority.
@@ -1474,6 +2112,15 @@ type RuntimeConfig struct {
 	// hcl: verify_incoming_https = (true|false)
 	VerifyIncomingHTTPS bool
 
+	// VerifyIncomingHTTPSExemptCIDRs lists networks whose HTTPS clients are
+	// not required to present a client certificate even when verify_incoming
+	// or verify_incoming_https is enabled. This keeps local tooling such as a
+	// localhost-only metrics scraper or health checker working while mTLS is
+	// rolled out.
+	//
+	// hcl: verify_incoming_https_exempt_cidrs = []string
+	VerifyIncomingHTTPSExemptCIDRs []*net.IPNet
+
 	// VerifyIncomingRPC is used to verify the authenticity of incoming RPC
 	// connections. This means that TCP requests are forbidden, only allowing
 	// for TLS. TLS connections must match a provided certificate authority.
@@ -1515,6 +2162,24 @@ type RuntimeConfig struct {
 	//
 	Watches []map[string]interface{}
 
+	// XDSEnableDelta switches proxies that support it to the incremental
+	// (delta) xDS protocol instead of full state-of-the-world updates.
+	//
+	// hcl: xds { enable_delta = (true|false) }
+	XDSEnableDelta bool
+
+	// XDSMaxConcurrentStreams limits how many concurrent xDS streams the
+	// gRPC server accepts. Zero means no limit.
+	//
+	// hcl: xds { max_concurrent_streams = int }
+	XDSMaxConcurrentStreams int
+
+	// XDSNackBackoff is how long the xDS server waits before re-sending a
+	// resource that a proxy rejected with a NACK.
+	//
+	// hcl: xds { nack_backoff = "duration" }
+	XDSNackBackoff time.Duration
+
 	EnterpriseRuntimeConfig
 }
 
@@ -1536,6 +2201,32 @@ type AutoConfigAuthorizer struct {
 	AllowReuse      bool
 }
 
+// AutoConfigStatus defines the subset of the agent's auto configuration state
+// that is safe to expose over the HTTP API when expose_auto_config_status is
+// enabled. It must never carry secret material: the intro token is reduced to
+// its expiry claim, the TLS client certificate to its validity window and the
+// authorizer to the type of its auth method.
+type AutoConfigStatus struct {
+	// Enabled indicates whether agent auto configuration is enabled.
+	Enabled bool
+
+	// IntroTokenExpiry is the expiry claim of the configured intro token. It
+	// is zero when no intro token is configured or the token has no expiry.
+	IntroTokenExpiry time.Time
+
+	// CertificateNotBefore and CertificateNotAfter are the validity window of
+	// the TLS client certificate obtained through auto_config or auto_encrypt.
+	// Both are zero when the agent holds no such certificate. The certificate
+	// is renewed automatically before CertificateNotAfter.
+	CertificateNotBefore time.Time
+	CertificateNotAfter  time.Time
+
+	// AuthorizerAuthMethod is the type of the auth method the auto_config
+	// authorizer uses to validate requests. It is only set on servers that
+	// have the authorizer enabled.
+	AuthorizerAuthMethod string
+}
+
 type UIConfig struct {
 	Enabled                    bool
 	Dir                        string
@@ -1558,6 +2249,61 @@ type UIMetricsProxyAddHeader struct {
 	Value string
 }
 
+// UnixSocketPermissions is the resolved ownership and mode applied to the
+// unix sockets of one listener. Empty fields leave the OS defaults in place.
+type UnixSocketPermissions struct {
+	Group string
+	Mode  string
+	User  string
+}
+
+// PortRange is an inclusive range of ports.
+type PortRange struct {
+	From int
+	To   int
+}
+
+func (r PortRange) Contains(port int) bool {
+	return port >= r.From && port <= r.To
+}
+
+func (r PortRange) String() string {
+	if r.From == r.To {
+		return strconv.Itoa(r.From)
+	}
+	return fmt.Sprintf("%d-%d", r.From, r.To)
+}
+
+// IsReservedPort returns true when port falls into one of the configured
+// limits.reserved_ports ranges or collides with one of the agent's own
+// listener ports, which are always reserved.
+func (c *RuntimeConfig) IsReservedPort(port int) bool {
+	if port <= 0 {
+		return false
+	}
+	for _, r := range c.ReservedPorts {
+		if r.Contains(port) {
+			return true
+		}
+	}
+	for _, p := range []int{c.DNSPort, c.DNSTLSPort, c.HTTPPort, c.HTTPSPort, c.GRPCPort, c.GRPCTLSPort, c.SerfPortLAN, c.SerfPortWAN, c.ServerPort} {
+		if port == p {
+			return true
+		}
+	}
+	return false
+}
+
+// ResolveDatacenter maps a datacenter alias from datacenter_aliases to
+// the datacenter it stands for. Names without an alias are returned
+// unchanged.
+func (c *RuntimeConfig) ResolveDatacenter(dc string) string {
+	if real, ok := c.DatacenterAliases[strings.ToLower(dc)]; ok {
+		return real
+	}
+	return dc
+}
+
 func (c *RuntimeConfig) apiAddresses(maxPerType int) (unixAddrs, httpAddrs, httpsAddrs []string) {
 	if len(c.HTTPSAddrs) > 0 {
 		for i, addr := range c.HTTPSAddrs {
@@ -1743,36 +2489,80 @@ func (c *RuntimeConfig) APIConfig(includeClientCerts bool) (*api.Config, error)
 // values replaced by 'hidden'. In addition, network addresses and
 // time.Duration values are formatted to improve readability.
 func (c *RuntimeConfig) Sanitized() map[string]interface{} {
-	return sanitize("rt", reflect.ValueOf(c)).Interface().(map[string]interface{})
+	s := newSanitizer(c.SanitizeRedactKeys, c.SanitizeRedactNone)
+	return s.sanitize("rt", reflect.ValueOf(c)).Interface().(map[string]interface{})
+}
+
+// SanitizedHCL renders the sanitized runtime configuration as HCL. The
+// sanitized map is marshaled to JSON and re-parsed with the HCL json parser
+// so that the HCL printer can format it, which guarantees the output is
+// valid HCL syntax. JSON nulls have no HCL equivalent and are dropped.
+func (c *RuntimeConfig) SanitizedHCL() (string, error) {
+	b, err := json.Marshal(c.Sanitized())
+	if err != nil {
+		return "", fmt.Errorf("Failed to encode sanitized config: %v", err)
+	}
+
+	// Round-trip through a plain map so that typed nil values have become
+	// JSON nulls which stripNulls can remove.
+	var m map[string]interface{}
+	if err := json.Unmarshal(b, &m); err != nil {
+		return "", fmt.Errorf("Failed to decode sanitized config: %v", err)
+	}
+	b, err = json.Marshal(stripNulls(m))
+	if err != nil {
+		return "", fmt.Errorf("Failed to encode sanitized config: %v", err)
+	}
+
+	f, err := jsonparser.Parse(b)
+	if err != nil {
+		return "", fmt.Errorf("Failed to parse sanitized config: %v", err)
+	}
+	var buf bytes.Buffer
+	if err := hclprinter.Fprint(&buf, f); err != nil {
+		return "", fmt.Errorf("Failed to print sanitized config: %v", err)
+	}
+	return buf.String(), nil
 }
 
 func (c *RuntimeConfig) ToTLSUtilConfig() tlsutil.Config {
 	return tlsutil.Config{
-		VerifyIncoming:           c.VerifyIncoming,
-		VerifyIncomingRPC:        c.VerifyIncomingRPC,
-		VerifyIncomingHTTPS:      c.VerifyIncomingHTTPS,
-		VerifyOutgoing:           c.VerifyOutgoing,
-		VerifyServerHostname:     c.VerifyServerHostname,
-		CAFile:                   c.CAFile,
-		CAPath:                   c.CAPath,
-		CertFile:                 c.CertFile,
-		KeyFile:                  c.KeyFile,
-		NodeName:                 c.NodeName,
-		Domain:                   c.DNSDomain,
-		ServerName:               c.ServerName,
-		TLSMinVersion:            c.TLSMinVersion,
-		CipherSuites:             c.TLSCipherSuites,
-		PreferServerCipherSuites: c.TLSPreferServerCipherSuites,
-		EnableAgentTLSForChecks:  c.EnableAgentTLSForChecks,
-		AutoTLS:                  c.AutoEncryptTLS || c.AutoConfig.Enabled,
+		VerifyIncoming:                 c.VerifyIncoming,
+		VerifyIncomingRPC:              c.VerifyIncomingRPC,
+		VerifyIncomingHTTPS:            c.VerifyIncomingHTTPS,
+		VerifyIncomingHTTPSExemptCIDRs: c.VerifyIncomingHTTPSExemptCIDRs,
+		VerifyOutgoing:                 c.VerifyOutgoing,
+		VerifyServerHostname:           c.VerifyServerHostname,
+		CAFile:                         c.CAFile,
+		CAFileContents:                 c.CAFileContents,
+		CAPath:                         c.CAPath,
+		CertFile:                       c.CertFile,
+		CertFileContents:               c.CertFileContents,
+		KeyFile:                        c.KeyFile,
+		KeyFileContents:                c.KeyFileContents,
+		NodeName:                       c.NodeName,
+		Domain:                         c.DNSDomain,
+		ServerName:                     c.ServerName,
+		TLSMinVersion:                  c.TLSMinVersion,
+		CipherSuites:                   c.TLSCipherSuites,
+		PreferServerCipherSuites:       c.TLSPreferServerCipherSuites,
+		EnableAgentTLSForChecks:        c.EnableAgentTLSForChecks,
+		AutoTLS:                        c.AutoEncryptTLS || c.AutoConfig.Enabled,
+		HTTPS:                          c.TLSHTTPS,
+		InternalRPC:                    c.TLSInternalRPC,
+		GRPC:                           c.TLSGRPC,
+		WAN:                            c.TLSWAN,
+		Checks:                         c.TLSChecks,
+		Datacenter:                     c.Datacenter,
 	}
 }
 
 // isSecret determines whether a field name represents a field which
 // may contain a secret.
 func isSecret(name string) bool {
-	// special cases for AuthMethod locality and intro token file
-	if name == "TokenLocality" || name == "IntroTokenFile" {
+	// special cases for AuthMethod locality, intro token file and the
+	// unknown_keys policy which merely talk about keys and tokens
+	if name == "TokenLocality" || name == "IntroTokenFile" || name == "UnknownKeys" {
 		return false
 	}
 	name = strings.ToLower(name)
@@ -1794,7 +2584,63 @@ func cleanRetryJoin(a string) string {
 	return strings.Join(fields, " ")
 }
 
-func sanitize(name string, v reflect.Value) reflect.Value {
+// stripNulls removes nil values from the sanitized map so that the result
+// can be rendered as HCL, which has no null literal.
+func stripNulls(m map[string]interface{}) map[string]interface{} {
+	r := make(map[string]interface{}, len(m))
+	for k, v := range m {
+		switch x := v.(type) {
+		case nil:
+			continue
+		case map[string]interface{}:
+			r[k] = stripNulls(x)
+		case []interface{}:
+			l := make([]interface{}, 0, len(x))
+			for _, e := range x {
+				if em, ok := e.(map[string]interface{}); ok {
+					e = stripNulls(em)
+				}
+				if e != nil {
+					l = append(l, e)
+				}
+			}
+			r[k] = l
+		default:
+			r[k] = v
+		}
+	}
+	return r
+}
+
+// sanitizer applies the redaction rules of the sanitize config stanza while
+// walking the runtime configuration.
+type sanitizer struct {
+	// redactKeys are additional field names to redact, lowercased.
+	redactKeys map[string]bool
+
+	// redactNone disables all redaction when set.
+	redactNone bool
+}
+
+func newSanitizer(redactKeys []string, redactNone bool) *sanitizer {
+	keys := make(map[string]bool, len(redactKeys))
+	for _, k := range redactKeys {
+		keys[strings.ToLower(k)] = true
+	}
+	return &sanitizer{redactKeys: keys, redactNone: redactNone}
+}
+
+func (s *sanitizer) isSecret(name string) bool {
+	if s.redactNone {
+		return false
+	}
+	if s.redactKeys[strings.ToLower(name)] {
+		return true
+	}
+	return isSecret(name)
+}
+
+func (s *sanitizer) sanitize(name string, v reflect.Value) reflect.Value {
 	typ := v.Type()
 	switch {
 	// check before isStruct and isPtr
@@ -1822,12 +2668,17 @@ func sanitize(name string, v reflect.Value) reflect.Value {
 		x := v.Interface().(time.Duration)
 		return reflect.ValueOf(x.String())
 
+	// check before isStruct
+	case isTime(typ):
+		x := v.Interface().(time.Time)
+		return reflect.ValueOf(x.Format(time.RFC3339Nano))
+
 	case isString(typ):
-		if strings.HasPrefix(name, "RetryJoinLAN[") || strings.HasPrefix(name, "RetryJoinWAN[") {
+		if !s.redactNone && (strings.HasPrefix(name, "RetryJoinLAN[") || strings.HasPrefix(name, "RetryJoinWAN[")) {
 			x := v.Interface().(string)
 			return reflect.ValueOf(cleanRetryJoin(x))
 		}
-		if isSecret(name) {
+		if s.isSecret(name) {
 			return reflect.ValueOf("hidden")
 		}
 		return v
@@ -1839,13 +2690,13 @@ func sanitize(name string, v reflect.Value) reflect.Value {
 		if v.IsNil() {
 			return v
 		}
-		return sanitize(name, v.Elem())
+		return s.sanitize(name, v.Elem())
 
 	case isStruct(typ):
 		m := map[string]interface{}{}
 		for i := 0; i < typ.NumField(); i++ {
 			key := typ.Field(i).Name
-			m[key] = sanitize(key, v.Field(i)).Interface()
+			m[key] = s.sanitize(key, v.Field(i)).Interface()
 		}
 		return reflect.ValueOf(m)
 
@@ -1857,9 +2708,13 @@ func sanitize(name string, v reflect.Value) reflect.Value {
 			for i := 0; i < v.Len(); i++ {
 				addr := v.Index(i).Addr()
 				hdr := addr.Interface().(*UIMetricsProxyAddHeader)
+				value := "hidden"
+				if s.redactNone {
+					value = hdr.Value
+				}
 				hm := map[string]interface{}{
 					"Name":  hdr.Name,
-					"Value": "hidden",
+					"Value": value,
 				}
 				ma = append(ma, hm)
 			}
@@ -1875,7 +2730,7 @@ func sanitize(name string, v reflect.Value) reflect.Value {
 			return reflect.ValueOf(ma)
 		}
 		for i := 0; i < v.Len(); i++ {
-			ma = append(ma, sanitize(fmt.Sprintf("%s[%d]", name, i), v.Index(i)).Interface())
+			ma = append(ma, s.sanitize(fmt.Sprintf("%s[%d]", name, i), v.Index(i)).Interface())
 		}
 		return reflect.ValueOf(ma)
 
@@ -1883,7 +2738,7 @@ func sanitize(name string, v reflect.Value) reflect.Value {
 		m := map[string]interface{}{}
 		for _, k := range v.MapKeys() {
 			key := k.String()
-			m[key] = sanitize(key, v.MapIndex(k)).Interface()
+			m[key] = s.sanitize(key, v.MapIndex(k)).Interface()
 		}
 		return reflect.ValueOf(m)
 
@@ -1893,6 +2748,7 @@ func sanitize(name string, v reflect.Value) reflect.Value {
 }
 
 func isDuration(t reflect.Type) bool { return t == reflect.TypeOf(time.Second) }
+func isTime(t reflect.Type) bool     { return t == reflect.TypeOf(time.Time{}) }
 func isMap(t reflect.Type) bool      { return t.Kind() == reflect.Map }
 func isNetAddr(t reflect.Type) bool  { return t.Implements(reflect.TypeOf((*net.Addr)(nil)).Elem()) }
 func isPtr(t reflect.Type) bool      { return t.Kind() == reflect.Ptr }
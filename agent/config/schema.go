@@ -0,0 +1,148 @@
+package config
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+)
+
+// deprecatedConfigKeys are top-level config keys that the agent still accepts
+// but warns about. They are kept in the generated schema so that validators
+// can accept old config files, but are marked as deprecated.
+var deprecatedConfigKeys = map[string]string{
+	"acl_enforce_version_8": "This key is deprecated and should be removed.",
+	"acl_datacenter":        "Deprecated, use the 'primary_datacenter' field instead.",
+	"ui":                    "Deprecated, use the 'ui_config.enabled' field instead.",
+	"ui_dir":                "Deprecated, use the 'ui_config.dir' field instead.",
+	"ui_content_path":       "Deprecated, use the 'ui_config.content_path' field instead.",
+}
+
+// JSONSchema returns a JSON Schema (draft-07) document describing the agent
+// configuration file format. The schema is generated from the Config struct
+// with reflection so that it cannot drift from the set of keys the agent
+// actually accepts. Deprecated aliases are included but flagged so that
+// validators can warn about them without rejecting old config files.
+func JSONSchema() ([]byte, error) {
+	g := &schemaGenerator{definitions: map[string]map[string]interface{}{}}
+
+	root := g.structSchema(reflect.TypeOf(Config{}))
+	for key, note := range deprecatedConfigKeys {
+		props := root["properties"].(map[string]interface{})
+		if prop, ok := props[key].(map[string]interface{}); ok {
+			prop["deprecated"] = true
+			prop["description"] = note
+		}
+	}
+
+	schema := map[string]interface{}{
+		"$schema": "http://json-schema.org/draft-07/schema#",
+		"title":   "Consul agent configuration",
+	}
+	for k, v := range root {
+		schema[k] = v
+	}
+	if len(g.definitions) > 0 {
+		schema["definitions"] = g.definitions
+	}
+	return json.MarshalIndent(schema, "", "    ")
+}
+
+type schemaGenerator struct {
+	definitions map[string]map[string]interface{}
+}
+
+// schemaFor returns the schema fragment for t. Named struct types are stored
+// under definitions and referenced with $ref so that recursive types such as
+// ServiceDefinition do not send the generator into an infinite loop.
+func (g *schemaGenerator) schemaFor(t reflect.Type) map[string]interface{} {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		if t.Name() == "" {
+			return g.structSchema(t)
+		}
+		if _, ok := g.definitions[t.Name()]; !ok {
+			// reserve the slot before recursing to break cycles
+			g.definitions[t.Name()] = nil
+			g.definitions[t.Name()] = g.structSchema(t)
+		}
+		return map[string]interface{}{"$ref": "#/definitions/" + t.Name()}
+	case reflect.Slice, reflect.Array:
+		return map[string]interface{}{
+			"type":  "array",
+			"items": g.schemaFor(t.Elem()),
+		}
+	case reflect.Map:
+		s := map[string]interface{}{"type": "object"}
+		if t.Elem().Kind() != reflect.Interface {
+			s["additionalProperties"] = g.schemaFor(t.Elem())
+		}
+		return s
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	default:
+		// interface{} and anything else: any value is allowed
+		return map[string]interface{}{}
+	}
+}
+
+func (g *schemaGenerator) structSchema(t reflect.Type) map[string]interface{} {
+	properties := map[string]interface{}{}
+	g.addFields(t, properties)
+	return map[string]interface{}{
+		"type":       "object",
+		"properties": properties,
+	}
+}
+
+func (g *schemaGenerator) addFields(t reflect.Type, properties map[string]interface{}) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		key, squash := fieldKey(f)
+		if squash || (f.Anonymous && key == "") {
+			// embedded struct whose fields are inlined by mapstructure
+			ft := f.Type
+			for ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				g.addFields(ft, properties)
+			}
+			continue
+		}
+		if key == "" || key == "-" {
+			continue
+		}
+		properties[key] = g.schemaFor(f.Type)
+	}
+}
+
+// fieldKey returns the config file key for a struct field. The config
+// decoder uses the mapstructure tags so those take precedence, with the json
+// tag as a fallback for fields that only declare one.
+func fieldKey(f reflect.StructField) (key string, squash bool) {
+	tag, ok := f.Tag.Lookup("mapstructure")
+	if !ok {
+		tag, ok = f.Tag.Lookup("json")
+	}
+	if !ok {
+		return "", false
+	}
+	parts := strings.Split(tag, ",")
+	for _, p := range parts[1:] {
+		if p == "squash" {
+			squash = true
+		}
+	}
+	return parts[0], squash
+}
@@ -0,0 +1,37 @@
+package config
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestJSONSchema(t *testing.T) {
+	b, err := JSONSchema()
+	require.NoError(t, err)
+
+	var schema struct {
+		Schema     string                            `json:"$schema"`
+		Type       string                            `json:"type"`
+		Properties map[string]map[string]interface{} `json:"properties"`
+		Definitions map[string]struct {
+			Properties map[string]interface{} `json:"properties"`
+		} `json:"definitions"`
+	}
+	require.NoError(t, json.Unmarshal(b, &schema))
+	require.Equal(t, "object", schema.Type)
+
+	// spot check some simple properties
+	require.Equal(t, map[string]interface{}{"type": "string"}, map[string]interface{}(schema.Properties["node_name"]))
+	require.Equal(t, map[string]interface{}{"type": "boolean"}, map[string]interface{}(schema.Properties["server"]))
+
+	// deprecated aliases are included but marked
+	require.Equal(t, true, schema.Properties["acl_datacenter"]["deprecated"])
+
+	// recursive types must be emitted as definitions instead of looping
+	// forever: sidecar_service references the service definition again
+	def, ok := schema.Definitions["ServiceConnect"]
+	require.True(t, ok)
+	require.Contains(t, def.Properties, "sidecar_service")
+}
@@ -0,0 +1,126 @@
+package config
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+	texttemplate "text/template"
+	"time"
+
+	"github.com/hashicorp/go-sockaddr"
+	"github.com/hashicorp/go-sockaddr/template"
+	"github.com/hashicorp/go-uuid"
+)
+
+// configTemplateFuncs are helper functions available in templated config
+// values in addition to the go-sockaddr template functions. They reduce
+// entrypoint-script gymnastics in containers where values like node names or
+// secrets come from files or the environment.
+var configTemplateFuncs = texttemplate.FuncMap{
+	// file returns the contents of the file at path with surrounding
+	// whitespace trimmed.
+	"file": func(path string) (string, error) {
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return "", fmt.Errorf("failed to read %s: %s", path, err)
+		}
+		return strings.TrimSpace(string(data)), nil
+	},
+
+	// env returns the value of the environment variable.
+	"env": os.Getenv,
+
+	// uuidv4 returns a random version 4 UUID.
+	"uuidv4": func() (string, error) {
+		return uuid.GenerateUUID()
+	},
+
+	// ec2, gce and azure read a value from the cloud instance metadata
+	// service, e.g. {{ ec2 "instance-id" }} or {{ gce "hostname" }}. An
+	// optional second argument is used as a fallback when the metadata
+	// service cannot be reached, so that configs stay portable across
+	// environments: {{ ec2 "instance-id" "dev-node" }}.
+	"ec2": func(path string, fallback ...string) (string, error) {
+		return fetchCloudMetadata(ec2MetadataBaseURL+path, nil, fallback)
+	},
+	"gce": func(path string, fallback ...string) (string, error) {
+		return fetchCloudMetadata(gceMetadataBaseURL+path, map[string]string{"Metadata-Flavor": "Google"}, fallback)
+	},
+	"azure": func(path string, fallback ...string) (string, error) {
+		return fetchCloudMetadata(azureMetadataBaseURL+path+"?api-version=2021-02-01&format=text", map[string]string{"Metadata": "true"}, fallback)
+	},
+}
+
+// Cloud instance metadata endpoints. These are variables so that tests can
+// point them at a stub server.
+var (
+	ec2MetadataBaseURL   = "http://169.254.169.254/latest/meta-data/"
+	gceMetadataBaseURL   = "http://169.254.169.254/computeMetadata/v1/instance/"
+	azureMetadataBaseURL = "http://169.254.169.254/metadata/instance/"
+)
+
+// cloudMetadataTimeout bounds how long a config load waits for the instance
+// metadata service before giving up and using the fallback.
+const cloudMetadataTimeout = 2 * time.Second
+
+// fetchCloudMetadata reads a value from a cloud instance metadata service.
+// When the service cannot be reached or returns an error the fallback is
+// used if one was given, otherwise the error is returned.
+func fetchCloudMetadata(url string, headers map[string]string, fallback []string) (string, error) {
+	fail := func(err error) (string, error) {
+		if len(fallback) > 0 {
+			return fallback[0], nil
+		}
+		return "", err
+	}
+
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return fail(err)
+	}
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	client := &http.Client{Timeout: cloudMetadataTimeout}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fail(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fail(fmt.Errorf("metadata service returned status %d for %s", resp.StatusCode, url))
+	}
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return fail(err)
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+// validateTemplate checks that s is a syntactically valid go-sockaddr
+// template without executing it. It is used for values like service
+// addresses which are not resolved until the service is registered.
+func validateTemplate(s string) error {
+	_, err := texttemplate.New("config.Validate").
+		Funcs(template.SourceFuncs).
+		Funcs(template.SortFuncs).
+		Funcs(template.FilterFuncs).
+		Funcs(template.HelperFuncs).
+		Parse(s)
+	return err
+}
+
+// parseTemplate evaluates the template in s with the go-sockaddr template
+// functions and the additional config helper functions and returns the
+// result.
+func parseTemplate(s string) (string, error) {
+	addrs, err := sockaddr.GetAllInterfaces()
+	if err != nil {
+		return "", fmt.Errorf("unable to query interface addresses: %s", err)
+	}
+	t := texttemplate.New("config.Parse").Funcs(configTemplateFuncs)
+	return template.ParseIfAddrsTemplate(s, addrs, t)
+}
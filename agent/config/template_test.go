@@ -0,0 +1,103 @@
+package config
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTemplate_File(t *testing.T) {
+	path, err := ioutil.TempDir("", t.Name())
+	require.NoError(t, err)
+	t.Cleanup(func() { os.RemoveAll(path) })
+
+	name := filepath.Join(path, "node-name")
+	require.NoError(t, ioutil.WriteFile(name, []byte("hobbiton\n"), 0644))
+
+	out, err := parseTemplate(`{{ file "` + name + `" }}`)
+	require.NoError(t, err)
+	require.Equal(t, "hobbiton", out)
+
+	_, err = parseTemplate(`{{ file "` + filepath.Join(path, "nope") + `" }}`)
+	require.Error(t, err)
+}
+
+func TestParseTemplate_Env(t *testing.T) {
+	os.Setenv("CONSUL_TEST_TEMPLATE_ENV", "bree")
+	t.Cleanup(func() { os.Unsetenv("CONSUL_TEST_TEMPLATE_ENV") })
+
+	out, err := parseTemplate(`{{ env "CONSUL_TEST_TEMPLATE_ENV" }}`)
+	require.NoError(t, err)
+	require.Equal(t, "bree", out)
+}
+
+func TestParseTemplate_UUIDv4(t *testing.T) {
+	out, err := parseTemplate(`{{ uuidv4 }}`)
+	require.NoError(t, err)
+	require.Regexp(t, regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}$`), out)
+}
+
+func TestParseTemplate_CloudMetadata(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/latest/meta-data/instance-id":
+			fmt.Fprintln(w, "i-0123456789abcdef0")
+		case r.URL.Path == "/computeMetadata/v1/instance/hostname" && r.Header.Get("Metadata-Flavor") == "Google":
+			fmt.Fprintln(w, "gce-node-1")
+		case r.URL.Path == "/metadata/instance/compute/name" && r.Header.Get("Metadata") == "true":
+			fmt.Fprintln(w, "azure-node-1")
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	restore := func(ec2, gce, azure string) {
+		ec2MetadataBaseURL = ec2
+		gceMetadataBaseURL = gce
+		azureMetadataBaseURL = azure
+	}
+	defer restore(ec2MetadataBaseURL, gceMetadataBaseURL, azureMetadataBaseURL)
+	restore(srv.URL+"/latest/meta-data/", srv.URL+"/computeMetadata/v1/instance/", srv.URL+"/metadata/instance/")
+
+	out, err := parseTemplate(`{{ ec2 "instance-id" }}`)
+	require.NoError(t, err)
+	require.Equal(t, "i-0123456789abcdef0", out)
+
+	out, err = parseTemplate(`{{ gce "hostname" }}`)
+	require.NoError(t, err)
+	require.Equal(t, "gce-node-1", out)
+
+	out, err = parseTemplate(`{{ azure "compute/name" }}`)
+	require.NoError(t, err)
+	require.Equal(t, "azure-node-1", out)
+
+	// unknown paths fall back when a fallback is given and error otherwise
+	out, err = parseTemplate(`{{ ec2 "nope" "dev-node" }}`)
+	require.NoError(t, err)
+	require.Equal(t, "dev-node", out)
+
+	_, err = parseTemplate(`{{ ec2 "nope" }}`)
+	require.Error(t, err)
+}
+
+func TestLoad_TemplatedNodeMeta(t *testing.T) {
+	os.Setenv("CONSUL_TEST_TEMPLATE_RACK", "r42")
+	t.Cleanup(func() { os.Unsetenv("CONSUL_TEST_TEMPLATE_RACK") })
+
+	rt := buildWithSource(t, `node_meta { rack = "{{ env \"CONSUL_TEST_TEMPLATE_RACK\" }}" }`)
+	require.Equal(t, "r42", rt.NodeMeta["rack"])
+}
+
+func TestValidateTemplate(t *testing.T) {
+	require.NoError(t, validateTemplate(`{{ GetInterfaceIP "eth1" }}`))
+	require.Error(t, validateTemplate(`{{ GetInterfaceIP "eth1" }`))
+	require.Error(t, validateTemplate(`{{ NoSuchFunc }}`))
+}
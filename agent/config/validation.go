@@ -0,0 +1,62 @@
+package config
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/go-multierror"
+)
+
+// ValidationError describes a single violated configuration constraint in a
+// form that automation can consume: the config key that failed, the
+// constraint it violated and, where it helps, the value that was provided.
+// It renders to the same flat message the Builder has always returned so
+// callers that only look at Error() are unaffected.
+type ValidationError struct {
+	// Field is the config key the constraint applies to, e.g. "data_dir"
+	// or "cache.entry_fetch_rate". It is empty for errors that do not
+	// concern a single key.
+	Field string `json:"field,omitempty"`
+
+	// Constraint describes the requirement that was not met, e.g.
+	// "cannot be empty". For errors that have not been converted to this
+	// type it carries the complete error message.
+	Constraint string `json:"constraint"`
+
+	// Value is the offending value, if it adds information beyond the
+	// constraint itself.
+	Value interface{} `json:"value,omitempty"`
+}
+
+func (e ValidationError) Error() string {
+	msg := e.Constraint
+	if e.Field != "" {
+		msg = e.Field + " " + msg
+	}
+	if e.Value != nil {
+		msg = fmt.Sprintf("%s, was: %v", msg, e.Value)
+	}
+	return msg
+}
+
+// ValidationErrors unpacks the error returned by Build or BuildAndValidate
+// into a list of ValidationError values. Errors that are not already typed
+// are returned with their message in the Constraint field so that the list
+// always covers everything the flat error would have reported.
+func ValidationErrors(err error) []ValidationError {
+	if err == nil {
+		return nil
+	}
+	errs := []error{err}
+	if merr, ok := err.(*multierror.Error); ok {
+		errs = merr.Errors
+	}
+	var out []ValidationError
+	for _, err := range errs {
+		if ve, ok := err.(ValidationError); ok {
+			out = append(out, ve)
+			continue
+		}
+		out = append(out, ValidationError{Constraint: err.Error()})
+	}
+	return out
+}
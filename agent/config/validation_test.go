@@ -0,0 +1,69 @@
+package config
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/hashicorp/go-multierror"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidationError_Error(t *testing.T) {
+	tests := []struct {
+		desc string
+		err  ValidationError
+		want string
+	}{
+		{
+			desc: "field and constraint",
+			err:  ValidationError{Field: "data_dir", Constraint: "cannot be empty"},
+			want: "data_dir cannot be empty",
+		},
+		{
+			desc: "field, constraint and value",
+			err:  ValidationError{Field: "cache.entry_fetch_rate", Constraint: "must be strictly positive", Value: -1},
+			want: "cache.entry_fetch_rate must be strictly positive, was: -1",
+		},
+		{
+			desc: "constraint only",
+			err:  ValidationError{Constraint: "invalid config key no_such_key"},
+			want: "invalid config key no_such_key",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			require.Equal(t, tt.want, tt.err.Error())
+		})
+	}
+}
+
+func TestValidationErrors(t *testing.T) {
+	require.Nil(t, ValidationErrors(nil))
+
+	typed := ValidationError{Field: "node_name", Constraint: "cannot be empty"}
+	plain := errors.New("something else went wrong")
+
+	require.Equal(t, []ValidationError{typed}, ValidationErrors(typed))
+	require.Equal(t, []ValidationError{{Constraint: plain.Error()}}, ValidationErrors(plain))
+
+	var merr *multierror.Error
+	merr = multierror.Append(merr, typed, plain)
+	require.Equal(t, []ValidationError{typed, {Constraint: plain.Error()}}, ValidationErrors(merr))
+}
+
+func TestValidationErrors_FromBuild(t *testing.T) {
+	b, err := NewBuilder(BuilderOpts{})
+	require.NoError(t, err)
+	b.Sources = append(b.Sources, FileSource{
+		Name:   "validation.json",
+		Format: "json",
+		Data:   `{"bind_addr": "10.0.0.1"}`,
+	})
+
+	_, err = b.BuildAndValidate()
+	require.Error(t, err)
+	errs := ValidationErrors(err)
+	require.Len(t, errs, 1)
+	require.Equal(t, "data_dir", errs[0].Field)
+	require.Equal(t, "cannot be empty", errs[0].Constraint)
+}
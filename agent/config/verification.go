@@ -0,0 +1,91 @@
+package config
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/base64"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// verifyConfigSources checks the detached ed25519 signature configured in
+// config_verification against the config files that were read, before any of
+// them are parsed or applied. Since the config files are exactly what the
+// signature protects, the config_verification settings are only taken from
+// the command line or the embedding application, never from the files
+// themselves.
+func (b *Builder) verifyConfigSources(cv ConfigVerification) error {
+	keyFile := b.stringVal(cv.PublicKeyFile)
+	sigFile := b.stringVal(cv.SignatureFile)
+	required := b.boolVal(cv.Required)
+
+	if keyFile == "" && sigFile == "" && !required {
+		return nil
+	}
+	if keyFile == "" {
+		return fmt.Errorf("config: config_verification.public_key_file must be set when config verification is enabled")
+	}
+
+	keyData, err := ioutil.ReadFile(keyFile)
+	if err != nil {
+		return fmt.Errorf("config: failed to read config_verification.public_key_file: %s", err)
+	}
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(keyData)))
+	if err != nil {
+		return fmt.Errorf("config: config_verification.public_key_file %s does not contain a base64 encoded key: %s", keyFile, err)
+	}
+	if len(key) != ed25519.PublicKeySize {
+		return fmt.Errorf("config: config_verification.public_key_file %s must contain a %d byte ed25519 public key, got %d bytes", keyFile, ed25519.PublicKeySize, len(key))
+	}
+
+	if sigFile == "" {
+		return fmt.Errorf("config: config_verification.signature_file must be set when config verification is enabled")
+	}
+	sigData, err := ioutil.ReadFile(sigFile)
+	switch {
+	case os.IsNotExist(err) && !required:
+		b.warning(Warning{
+			Code:    "unsigned-config",
+			Source:  sigFile,
+			Message: fmt.Sprintf("skipping config verification: signature file %s does not exist", sigFile),
+		})
+		return nil
+	case err != nil:
+		return fmt.Errorf("config: failed to read config_verification.signature_file: %s", err)
+	}
+	sig, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(sigData)))
+	if err != nil {
+		return fmt.Errorf("config: config_verification.signature_file %s does not contain a base64 encoded signature: %s", sigFile, err)
+	}
+	if len(sig) != ed25519.SignatureSize {
+		return fmt.Errorf("config: config_verification.signature_file %s must contain a %d byte ed25519 signature, got %d bytes", sigFile, ed25519.SignatureSize, len(sig))
+	}
+
+	if !ed25519.Verify(ed25519.PublicKey(key), b.signedConfigContents(), sig) {
+		return fmt.Errorf("config: signature in %s does not match the config file contents", sigFile)
+	}
+	return nil
+}
+
+// signedConfigContents returns the message the config signature is computed
+// over: the base name and the content of every config file in the order they
+// were read, each followed by a newline. Using the base name keeps signatures
+// valid when the config directory is deployed to a different path than it was
+// signed at while still protecting the merge order of the files.
+func (b *Builder) signedConfigContents() []byte {
+	var buf bytes.Buffer
+	for _, s := range b.Sources {
+		fs, ok := s.(FileSource)
+		if !ok {
+			continue
+		}
+		buf.WriteString(filepath.Base(fs.Name))
+		buf.WriteString("\n")
+		buf.WriteString(fs.Data)
+		buf.WriteString("\n")
+	}
+	return buf.Bytes()
+}
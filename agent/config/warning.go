@@ -0,0 +1,81 @@
+package config
+
+import "strings"
+
+// knownWarningCodes is the set of stable codes the warnc call sites use.
+// suppress_warnings entries in the "code:<code>" form are validated
+// against it so a typo fails config validation instead of silently
+// suppressing nothing. Deprecation warnings use the dynamic
+// "deprecated-key:<key>" form and are validated against deprecatedKeys.
+var knownWarningCodes = map[string]bool{
+	"auto-config-intro-token":             true,
+	"auto-config-ip-san":                  true,
+	"auto-encrypt-ip-san":                 true,
+	"auto-encrypt-verify-incoming":        true,
+	"bootstrap":                           true,
+	"bootstrap-expect":                    true,
+	"deprecated-config-metrics-filtered":  true,
+	"dns-node-meta-txt-keys":              true,
+	"enterprise-key":                      true,
+	"memory-limit-headroom":               true,
+	"node-name-dns":                       true,
+	"remote-script-checks":                true,
+	"service-intentions-redundant-source": true,
+	"skipped-file":                        true,
+	"start-drained":                       true,
+	"telemetry-prefix-filter":             true,
+	"tls-bootstrap-mode-migration":        true,
+	"unknown-key":                         true,
+	"unsigned-config":                     true,
+	"verify-incoming-https-exempt":        true,
+}
+
+// knownWarningCode reports whether code is a code a warning can actually
+// carry, including the dynamic deprecated-key form.
+func knownWarningCode(code string) bool {
+	if knownWarningCodes[code] {
+		return true
+	}
+	if key := strings.TrimPrefix(code, "deprecated-key:"); key != code {
+		_, ok := deprecatedKeys[key]
+		return ok
+	}
+	return false
+}
+
+// Warning is a non-fatal finding recorded while building the runtime
+// configuration.
+type Warning struct {
+	// Code is the stable identifier assigned to the warning, or "" for
+	// warnings without one. Coded warnings can be silenced by listing the
+	// code in suppress_warnings.
+	Code string
+
+	// Message is the human readable warning text.
+	Message string
+
+	// Source is the config source the warning refers to, when known.
+	Source string
+
+	// Key is the config key the warning refers to, when known.
+	Key string
+}
+
+// String returns the warning in the free-form string format that was used
+// before warnings carried structured fields.
+func (w Warning) String() string {
+	return w.Message
+}
+
+// WarningStrings returns the recorded warnings in their string form for
+// callers that log or display them without inspecting the structured fields.
+func (b *Builder) WarningStrings() []string {
+	if len(b.Warnings) == 0 {
+		return nil
+	}
+	ws := make([]string, len(b.Warnings))
+	for i, w := range b.Warnings {
+		ws[i] = w.String()
+	}
+	return ws
+}
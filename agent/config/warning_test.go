@@ -0,0 +1,92 @@
+package config
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestKnownWarningCodes_CoverEmittedCodes scans the package source for
+// every code a warning is emitted with, either as the first argument of a
+// warnc call or as the Code field of a Warning literal, and asserts that
+// knownWarningCode accepts each one. This keeps the suppress_warnings
+// validation in sync with the call sites: a new coded warning that is not
+// added to knownWarningCodes would be impossible to suppress by code.
+func TestKnownWarningCodes_CoverEmittedCodes(t *testing.T) {
+	fset := token.NewFileSet()
+	pkgs, err := parser.ParseDir(fset, ".", nil, 0)
+	require.NoError(t, err)
+
+	litString := func(e ast.Expr) (string, bool) {
+		lit, ok := e.(*ast.BasicLit)
+		if !ok || lit.Kind != token.STRING {
+			return "", false
+		}
+		s, err := strconv.Unquote(lit.Value)
+		require.NoError(t, err)
+		return s, true
+	}
+
+	emitted := map[string][]string{}
+	record := func(code string, pos token.Pos) {
+		// The warn helper forwards an empty code for warnings that have
+		// no stable identifier and cannot be suppressed by code.
+		if code == "" {
+			return
+		}
+		emitted[code] = append(emitted[code], fset.Position(pos).String())
+	}
+
+	for _, pkg := range pkgs {
+		for name, file := range pkg.Files {
+			if strings.HasSuffix(name, "_test.go") {
+				continue
+			}
+			ast.Inspect(file, func(n ast.Node) bool {
+				switch n := n.(type) {
+				case *ast.CallExpr:
+					if sel, ok := n.Fun.(*ast.SelectorExpr); ok && sel.Sel.Name == "warnc" && len(n.Args) > 0 {
+						if code, ok := litString(n.Args[0]); ok {
+							record(code, n.Pos())
+						}
+					}
+				case *ast.KeyValueExpr:
+					if key, ok := n.Key.(*ast.Ident); ok && key.Name == "Code" {
+						if code, ok := litString(n.Value); ok {
+							record(code, n.Pos())
+						}
+					}
+				}
+				return true
+			})
+		}
+	}
+	require.NotEmpty(t, emitted, "no emitted warning codes found; the scan is broken")
+
+	for code, sites := range emitted {
+		require.True(t, knownWarningCode(code),
+			"code %q is emitted at %v but missing from knownWarningCodes", code, sites)
+	}
+
+	// The codes the agent is known to emit today must all be present so
+	// that the scan not finding them would itself be a failure.
+	for _, code := range []string{"skipped-file", "unknown-key", "unsigned-config"} {
+		require.Contains(t, emitted, code)
+	}
+}
+
+// TestKnownWarningCode_DeprecatedKeys covers the dynamic deprecated-key
+// form, which is validated against deprecatedKeys rather than the static
+// code map.
+func TestKnownWarningCode_DeprecatedKeys(t *testing.T) {
+	for key := range deprecatedKeys {
+		require.True(t, knownWarningCode("deprecated-key:"+key))
+	}
+	require.False(t, knownWarningCode("deprecated-key:not_a_real_key"))
+	require.False(t, knownWarningCode("not-a-real-code"))
+}
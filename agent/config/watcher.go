@@ -0,0 +1,123 @@
+package config
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"time"
+)
+
+const (
+	// defaultWatchInterval is how often the watched paths are polled for
+	// changes.
+	defaultWatchInterval = 2 * time.Second
+
+	// defaultWatchDebounce is how long the watched paths must be unchanged
+	// after a modification before a notification is sent. This coalesces
+	// multi-file updates, such as a config directory being rewritten file by
+	// file, into a single reload.
+	defaultWatchDebounce = 500 * time.Millisecond
+)
+
+// FileWatcher polls a set of files and directories for changes so that the
+// agent can reload its configuration automatically when auto_reload_config
+// is enabled. Polling is used instead of inotify style watches since config
+// files are commonly replaced through renames and symlink swaps (see
+// config_dir_follow_symlink) which are easy to miss with event based
+// watchers.
+type FileWatcher struct {
+	paths    []string
+	interval time.Duration
+	debounce time.Duration
+}
+
+// NewFileWatcher returns a FileWatcher for the given files and directories.
+// Directories are watched one level deep, matching what the config loader
+// reads.
+func NewFileWatcher(paths []string) *FileWatcher {
+	return &FileWatcher{
+		paths:    paths,
+		interval: defaultWatchInterval,
+		debounce: defaultWatchDebounce,
+	}
+}
+
+// Watch polls the watched paths until ctx is cancelled and sends on the
+// returned channel whenever their contents settle on a new state. The
+// channel has a buffer of one so that a slow receiver coalesces further
+// changes instead of blocking the watcher.
+func (w *FileWatcher) Watch(ctx context.Context) <-chan struct{} {
+	ch := make(chan struct{}, 1)
+	go func() {
+		last := w.snapshot()
+		ticker := time.NewTicker(w.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			cur := w.snapshot()
+			if reflect.DeepEqual(last, cur) {
+				continue
+			}
+
+			// wait for the state to settle before notifying
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case <-time.After(w.debounce):
+				}
+				next := w.snapshot()
+				if reflect.DeepEqual(cur, next) {
+					break
+				}
+				cur = next
+			}
+
+			last = cur
+			select {
+			case ch <- struct{}{}:
+			default:
+			}
+		}
+	}()
+	return ch
+}
+
+type fileState struct {
+	modTime time.Time
+	size    int64
+}
+
+// snapshot stats every watched file. Paths that cannot be read are left out
+// so that a file appearing or disappearing also counts as a change.
+func (w *FileWatcher) snapshot() map[string]fileState {
+	states := map[string]fileState{}
+	for _, path := range w.paths {
+		fi, err := os.Stat(path)
+		if err != nil {
+			continue
+		}
+		if !fi.IsDir() {
+			states[path] = fileState{modTime: fi.ModTime(), size: fi.Size()}
+			continue
+		}
+		fis, err := ioutil.ReadDir(path)
+		if err != nil {
+			continue
+		}
+		for _, fi := range fis {
+			if fi.IsDir() {
+				continue
+			}
+			states[filepath.Join(path, fi.Name())] = fileState{modTime: fi.ModTime(), size: fi.Size()}
+		}
+	}
+	return states
+}
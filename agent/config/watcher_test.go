@@ -0,0 +1,53 @@
+package config
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileWatcher(t *testing.T) {
+	path, err := ioutil.TempDir("", t.Name())
+	require.NoError(t, err)
+	t.Cleanup(func() { os.RemoveAll(path) })
+
+	fp := filepath.Join(path, "a.hcl")
+	require.NoError(t, ioutil.WriteFile(fp, []byte(`node_name = "hobbiton"`), 0644))
+
+	w := NewFileWatcher([]string{path})
+	w.interval = 10 * time.Millisecond
+	w.debounce = 10 * time.Millisecond
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ch := w.Watch(ctx)
+
+	// no change, no notification
+	select {
+	case <-ch:
+		t.Fatal("unexpected notification without a change")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	require.NoError(t, ioutil.WriteFile(fp, []byte(`node_name = "bree"`), 0644))
+
+	select {
+	case <-ch:
+	case <-time.After(2 * time.Second):
+		t.Fatal("timeout waiting for change notification")
+	}
+
+	// a new file in the watched directory is a change as well
+	require.NoError(t, ioutil.WriteFile(filepath.Join(path, "b.hcl"), []byte(`datacenter = "dc2"`), 0644))
+
+	select {
+	case <-ch:
+	case <-time.After(2 * time.Second):
+		t.Fatal("timeout waiting for change notification")
+	}
+}
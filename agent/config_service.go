@@ -0,0 +1,152 @@
+package agent
+
+import (
+	"encoding/json"
+	"sync"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hashicorp/go-hclog"
+	"google.golang.org/grpc"
+
+	"github.com/hashicorp/consul/agent/config"
+)
+
+// configService streams the sanitized runtime configuration over gRPC so
+// sidecar controllers can react to agent config changes without polling
+// /v1/agent/self. It is registered on the agent's gRPC servers when
+// grpc.enable_config_service is set. Every subscriber receives the
+// current configuration immediately and a new snapshot after each
+// successful reload.
+type configService struct {
+	logger hclog.Logger
+
+	mu sync.Mutex
+	// current is the sanitized runtime configuration as JSON.
+	current []byte
+	// index increments on every reload so clients can de-duplicate.
+	index uint64
+	// subs holds one buffered channel per active Watch stream.
+	subs map[chan *ConfigSnapshot]struct{}
+}
+
+// ConfigSnapshot is the message sent for every configuration update. It
+// is hand-maintained instead of generated: the payload is the sanitized
+// configuration JSON rather than a typed schema, so the wire format is a
+// single bytes field plus an update counter.
+type ConfigSnapshot struct {
+	Config []byte `protobuf:"bytes,1,opt,name=Config,proto3" json:"Config,omitempty"`
+	Index  uint64 `protobuf:"varint,2,opt,name=Index,proto3" json:"Index,omitempty"`
+}
+
+func (m *ConfigSnapshot) Reset()         { *m = ConfigSnapshot{} }
+func (m *ConfigSnapshot) String() string { return proto.CompactTextString(m) }
+func (*ConfigSnapshot) ProtoMessage()    {}
+
+// ConfigWatchRequest is the empty request message for Watch.
+type ConfigWatchRequest struct{}
+
+func (m *ConfigWatchRequest) Reset()         { *m = ConfigWatchRequest{} }
+func (m *ConfigWatchRequest) String() string { return proto.CompactTextString(m) }
+func (*ConfigWatchRequest) ProtoMessage()    {}
+
+func newConfigService(logger hclog.Logger, cfg *config.RuntimeConfig) (*configService, error) {
+	s := &configService{
+		logger: logger,
+		subs:   make(map[chan *ConfigSnapshot]struct{}),
+	}
+	if err := s.Reload(cfg); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Reload implements ConfigReloader. It re-sanitizes the configuration
+// and notifies every active Watch stream.
+func (s *configService) Reload(cfg *config.RuntimeConfig) error {
+	data, err := json.Marshal(cfg.Sanitized())
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.current = data
+	s.index++
+	snap := &ConfigSnapshot{Config: data, Index: s.index}
+	for ch := range s.subs {
+		select {
+		case ch <- snap:
+		default:
+			// The subscriber has not drained the previous snapshot
+			// yet; it will pick up the latest state on its next
+			// receive since snapshots are cumulative.
+		}
+	}
+	return nil
+}
+
+// Register adds the service to the given gRPC server.
+func (s *configService) Register(srv *grpc.Server) {
+	srv.RegisterService(&configServiceDesc, s)
+}
+
+func (s *configService) subscribe() chan *ConfigSnapshot {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ch := make(chan *ConfigSnapshot, 1)
+	ch <- &ConfigSnapshot{Config: s.current, Index: s.index}
+	s.subs[ch] = struct{}{}
+	return ch
+}
+
+func (s *configService) unsubscribe(ch chan *ConfigSnapshot) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.subs, ch)
+}
+
+// Watch sends the current sanitized configuration and then one snapshot
+// per reload until the client goes away.
+func (s *configService) Watch(_ *ConfigWatchRequest, stream grpc.ServerStream) error {
+	ch := s.subscribe()
+	defer s.unsubscribe(ch)
+
+	for {
+		select {
+		case snap := <-ch:
+			if err := stream.SendMsg(snap); err != nil {
+				return err
+			}
+		case <-stream.Context().Done():
+			return nil
+		}
+	}
+}
+
+// configServiceServer is the server interface the service descriptor is
+// registered against.
+type configServiceServer interface {
+	Watch(*ConfigWatchRequest, grpc.ServerStream) error
+}
+
+func configServiceWatchHandler(srv interface{}, stream grpc.ServerStream) error {
+	req := new(ConfigWatchRequest)
+	if err := stream.RecvMsg(req); err != nil {
+		return err
+	}
+	return srv.(configServiceServer).Watch(req, stream)
+}
+
+var configServiceDesc = grpc.ServiceDesc{
+	ServiceName: "consul.agent.config.ConfigService",
+	HandlerType: (*configServiceServer)(nil),
+	Methods:     []grpc.MethodDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Watch",
+			Handler:       configServiceWatchHandler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "agent/config_service.go",
+}
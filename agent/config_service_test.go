@@ -0,0 +1,66 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/hashicorp/consul/agent/config"
+)
+
+func TestConfigService_Watch(t *testing.T) {
+	t.Parallel()
+
+	cfg := &config.RuntimeConfig{NodeName: "hobbiton"}
+	svc, err := newConfigService(hclog.NewNullLogger(), cfg)
+	require.NoError(t, err)
+
+	srv := grpc.NewServer()
+	svc.Register(srv)
+	lis := bufconn.Listen(1024 * 1024)
+	go srv.Serve(lis)
+	defer srv.Stop()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	conn, err := grpc.DialContext(ctx, "bufconn",
+		grpc.WithInsecure(),
+		grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) {
+			return lis.Dial()
+		}))
+	require.NoError(t, err)
+	defer conn.Close()
+
+	desc := &grpc.StreamDesc{StreamName: "Watch", ServerStreams: true}
+	stream, err := conn.NewStream(ctx, desc, "/consul.agent.config.ConfigService/Watch")
+	require.NoError(t, err)
+	require.NoError(t, stream.SendMsg(&ConfigWatchRequest{}))
+	require.NoError(t, stream.CloseSend())
+
+	// The current configuration arrives immediately.
+	var snap ConfigSnapshot
+	require.NoError(t, stream.RecvMsg(&snap))
+	require.Equal(t, uint64(1), snap.Index)
+
+	var sanitized map[string]interface{}
+	require.NoError(t, json.Unmarshal(snap.Config, &sanitized))
+	require.Equal(t, "hobbiton", sanitized["NodeName"])
+
+	// A reload produces a new snapshot with the updated config.
+	cfg2 := &config.RuntimeConfig{NodeName: "bree"}
+	require.NoError(t, svc.Reload(cfg2))
+
+	snap = ConfigSnapshot{}
+	require.NoError(t, stream.RecvMsg(&snap))
+	require.Equal(t, uint64(2), snap.Index)
+	require.NoError(t, json.Unmarshal(snap.Config, &sanitized))
+	require.Equal(t, "bree", sanitized["NodeName"])
+}
@@ -12,6 +12,7 @@ import (
 	"github.com/hashicorp/consul/logging"
 	"github.com/hashicorp/consul/types"
 	"github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/memberlist"
 	"github.com/hashicorp/serf/serf"
 )
 
@@ -71,6 +72,20 @@ func (c *Client) setupSerf(conf *serf.Config, ch chan serf.Event, path string) (
 
 	conf.ReconnectTimeoutOverride = libserf.NewReconnectOverride(c.logger)
 
+	// bind_addrs binds the gossip listener to every configured address
+	// of a multi-homed host instead of the single memberlist bind address.
+	if len(c.config.SerfBindAddrs) > 0 {
+		nt, err := memberlist.NewNetTransport(&memberlist.NetTransportConfig{
+			BindAddrs: c.config.SerfBindAddrs,
+			BindPort:  conf.MemberlistConfig.BindPort,
+			Logger:    conf.MemberlistConfig.Logger,
+		})
+		if err != nil {
+			return nil, err
+		}
+		conf.MemberlistConfig.Transport = nt
+	}
+
 	return serf.Create(conf)
 }
 
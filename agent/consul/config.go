@@ -13,6 +13,7 @@ import (
 
 	"github.com/hashicorp/consul/agent/checks"
 	"github.com/hashicorp/consul/agent/structs"
+	"github.com/hashicorp/consul/lib"
 	libserf "github.com/hashicorp/consul/lib/serf"
 	"github.com/hashicorp/consul/tlsutil"
 	"github.com/hashicorp/consul/types"
@@ -123,6 +124,16 @@ type Config struct {
 	// by the WAN and LAN
 	RPCAddr *net.TCPAddr
 
+	// RPCBindAddrs are additional addresses the RPC listener binds to on
+	// multi-homed hosts. RPCAddr is always bound and entries equal to it
+	// are skipped.
+	RPCBindAddrs []*net.TCPAddr
+
+	// SerfBindAddrs are the addresses the LAN and WAN Serf listeners bind
+	// to on multi-homed hosts. When empty the single memberlist bind
+	// address is used.
+	SerfBindAddrs []string
+
 	// RPCAdvertise is the address that is advertised to other nodes for
 	// the RPC endpoint. This can differ from the RPC address, if for example
 	// the RPCAddr is unspecified "0.0.0.0:8300", but this address must be
@@ -482,6 +493,14 @@ type Config struct {
 
 	RPCConfig RPCConfig
 
+	// Tracing configures trace export for RPC handling. It is threaded
+	// through from the agent configuration.
+	Tracing lib.TracingConfig
+
+	// KVQuotas are the key prefix quotas enforced on KV writes. They are
+	// threaded through from the agent configuration.
+	KVQuotas []KVQuota
+
 	// Embedded Consul Enterprise specific configuration
 	*EnterpriseConfig
 }
@@ -655,3 +674,11 @@ func DefaultConfig() *Config {
 type RPCConfig struct {
 	EnableStreaming bool
 }
+
+// KVQuota caps the number of keys and the total value bytes stored under a
+// key prefix. A zero limit is unrestricted.
+type KVQuota struct {
+	Prefix   string
+	MaxKeys  int
+	MaxBytes int64
+}
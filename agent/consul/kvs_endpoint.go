@@ -119,6 +119,10 @@ func (k *KVS) Apply(args *structs.KVSRequest, reply *bool) error {
 		return nil
 	}
 
+	if err := k.srv.enforceKVQuota(args.Op, &args.DirEnt); err != nil {
+		return err
+	}
+
 	// Apply the update.
 	resp, err := k.srv.raftApply(structs.KVSRequestType, args)
 	if err != nil {
@@ -136,6 +140,55 @@ func (k *KVS) Apply(args *structs.KVSRequest, reply *bool) error {
 	return nil
 }
 
+// enforceKVQuota rejects writes that would push a configured
+// limits.kv_quota prefix over its key count or byte limit. Like the
+// lock-delay check this must happen before the raft commit, so only the
+// leader's view of the store is consulted.
+func (s *Server) enforceKVQuota(op api.KVOp, dirEnt *structs.DirEntry) error {
+	if len(s.config.KVQuotas) == 0 {
+		return nil
+	}
+	switch op {
+	case api.KVSet, api.KVCAS, api.KVLock, api.KVUnlock:
+	default:
+		// deletes and reads cannot push a prefix over its quota
+		return nil
+	}
+	for _, q := range s.config.KVQuotas {
+		if !strings.HasPrefix(dirEnt.Key, q.Prefix) {
+			continue
+		}
+		_, entries, err := s.fsm.State().KVSList(nil, q.Prefix, &dirEnt.EnterpriseMeta)
+		if err != nil {
+			return err
+		}
+		keys := 0
+		var bytes int64
+		exists := false
+		for _, e := range entries {
+			keys++
+			if e.Key == dirEnt.Key {
+				// the new value replaces the stored one
+				exists = true
+				bytes += int64(len(dirEnt.Value))
+			} else {
+				bytes += int64(len(e.Value))
+			}
+		}
+		if !exists {
+			keys++
+			bytes += int64(len(dirEnt.Value))
+		}
+		if q.MaxKeys > 0 && keys > q.MaxKeys {
+			return fmt.Errorf("kv quota exceeded for prefix %q: %d keys, limit is %d", q.Prefix, keys, q.MaxKeys)
+		}
+		if q.MaxBytes > 0 && bytes > q.MaxBytes {
+			return fmt.Errorf("kv quota exceeded for prefix %q: %d bytes, limit is %d", q.Prefix, bytes, q.MaxBytes)
+		}
+	}
+	return nil
+}
+
 // Get is used to lookup a single key.
 func (k *KVS) Get(args *structs.KeyRequest, reply *structs.IndexedDirEntries) error {
 	if done, err := k.srv.ForwardRPC("KVS.Get", args, args, reply); done {
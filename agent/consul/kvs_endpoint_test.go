@@ -2,6 +2,7 @@ package consul
 
 import (
 	"os"
+	"strings"
 	"testing"
 	"time"
 
@@ -74,6 +75,68 @@ func TestKVS_Apply(t *testing.T) {
 	}
 }
 
+func TestKVS_Apply_KVQuota(t *testing.T) {
+	if testing.Short() {
+		t.Skip("too slow for testing.Short")
+	}
+
+	t.Parallel()
+	dir1, s1 := testServerWithConfig(t, func(c *Config) {
+		c.KVQuotas = []KVQuota{
+			{Prefix: "quota/", MaxKeys: 2, MaxBytes: 20},
+		}
+	})
+	defer os.RemoveAll(dir1)
+	defer s1.Shutdown()
+	codec := rpcClient(t, s1)
+	defer codec.Close()
+
+	testrpc.WaitForTestAgent(t, s1.RPC, "dc1")
+
+	set := func(key, value string) error {
+		arg := structs.KVSRequest{
+			Datacenter: "dc1",
+			Op:         api.KVSet,
+			DirEnt: structs.DirEntry{
+				Key:   key,
+				Value: []byte(value),
+			},
+		}
+		var out bool
+		return msgpackrpc.CallWithCodec(codec, "KVS.Apply", &arg, &out)
+	}
+
+	// two keys fit under the quota
+	if err := set("quota/a", "12345"); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if err := set("quota/b", "12345"); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	// a third key exceeds max_keys
+	err := set("quota/c", "1")
+	if err == nil || !strings.Contains(err.Error(), `kv quota exceeded for prefix "quota/": 3 keys, limit is 2`) {
+		t.Fatalf("err: %v", err)
+	}
+
+	// updating an existing key replaces its stored bytes
+	if err := set("quota/a", "123456789012345"); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	// pushing the prefix over max_bytes fails
+	err = set("quota/b", "123456")
+	if err == nil || !strings.Contains(err.Error(), `kv quota exceeded for prefix "quota/": 21 bytes, limit is 20`) {
+		t.Fatalf("err: %v", err)
+	}
+
+	// keys outside the prefix are unaffected
+	if err := set("other/a", "123456789012345678901234567890"); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+}
+
 func TestKVS_Apply_ACLDeny(t *testing.T) {
 	if testing.Short() {
 		t.Skip("too slow for testing.Short")
@@ -401,7 +401,7 @@ func (s *Server) handleMultiplexV2(conn net.Conn) {
 // handleConsulConn is used to service a single Consul RPC connection
 func (s *Server) handleConsulConn(conn net.Conn) {
 	defer conn.Close()
-	rpcCodec := msgpackrpc.NewCodecFromHandle(true, true, conn, structs.MsgpackHandle)
+	rpcCodec := tracedCodec(msgpackrpc.NewCodecFromHandle(true, true, conn, structs.MsgpackHandle), s.tracer, conn.RemoteAddr().String())
 	for {
 		select {
 		case <-s.shutdownCh:
@@ -426,7 +426,7 @@ func (s *Server) handleConsulConn(conn net.Conn) {
 // handleInsecureConsulConn is used to service a single Consul INSECURERPC connection
 func (s *Server) handleInsecureConn(conn net.Conn) {
 	defer conn.Close()
-	rpcCodec := msgpackrpc.NewCodecFromHandle(true, true, conn, structs.MsgpackHandle)
+	rpcCodec := tracedCodec(msgpackrpc.NewCodecFromHandle(true, true, conn, structs.MsgpackHandle), s.tracer, conn.RemoteAddr().String())
 	for {
 		select {
 		case <-s.shutdownCh:
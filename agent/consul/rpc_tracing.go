@@ -0,0 +1,68 @@
+package consul
+
+import (
+	"errors"
+	"net/rpc"
+	"sync"
+
+	"github.com/hashicorp/consul/lib"
+)
+
+// tracedServerCodec wraps a net/rpc server codec so that every request
+// served through it is recorded as a span. The method name is only known
+// once the request header has been read, so the span is started there and
+// matched up with its response by sequence number.
+type tracedServerCodec struct {
+	rpc.ServerCodec
+	tracer *lib.Tracer
+	source string
+
+	mu    sync.Mutex
+	spans map[uint64]*lib.Span
+}
+
+// tracedCodec wraps codec so that requests served through it are traced,
+// annotated with source as the peer address. The codec is returned
+// unchanged when the tracer is disabled.
+func tracedCodec(codec rpc.ServerCodec, tracer *lib.Tracer, source string) rpc.ServerCodec {
+	if tracer == nil {
+		return codec
+	}
+	return &tracedServerCodec{
+		ServerCodec: codec,
+		tracer:      tracer,
+		source:      source,
+		spans:       make(map[uint64]*lib.Span),
+	}
+}
+
+func (c *tracedServerCodec) ReadRequestHeader(req *rpc.Request) error {
+	if err := c.ServerCodec.ReadRequestHeader(req); err != nil {
+		return err
+	}
+	if span := c.tracer.StartSpan("RPC " + req.ServiceMethod); span != nil {
+		span.SetAttribute("rpc.method", req.ServiceMethod)
+		if c.source != "" {
+			span.SetAttribute("peer.address", c.source)
+		}
+		c.mu.Lock()
+		c.spans[req.Seq] = span
+		c.mu.Unlock()
+	}
+	return nil
+}
+
+func (c *tracedServerCodec) WriteResponse(resp *rpc.Response, body interface{}) error {
+	c.mu.Lock()
+	span := c.spans[resp.Seq]
+	delete(c.spans, resp.Seq)
+	c.mu.Unlock()
+	if span != nil {
+		var err error
+		if resp.Error != "" {
+			err = errors.New(resp.Error)
+		}
+		span.Finish(err)
+	}
+	return c.ServerCodec.WriteResponse(resp, body)
+}
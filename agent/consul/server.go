@@ -225,10 +225,19 @@ type Server struct {
 	rpcConnLimiter connlimit.Limiter
 
 	// Listener is used to listen for incoming connections
-	Listener    net.Listener
+	Listener net.Listener
+
+	// extraListeners are RPC listeners for the additional bind addresses
+	// of multi-homed hosts.
+	extraListeners []net.Listener
+
 	grpcHandler connHandler
 	rpcServer   *rpc.Server
 
+	// tracer exports spans for RPC request handling when tracing is
+	// configured. It is nil when tracing is disabled.
+	tracer *lib.Tracer
+
 	// insecureRPCServer is a RPC server that is configure with
 	// IncomingInsecureRPCConfig to allow clients to call AutoEncrypt.Sign
 	// to request client certificates. At this point a client doesn't have
@@ -379,6 +388,12 @@ func NewServer(config *Config, flat Deps) (*Server, error) {
 		fsm:                     newFSMFromConfig(flat.Logger, gc, config),
 	}
 
+	s.tracer, err = lib.NewTracer(config.Tracing)
+	if err != nil {
+		s.Shutdown()
+		return nil, err
+	}
+
 	if s.config.ConnectMeshGatewayWANFederationEnabled {
 		s.gatewayLocator = NewGatewayLocator(
 			s.logger,
@@ -591,6 +606,9 @@ func NewServer(config *Config, flat Deps) (*Server, error) {
 		}
 	}()
 	go s.listen(s.Listener)
+	for _, listener := range s.extraListeners {
+		go s.listen(listener)
+	}
 
 	// Start listeners for any segments with separate RPC listeners.
 	for _, listener := range segmentListeners {
@@ -873,6 +891,22 @@ func (s *Server) setupRPC() error {
 	}
 	s.Listener = ln
 
+	// Bind the additional RPC addresses of multi-homed hosts.
+	for _, addr := range s.config.RPCBindAddrs {
+		if addr.IP.Equal(s.config.RPCAddr.IP) && addr.Port == s.config.RPCAddr.Port {
+			continue
+		}
+		extra, err := net.ListenTCP("tcp", addr)
+		if err != nil {
+			ln.Close()
+			for _, l := range s.extraListeners {
+				l.Close()
+			}
+			return err
+		}
+		s.extraListeners = append(s.extraListeners, extra)
+	}
+
 	if s.config.NotifyListen != nil {
 		s.config.NotifyListen()
 	}
@@ -951,6 +985,9 @@ func (s *Server) Shutdown() error {
 	if s.Listener != nil {
 		s.Listener.Close()
 	}
+	for _, listener := range s.extraListeners {
+		listener.Close()
+	}
 
 	if s.grpcHandler != nil {
 		if err := s.grpcHandler.Shutdown(); err != nil {
@@ -958,6 +995,9 @@ func (s *Server) Shutdown() error {
 		}
 	}
 
+	// Flush any remaining spans now that no more requests are handled.
+	s.tracer.Shutdown()
+
 	// Close the connection pool
 	if s.connPool != nil {
 		s.connPool.Shutdown()
@@ -1259,7 +1299,7 @@ func (s *Server) RPC(method string, args interface{}, reply interface{}) error {
 		metrics.IncrCounter([]string{"client", "rpc", "exceeded"}, 1)
 		return structs.ErrRPCRateExceeded
 	}
-	if err := s.rpcServer.ServeRequest(codec); err != nil {
+	if err := s.rpcServer.ServeRequest(tracedCodec(codec, s.tracer, "")); err != nil {
 		return err
 	}
 	return codec.err
@@ -1399,6 +1439,61 @@ func (s *Server) ReloadConfig(config *Config) error {
 	return nil
 }
 
+// ReloadBootstrapExpect applies a changed bootstrap_expect value on a running
+// server. Only lowering the value is supported: raising it cannot retract an
+// already completed bootstrap and would silently do nothing, so it is
+// rejected. If the cluster has already bootstrapped the new value must not
+// drop below the quorum size of the current raft voter set.
+func (s *Server) ReloadBootstrapExpect(expect int) error {
+	old := s.config.BootstrapExpect
+	if expect == old {
+		return nil
+	}
+	if expect > old {
+		return fmt.Errorf("bootstrap_expect cannot be raised from %d to %d on a running server; restart the server to apply it", old, expect)
+	}
+
+	future := s.raft.GetConfiguration()
+	if err := future.Error(); err != nil {
+		return fmt.Errorf("failed to read raft configuration: %v", err)
+	}
+	voters := 0
+	for _, server := range future.Configuration().Servers {
+		if server.Suffrage == raft.Voter {
+			voters++
+		}
+	}
+	if quorum := voters/2 + 1; voters > 0 && expect > 0 && expect < quorum {
+		return fmt.Errorf("bootstrap_expect %d is below the quorum size %d of the current raft configuration (%d voters)", expect, quorum, voters)
+	}
+
+	s.config.BootstrapExpect = expect
+	s.logger.Info("bootstrap_expect changed via reload", "old", old, "new", expect)
+
+	// Advertise the new value so that peers which are still expecting to
+	// bootstrap do not see a conflicting expect tag.
+	tags := make(map[string]string)
+	for k, v := range s.serfLAN.LocalMember().Tags {
+		tags[k] = v
+	}
+	if expect == 0 {
+		delete(tags, "expect")
+	} else {
+		tags["expect"] = fmt.Sprintf("%d", expect)
+	}
+	if err := s.serfLAN.SetTags(tags); err != nil {
+		return fmt.Errorf("failed to update serf tags: %v", err)
+	}
+
+	// Lowering the value may mean the expected number of servers is already
+	// present; re-run the bootstrap check in the background since it can
+	// block on peer RPCs.
+	if expect != 0 {
+		go s.maybeBootstrap()
+	}
+	return nil
+}
+
 // Atomically sets a readiness state flag when leadership is obtained, to indicate that server is past its barrier write
 func (s *Server) setConsistentReadReady() {
 	atomic.StoreInt32(&s.readyForConsistentReads, 1)
@@ -119,9 +119,16 @@ func (s *Server) setupSerf(conf *serf.Config, ch chan serf.Event, path string, w
 		}
 	}
 
+	// bind_addrs binds the gossip listeners to every configured address
+	// of a multi-homed host instead of the single memberlist bind address.
+	bindAddrs := []string{conf.MemberlistConfig.BindAddr}
+	if len(s.config.SerfBindAddrs) > 0 {
+		bindAddrs = s.config.SerfBindAddrs
+	}
+
 	if wan {
 		nt, err := memberlist.NewNetTransport(&memberlist.NetTransportConfig{
-			BindAddrs: []string{conf.MemberlistConfig.BindAddr},
+			BindAddrs: bindAddrs,
 			BindPort:  conf.MemberlistConfig.BindPort,
 			Logger:    conf.MemberlistConfig.Logger,
 		})
@@ -144,6 +151,16 @@ func (s *Server) setupSerf(conf *serf.Config, ch chan serf.Event, path string, w
 		} else {
 			conf.MemberlistConfig.Transport = nt
 		}
+	} else if len(s.config.SerfBindAddrs) > 0 {
+		nt, err := memberlist.NewNetTransport(&memberlist.NetTransportConfig{
+			BindAddrs: bindAddrs,
+			BindPort:  conf.MemberlistConfig.BindPort,
+			Logger:    conf.MemberlistConfig.Logger,
+		})
+		if err != nil {
+			return nil, err
+		}
+		conf.MemberlistConfig.Transport = nt
 	}
 
 	// Until Consul supports this fully, we disable automatic resolution.
@@ -1294,6 +1294,40 @@ func TestServer_BadExpect(t *testing.T) {
 	})
 }
 
+func TestServer_ReloadBootstrapExpect(t *testing.T) {
+	t.Parallel()
+	// this one is in expect=3 mode and will not bootstrap on its own
+	dir1, s1 := testServerDCExpect(t, "dc1", 3)
+	defer os.RemoveAll(dir1)
+	defer s1.Shutdown()
+
+	// should have no peers yet since the expect count was not reached
+	retry.Run(t, func(r *retry.R) {
+		r.Check(wantPeers(s1, 0))
+	})
+
+	// raising the value requires a restart
+	err := s1.ReloadBootstrapExpect(5)
+	if err == nil || !strings.Contains(err.Error(), "cannot be raised") {
+		t.Fatalf("expected raise error, got: %v", err)
+	}
+
+	// lowering to 1 should trigger the bootstrap
+	if err := s1.ReloadBootstrapExpect(1); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	testrpc.WaitForLeader(t, s1.RPC, "dc1")
+
+	// once bootstrapped the expect count is zeroed so any non-zero value
+	// is a raise
+	retry.Run(t, func(r *retry.R) {
+		err := s1.ReloadBootstrapExpect(3)
+		if err == nil || !strings.Contains(err.Error(), "cannot be raised") {
+			r.Fatalf("expected raise error, got: %v", err)
+		}
+	})
+}
+
 type fakeGlobalResp struct{}
 
 func (r *fakeGlobalResp) Add(interface{}) {
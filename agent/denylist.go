@@ -1,22 +1,43 @@
 package agent
 
 import (
+	"regexp"
+
 	"github.com/armon/go-radix"
+
+	"github.com/hashicorp/consul/agent/config"
 )
 
 // Denylist implements an HTTP endpoint denylist based on a list of endpoint
-// prefixes which should be blocked.
+// prefixes which should be blocked and a list of structured rules matching
+// by path regex and method.
 type Denylist struct {
-	tree *radix.Tree
+	tree  *radix.Tree
+	rules []endpointRule
+}
+
+type endpointRule struct {
+	rule    config.RuntimeBlockEndpointRule
+	pattern *regexp.Regexp
 }
 
-// NewDenylist returns a denylist for the given list of prefixes.
-func NewDenylist(prefixes []string) *Denylist {
+// NewDenylist returns a denylist for the given list of prefixes and
+// structured rules. Rules whose path regex does not compile are dropped;
+// the config builder has already validated them.
+func NewDenylist(prefixes []string, rules []config.RuntimeBlockEndpointRule) *Denylist {
 	tree := radix.New()
 	for _, prefix := range prefixes {
 		tree.Insert(prefix, nil)
 	}
-	return &Denylist{tree}
+	d := &Denylist{tree: tree}
+	for _, r := range rules {
+		pattern, err := regexp.Compile(r.PathRegex)
+		if err != nil {
+			continue
+		}
+		d.rules = append(d.rules, endpointRule{rule: r, pattern: pattern})
+	}
+	return d
 }
 
 // Block will return true if the given path is included among any of the
@@ -25,3 +46,23 @@ func (d *Denylist) Block(path string) bool {
 	_, _, blocked := d.tree.LongestPrefix(path)
 	return blocked
 }
+
+// MatchRule returns the first structured rule that blocks the given request,
+// or nil if none does. A rule matches when its path regex matches the path
+// and it either lists the request method or lists no methods at all.
+func (d *Denylist) MatchRule(method, path string) *config.RuntimeBlockEndpointRule {
+	for _, r := range d.rules {
+		if !r.pattern.MatchString(path) {
+			continue
+		}
+		if len(r.rule.Methods) == 0 {
+			return &r.rule
+		}
+		for _, m := range r.rule.Methods {
+			if m == method {
+				return &r.rule
+			}
+		}
+	}
+	return nil
+}
@@ -2,6 +2,8 @@ package agent
 
 import (
 	"testing"
+
+	"github.com/hashicorp/consul/agent/config"
 )
 
 func TestDenylist(t *testing.T) {
@@ -30,10 +32,54 @@ func TestDenylist(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.desc, func(t *testing.T) {
-			denylist := NewDenylist(tt.prefixes)
+			denylist := NewDenylist(tt.prefixes, nil)
 			if got, want := denylist.Block(tt.path), tt.block; got != want {
 				t.Fatalf("got %v want %v", got, want)
 			}
 		})
 	}
 }
+
+func TestDenylistRules(t *testing.T) {
+	t.Parallel()
+
+	rules := []config.RuntimeBlockEndpointRule{
+		{
+			Name:      "kv-writes",
+			PathRegex: "^/v1/kv/",
+			Methods:   []string{"PUT", "DELETE"},
+		},
+		{
+			Name:      "no-snapshots",
+			PathRegex: "^/v1/snapshot$",
+		},
+	}
+
+	tests := []struct {
+		desc   string
+		method string
+		path   string
+		rule   string
+	}{
+		{"no rules match", "GET", "/v1/agent/self", ""},
+		{"method not listed", "GET", "/v1/kv/foo", ""},
+		{"path and method match", "PUT", "/v1/kv/foo", "kv-writes"},
+		{"second method matches", "DELETE", "/v1/kv/foo", "kv-writes"},
+		{"no methods matches all", "POST", "/v1/snapshot", "no-snapshots"},
+		{"anchored regex", "POST", "/v1/snapshot/extra", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			denylist := NewDenylist(nil, rules)
+			rule := denylist.MatchRule(tt.method, tt.path)
+			switch {
+			case tt.rule == "" && rule != nil:
+				t.Fatalf("got rule %q want none", rule.Name)
+			case tt.rule != "" && rule == nil:
+				t.Fatalf("got no rule want %q", tt.rule)
+			case tt.rule != "" && rule.Name != tt.rule:
+				t.Fatalf("got rule %q want %q", rule.Name, tt.rule)
+			}
+		})
+	}
+}
@@ -2,9 +2,11 @@ package agent
 
 import (
 	"context"
+	"crypto/tls"
 	"encoding/hex"
 	"fmt"
 	"net"
+	"net/url"
 	"regexp"
 	"strings"
 	"sync/atomic"
@@ -83,13 +85,24 @@ type dnsConfig struct {
 	SegmentName     string
 	UDPAnswerLimit  int
 	ARecordLimit    int
+	// MaxResults caps how many service instances a single service or
+	// prepared query lookup may return. Applied after shuffling so
+	// clients still see a spread of instances. Zero means unlimited.
+	MaxResults      int
 	NodeMetaTXT     bool
-	SOAConfig       dnsSOAConfig
+	NodeMetaTXTKeys map[string]bool
+	// TagAllowlist restricts which tags are resolvable as
+	// tag.name.service.consul. Entries are exact tags or prefix patterns
+	// with a trailing '*'. When empty all tags are resolvable.
+	TagAllowlistExact    map[string]bool
+	TagAllowlistPrefixes []string
+	SOAConfig            dnsSOAConfig
 	// TTLRadix sets service TTLs by prefix, eg: "database-*"
 	TTLRadix *radix.Tree
 	// TTLStict sets TTLs to service by full name match. It Has higher priority than TTLRadix
 	TTLStrict          map[string]time.Duration
 	DisableCompression bool
+	SlowQueryThreshold time.Duration
 
 	enterpriseDNSConfig
 }
@@ -154,13 +167,16 @@ func GetDNSConfig(conf *config.RuntimeConfig) (*dnsConfig, error) {
 		NodeName:           conf.NodeName,
 		NodeTTL:            conf.DNSNodeTTL,
 		OnlyPassing:        conf.DNSOnlyPassing,
+		MaxResults:         conf.DiscoveryMaxResultsDNS,
 		RecursorTimeout:    conf.DNSRecursorTimeout,
 		SegmentName:        conf.SegmentName,
 		UDPAnswerLimit:     conf.DNSUDPAnswerLimit,
 		NodeMetaTXT:        conf.DNSNodeMetaTXT,
+		NodeMetaTXTKeys:    nodeMetaTXTKeys(conf.DNSNodeMetaTXTKeys),
 		DisableCompression: conf.DNSDisableCompression,
 		UseCache:           conf.DNSUseCache,
 		CacheMaxAge:        conf.DNSCacheMaxAge,
+		SlowQueryThreshold: conf.LoggingSlowQueryThreshold,
 		SOAConfig: dnsSOAConfig{
 			Expire:  conf.DNSSOA.Expire,
 			Minttl:  conf.DNSSOA.Minttl,
@@ -191,9 +207,51 @@ func GetDNSConfig(conf *config.RuntimeConfig) (*dnsConfig, error) {
 		cfg.Recursors = append(cfg.Recursors, ra)
 	}
 
+	for _, pattern := range conf.DNSTagAllowlist {
+		if strings.HasSuffix(pattern, "*") {
+			cfg.TagAllowlistPrefixes = append(cfg.TagAllowlistPrefixes, pattern[:len(pattern)-1])
+			continue
+		}
+		if cfg.TagAllowlistExact == nil {
+			cfg.TagAllowlistExact = make(map[string]bool)
+		}
+		cfg.TagAllowlistExact[pattern] = true
+	}
+
 	return cfg, nil
 }
 
+// nodeMetaTXTKeys converts the configured node_meta_txt_keys list into a
+// lookup set. A nil result means no filtering.
+func nodeMetaTXTKeys(keys []string) map[string]bool {
+	if len(keys) == 0 {
+		return nil
+	}
+	set := make(map[string]bool, len(keys))
+	for _, k := range keys {
+		set[k] = true
+	}
+	return set
+}
+
+// tagAllowed determines whether a tag may be used in a tag.name.service
+// lookup. The empty tag (plain service lookups) is always allowed, as is
+// every tag when no allowlist is configured.
+func (cfg *dnsConfig) tagAllowed(tag string) bool {
+	if tag == "" || (cfg.TagAllowlistExact == nil && len(cfg.TagAllowlistPrefixes) == 0) {
+		return true
+	}
+	if cfg.TagAllowlistExact[tag] {
+		return true
+	}
+	for _, p := range cfg.TagAllowlistPrefixes {
+		if strings.HasPrefix(tag, p) {
+			return true
+		}
+	}
+	return false
+}
+
 // GetTTLForService Find the TTL for a given service.
 // return ttl, true if found, 0, false otherwise
 func (cfg *dnsConfig) GetTTLForService(service string) (time.Duration, bool) {
@@ -212,7 +270,81 @@ func (cfg *dnsConfig) GetTTLForService(service string) (time.Duration, bool) {
 	return 0, false
 }
 
+// logSlowQuery emits a warning for lookups that took longer than the
+// configured logging.slow_query_threshold. A zero threshold disables it.
+func (d *DNSServer) logSlowQuery(elapsed time.Duration, name string, qtype uint16, resp dns.ResponseWriter) {
+	cfg := d.config.Load().(*dnsConfig)
+	if cfg.SlowQueryThreshold <= 0 || elapsed <= cfg.SlowQueryThreshold {
+		return
+	}
+	d.logger.Warn("Slow DNS query",
+		"name", name,
+		"type", dns.Type(qtype),
+		"latency", elapsed.String(),
+		"threshold", cfg.SlowQueryThreshold.String(),
+		"client", resp.RemoteAddr().String(),
+		"client_network", resp.RemoteAddr().Network(),
+	)
+}
+
 func (d *DNSServer) ListenAndServe(network, addr string, notif func()) error {
+	d.setupMux()
+
+	d.Server = &dns.Server{
+		Addr:              addr,
+		Net:               network,
+		Handler:           d.mux,
+		NotifyStartedFunc: notif,
+	}
+	if network == "udp" {
+		d.UDPSize = 65535
+	}
+	return d.Server.ListenAndServe()
+}
+
+// ServeListener serves DNS on a pre-bound TCP listener, such as one
+// inherited from the service manager through socket activation.
+func (d *DNSServer) ServeListener(l net.Listener, notif func()) error {
+	d.setupMux()
+
+	d.Server = &dns.Server{
+		Listener:          l,
+		Handler:           d.mux,
+		NotifyStartedFunc: notif,
+	}
+	return d.Server.ActivateAndServe()
+}
+
+// ServePacketConn serves DNS on a pre-bound UDP packet connection, such as
+// one inherited from the service manager through socket activation.
+func (d *DNSServer) ServePacketConn(pc net.PacketConn, notif func()) error {
+	d.setupMux()
+
+	d.Server = &dns.Server{
+		PacketConn:        pc,
+		Handler:           d.mux,
+		NotifyStartedFunc: notif,
+	}
+	d.UDPSize = 65535
+	return d.Server.ActivateAndServe()
+}
+
+// ListenAndServeTLS serves DNS over TLS (RFC 7858) on a TCP address
+// using the given certificate configuration.
+func (d *DNSServer) ListenAndServeTLS(addr string, tlsConfig *tls.Config, notif func()) error {
+	d.setupMux()
+
+	d.Server = &dns.Server{
+		Addr:              addr,
+		Net:               "tcp-tls",
+		TLSConfig:         tlsConfig,
+		Handler:           d.mux,
+		NotifyStartedFunc: notif,
+	}
+	return d.Server.ListenAndServe()
+}
+
+func (d *DNSServer) setupMux() {
 	cfg := d.config.Load().(*dnsConfig)
 
 	d.mux = dns.NewServeMux()
@@ -228,17 +360,6 @@ func (d *DNSServer) ListenAndServe(network, addr string, notif func()) error {
 		d.mux.HandleFunc(d.altDomain, d.handleQuery)
 	}
 	d.toggleRecursorHandlerFromConfig(cfg)
-
-	d.Server = &dns.Server{
-		Addr:              addr,
-		Net:               network,
-		Handler:           d.mux,
-		NotifyStartedFunc: notif,
-	}
-	if network == "udp" {
-		d.UDPSize = 65535
-	}
-	return d.Server.ListenAndServe()
 }
 
 // toggleRecursorHandlerFromConfig enables or disables the recursor handler based on config idempotently
@@ -303,22 +424,44 @@ func setEDNS(request *dns.Msg, response *dns.Msg, ecsGlobal bool) {
 	}
 }
 
-// recursorAddr is used to add a port to the recursor if omitted.
+// recursorAddr normalizes a recursor entry. Plain entries get the default
+// DNS port appended, tls:// entries get the DNS-over-TLS port and keep
+// their optional #server_name fragment, and https:// entries are used
+// verbatim after URL validation.
 func recursorAddr(recursor string) (string, error) {
-	// Add the port if none
-START:
-	_, _, err := net.SplitHostPort(recursor)
-	if ae, ok := err.(*net.AddrError); ok {
-		if ae.Err == "missing port in address" {
-			recursor = ipaddr.FormatAddressPort(recursor, 53)
-			goto START
-		} else if ae.Err == "too many colons in address" {
-			if ip := net.ParseIP(recursor); ip != nil && ip.To4() == nil {
-				recursor = ipaddr.FormatAddressPort(recursor, 53)
-				goto START
-			}
+	switch {
+	case strings.HasPrefix(recursor, "tls://"):
+		rest := strings.TrimPrefix(recursor, "tls://")
+		serverName := ""
+		if i := strings.Index(rest, "#"); i >= 0 {
+			rest, serverName = rest[:i], rest[i+1:]
+		}
+		// The host is not resolved here so a hostname upstream keeps
+		// its name for certificate verification.
+		addr, err := ensureRecursorPort(rest, 853)
+		if err != nil {
+			return "", err
 		}
+		if serverName != "" {
+			addr += "#" + serverName
+		}
+		return "tls://" + addr, nil
+
+	case strings.HasPrefix(recursor, "https://"):
+		u, err := url.Parse(recursor)
+		if err != nil {
+			return "", err
+		}
+		if u.Host == "" {
+			return "", fmt.Errorf("no host in DNS-over-HTTPS recursor %q", recursor)
+		}
+		if u.Path == "" {
+			u.Path = "/dns-query"
+		}
+		return u.String(), nil
 	}
+
+	recursor, err := ensureRecursorPort(recursor, 53)
 	if err != nil {
 		return "", err
 	}
@@ -333,6 +476,47 @@ START:
 	return addr.String(), nil
 }
 
+// ensureRecursorPort adds the default port to an address if it has none.
+func ensureRecursorPort(addr string, defaultPort int) (string, error) {
+START:
+	_, _, err := net.SplitHostPort(addr)
+	if ae, ok := err.(*net.AddrError); ok {
+		if ae.Err == "missing port in address" {
+			addr = ipaddr.FormatAddressPort(addr, defaultPort)
+			goto START
+		} else if ae.Err == "too many colons in address" {
+			if ip := net.ParseIP(addr); ip != nil && ip.To4() == nil {
+				addr = ipaddr.FormatAddressPort(addr, defaultPort)
+				goto START
+			}
+		}
+	}
+	if err != nil {
+		return "", err
+	}
+	return addr, nil
+}
+
+// exchangeRecursor forwards one query to a single upstream recursor,
+// selecting the transport from the address form produced by recursorAddr.
+func (d *DNSServer) exchangeRecursor(req *dns.Msg, recursor, network string, timeout time.Duration) (*dns.Msg, time.Duration, error) {
+	switch {
+	case strings.HasPrefix(recursor, "tls://"):
+		addr := strings.TrimPrefix(recursor, "tls://")
+		c := &dns.Client{Net: "tcp-tls", Timeout: timeout}
+		if i := strings.Index(addr, "#"); i >= 0 {
+			c.TLSConfig = &tls.Config{ServerName: addr[i+1:]}
+			addr = addr[:i]
+		}
+		return c.Exchange(req, addr)
+	case strings.HasPrefix(recursor, "https://"):
+		return exchangeDOH(req, recursor, timeout)
+	default:
+		c := &dns.Client{Net: network, Timeout: timeout}
+		return c.Exchange(req, recursor)
+	}
+}
+
 func serviceNodeCanonicalDNSName(sn *structs.ServiceNode, domain string) string {
 	return serviceCanonicalDNSName(sn.ServiceName, "service", sn.Datacenter, domain, &sn.EnterpriseMeta)
 }
@@ -353,6 +537,7 @@ func (d *DNSServer) handlePtr(resp dns.ResponseWriter, req *dns.Msg) {
 			"client", resp.RemoteAddr().String(),
 			"client_network", resp.RemoteAddr().Network(),
 		)
+		d.logSlowQuery(time.Since(s), q.Name, q.Qtype, resp)
 	}(time.Now())
 
 	cfg := d.config.Load().(*dnsConfig)
@@ -457,6 +642,7 @@ func (d *DNSServer) handleQuery(resp dns.ResponseWriter, req *dns.Msg) {
 			"client", resp.RemoteAddr().String(),
 			"client_network", resp.RemoteAddr().Network(),
 		)
+		d.logSlowQuery(time.Since(s), q.Name, q.Qtype, resp)
 	}(time.Now())
 
 	// Switch to TCP if the client is
@@ -601,7 +787,10 @@ func (d *DNSServer) invalidQuery(req, resp *dns.Msg, cfg *dnsConfig, qName strin
 func (d *DNSServer) parseDatacenter(labels []string, datacenter *string) bool {
 	switch len(labels) {
 	case 1:
-		*datacenter = labels[0]
+		*datacenter = d.agent.config.ResolveDatacenter(labels[0])
+		if *datacenter != labels[0] {
+			d.logger.Debug("datacenter alias used in query", "alias", labels[0], "datacenter", *datacenter)
+		}
 		return true
 	case 0:
 		return true
@@ -695,6 +884,9 @@ func (d *DNSServer) doDispatch(network string, remoteAddr net.Addr, req, resp *d
 
 			lookup.Tag = tag
 			lookup.Service = queryParts[0][1:]
+			if !cfg.tagAllowed(lookup.Tag) {
+				return invalid()
+			}
 			// _name._tag.service.consul
 			d.serviceLookup(cfg, lookup, req, resp)
 
@@ -709,6 +901,9 @@ func (d *DNSServer) doDispatch(network string, remoteAddr net.Addr, req, resp *d
 
 			lookup.Tag = tag
 			lookup.Service = queryParts[n-1]
+			if !cfg.tagAllowed(lookup.Tag) {
+				return invalid()
+			}
 
 			// tag[.tag].name.service.consul
 			d.serviceLookup(cfg, lookup, req, resp)
@@ -900,7 +1095,7 @@ func (d *DNSServer) nodeLookup(cfg *dnsConfig, datacenter, node string, req, res
 	}
 
 	if cfg.NodeMetaTXT || qType == dns.TypeTXT || qType == dns.TypeANY {
-		metas := d.generateMeta(q.Name, n, cfg.NodeTTL)
+		metas := d.generateMeta(cfg, q.Name, n, cfg.NodeTTL)
 		*metaTarget = append(*metaTarget, metas...)
 	}
 }
@@ -1224,6 +1419,10 @@ func (d *DNSServer) serviceLookup(cfg *dnsConfig, lookup serviceLookup, req, res
 	// Perform a random shuffle
 	out.Nodes.Shuffle()
 
+	if cfg.MaxResults > 0 && len(out.Nodes) > cfg.MaxResults {
+		out.Nodes = out.Nodes[:cfg.MaxResults]
+	}
+
 	// Determine the TTL
 	ttl, _ := cfg.GetTTLForService(lookup.Service)
 
@@ -1346,6 +1545,12 @@ func (d *DNSServer) preparedQueryLookup(cfg *dnsConfig, network, datacenter, que
 		return
 	}
 
+	// The query execution already shuffles the nodes, so the cap keeps a
+	// random subset.
+	if cfg.MaxResults > 0 && len(out.Nodes) > cfg.MaxResults {
+		out.Nodes = out.Nodes[:cfg.MaxResults]
+	}
+
 	// Add various responses depending on the request.
 	qType := req.Question[0].Qtype
 	if qType == dns.TypeSRV {
@@ -1758,9 +1963,12 @@ func (d *DNSServer) nodeServiceRecords(dc string, node structs.CheckServiceNode,
 	return d.makeRecordFromFQDN(dc, serviceAddr, node, req, ttl, cfg, maxRecursionLevel)
 }
 
-func (d *DNSServer) generateMeta(qName string, node *structs.Node, ttl time.Duration) []dns.RR {
+func (d *DNSServer) generateMeta(cfg *dnsConfig, qName string, node *structs.Node, ttl time.Duration) []dns.RR {
 	extra := make([]dns.RR, 0, len(node.Meta))
 	for key, value := range node.Meta {
+		if cfg.NodeMetaTXTKeys != nil && !cfg.NodeMetaTXTKeys[key] {
+			continue
+		}
 		txt := value
 		if !strings.HasPrefix(strings.ToLower(key), "rfc1035-") {
 			txt = encodeKVasRFC1464(key, value)
@@ -1800,7 +2008,7 @@ func (d *DNSServer) serviceSRVRecords(cfg *dnsConfig, dc string, nodes structs.C
 		resp.Extra = append(resp.Extra, extra...)
 
 		if cfg.NodeMetaTXT {
-			resp.Extra = append(resp.Extra, d.generateMeta(fmt.Sprintf("%s.node.%s.%s", node.Node.Node, dc, d.domain), node.Node, ttl)...)
+			resp.Extra = append(resp.Extra, d.generateMeta(cfg, fmt.Sprintf("%s.node.%s.%s", node.Node.Node, dc, d.domain), node.Node, ttl)...)
 		}
 	}
 }
@@ -1819,6 +2027,7 @@ func (d *DNSServer) handleRecurse(resp dns.ResponseWriter, req *dns.Msg) {
 			"client", resp.RemoteAddr().String(),
 			"client_network", resp.RemoteAddr().Network(),
 		)
+		d.logSlowQuery(time.Since(s), q.Name, q.Qtype, resp)
 	}(time.Now())
 
 	// Switch to TCP if the client is
@@ -1827,12 +2036,11 @@ func (d *DNSServer) handleRecurse(resp dns.ResponseWriter, req *dns.Msg) {
 	}
 
 	// Recursively resolve
-	c := &dns.Client{Net: network, Timeout: cfg.RecursorTimeout}
 	var r *dns.Msg
 	var rtt time.Duration
 	var err error
 	for _, recursor := range cfg.Recursors {
-		r, rtt, err = c.Exchange(req, recursor)
+		r, rtt, err = d.exchangeRecursor(req, recursor, network, cfg.RecursorTimeout)
 		// Check if the response is valid and has the desired Response code
 		if r != nil && (r.Rcode != dns.RcodeSuccess && r.Rcode != dns.RcodeNameError) {
 			d.logger.Debug("recurse failed for question",
@@ -1911,12 +2119,11 @@ func (d *DNSServer) resolveCNAME(cfg *dnsConfig, name string, maxRecursionLevel
 	m.SetQuestion(name, dns.TypeA)
 
 	// Make a DNS lookup request
-	c := &dns.Client{Net: "udp", Timeout: cfg.RecursorTimeout}
 	var r *dns.Msg
 	var rtt time.Duration
 	var err error
 	for _, recursor := range cfg.Recursors {
-		r, rtt, err = c.Exchange(m, recursor)
+		r, rtt, err = d.exchangeRecursor(m, recursor, "udp", cfg.RecursorTimeout)
 		if err == nil {
 			d.logger.Debug("cname recurse RTT for name",
 				"name", name,
@@ -0,0 +1,186 @@
+package agent
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/miekg/dns"
+)
+
+// dohMaxRequestSize bounds the size of an encoded DNS-over-HTTPS request.
+// DNS messages cannot exceed 64 KiB.
+const dohMaxRequestSize = 65535
+
+// dohContentType is the media type for DNS messages defined by RFC 8484.
+const dohContentType = "application/dns-message"
+
+// dohResponseWriter is a minimal dns.ResponseWriter that captures the
+// response message so it can be returned over HTTP instead of a DNS
+// listener. The remote address is reported as a TCP address so the handlers
+// do not apply UDP truncation.
+type dohResponseWriter struct {
+	msg        *dns.Msg
+	localAddr  net.Addr
+	remoteAddr net.Addr
+}
+
+func (w *dohResponseWriter) LocalAddr() net.Addr  { return w.localAddr }
+func (w *dohResponseWriter) RemoteAddr() net.Addr { return w.remoteAddr }
+
+func (w *dohResponseWriter) WriteMsg(m *dns.Msg) error {
+	w.msg = m
+	return nil
+}
+
+func (w *dohResponseWriter) Write(b []byte) (int, error) {
+	m := new(dns.Msg)
+	if err := m.Unpack(b); err != nil {
+		return 0, err
+	}
+	w.msg = m
+	return len(b), nil
+}
+
+func (w *dohResponseWriter) Close() error        { return nil }
+func (w *dohResponseWriter) TsigStatus() error   { return nil }
+func (w *dohResponseWriter) TsigTimersOnly(bool) {}
+func (w *dohResponseWriter) Hijack()             {}
+
+// ServeDNSMessage resolves a single DNS message through the server's regular
+// handler pipeline and returns the response. It is used by the
+// DNS-over-HTTPS endpoint which receives messages over HTTP rather than a
+// DNS listener.
+func (d *DNSServer) ServeDNSMessage(msg *dns.Msg, remoteAddr net.Addr) *dns.Msg {
+	if d.mux == nil {
+		return nil
+	}
+	w := &dohResponseWriter{
+		localAddr:  &net.TCPAddr{IP: net.IPv4zero},
+		remoteAddr: remoteAddr,
+	}
+	d.mux.ServeDNS(w, msg)
+	return w.msg
+}
+
+// handleDOH serves DNS-over-HTTPS (RFC 8484) queries on the HTTP(S)
+// listeners under the path configured by dns_config.doh_path.
+func (s *HTTPHandlers) handleDOH(resp http.ResponseWriter, req *http.Request) {
+	var payload []byte
+	var err error
+	switch req.Method {
+	case "GET":
+		q := req.URL.Query().Get("dns")
+		if q == "" {
+			http.Error(resp, "missing 'dns' query parameter", http.StatusBadRequest)
+			return
+		}
+		payload, err = base64.RawURLEncoding.DecodeString(q)
+		if err != nil {
+			http.Error(resp, "'dns' query parameter is not valid base64url", http.StatusBadRequest)
+			return
+		}
+	case "POST":
+		if ct := req.Header.Get("Content-Type"); ct != dohContentType {
+			http.Error(resp, fmt.Sprintf("Content-Type must be %s", dohContentType), http.StatusUnsupportedMediaType)
+			return
+		}
+		payload, err = ioutil.ReadAll(io.LimitReader(req.Body, dohMaxRequestSize+1))
+		if err != nil {
+			http.Error(resp, "failed to read request body", http.StatusBadRequest)
+			return
+		}
+		if len(payload) > dohMaxRequestSize {
+			http.Error(resp, "request body too large", http.StatusRequestEntityTooLarge)
+			return
+		}
+	default:
+		resp.Header().Set("Allow", "GET, POST")
+		http.Error(resp, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	msg := new(dns.Msg)
+	if err := msg.Unpack(payload); err != nil {
+		http.Error(resp, "invalid DNS message", http.StatusBadRequest)
+		return
+	}
+
+	if len(s.agent.dnsServers) == 0 {
+		http.Error(resp, "DNS is disabled", http.StatusServiceUnavailable)
+		return
+	}
+
+	remoteAddr, err := net.ResolveTCPAddr("tcp", req.RemoteAddr)
+	if err != nil {
+		remoteAddr = &net.TCPAddr{IP: net.IPv4zero}
+	}
+	reply := s.agent.dnsServers[0].ServeDNSMessage(msg, remoteAddr)
+	if reply == nil {
+		http.Error(resp, "no response from DNS handler", http.StatusInternalServerError)
+		return
+	}
+
+	out, err := reply.Pack()
+	if err != nil {
+		http.Error(resp, "failed to encode DNS response", http.StatusInternalServerError)
+		return
+	}
+
+	resp.Header().Set("Content-Type", dohContentType)
+	// RFC 8484 recommends deriving HTTP freshness from the answer TTLs so
+	// that caches do not serve records past their DNS expiry.
+	maxAge := -1
+	for _, rr := range reply.Answer {
+		if ttl := int(rr.Header().Ttl); maxAge == -1 || ttl < maxAge {
+			maxAge = ttl
+		}
+	}
+	if maxAge >= 0 {
+		resp.Header().Set("Cache-Control", fmt.Sprintf("max-age=%d", maxAge))
+	}
+	resp.Write(out)
+}
+
+// exchangeDOH forwards a query to a DNS-over-HTTPS upstream recursor as an
+// RFC 8484 POST. The client uses the default transport, so DoH recursion
+// also traverses a configured outbound proxy.
+func exchangeDOH(req *dns.Msg, upstream string, timeout time.Duration) (*dns.Msg, time.Duration, error) {
+	start := time.Now()
+	packed, err := req.Pack()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	httpReq, err := http.NewRequest("POST", upstream, bytes.NewReader(packed))
+	if err != nil {
+		return nil, 0, err
+	}
+	httpReq.Header.Set("Content-Type", dohContentType)
+	httpReq.Header.Set("Accept", dohContentType)
+
+	client := &http.Client{Timeout: timeout}
+	httpResp, err := client.Do(httpReq)
+	if err != nil {
+		return nil, time.Since(start), err
+	}
+	defer httpResp.Body.Close()
+	if httpResp.StatusCode != http.StatusOK {
+		return nil, time.Since(start), fmt.Errorf("DNS-over-HTTPS upstream returned %s", httpResp.Status)
+	}
+
+	body, err := ioutil.ReadAll(io.LimitReader(httpResp.Body, dohMaxRequestSize+1))
+	if err != nil {
+		return nil, time.Since(start), err
+	}
+	r := new(dns.Msg)
+	if err := r.Unpack(body); err != nil {
+		return nil, time.Since(start), err
+	}
+	return r, time.Since(start), nil
+}
@@ -0,0 +1,138 @@
+package agent
+
+import (
+	"bytes"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hashicorp/consul/agent/structs"
+	"github.com/hashicorp/consul/testrpc"
+)
+
+func TestDNS_DOH(t *testing.T) {
+	if testing.Short() {
+		t.Skip("too slow for testing.Short")
+	}
+
+	t.Parallel()
+	a := NewTestAgent(t, `dns_config { doh_path = "/dns-query" }`)
+	defer a.Shutdown()
+	testrpc.WaitForLeader(t, a.RPC, "dc1")
+
+	args := &structs.RegisterRequest{
+		Datacenter: "dc1",
+		Node:       "foo",
+		Address:    "127.0.0.55",
+	}
+	var out struct{}
+	require.NoError(t, a.RPC("Catalog.Register", args, &out))
+
+	m := new(dns.Msg)
+	m.SetQuestion("foo.node.consul.", dns.TypeA)
+	packed, err := m.Pack()
+	require.NoError(t, err)
+
+	verify := func(t *testing.T, resp *httptest.ResponseRecorder) {
+		require.Equal(t, http.StatusOK, resp.Code)
+		require.Equal(t, "application/dns-message", resp.Header().Get("Content-Type"))
+
+		reply := new(dns.Msg)
+		require.NoError(t, reply.Unpack(resp.Body.Bytes()))
+		require.Len(t, reply.Answer, 1)
+		aRec, ok := reply.Answer[0].(*dns.A)
+		require.True(t, ok)
+		require.Equal(t, "127.0.0.55", aRec.A.String())
+	}
+
+	t.Run("GET", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/dns-query?dns="+base64.RawURLEncoding.EncodeToString(packed), nil)
+		resp := httptest.NewRecorder()
+		a.srv.handleDOH(resp, req)
+		verify(t, resp)
+	})
+
+	t.Run("POST", func(t *testing.T) {
+		req, _ := http.NewRequest("POST", "/dns-query", bytes.NewReader(packed))
+		req.Header.Set("Content-Type", "application/dns-message")
+		resp := httptest.NewRecorder()
+		a.srv.handleDOH(resp, req)
+		verify(t, resp)
+	})
+
+	t.Run("POST wrong content type", func(t *testing.T) {
+		req, _ := http.NewRequest("POST", "/dns-query", bytes.NewReader(packed))
+		req.Header.Set("Content-Type", "application/json")
+		resp := httptest.NewRecorder()
+		a.srv.handleDOH(resp, req)
+		require.Equal(t, http.StatusUnsupportedMediaType, resp.Code)
+	})
+
+	t.Run("GET bad encoding", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/dns-query?dns=!!!", nil)
+		resp := httptest.NewRecorder()
+		a.srv.handleDOH(resp, req)
+		require.Equal(t, http.StatusBadRequest, resp.Code)
+	})
+
+	t.Run("method not allowed", func(t *testing.T) {
+		req, _ := http.NewRequest("PUT", "/dns-query", nil)
+		resp := httptest.NewRecorder()
+		a.srv.handleDOH(resp, req)
+		require.Equal(t, http.StatusMethodNotAllowed, resp.Code)
+	})
+}
+
+func TestExchangeDOH(t *testing.T) {
+	t.Parallel()
+	srv := httptest.NewServer(http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+		require.Equal(t, "POST", req.Method)
+		require.Equal(t, dohContentType, req.Header.Get("Content-Type"))
+
+		msg := new(dns.Msg)
+		payload := make([]byte, dohMaxRequestSize)
+		n, _ := req.Body.Read(payload)
+		require.NoError(t, msg.Unpack(payload[:n]))
+
+		reply := new(dns.Msg)
+		reply.SetReply(msg)
+		reply.Answer = append(reply.Answer, &dns.A{
+			Hdr: dns.RR_Header{Name: msg.Question[0].Name, Rrtype: dns.TypeA, Class: dns.ClassINET, Ttl: 30},
+			A:   []byte{192, 0, 2, 10},
+		})
+		out, err := reply.Pack()
+		require.NoError(t, err)
+		resp.Header().Set("Content-Type", dohContentType)
+		resp.Write(out)
+	}))
+	defer srv.Close()
+
+	req := new(dns.Msg)
+	req.SetQuestion("upstream.example.com.", dns.TypeA)
+
+	r, rtt, err := exchangeDOH(req, srv.URL+"/dns-query", 5*time.Second)
+	require.NoError(t, err)
+	require.NotZero(t, rtt)
+	require.Len(t, r.Answer, 1)
+	require.Equal(t, req.Id, r.Id)
+}
+
+func TestExchangeDOH_HTTPError(t *testing.T) {
+	t.Parallel()
+	srv := httptest.NewServer(http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+		http.Error(resp, "nope", http.StatusBadGateway)
+	}))
+	defer srv.Close()
+
+	req := new(dns.Msg)
+	req.SetQuestion("upstream.example.com.", dns.TypeA)
+
+	_, _, err := exchangeDOH(req, srv.URL+"/dns-query", 5*time.Second)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "502")
+}
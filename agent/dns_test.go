@@ -124,6 +124,31 @@ func TestRecursorAddr(t *testing.T) {
 	if err == nil || !strings.Contains(err.Error(), "too many colons in address") {
 		t.Fatalf("err: %v", err)
 	}
+	addr, err = recursorAddr("tls://1.1.1.1")
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if addr != "tls://1.1.1.1:853" {
+		t.Fatalf("bad: %v", addr)
+	}
+	addr, err = recursorAddr("tls://9.9.9.9:8853#dns.quad9.net")
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if addr != "tls://9.9.9.9:8853#dns.quad9.net" {
+		t.Fatalf("bad: %v", addr)
+	}
+	addr, err = recursorAddr("https://dns.example.com")
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if addr != "https://dns.example.com/dns-query" {
+		t.Fatalf("bad: %v", addr)
+	}
+	_, err = recursorAddr("https:///dns-query")
+	if err == nil || !strings.Contains(err.Error(), "no host") {
+		t.Fatalf("err: %v", err)
+	}
 }
 
 func TestEncodeKVasRFC1464(t *testing.T) {
@@ -3160,6 +3185,69 @@ func TestDNS_ServiceLookup_TagPeriod(t *testing.T) {
 	}
 }
 
+func TestDNS_ServiceLookup_TagAllowlist(t *testing.T) {
+	if testing.Short() {
+		t.Skip("too slow for testing.Short")
+	}
+
+	t.Parallel()
+	a := NewTestAgent(t, `dns_config = { tag_allowlist = ["primary", "v*"] }`)
+	defer a.Shutdown()
+	testrpc.WaitForLeader(t, a.RPC, "dc1")
+
+	// Register node
+	args := &structs.RegisterRequest{
+		Datacenter: "dc1",
+		Node:       "foo",
+		Address:    "127.0.0.1",
+		Service: &structs.NodeService{
+			Service: "db",
+			Tags:    []string{"primary", "v1", "internal-hint"},
+			Port:    12345,
+		},
+	}
+
+	var out struct{}
+	if err := a.RPC("Catalog.Register", args, &out); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	// allowlisted tags resolve
+	for _, question := range []string{
+		"primary.db.service.consul.",
+		"v1.db.service.consul.",
+		"db.service.consul.",
+	} {
+		m := new(dns.Msg)
+		m.SetQuestion(question, dns.TypeSRV)
+
+		c := new(dns.Client)
+		in, _, err := c.Exchange(m, a.DNSAddr())
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		if len(in.Answer) != 1 {
+			t.Fatalf("Bad: %#v", in)
+		}
+	}
+
+	// tags outside the allowlist return a name error
+	m := new(dns.Msg)
+	m.SetQuestion("internal-hint.db.service.consul.", dns.TypeSRV)
+
+	c := new(dns.Client)
+	in, _, err := c.Exchange(m, a.DNSAddr())
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if in.Rcode != dns.RcodeNameError {
+		t.Fatalf("Bad: %#v", in)
+	}
+	if len(in.Answer) != 0 {
+		t.Fatalf("Bad: %#v", in)
+	}
+}
+
 func TestDNS_PreparedQueryNearIPEDNS(t *testing.T) {
 	if testing.Short() {
 		t.Skip("too slow for testing.Short")
@@ -4289,6 +4377,47 @@ func TestDNS_ServiceLookup_OnlyPassing(t *testing.T) {
 	require.Equal(t, []string{"127.0.0.1", "127.0.0.2"}, ips)
 }
 
+func TestDNS_ServiceLookup_DiscoveryMaxResults(t *testing.T) {
+	if testing.Short() {
+		t.Skip("too slow for testing.Short")
+	}
+
+	t.Parallel()
+	a := NewTestAgent(t, `discovery_max_results { dns = 2 }`)
+	defer a.Shutdown()
+	testrpc.WaitForLeader(t, a.RPC, "dc1")
+
+	// Register more instances than the configured cap.
+	for i := 0; i < 6; i++ {
+		args := &structs.RegisterRequest{
+			Datacenter: "dc1",
+			Node:       fmt.Sprintf("foo%d", i),
+			Address:    fmt.Sprintf("127.0.0.%d", i+1),
+			Service: &structs.NodeService{
+				Service: "web",
+				Port:    8000,
+			},
+		}
+
+		var out struct{}
+		if err := a.RPC("Catalog.Register", args, &out); err != nil {
+			t.Fatalf("err: %v", err)
+		}
+	}
+
+	m := new(dns.Msg)
+	m.SetQuestion("web.service.consul.", dns.TypeSRV)
+
+	c := &dns.Client{Net: "udp"}
+	in, _, err := c.Exchange(m, a.DNSAddr())
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if len(in.Answer) != 2 {
+		t.Fatalf("Bad: %#v", in)
+	}
+}
+
 func TestDNS_ServiceLookup_Randomize(t *testing.T) {
 	if testing.Short() {
 		t.Skip("too slow for testing.Short")
@@ -5957,6 +6086,58 @@ func TestDNS_ServiceLookup_MetaTXT(t *testing.T) {
 	require.Equal(t, wantAdditional, in.Extra)
 }
 
+func TestDNS_ServiceLookup_MetaTXT_Filtered(t *testing.T) {
+	if testing.Short() {
+		t.Skip("too slow for testing.Short")
+	}
+
+	a := NewTestAgent(t, `dns_config = { enable_additional_node_meta_txt = true node_meta_txt_keys = ["rack"] }`)
+	defer a.Shutdown()
+	testrpc.WaitForLeader(t, a.RPC, "dc1")
+
+	args := &structs.RegisterRequest{
+		Datacenter: "dc1",
+		Node:       "bar",
+		Address:    "127.0.0.1",
+		NodeMeta: map[string]string{
+			"rack":   "r1",
+			"secret": "hunter2",
+		},
+		Service: &structs.NodeService{
+			Service: "db",
+			Tags:    []string{"primary"},
+			Port:    12345,
+		},
+	}
+
+	var out struct{}
+	if err := a.RPC("Catalog.Register", args, &out); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	m := new(dns.Msg)
+	m.SetQuestion("db.service.consul.", dns.TypeSRV)
+
+	c := new(dns.Client)
+	in, _, err := c.Exchange(m, a.DNSAddr())
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	// only the filtered key is exposed over DNS
+	wantAdditional := []dns.RR{
+		&dns.A{
+			Hdr: dns.RR_Header{Name: "bar.node.dc1.consul.", Rrtype: dns.TypeA, Class: dns.ClassINET, Rdlength: 0x4},
+			A:   []byte{0x7f, 0x0, 0x0, 0x1}, // 127.0.0.1
+		},
+		&dns.TXT{
+			Hdr: dns.RR_Header{Name: "bar.node.dc1.consul.", Rrtype: dns.TypeTXT, Class: dns.ClassINET, Rdlength: 0x8},
+			Txt: []string{"rack=r1"},
+		},
+	}
+	require.Equal(t, wantAdditional, in.Extra)
+}
+
 func TestDNS_ServiceLookup_SuppressTXT(t *testing.T) {
 	if testing.Short() {
 		t.Skip("too slow for testing.Short")
@@ -0,0 +1,8 @@
+// +build !linux
+
+package exec
+
+// ApplyLimits is a no-op on platforms without prlimit support.
+func ApplyLimits(pid int, cpuSeconds int, memoryBytes int64, nice int) error {
+	return nil
+}
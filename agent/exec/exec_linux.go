@@ -0,0 +1,43 @@
+// +build linux
+
+package exec
+
+import (
+	"fmt"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+// ApplyLimits applies the given rlimits and nice value to a started
+// process. Zero values leave the corresponding limit unset.
+func ApplyLimits(pid int, cpuSeconds int, memoryBytes int64, nice int) error {
+	if cpuSeconds > 0 {
+		if err := prlimit(pid, unix.RLIMIT_CPU, uint64(cpuSeconds)); err != nil {
+			return fmt.Errorf("failed to set CPU limit: %s", err)
+		}
+	}
+	if memoryBytes > 0 {
+		if err := prlimit(pid, unix.RLIMIT_AS, uint64(memoryBytes)); err != nil {
+			return fmt.Errorf("failed to set memory limit: %s", err)
+		}
+	}
+	if nice != 0 {
+		if err := unix.Setpriority(unix.PRIO_PROCESS, pid, nice); err != nil {
+			return fmt.Errorf("failed to set nice value: %s", err)
+		}
+	}
+	return nil
+}
+
+// prlimit sets both the soft and hard limit of the given resource on
+// another process via the prlimit64 syscall.
+func prlimit(pid int, resource int, limit uint64) error {
+	lim := unix.Rlimit{Cur: limit, Max: limit}
+	_, _, errno := unix.RawSyscall6(unix.SYS_PRLIMIT64,
+		uintptr(pid), uintptr(resource), uintptr(unsafe.Pointer(&lim)), 0, 0, 0)
+	if errno != 0 {
+		return errno
+	}
+	return nil
+}
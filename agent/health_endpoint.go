@@ -247,6 +247,12 @@ func (s *HTTPHandlers) healthServiceNodes(resp http.ResponseWriter, req *http.Re
 		out.Nodes = filterNonPassing(out.Nodes)
 	}
 
+	// Cap the fan-out after filtering so oversized services do not
+	// balloon the response.
+	if limit := s.agent.config.DiscoveryMaxResultsHTTP; limit > 0 && len(out.Nodes) > limit {
+		out.Nodes = out.Nodes[:limit]
+	}
+
 	// Translate addresses after filtering so we don't waste effort.
 	s.agent.TranslateAddresses(args.Datacenter, out.Nodes, TranslateAddressAcceptAny)
 
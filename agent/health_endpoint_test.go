@@ -597,6 +597,46 @@ func TestHealthServiceChecks_DistanceSort(t *testing.T) {
 	})
 }
 
+func TestHealthServiceNodes_DiscoveryMaxResults(t *testing.T) {
+	if testing.Short() {
+		t.Skip("too slow for testing.Short")
+	}
+
+	t.Parallel()
+	a := NewTestAgent(t, `discovery_max_results { http = 2 }`)
+	defer a.Shutdown()
+	testrpc.WaitForTestAgent(t, a.RPC, "dc1")
+
+	for i := 0; i < 5; i++ {
+		args := &structs.RegisterRequest{
+			Datacenter: "dc1",
+			Node:       fmt.Sprintf("node-%d", i),
+			Address:    fmt.Sprintf("10.0.0.%d", i+1),
+			Service: &structs.NodeService{
+				ID:      "test",
+				Service: "test",
+			},
+		}
+		var out struct{}
+		require.NoError(t, a.RPC("Catalog.Register", args, &out))
+	}
+
+	req, _ := http.NewRequest("GET", "/v1/health/service/test?dc=dc1", nil)
+	resp := httptest.NewRecorder()
+	obj, err := a.srv.HealthServiceNodes(resp, req)
+	require.NoError(t, err)
+
+	nodes := obj.(structs.CheckServiceNodes)
+	require.Len(t, nodes, 2)
+
+	// The catalog endpoint is capped by the same limit.
+	req, _ = http.NewRequest("GET", "/v1/catalog/service/test?dc=dc1", nil)
+	resp = httptest.NewRecorder()
+	obj, err = a.srv.CatalogServiceNodes(resp, req)
+	require.NoError(t, err)
+	require.Len(t, obj.(structs.ServiceNodes), 2)
+}
+
 func TestHealthServiceNodes(t *testing.T) {
 	if testing.Short() {
 		t.Skip("too slow for testing.Short")
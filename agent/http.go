@@ -1,6 +1,7 @@
 package agent
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -19,6 +20,7 @@ import (
 	"github.com/armon/go-metrics"
 	"github.com/armon/go-metrics/prometheus"
 	"github.com/hashicorp/go-cleanhttp"
+	"github.com/hashicorp/go-uuid"
 	"github.com/mitchellh/mapstructure"
 	"github.com/pkg/errors"
 
@@ -40,6 +42,25 @@ var HTTPSummaries = []prometheus.SummaryDefinition{
 	},
 }
 
+var HTTPCounters = []prometheus.CounterDefinition{
+	{
+		Name: []string{"agent", "http", "blocked"},
+		Help: "Increments for each HTTP request blocked by a http_config.block_endpoint_rules rule, labeled by rule name.",
+	},
+}
+
+// requestIDContextKey is the context key under which wrap stores the request
+// id taken from, or generated for, the configured
+// http_config.request_id_header.
+type requestIDContextKey struct{}
+
+// RequestIDFromContext returns the request id associated with the request
+// context, or "" if request id handling is not enabled.
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDContextKey{}).(string)
+	return id
+}
+
 // MethodNotAllowedError should be returned by a handler when the HTTP method is not allowed.
 type MethodNotAllowedError struct {
 	Method string
@@ -282,6 +303,11 @@ func (s *HTTPHandlers) handler(enableDebug bool) http.Handler {
 	handlePProf("/debug/pprof/symbol", pprof.Symbol)
 	handlePProf("/debug/pprof/trace", pprof.Trace)
 
+	// Register the DNS-over-HTTPS handler (RFC 8484) when configured.
+	if path := s.agent.config.DNSDOHPath; path != "" {
+		handleFuncMetrics(path, s.handleDOH)
+	}
+
 	if s.IsUIEnabled() {
 		// Note that we _don't_ support reloading ui_config.{enabled, content_dir,
 		// content_path} since this only runs at initial startup.
@@ -368,6 +394,25 @@ func (s *HTTPHandlers) wrap(handler endpoint, methods []string) http.HandlerFunc
 		setTranslateAddr(resp, s.agent.config.TranslateWANAddrs)
 		setACLDefaultPolicy(resp, s.agent.config.ACLDefaultPolicy)
 
+		// Accept or generate the request id before anything is logged so
+		// that every log entry for this request can be correlated. The id
+		// is echoed on the response and stored in the request context for
+		// handlers that forward it.
+		httpLogger := httpLogger
+		if header := s.agent.config.HTTPRequestIDHeader; header != "" {
+			reqID := req.Header.Get(header)
+			if reqID == "" {
+				if id, err := uuid.GenerateUUID(); err == nil {
+					reqID = id
+				}
+			}
+			if reqID != "" {
+				resp.Header().Set(header, reqID)
+				req = req.WithContext(context.WithValue(req.Context(), requestIDContextKey{}, reqID))
+				httpLogger = httpLogger.With("request_id", reqID)
+			}
+		}
+
 		// Obfuscate any tokens from appearing in the logs
 		formVals, err := url.ParseQuery(req.URL.RawQuery)
 		if err != nil {
@@ -403,6 +448,36 @@ func (s *HTTPHandlers) wrap(handler endpoint, methods []string) http.HandlerFunc
 			return
 		}
 
+		if rule := s.denylist.MatchRule(req.Method, req.URL.Path); rule != nil {
+			metrics.IncrCounterWithLabels([]string{"agent", "http", "blocked"}, 1,
+				[]metrics.Label{{Name: "rule", Value: rule.Name}})
+			httpLogger.Error("Request error",
+				"method", req.Method,
+				"url", logURL,
+				"from", req.RemoteAddr,
+				"error", rule.ResponseMessage,
+				"rule", rule.Name,
+			)
+			resp.WriteHeader(rule.ResponseCode)
+			fmt.Fprint(resp, rule.ResponseMessage)
+			return
+		}
+
+		for _, e := range s.agent.config.HTTPDisableLegacyEndpoints {
+			if strings.HasPrefix(req.URL.Path, e) {
+				errMsg := "Legacy endpoint is disabled by agent configuration"
+				httpLogger.Error("Request error",
+					"method", req.Method,
+					"url", logURL,
+					"from", req.RemoteAddr,
+					"error", errMsg,
+				)
+				resp.WriteHeader(http.StatusForbidden)
+				fmt.Fprint(resp, errMsg)
+				return
+			}
+		}
+
 		isForbidden := func(err error) bool {
 			if acl.IsErrPermissionDenied(err) || acl.IsErrNotFound(err) {
 				return true
@@ -471,14 +546,32 @@ func (s *HTTPHandlers) wrap(handler endpoint, methods []string) http.HandlerFunc
 			}
 		}
 
+		span := s.agent.tracer.StartSpan("HTTP " + req.Method + " " + req.URL.Path)
+		span.SetAttribute("http.method", req.Method)
+		span.SetAttribute("http.url", logURL)
+		span.SetAttribute("peer.address", req.RemoteAddr)
+
 		start := time.Now()
 		defer func() {
+			span.Finish(err)
+			elapsed := time.Since(start)
 			httpLogger.Debug("Request finished",
 				"method", req.Method,
 				"url", logURL,
 				"from", req.RemoteAddr,
-				"latency", time.Since(start).String(),
+				"latency", elapsed.String(),
 			)
+			// surface requests, including blocking queries, that exceeded
+			// the configured slow query threshold
+			if threshold := s.agent.config.LoggingSlowQueryThreshold; threshold > 0 && elapsed > threshold {
+				httpLogger.Warn("Slow request",
+					"method", req.Method,
+					"url", logURL,
+					"from", req.RemoteAddr,
+					"latency", elapsed.String(),
+					"threshold", threshold.String(),
+				)
+			}
 		}()
 
 		var obj interface{}
@@ -902,7 +995,10 @@ func (s *HTTPHandlers) parseConsistency(resp http.ResponseWriter, req *http.Requ
 // parseDC is used to parse the ?dc query param
 func (s *HTTPHandlers) parseDC(req *http.Request, dc *string) {
 	if other := req.URL.Query().Get("dc"); other != "" {
-		*dc = other
+		*dc = s.agent.config.ResolveDatacenter(other)
+		if *dc != other {
+			s.agent.logger.Debug("datacenter alias used in query", "alias", other, "datacenter", *dc)
+		}
 	} else if *dc == "" {
 		*dc = s.agent.config.Datacenter
 	}
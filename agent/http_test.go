@@ -1,6 +1,7 @@
 package agent
 
 import (
+	"bufio"
 	"bytes"
 	"context"
 	"crypto/tls"
@@ -95,6 +96,94 @@ func TestHTTPServer_UnixSocket(t *testing.T) {
 	}
 }
 
+func TestHTTPServer_UnixSocket_PerListenerPermissions(t *testing.T) {
+	if testing.Short() {
+		t.Skip("too slow for testing.Short")
+	}
+
+	t.Parallel()
+	if runtime.GOOS == "windows" {
+		t.SkipNow()
+	}
+
+	tempDir := testutil.TempDir(t, "consul")
+	socket := filepath.Join(tempDir, "test.sock")
+
+	// The http sub-stanza should win over the global mode for the HTTP
+	// socket. Only testing mode, since uid/gid might not be settable from
+	// test environment.
+	a := NewTestAgent(t, `
+		addresses {
+			http = "unix://`+socket+`"
+		}
+		unix_sockets {
+			mode = "0700"
+			http {
+				mode = "0777"
+			}
+		}
+	`)
+	defer a.Shutdown()
+
+	fi, err := os.Stat(socket)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if fi.Mode().String() != "Srwxrwxrwx" {
+		t.Fatalf("bad permissions: %s", fi.Mode())
+	}
+}
+
+func TestHTTPServer_ProxyProtocol(t *testing.T) {
+	if testing.Short() {
+		t.Skip("too slow for testing.Short")
+	}
+
+	t.Parallel()
+
+	a := NewTestAgent(t, `
+		http_config {
+			proxy_protocol {
+				enabled = true
+			}
+			allow_write_http_from = [ "10.0.0.0/8" ]
+		}
+	`)
+	defer a.Shutdown()
+	testrpc.WaitForTestAgent(t, a.RPC, "dc1")
+
+	request := func(header string) string {
+		conn, err := net.Dial("tcp", a.HTTPAddr())
+		if err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		defer conn.Close()
+
+		req := header + "PUT /v1/agent/maintenance?enable=true&reason=test HTTP/1.0\r\n\r\n"
+		if _, err := conn.Write([]byte(req)); err != nil {
+			t.Fatalf("err: %s", err)
+		}
+
+		status, err := bufio.NewReader(conn).ReadString('\n')
+		if err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		return status
+	}
+
+	// A PROXY header from the load balancer carrying an allowed client
+	// address satisfies allow_write_http_from.
+	if status := request("PROXY TCP4 10.1.2.3 10.9.9.9 56324 8500\r\n"); !strings.Contains(status, "200") {
+		t.Fatalf("expected write from proxied client to be allowed, got %q", status)
+	}
+
+	// Without a header the loopback transport address is used, which is
+	// outside the allowed networks.
+	if status := request(""); !strings.Contains(status, "403") {
+		t.Fatalf("expected write from loopback to be forbidden, got %q", status)
+	}
+}
+
 func TestHTTPServer_UnixSocket_FileExists(t *testing.T) {
 	if testing.Short() {
 		t.Skip("too slow for testing.Short")
@@ -349,6 +438,157 @@ func TestHTTPAPI_BlockEndpoints(t *testing.T) {
 	}
 }
 
+func TestHTTPAPI_BlockEndpointRules(t *testing.T) {
+	if testing.Short() {
+		t.Skip("too slow for testing.Short")
+	}
+
+	t.Parallel()
+
+	a := NewTestAgent(t, `
+		http_config {
+			block_endpoint_rules = [
+				{
+					name = "no-agent-self"
+					path_regex = "^/v1/agent/self$"
+					methods = ["GET"]
+					response_code = 405
+					response_message = "self is read-blocked"
+				}
+			]
+		}
+	`)
+	defer a.Shutdown()
+
+	handler := func(resp http.ResponseWriter, req *http.Request) (interface{}, error) {
+		return nil, nil
+	}
+
+	// A request matching path and method gets the configured response.
+	{
+		req, _ := http.NewRequest("GET", "/v1/agent/self", nil)
+		resp := httptest.NewRecorder()
+		a.srv.wrap(handler, []string{"GET", "PUT"})(resp, req)
+		if got, want := resp.Code, http.StatusMethodNotAllowed; got != want {
+			t.Fatalf("bad response code got %d want %d", got, want)
+		}
+		if got, want := resp.Body.String(), "self is read-blocked"; got != want {
+			t.Fatalf("bad response body got %q want %q", got, want)
+		}
+	}
+
+	// A different method on the same path is not blocked.
+	{
+		req, _ := http.NewRequest("PUT", "/v1/agent/self", nil)
+		resp := httptest.NewRecorder()
+		a.srv.wrap(handler, []string{"GET", "PUT"})(resp, req)
+		if got, want := resp.Code, http.StatusOK; got != want {
+			t.Fatalf("bad response code got %d want %d", got, want)
+		}
+	}
+
+	// A different path is not blocked.
+	{
+		req, _ := http.NewRequest("GET", "/v1/agent/checks", nil)
+		resp := httptest.NewRecorder()
+		a.srv.wrap(handler, []string{"GET"})(resp, req)
+		if got, want := resp.Code, http.StatusOK; got != want {
+			t.Fatalf("bad response code got %d want %d", got, want)
+		}
+	}
+}
+
+func TestHTTPAPI_DisableLegacyEndpoints(t *testing.T) {
+	if testing.Short() {
+		t.Skip("too slow for testing.Short")
+	}
+
+	t.Parallel()
+
+	a := NewTestAgent(t, `
+		http_config {
+			disable_legacy_endpoints = ["/v1/acl/create", "/v1/agent/check/fail"]
+		}
+	`)
+	defer a.Shutdown()
+
+	handler := func(resp http.ResponseWriter, req *http.Request) (interface{}, error) {
+		return nil, nil
+	}
+
+	// Requests to a disabled legacy endpoint get a 403, including ones
+	// with a trailing path segment.
+	for _, path := range []string{"/v1/acl/create", "/v1/agent/check/fail/my-check"} {
+		req, _ := http.NewRequest("PUT", path, nil)
+		resp := httptest.NewRecorder()
+		a.srv.wrap(handler, []string{"PUT"})(resp, req)
+		if got, want := resp.Code, http.StatusForbidden; got != want {
+			t.Fatalf("%s: bad response code got %d want %d", path, got, want)
+		}
+		if got, want := resp.Body.String(), "Legacy endpoint is disabled by agent configuration"; got != want {
+			t.Fatalf("%s: bad response body got %q want %q", path, got, want)
+		}
+	}
+
+	// Endpoints not listed stay reachable.
+	{
+		req, _ := http.NewRequest("PUT", "/v1/agent/check/update/my-check", nil)
+		resp := httptest.NewRecorder()
+		a.srv.wrap(handler, []string{"PUT"})(resp, req)
+		if got, want := resp.Code, http.StatusOK; got != want {
+			t.Fatalf("bad response code got %d want %d", got, want)
+		}
+	}
+}
+
+func TestHTTPAPI_RequestIDHeader(t *testing.T) {
+	if testing.Short() {
+		t.Skip("too slow for testing.Short")
+	}
+
+	t.Parallel()
+
+	a := NewTestAgent(t, `
+		http_config {
+			request_id_header = "X-Request-Id"
+		}
+	`)
+	defer a.Shutdown()
+
+	var ctxID string
+	handler := func(resp http.ResponseWriter, req *http.Request) (interface{}, error) {
+		ctxID = RequestIDFromContext(req.Context())
+		return nil, nil
+	}
+
+	// An incoming id is accepted, echoed and visible to the handler.
+	{
+		req, _ := http.NewRequest("GET", "/v1/agent/checks", nil)
+		req.Header.Set("X-Request-Id", "abc-123")
+		resp := httptest.NewRecorder()
+		a.srv.wrap(handler, []string{"GET"})(resp, req)
+		if got, want := resp.Header().Get("X-Request-Id"), "abc-123"; got != want {
+			t.Fatalf("bad response header got %q want %q", got, want)
+		}
+		if got, want := ctxID, "abc-123"; got != want {
+			t.Fatalf("bad context id got %q want %q", got, want)
+		}
+	}
+
+	// Without an incoming id one is generated.
+	{
+		req, _ := http.NewRequest("GET", "/v1/agent/checks", nil)
+		resp := httptest.NewRecorder()
+		a.srv.wrap(handler, []string{"GET"})(resp, req)
+		if resp.Header().Get("X-Request-Id") == "" {
+			t.Fatal("expected a generated request id header")
+		}
+		if ctxID == "" || ctxID != resp.Header().Get("X-Request-Id") {
+			t.Fatalf("context id %q does not match header %q", ctxID, resp.Header().Get("X-Request-Id"))
+		}
+	}
+}
+
 func TestHTTPAPI_Ban_Nonprintable_Characters(t *testing.T) {
 	if testing.Short() {
 		t.Skip("too slow for testing.Short")
@@ -728,6 +968,35 @@ func testPrettyPrint(pretty string, t *testing.T) {
 	}
 }
 
+func TestParseDC_DatacenterAlias(t *testing.T) {
+	if testing.Short() {
+		t.Skip("too slow for testing.Short")
+	}
+
+	t.Parallel()
+	a := NewTestAgent(t, `
+		datacenter_aliases = {
+			"us-east-1" = "dc1"
+		}
+	`)
+	defer a.Shutdown()
+
+	req, _ := http.NewRequest("GET", "/v1/catalog/nodes?dc=us-east-1", nil)
+	var dc string
+	a.srv.parseDC(req, &dc)
+	if dc != "dc1" {
+		t.Fatalf("bad: %v", dc)
+	}
+
+	// Unaliased names pass through unchanged.
+	req, _ = http.NewRequest("GET", "/v1/catalog/nodes?dc=dc2", nil)
+	dc = ""
+	a.srv.parseDC(req, &dc)
+	if dc != "dc2" {
+		t.Fatalf("bad: %v", dc)
+	}
+}
+
 func TestParseSource(t *testing.T) {
 	if testing.Short() {
 		t.Skip("too slow for testing.Short")
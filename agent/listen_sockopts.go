@@ -0,0 +1,104 @@
+// +build !windows
+
+package agent
+
+import (
+	"context"
+	"net"
+	"os"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+
+	"github.com/hashicorp/consul/agent/config"
+)
+
+// listenTCP binds a TCP listener and applies the configured socket
+// options. A custom backlog requires building the socket by hand because
+// the standard library always listens with the kernel maximum.
+func listenTCP(addr *net.TCPAddr, opts config.ListenerSocketOptions) (net.Listener, error) {
+	if opts.Backlog > 0 {
+		return listenTCPBacklog(addr, opts)
+	}
+	lc := net.ListenConfig{Control: socketControl(opts)}
+	return lc.Listen(context.Background(), "tcp", addr.String())
+}
+
+// listenUDP binds a UDP packet conn and applies the configured socket
+// options.
+func listenUDP(addr string, opts config.ListenerSocketOptions) (net.PacketConn, error) {
+	lc := net.ListenConfig{Control: socketControl(opts)}
+	return lc.ListenPacket(context.Background(), "udp", addr)
+}
+
+// socketControl returns a ListenConfig control function which sets the
+// configured socket options before the socket is bound, or nil when none
+// apply.
+func socketControl(opts config.ListenerSocketOptions) func(network, address string, c syscall.RawConn) error {
+	if !opts.SOReusePort {
+		return nil
+	}
+	return func(network, address string, c syscall.RawConn) error {
+		var optErr error
+		err := c.Control(func(fd uintptr) {
+			optErr = unix.SetsockoptInt(int(fd), unix.SOL_SOCKET, unix.SO_REUSEPORT, 1)
+		})
+		if err != nil {
+			return err
+		}
+		return optErr
+	}
+}
+
+// listenTCPBacklog binds a TCP listener with an explicit listen(2) backlog.
+func listenTCPBacklog(addr *net.TCPAddr, opts config.ListenerSocketOptions) (net.Listener, error) {
+	family := unix.AF_INET
+	if addr.IP.To4() == nil {
+		family = unix.AF_INET6
+	}
+
+	fd, err := unix.Socket(family, unix.SOCK_STREAM, 0)
+	if err != nil {
+		return nil, os.NewSyscallError("socket", err)
+	}
+	closeOnErr := func(op string, err error) (net.Listener, error) {
+		unix.Close(fd)
+		return nil, os.NewSyscallError(op, err)
+	}
+
+	unix.CloseOnExec(fd)
+	if err := unix.SetNonblock(fd, true); err != nil {
+		return closeOnErr("setnonblock", err)
+	}
+	if err := unix.SetsockoptInt(fd, unix.SOL_SOCKET, unix.SO_REUSEADDR, 1); err != nil {
+		return closeOnErr("setsockopt", err)
+	}
+	if opts.SOReusePort {
+		if err := unix.SetsockoptInt(fd, unix.SOL_SOCKET, unix.SO_REUSEPORT, 1); err != nil {
+			return closeOnErr("setsockopt", err)
+		}
+	}
+
+	var sa unix.Sockaddr
+	if family == unix.AF_INET {
+		sa4 := &unix.SockaddrInet4{Port: addr.Port}
+		copy(sa4.Addr[:], addr.IP.To4())
+		sa = sa4
+	} else {
+		sa6 := &unix.SockaddrInet6{Port: addr.Port}
+		copy(sa6.Addr[:], addr.IP.To16())
+		sa = sa6
+	}
+	if err := unix.Bind(fd, sa); err != nil {
+		return closeOnErr("bind", err)
+	}
+	if err := unix.Listen(fd, opts.Backlog); err != nil {
+		return closeOnErr("listen", err)
+	}
+
+	// net.FileListener duplicates the descriptor, so the original is
+	// closed regardless of the outcome.
+	f := os.NewFile(uintptr(fd), addr.String())
+	defer f.Close()
+	return net.FileListener(f)
+}
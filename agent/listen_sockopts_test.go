@@ -0,0 +1,64 @@
+// +build !windows
+
+package agent
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/consul/agent/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListenTCP_SOReusePort(t *testing.T) {
+	opts := config.ListenerSocketOptions{SOReusePort: true}
+
+	l1, err := listenTCP(&net.TCPAddr{IP: net.ParseIP("127.0.0.1")}, opts)
+	require.NoError(t, err)
+	defer l1.Close()
+
+	// A second bind of the same address must succeed with SO_REUSEPORT.
+	l2, err := listenTCP(l1.Addr().(*net.TCPAddr), opts)
+	require.NoError(t, err)
+	defer l2.Close()
+	require.Equal(t, l1.Addr().String(), l2.Addr().String())
+
+	// Without the option the second bind is rejected.
+	_, err = listenTCP(l1.Addr().(*net.TCPAddr), config.ListenerSocketOptions{})
+	require.Error(t, err)
+}
+
+func TestListenTCP_Backlog(t *testing.T) {
+	opts := config.ListenerSocketOptions{Backlog: 1}
+
+	l, err := listenTCP(&net.TCPAddr{IP: net.ParseIP("127.0.0.1")}, opts)
+	require.NoError(t, err)
+	defer l.Close()
+
+	// The hand-built socket must still behave like a regular listener.
+	require.IsType(t, &net.TCPListener{}, l)
+	go func() {
+		c, err := net.Dial("tcp", l.Addr().String())
+		if err == nil {
+			c.Close()
+		}
+	}()
+	l.(*net.TCPListener).SetDeadline(time.Now().Add(5 * time.Second))
+	c, err := l.Accept()
+	require.NoError(t, err)
+	c.Close()
+}
+
+func TestListenUDP_SOReusePort(t *testing.T) {
+	opts := config.ListenerSocketOptions{SOReusePort: true}
+
+	pc1, err := listenUDP("127.0.0.1:0", opts)
+	require.NoError(t, err)
+	defer pc1.Close()
+
+	pc2, err := listenUDP(pc1.LocalAddr().String(), opts)
+	require.NoError(t, err)
+	defer pc2.Close()
+	require.Equal(t, pc1.LocalAddr().String(), pc2.LocalAddr().String())
+}
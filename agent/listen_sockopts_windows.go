@@ -0,0 +1,20 @@
+// +build windows
+
+package agent
+
+import (
+	"net"
+
+	"github.com/hashicorp/consul/agent/config"
+)
+
+// SO_REUSEPORT and the listen(2) backlog are not tunable on Windows, so
+// only the keep-alive interval from listener_options has an effect there.
+
+func listenTCP(addr *net.TCPAddr, opts config.ListenerSocketOptions) (net.Listener, error) {
+	return net.Listen("tcp", addr.String())
+}
+
+func listenUDP(addr string, opts config.ListenerSocketOptions) (net.PacketConn, error) {
+	return net.ListenPacket("udp", addr)
+}
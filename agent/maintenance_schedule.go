@@ -0,0 +1,58 @@
+package agent
+
+import (
+	"time"
+
+	"github.com/hashicorp/consul/lib"
+)
+
+// startMaintenanceSchedule kicks off the scheduled maintenance loop if a
+// maintenance_schedule was configured. It is a no-op otherwise.
+func (a *Agent) startMaintenanceSchedule() {
+	if a.config.MaintenanceScheduleCron == "" {
+		return
+	}
+	expr, err := lib.ParseCron(a.config.MaintenanceScheduleCron)
+	if err != nil {
+		// the expression was already validated during config load
+		a.logger.Error("Invalid maintenance schedule", "error", err)
+		return
+	}
+	go a.runMaintenanceSchedule(expr)
+}
+
+// runMaintenanceSchedule is a long-running loop that enters maintenance mode
+// when the configured cron expression matches and leaves it again once the
+// window duration has passed. Closing the agent's shutdownChannel will cause
+// this to exit.
+func (a *Agent) runMaintenanceSchedule(expr *lib.CronExpr) {
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+
+	var until time.Time
+	for {
+		select {
+		case now := <-ticker.C:
+			until = a.checkMaintenanceWindow(expr, now, until)
+		case <-a.shutdownCh:
+			return
+		}
+	}
+}
+
+// checkMaintenanceWindow performs a single pass of the maintenance schedule.
+// until is the end of the currently open window, or the zero time when none
+// is open. It returns the end of the window that is open after the pass.
+func (a *Agent) checkMaintenanceWindow(expr *lib.CronExpr, now, until time.Time) time.Time {
+	if !until.IsZero() {
+		if now.Before(until) {
+			return until
+		}
+		a.DisableNodeMaintenance()
+	}
+	if expr.Matches(now) {
+		a.EnableNodeMaintenance(a.config.MaintenanceScheduleReason, "")
+		return now.Add(a.config.MaintenanceScheduleDuration)
+	}
+	return time.Time{}
+}
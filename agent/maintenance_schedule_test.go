@@ -0,0 +1,52 @@
+package agent
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hashicorp/consul/agent/structs"
+	"github.com/hashicorp/consul/lib"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAgent_MaintenanceSchedule(t *testing.T) {
+	if testing.Short() {
+		t.Skip("too slow for testing.Short")
+	}
+
+	t.Parallel()
+	a := NewTestAgent(t, `
+		maintenance_schedule {
+			cron = "0 3 * * *"
+			duration = "2h"
+			reason = "patching"
+		}
+	`)
+	defer a.Shutdown()
+
+	expr, err := lib.ParseCron(a.config.MaintenanceScheduleCron)
+	require.NoError(t, err)
+
+	start := time.Date(2021, 3, 1, 3, 0, 0, 0, time.UTC)
+
+	// the window opens when the cron expression matches
+	until := a.checkMaintenanceWindow(expr, start, time.Time{})
+	require.Equal(t, start.Add(2*time.Hour), until)
+	check := requireCheckExists(t, a, structs.NodeMaint)
+	require.Equal(t, "patching", check.Notes)
+
+	// still inside the window
+	until = a.checkMaintenanceWindow(expr, start.Add(time.Hour), until)
+	require.Equal(t, start.Add(2*time.Hour), until)
+	requireCheckExists(t, a, structs.NodeMaint)
+
+	// the window closes once the duration has passed
+	until = a.checkMaintenanceWindow(expr, start.Add(2*time.Hour+time.Minute), until)
+	require.True(t, until.IsZero())
+	requireCheckMissing(t, a, structs.NodeMaint)
+
+	// outside the window nothing happens
+	until = a.checkMaintenanceWindow(expr, start.Add(3*time.Hour), until)
+	require.True(t, until.IsZero())
+	requireCheckMissing(t, a, structs.NodeMaint)
+}
@@ -0,0 +1,48 @@
+package agent
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/hashicorp/go-hclog"
+
+	"github.com/hashicorp/consul/agent/config"
+)
+
+// configureOutboundProxy exports the configured outbound proxy through the
+// standard proxy environment variables so every outbound HTTP client in
+// the process — retry-join cloud discovery, the update check, OIDC
+// discovery and the Vault CA provider — picks it up via
+// http.ProxyFromEnvironment. It must run before the first outbound
+// request is made because net/http caches the environment.
+func configureOutboundProxy(cfg *config.RuntimeConfig, logger hclog.Logger) error {
+	if cfg.OutboundProxyURL == "" {
+		return nil
+	}
+
+	proxyURL, err := url.Parse(cfg.OutboundProxyURL)
+	if err != nil {
+		return fmt.Errorf("invalid outbound_proxy.url: %s", err)
+	}
+	if cfg.OutboundProxyCredentialsFile != "" {
+		user, err := config.OutboundProxyCredentials(cfg.OutboundProxyCredentialsFile)
+		if err != nil {
+			return err
+		}
+		proxyURL.User = user
+	}
+
+	os.Setenv("HTTP_PROXY", proxyURL.String())
+	os.Setenv("HTTPS_PROXY", proxyURL.String())
+	if len(cfg.OutboundProxyNoProxy) > 0 {
+		os.Setenv("NO_PROXY", strings.Join(cfg.OutboundProxyNoProxy, ","))
+	}
+
+	logger.Info("Routing outbound agent traffic through proxy",
+		"url", cfg.OutboundProxyURL,
+		"no_proxy", strings.Join(cfg.OutboundProxyNoProxy, ","),
+	)
+	return nil
+}
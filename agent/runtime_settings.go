@@ -0,0 +1,26 @@
+package agent
+
+import (
+	"runtime"
+	"runtime/debug"
+)
+
+// applyRuntimeSettings applies the runtime { } config block to the Go
+// runtime of the agent process. Zero values leave the corresponding
+// setting untouched so that the GOMAXPROCS, GOGC and GOMEMLIMIT
+// environment variables keep working when the config does not override
+// them.
+func (a *Agent) applyRuntimeSettings() {
+	if n := a.config.RuntimeGOMAXPROCS; n > 0 {
+		prev := runtime.GOMAXPROCS(n)
+		a.logger.Info("Set GOMAXPROCS", "gomaxprocs", n, "previous", prev)
+	}
+	if gogc := a.config.RuntimeGOGC; gogc != 0 {
+		prev := debug.SetGCPercent(gogc)
+		a.logger.Info("Set GC percent", "gogc", gogc, "previous", prev)
+	}
+	if limit := a.config.RuntimeMemoryLimit; limit > 0 {
+		debug.SetMemoryLimit(limit)
+		a.logger.Info("Set memory limit", "memory_limit_bytes", limit)
+	}
+}
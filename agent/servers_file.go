@@ -0,0 +1,113 @@
+package agent
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/consul/agent/metadata"
+	"github.com/hashicorp/consul/lib/file"
+	"github.com/hashicorp/serf/serf"
+)
+
+// serversFileInterval is how often the servers file is rewritten with the
+// current set of known servers.
+const serversFileInterval = 30 * time.Second
+
+// startServersFile seeds the LAN gossip pool from the configured servers
+// file and keeps the file updated with the servers the agent currently
+// knows about. This lets a client rejoin the cluster after a long outage
+// even when the persisted serf snapshot has been lost. It is a no-op when
+// servers_file is not configured.
+func (a *Agent) startServersFile() {
+	if a.config.ServersFile == "" {
+		return
+	}
+	if err := a.joinServersFile(); err != nil {
+		a.logger.Warn("Failed to join servers from servers file",
+			"file", a.config.ServersFile,
+			"error", err,
+		)
+	}
+	go a.runServersFile()
+}
+
+// runServersFile is a long-running loop that periodically rewrites the
+// servers file. Closing the agent's shutdownChannel will cause this to
+// exit.
+func (a *Agent) runServersFile() {
+	ticker := time.NewTicker(serversFileInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if err := a.persistServersFile(); err != nil {
+				a.logger.Warn("Failed to persist servers file",
+					"file", a.config.ServersFile,
+					"error", err,
+				)
+			}
+		case <-a.shutdownCh:
+			return
+		}
+	}
+}
+
+// joinServersFile joins the addresses listed in the servers file, one
+// address per line. Blank lines and lines starting with '#' are ignored.
+// A missing file is not an error since the agent creates it on the first
+// persistence pass.
+func (a *Agent) joinServersFile() error {
+	buf, err := ioutil.ReadFile(a.config.ServersFile)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+
+	var addrs []string
+	for _, line := range strings.Split(string(buf), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		addrs = append(addrs, line)
+	}
+	if len(addrs) == 0 {
+		return nil
+	}
+
+	if _, err := a.JoinLAN(addrs); err != nil {
+		return fmt.Errorf("error joining servers from %q: %v", a.config.ServersFile, err)
+	}
+	return nil
+}
+
+// persistServersFile writes the serf addresses of the currently known
+// alive servers to the servers file. When no servers are known the file
+// is left untouched so the last known set survives an outage.
+func (a *Agent) persistServersFile() error {
+	var addrs []string
+	for _, member := range a.LANMembers() {
+		if member.Status != serf.StatusAlive {
+			continue
+		}
+		if ok, _ := metadata.IsConsulServer(member); !ok {
+			continue
+		}
+		addrs = append(addrs, net.JoinHostPort(member.Addr.String(), strconv.Itoa(int(member.Port))))
+	}
+	if len(addrs) == 0 {
+		return nil
+	}
+	sort.Strings(addrs)
+
+	buf := strings.Join(addrs, "\n") + "\n"
+	return file.WriteAtomic(a.config.ServersFile, []byte(buf))
+}
@@ -0,0 +1,71 @@
+package agent
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/hashicorp/consul/sdk/testutil"
+	"github.com/hashicorp/consul/sdk/testutil/retry"
+	"github.com/hashicorp/consul/testrpc"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAgent_ServersFile_Persist(t *testing.T) {
+	if testing.Short() {
+		t.Skip("too slow for testing.Short")
+	}
+
+	t.Parallel()
+
+	path := filepath.Join(testutil.TempDir(t, "servers-file"), "known-servers")
+	a := NewTestAgent(t, fmt.Sprintf(`servers_file = %q`, path))
+	defer a.Shutdown()
+	testrpc.WaitForLeader(t, a.RPC, "dc1")
+
+	require.NoError(t, a.persistServersFile())
+
+	buf, err := ioutil.ReadFile(path)
+	require.NoError(t, err)
+
+	member := a.LANMembers()[0]
+	want := net.JoinHostPort(member.Addr.String(), strconv.Itoa(int(member.Port)))
+	require.Equal(t, want+"\n", string(buf))
+}
+
+func TestAgent_ServersFile_JoinOnStart(t *testing.T) {
+	if testing.Short() {
+		t.Skip("too slow for testing.Short")
+	}
+
+	t.Parallel()
+
+	a1 := NewTestAgent(t, "")
+	defer a1.Shutdown()
+	testrpc.WaitForLeader(t, a1.RPC, "dc1")
+
+	member := a1.LANMembers()[0]
+	addr := net.JoinHostPort(member.Addr.String(), strconv.Itoa(int(member.Port)))
+
+	// Seed the file the way an operator would, with comments and blank
+	// lines around the address.
+	path := filepath.Join(testutil.TempDir(t, "servers-file"), "known-servers")
+	content := "# seeded by the operator\n\n" + addr + "\n"
+	require.NoError(t, ioutil.WriteFile(path, []byte(content), 0600))
+
+	a2 := NewTestAgent(t, fmt.Sprintf(`
+		server = false
+		bootstrap = false
+		servers_file = %q
+	`, path))
+	defer a2.Shutdown()
+
+	retry.Run(t, func(r *retry.R) {
+		if got, want := len(a2.LANMembers()), 2; got != want {
+			r.Fatalf("got %d LAN members want %d", got, want)
+		}
+	})
+}
@@ -52,7 +52,7 @@ type MetricsHandler interface {
 	DisplayMetrics(resp http.ResponseWriter, req *http.Request) (interface{}, error)
 }
 
-type ConfigLoader func(source config.Source) (cfg *config.RuntimeConfig, warnings []string, err error)
+type ConfigLoader func(source config.Source, overrides ...config.Source) (cfg *config.RuntimeConfig, warnings []string, err error)
 
 func NewBaseDeps(configLoader ConfigLoader, logOut io.Writer) (BaseDeps, error) {
 	d := BaseDeps{}
@@ -73,6 +73,10 @@ func NewBaseDeps(configLoader ConfigLoader, logOut io.Writer) (BaseDeps, error)
 		d.Logger.Warn(w)
 	}
 
+	if err := configureOutboundProxy(cfg, d.Logger); err != nil {
+		return d, err
+	}
+
 	cfg.NodeID, err = newNodeIDFromConfig(cfg, d.Logger)
 	if err != nil {
 		return d, fmt.Errorf("failed to setup node ID: %w", err)
@@ -234,6 +238,7 @@ func getPrometheusDefs(cfg lib.TelemetryConfig) ([]prometheus.GaugeDefinition, [
 
 	var counters = [][]prometheus.CounterDefinition{
 		CatalogCounters,
+		HTTPCounters,
 		cache.Counters,
 		consul.ACLCounters,
 		consul.CatalogCounters,
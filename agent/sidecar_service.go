@@ -141,7 +141,7 @@ func (a *Agent) sidecarServiceFromNodeService(ns *structs.NodeService, token str
 			// Iterate until we find lowest unused port
 			for p := a.config.ConnectSidecarMinPort; p <= a.config.ConnectSidecarMaxPort; p++ {
 				_, used := usedPorts[p]
-				if !used {
+				if !used && !a.config.IsReservedPort(p) {
 					sidecar.Port = p
 					break
 				}
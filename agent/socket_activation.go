@@ -0,0 +1,91 @@
+package agent
+
+import (
+	"net"
+	"os"
+
+	"github.com/hashicorp/consul/agent/systemd"
+	"github.com/hashicorp/go-hclog"
+)
+
+// socketInventory indexes the pre-bound sockets a service manager passed to
+// the agent so listeners can claim them by bind address instead of binding
+// the address themselves. This allows privileged ports such as 53 to be
+// bound by systemd while the agent runs unprivileged.
+type socketInventory struct {
+	listeners   map[string]net.Listener
+	packetConns map[string]net.PacketConn
+}
+
+// inheritedSockets builds an inventory from the sockets passed via the
+// LISTEN_FDS protocol. It returns nil when the agent was not socket
+// activated.
+func inheritedSockets(logger hclog.Logger) (*socketInventory, error) {
+	files, err := systemd.ListenFDs()
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return nil, nil
+	}
+
+	inv := &socketInventory{
+		listeners:   make(map[string]net.Listener),
+		packetConns: make(map[string]net.PacketConn),
+	}
+	for _, f := range files {
+		inv.add(f, logger)
+		f.Close()
+	}
+	return inv, nil
+}
+
+// add registers the socket behind f as either a stream listener or a packet
+// connection, keyed by its bind address. Unusable descriptors are logged and
+// skipped so a misconfigured unit does not prevent startup.
+func (inv *socketInventory) add(f *os.File, logger hclog.Logger) {
+	if l, err := net.FileListener(f); err == nil {
+		inv.listeners[socketKey(l.Addr())] = l
+		return
+	}
+	if pc, err := net.FilePacketConn(f); err == nil {
+		inv.packetConns[socketKey(pc.LocalAddr())] = pc
+		return
+	}
+	logger.Warn("ignoring inherited file descriptor that is not a listening socket", "name", f.Name())
+}
+
+// takeListener claims the inherited stream listener bound to addr, or nil if
+// there is none. A listener can only be claimed once.
+func (inv *socketInventory) takeListener(addr net.Addr) net.Listener {
+	if inv == nil {
+		return nil
+	}
+	key := socketKey(addr)
+	l, ok := inv.listeners[key]
+	if ok {
+		delete(inv.listeners, key)
+	}
+	return l
+}
+
+// takePacketConn claims the inherited packet connection bound to addr, or
+// nil if there is none. A connection can only be claimed once.
+func (inv *socketInventory) takePacketConn(addr net.Addr) net.PacketConn {
+	if inv == nil {
+		return nil
+	}
+	key := socketKey(addr)
+	pc, ok := inv.packetConns[key]
+	if ok {
+		delete(inv.packetConns, key)
+	}
+	return pc
+}
+
+// socketKey normalizes an address for matching an inherited socket against a
+// configured listener address. The configured bind address must match the
+// address the service manager bound.
+func socketKey(addr net.Addr) string {
+	return addr.Network() + "://" + addr.String()
+}
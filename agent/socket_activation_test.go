@@ -0,0 +1,60 @@
+package agent
+
+import (
+	"net"
+	"testing"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSocketInventory(t *testing.T) {
+	tcpLn, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer tcpLn.Close()
+
+	udpConn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer udpConn.Close()
+
+	tcpFile, err := tcpLn.(*net.TCPListener).File()
+	require.NoError(t, err)
+	defer tcpFile.Close()
+
+	udpFile, err := udpConn.(*net.UDPConn).File()
+	require.NoError(t, err)
+	defer udpFile.Close()
+
+	inv := &socketInventory{
+		listeners:   make(map[string]net.Listener),
+		packetConns: make(map[string]net.PacketConn),
+	}
+	logger := hclog.NewNullLogger()
+	inv.add(tcpFile, logger)
+	inv.add(udpFile, logger)
+
+	// A UDP address does not claim the TCP listener and vice versa.
+	require.Nil(t, inv.takeListener(udpConn.LocalAddr()))
+	require.Nil(t, inv.takePacketConn(tcpLn.Addr()))
+
+	ln := inv.takeListener(tcpLn.Addr())
+	require.NotNil(t, ln)
+	defer ln.Close()
+	require.Equal(t, tcpLn.Addr().String(), ln.Addr().String())
+
+	pc := inv.takePacketConn(udpConn.LocalAddr())
+	require.NotNil(t, pc)
+	defer pc.Close()
+	require.Equal(t, udpConn.LocalAddr().String(), pc.LocalAddr().String())
+
+	// Sockets can only be claimed once.
+	require.Nil(t, inv.takeListener(tcpLn.Addr()))
+	require.Nil(t, inv.takePacketConn(udpConn.LocalAddr()))
+}
+
+func TestSocketInventory_NilIsSafe(t *testing.T) {
+	var inv *socketInventory
+	addr := &net.TCPAddr{IP: net.ParseIP("127.0.0.1"), Port: 8500}
+	require.Nil(t, inv.takeListener(addr))
+	require.Nil(t, inv.takePacketConn(addr))
+}
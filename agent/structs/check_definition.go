@@ -30,6 +30,7 @@ type CheckDefinition struct {
 	TCP                            string
 	Interval                       time.Duration
 	DockerContainerID              string
+	CRIContainerID                 string
 	Shell                          string
 	GRPC                           string
 	GRPCUseTLS                     bool
@@ -62,6 +63,7 @@ func (t *CheckDefinition) UnmarshalJSON(data []byte) (err error) {
 		ScriptArgsSnake                     []string    `json:"script_args"`
 		DeregisterCriticalServiceAfterSnake interface{} `json:"deregister_critical_service_after"`
 		DockerContainerIDSnake              string      `json:"docker_container_id"`
+		CRIContainerIDSnake                 string      `json:"cri_container_id"`
 		TLSSkipVerifySnake                  bool        `json:"tls_skip_verify"`
 		GRPCUseTLSSnake                     bool        `json:"grpc_use_tls"`
 		ServiceIDSnake                      string      `json:"service_id"`
@@ -87,6 +89,9 @@ func (t *CheckDefinition) UnmarshalJSON(data []byte) (err error) {
 	if t.DockerContainerID == "" {
 		t.DockerContainerID = aux.DockerContainerIDSnake
 	}
+	if t.CRIContainerID == "" {
+		t.CRIContainerID = aux.CRIContainerIDSnake
+	}
 	if aux.TLSSkipVerifySnake {
 		t.TLSSkipVerify = aux.TLSSkipVerifySnake
 	}
@@ -181,6 +186,7 @@ func (c *CheckDefinition) CheckType() *CheckType {
 		TCP:                            c.TCP,
 		Interval:                       c.Interval,
 		DockerContainerID:              c.DockerContainerID,
+		CRIContainerID:                 c.CRIContainerID,
 		Shell:                          c.Shell,
 		TLSSkipVerify:                  c.TLSSkipVerify,
 		Timeout:                        c.Timeout,
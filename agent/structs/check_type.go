@@ -40,6 +40,7 @@ type CheckType struct {
 	AliasNode              string
 	AliasService           string
 	DockerContainerID      string
+	CRIContainerID         string
 	Shell                  string
 	GRPC                   string
 	GRPCUseTLS             bool
@@ -75,6 +76,7 @@ func (t *CheckType) UnmarshalJSON(data []byte) (err error) {
 		ScriptArgsSnake                     []string    `json:"script_args"`
 		DeregisterCriticalServiceAfterSnake interface{} `json:"deregister_critical_service_after"`
 		DockerContainerIDSnake              string      `json:"docker_container_id"`
+		CRIContainerIDSnake                 string      `json:"cri_container_id"`
 		TLSSkipVerifySnake                  bool        `json:"tls_skip_verify"`
 		GRPCUseTLSSnake                     bool        `json:"grpc_use_tls"`
 
@@ -102,6 +104,9 @@ func (t *CheckType) UnmarshalJSON(data []byte) (err error) {
 	if t.DockerContainerID == "" {
 		t.DockerContainerID = aux.DockerContainerIDSnake
 	}
+	if t.CRIContainerID == "" {
+		t.CRIContainerID = aux.CRIContainerIDSnake
+	}
 	if aux.TLSSkipVerifySnake {
 		t.TLSSkipVerify = aux.TLSSkipVerifySnake
 	}
@@ -176,6 +181,9 @@ func (c *CheckType) Validate() error {
 	if c.OutputMaxSize < 0 {
 		return fmt.Errorf("MaxOutputMaxSize must be positive")
 	}
+	if c.DockerContainerID != "" && c.CRIContainerID != "" {
+		return fmt.Errorf("DockerContainerID and CRIContainerID cannot both be specified")
+	}
 	return nil
 }
 
@@ -201,7 +209,7 @@ func (c *CheckType) IsTTL() bool {
 
 // IsMonitor checks if this is a Monitor type
 func (c *CheckType) IsMonitor() bool {
-	return c.IsScript() && c.DockerContainerID == "" && c.Interval > 0
+	return c.IsScript() && c.DockerContainerID == "" && c.CRIContainerID == "" && c.Interval > 0
 }
 
 // IsHTTP checks if this is a HTTP type
@@ -219,6 +227,12 @@ func (c *CheckType) IsDocker() bool {
 	return c.IsScript() && c.DockerContainerID != "" && c.Interval > 0
 }
 
+// IsCRI returns true when checking inside a container through the CRI
+// runtime service.
+func (c *CheckType) IsCRI() bool {
+	return c.IsScript() && c.CRIContainerID != "" && c.Interval > 0
+}
+
 // IsGRPC checks if this is a GRPC type
 func (c *CheckType) IsGRPC() bool {
 	return c.GRPC != "" && c.Interval > 0
@@ -238,6 +252,8 @@ func (c *CheckType) Type() string {
 		return "alias"
 	case c.IsDocker():
 		return "docker"
+	case c.IsCRI():
+		return "cri"
 	case c.IsScript():
 		return "script"
 	default:
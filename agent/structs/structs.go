@@ -972,10 +972,16 @@ const (
 type ServiceAddress struct {
 	Address string
 	Port    int
+
+	// Health controls how service health is reflected on this tagged
+	// address. The default "inherit" (or empty) follows the overall
+	// service health while "independent" keeps the address advertised
+	// even when the service checks fail.
+	Health string
 }
 
 func (a ServiceAddress) ToAPIServiceAddress() api.ServiceAddress {
-	return api.ServiceAddress{Address: a.Address, Port: a.Port}
+	return api.ServiceAddress{Address: a.Address, Port: a.Port, Health: a.Health}
 }
 
 // NodeService is a service provided by a node
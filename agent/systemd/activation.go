@@ -0,0 +1,58 @@
+// +build !windows
+
+package systemd
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// listenFDsStart is the first file descriptor number the service manager
+// assigns to passed sockets, as defined by the sd_listen_fds protocol.
+// See https://www.freedesktop.org/software/systemd/man/sd_listen_fds.html.
+const listenFDsStart = 3
+
+// ListenFDs returns the pre-bound sockets inherited from the service manager
+// via the LISTEN_FDS protocol. It returns nil when the process was not socket
+// activated. The environment variables are cleared so the sockets are not
+// offered to child processes, and close-on-exec is set on each descriptor.
+func ListenFDs() ([]*os.File, error) {
+	defer os.Unsetenv("LISTEN_PID")
+	defer os.Unsetenv("LISTEN_FDS")
+	defer os.Unsetenv("LISTEN_FDNAMES")
+
+	pidStr := os.Getenv("LISTEN_PID")
+	if pidStr == "" {
+		return nil, nil
+	}
+	pid, err := strconv.Atoi(pidStr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid LISTEN_PID %q: %v", pidStr, err)
+	}
+	if pid != os.Getpid() {
+		// The sockets were meant for another process; leave them alone.
+		return nil, nil
+	}
+
+	fdsStr := os.Getenv("LISTEN_FDS")
+	nfds, err := strconv.Atoi(fdsStr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid LISTEN_FDS %q: %v", fdsStr, err)
+	}
+
+	names := strings.Split(os.Getenv("LISTEN_FDNAMES"), ":")
+	files := make([]*os.File, 0, nfds)
+	for i := 0; i < nfds; i++ {
+		fd := listenFDsStart + i
+		syscall.CloseOnExec(fd)
+		name := fmt.Sprintf("LISTEN_FD_%d", fd)
+		if i < len(names) && names[i] != "" {
+			name = names[i]
+		}
+		files = append(files, os.NewFile(uintptr(fd), name))
+	}
+	return files, nil
+}
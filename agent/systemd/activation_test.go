@@ -0,0 +1,52 @@
+// +build !windows
+
+package systemd
+
+import (
+	"os"
+	"strconv"
+	"testing"
+)
+
+func TestListenFDs_NotActivated(t *testing.T) {
+	os.Unsetenv("LISTEN_PID")
+	os.Unsetenv("LISTEN_FDS")
+
+	files, err := ListenFDs()
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if files != nil {
+		t.Fatalf("expected no files, got %d", len(files))
+	}
+}
+
+func TestListenFDs_OtherProcess(t *testing.T) {
+	// Sockets addressed to a different pid must be left alone.
+	os.Setenv("LISTEN_PID", strconv.Itoa(os.Getpid()+1))
+	os.Setenv("LISTEN_FDS", "2")
+	defer os.Unsetenv("LISTEN_PID")
+	defer os.Unsetenv("LISTEN_FDS")
+
+	files, err := ListenFDs()
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if files != nil {
+		t.Fatalf("expected no files, got %d", len(files))
+	}
+	if os.Getenv("LISTEN_PID") != "" {
+		t.Fatal("expected LISTEN_PID to be cleared")
+	}
+}
+
+func TestListenFDs_InvalidCount(t *testing.T) {
+	os.Setenv("LISTEN_PID", strconv.Itoa(os.Getpid()))
+	os.Setenv("LISTEN_FDS", "two")
+	defer os.Unsetenv("LISTEN_PID")
+	defer os.Unsetenv("LISTEN_FDS")
+
+	if _, err := ListenFDs(); err == nil {
+		t.Fatal("expected an error for a malformed LISTEN_FDS")
+	}
+}
@@ -0,0 +1,11 @@
+// +build windows
+
+package systemd
+
+import "os"
+
+// ListenFDs always returns nil on platforms without a service manager that
+// implements the LISTEN_FDS protocol.
+func ListenFDs() ([]*os.File, error) {
+	return nil, nil
+}
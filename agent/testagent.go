@@ -170,7 +170,7 @@ func (a *TestAgent) Start(t *testing.T) (err error) {
 
 	// Create NodeID outside the closure, so that it does not change
 	testHCLConfig := TestConfigHCL(NodeID())
-	loader := func(source config.Source) (*config.RuntimeConfig, []string, error) {
+	loader := func(source config.Source, extraOverrides ...config.Source) (*config.RuntimeConfig, []string, error) {
 		opts := config.BuilderOpts{
 			HCL: []string{testHCLConfig, portsConfig, a.HCL, hclDataDir},
 		}
@@ -182,6 +182,7 @@ func (a *TestAgent) Start(t *testing.T) (err error) {
 			config.DefaultConsulSource(),
 			config.DevConsulSource(),
 		}
+		overrides = append(overrides, extraOverrides...)
 		cfg, warnings, err := config.Load(opts, source, overrides...)
 		if cfg != nil {
 			cfg.Telemetry.Disable = true
@@ -451,7 +452,7 @@ func TestConfig(logger hclog.Logger, sources ...config.Source) *config.RuntimeCo
 	}
 
 	for _, w := range b.Warnings {
-		logger.Warn(w)
+		logger.Warn(w.String())
 	}
 
 	// Effectively disables the delay after root rotation before requesting CSRs
@@ -0,0 +1,76 @@
+package agent
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/hashicorp/go-cleanhttp"
+)
+
+// usageReport is the payload sent to the configured usage_reporting endpoint.
+// It deliberately contains no identifying information beyond the agent
+// version; only coarse feature flags are reported.
+type usageReport struct {
+	Version  string          `json:"version"`
+	Features map[string]bool `json:"features"`
+}
+
+// startUsageReporting kicks off the periodic usage reporter if the operator
+// opted in via the usage_reporting config block. It is a no-op otherwise.
+func (a *Agent) startUsageReporting() {
+	if !a.config.UsageReportingEnabled {
+		return
+	}
+	go a.runUsageReporting()
+}
+
+// runUsageReporting is a long-running loop that periodically reports enabled
+// feature usage to the configured endpoint. Closing the agent's
+// shutdownChannel will cause this to exit.
+func (a *Agent) runUsageReporting() {
+	ticker := time.NewTicker(a.config.UsageReportingInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if err := a.reportUsage(); err != nil {
+				a.logger.Warn("Usage report failed", "error", err)
+			}
+		case <-a.shutdownCh:
+			return
+		}
+	}
+}
+
+// reportUsage builds the feature usage payload and posts it to the configured
+// endpoint.
+func (a *Agent) reportUsage() error {
+	report := usageReport{
+		Version: a.config.Version,
+		Features: map[string]bool{
+			"acl":      a.config.ACLsEnabled,
+			"connect":  a.config.ConnectEnabled,
+			"segments": a.config.SegmentName != "" || len(a.config.Segments) > 0,
+			"server":   a.config.ServerMode,
+		},
+	}
+	payload, err := json.Marshal(report)
+	if err != nil {
+		return err
+	}
+
+	client := cleanhttp.DefaultClient()
+	client.Timeout = 10 * time.Second
+	resp, err := client.Post(a.config.UsageReportingEndpoint, "application/json", bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected response code: %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,48 @@
+package agent
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAgent_ReportUsage(t *testing.T) {
+	if testing.Short() {
+		t.Skip("too slow for testing.Short")
+	}
+
+	t.Parallel()
+
+	ch := make(chan usageReport, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var report usageReport
+		if err := json.NewDecoder(r.Body).Decode(&report); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		ch <- report
+	}))
+	defer srv.Close()
+
+	a := NewTestAgent(t, fmt.Sprintf(`
+		connect { enabled = true }
+		usage_reporting {
+			enabled = true
+			endpoint = %q
+		}
+	`, srv.URL))
+	defer a.Shutdown()
+
+	require.NoError(t, a.reportUsage())
+
+	report := <-ch
+	require.Equal(t, a.config.Version, report.Version)
+	require.True(t, report.Features["connect"])
+	require.True(t, report.Features["server"])
+	require.False(t, report.Features["acl"])
+	require.False(t, report.Features["segments"])
+}
@@ -79,6 +79,10 @@ const (
 	// DefaultAuthCheckFrequency is the default value for
 	// Server.AuthCheckFrequency to use when the zero value is provided.
 	DefaultAuthCheckFrequency = 5 * time.Minute
+
+	// DefaultMaxConcurrentStreams is the default value for
+	// Server.MaxConcurrentStreams to use when the zero value is provided.
+	DefaultMaxConcurrentStreams = 2048
 )
 
 // ACLResolverFunc is a shim to resolve ACLs. Since ACL enforcement is so far
@@ -121,8 +125,21 @@ type Server struct {
 	// This is only used during idle periods of stream interactions (i.e. when
 	// there has been no recent DiscoveryRequest).
 	AuthCheckFrequency time.Duration
-	CheckFetcher       HTTPCheckFetcher
-	CfgFetcher         ConfigFetcher
+	// MaxConcurrentStreams limits the number of concurrent xDS streams the
+	// gRPC server accepts. The zero value falls back to
+	// DefaultMaxConcurrentStreams.
+	MaxConcurrentStreams uint32
+	// NackBackoff is how long to wait before sending a new config version to
+	// a proxy that rejected the previous one with a NACK. This keeps a proxy
+	// that persistently rejects its config from being hammered with updates.
+	// Zero disables the backoff.
+	NackBackoff time.Duration
+	// EnableDelta opts the agent in to serving the incremental (delta) xDS
+	// protocol. The protocol itself is not implemented yet so for now this
+	// only controls the error returned to proxies that request it.
+	EnableDelta  bool
+	CheckFetcher HTTPCheckFetcher
+	CfgFetcher   ConfigFetcher
 }
 
 // StreamAggregatedResources implements
@@ -192,14 +209,16 @@ func (s *Server) process(stream ADSStream, reqCh <-chan *envoy.DiscoveryRequest)
 	// Configure handlers for each type of request
 	handlers := map[string]*xDSType{
 		EndpointType: {
-			typeURL:   EndpointType,
-			resources: s.endpointsFromSnapshot,
-			stream:    stream,
+			typeURL:     EndpointType,
+			resources:   s.endpointsFromSnapshot,
+			stream:      stream,
+			nackBackoff: s.NackBackoff,
 		},
 		ClusterType: {
-			typeURL:   ClusterType,
-			resources: s.clustersFromSnapshot,
-			stream:    stream,
+			typeURL:     ClusterType,
+			resources:   s.clustersFromSnapshot,
+			stream:      stream,
+			nackBackoff: s.NackBackoff,
 			allowEmptyFn: func(cfgSnap *proxycfg.ConfigSnapshot) bool {
 				// Mesh, Ingress, and Terminating gateways are allowed to inform CDS of
 				// no clusters.
@@ -209,17 +228,19 @@ func (s *Server) process(stream ADSStream, reqCh <-chan *envoy.DiscoveryRequest)
 			},
 		},
 		RouteType: {
-			typeURL:   RouteType,
-			resources: s.routesFromSnapshot,
-			stream:    stream,
+			typeURL:     RouteType,
+			resources:   s.routesFromSnapshot,
+			stream:      stream,
+			nackBackoff: s.NackBackoff,
 			allowEmptyFn: func(cfgSnap *proxycfg.ConfigSnapshot) bool {
 				return cfgSnap.Kind == structs.ServiceKindIngressGateway
 			},
 		},
 		ListenerType: {
-			typeURL:   ListenerType,
-			resources: s.listenersFromSnapshot,
-			stream:    stream,
+			typeURL:     ListenerType,
+			resources:   s.listenersFromSnapshot,
+			stream:      stream,
+			nackBackoff: s.NackBackoff,
 			allowEmptyFn: func(cfgSnap *proxycfg.ConfigSnapshot) bool {
 				return cfgSnap.Kind == structs.ServiceKindIngressGateway
 			},
@@ -231,6 +252,11 @@ func (s *Server) process(stream ADSStream, reqCh <-chan *envoy.DiscoveryRequest)
 		authTimer = time.After(s.AuthCheckFrequency)
 	}
 
+	// nackTimer wakes the loop up after a Nack backoff has passed so that
+	// sends skipped by SendIfNew are retried even if no new request or config
+	// arrives in the meantime.
+	var nackTimer <-chan time.Time
+
 	checkStreamACLs := func(cfgSnap *proxycfg.ConfigSnapshot) error {
 		if cfgSnap == nil {
 			return status.Errorf(codes.Unauthenticated, "unauthenticated: no config snapshot")
@@ -299,9 +325,16 @@ func (s *Server) process(stream ADSStream, reqCh <-chan *envoy.DiscoveryRequest)
 			if handler, ok := handlers[req.TypeUrl]; ok {
 				handler.Recv(req, node, proxyFeatures)
 			}
+			if req.ErrorDetail != nil && s.NackBackoff > 0 {
+				nackTimer = time.After(s.NackBackoff)
+			}
 		case cfgSnap = <-stateCh:
 			// We got a new config, update the version counter
 			configVersion++
+		case <-nackTimer:
+			// A Nack backoff has passed, fall through to the state machine so
+			// any held back responses are sent.
+			nackTimer = nil
 		}
 
 		// Trigger state machine
@@ -383,6 +416,11 @@ type xDSType struct {
 	lastVersion  uint64
 	resources    func(cInfo connectionInfo, cfgSnap *proxycfg.ConfigSnapshot) ([]proto.Message, error)
 	allowEmptyFn func(cfgSnap *proxycfg.ConfigSnapshot) bool
+	// nackBackoff is copied from Server.NackBackoff. When the proxy Nacks a
+	// response no new version is sent for this type until nackedUntil has
+	// passed.
+	nackBackoff time.Duration
+	nackedUntil time.Time
 }
 
 // connectionInfo represents details specific to this connection
@@ -396,6 +434,11 @@ func (t *xDSType) Recv(req *envoy.DiscoveryRequest, node *envoycore.Node, proxyF
 		t.req = req
 		t.node = node
 		t.proxyFeatures = proxyFeatures
+		if req.ErrorDetail != nil && t.nackBackoff > 0 {
+			// The proxy rejected the last response so hold off new versions of
+			// this type for a while.
+			t.nackedUntil = time.Now().Add(t.nackBackoff)
+		}
 	}
 }
 
@@ -407,6 +450,11 @@ func (t *xDSType) SendIfNew(cfgSnap *proxycfg.ConfigSnapshot, version uint64, no
 		// Already sent this version
 		return nil
 	}
+	if time.Now().Before(t.nackedUntil) {
+		// Still backing off after a Nack. The process loop arms a timer so
+		// this send is retried once the backoff has passed.
+		return nil
+	}
 
 	cInfo := connectionInfo{
 		Token:         tokenFromContext(t.stream.Context()),
@@ -465,13 +513,20 @@ func tokenFromContext(ctx context.Context) string {
 
 // DeltaAggregatedResources implements envoydisco.AggregatedDiscoveryServiceServer
 func (s *Server) DeltaAggregatedResources(_ envoydisco.AggregatedDiscoveryService_DeltaAggregatedResourcesServer) error {
+	if !s.EnableDelta {
+		return status.Errorf(codes.Unimplemented, "incremental xDS is disabled on this agent, set xds.enable_delta to opt in")
+	}
 	return errors.New("not implemented")
 }
 
 // GRPCServer returns a server instance that can handle xDS requests.
 func (s *Server) GRPCServer(tlsConfigurator *tlsutil.Configurator) (*grpc.Server, error) {
+	maxStreams := s.MaxConcurrentStreams
+	if maxStreams == 0 {
+		maxStreams = DefaultMaxConcurrentStreams
+	}
 	opts := []grpc.ServerOption{
-		grpc.MaxConcurrentStreams(2048),
+		grpc.MaxConcurrentStreams(maxStreams),
 	}
 	if tlsConfigurator != nil {
 		if tlsConfigurator.Cert() != nil {
@@ -302,7 +302,8 @@ type AgentServiceCheck struct {
 	Name                   string              `json:",omitempty"`
 	Args                   []string            `json:"ScriptArgs,omitempty"`
 	DockerContainerID      string              `json:",omitempty"`
-	Shell                  string              `json:",omitempty"` // Only supported for Docker.
+	CRIContainerID         string              `json:",omitempty"`
+	Shell                  string              `json:",omitempty"` // Only supported for Docker and CRI.
 	Interval               string              `json:",omitempty"`
 	Timeout                string              `json:",omitempty"`
 	TTL                    string              `json:",omitempty"`
@@ -24,6 +24,7 @@ type Node struct {
 type ServiceAddress struct {
 	Address string
 	Port    int
+	Health  string `json:",omitempty"`
 }
 
 type CatalogService struct {
@@ -2,6 +2,7 @@ package agent
 
 import (
 	"context"
+	"encoding/json"
 	"flag"
 	"fmt"
 	"os"
@@ -11,6 +12,7 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/armon/go-metrics"
 	"github.com/hashicorp/consul/agent"
 	"github.com/hashicorp/consul/agent/config"
 	"github.com/hashicorp/consul/command/flags"
@@ -57,12 +59,21 @@ type cmd struct {
 	versionHuman      string
 	shutdownCh        <-chan struct{}
 	flagArgs          config.BuilderOpts
+	configCheckOnly   bool
+	printConfig       string
+	printConfigExit   bool
 	logger            hclog.InterceptLogger
 }
 
 func (c *cmd) init() {
 	c.flags = flag.NewFlagSet("", flag.ContinueOnError)
 	config.AddFlags(c.flags, &c.flagArgs)
+	c.flags.BoolVar(&c.configCheckOnly, "config-check-only", false,
+		"Load and validate the agent configuration, print any warnings and exit without starting the agent.")
+	c.flags.StringVar(&c.printConfig, "print-config", "",
+		"After validation, print the resolved runtime configuration in this format before starting the agent. Must be 'hcl' or 'json'.")
+	c.flags.BoolVar(&c.printConfigExit, "print-config-exit", false,
+		"With -print-config, exit after printing the configuration instead of starting the agent.")
 	c.help = flags.Usage(help, c.flags)
 }
 
@@ -148,6 +159,59 @@ func (c *cmd) startupJoinWan(agent *agent.Agent, cfg *config.RuntimeConfig) erro
 	return nil
 }
 
+// watchConfigFiles starts a watcher over the config files and directories
+// the agent was started with plus the certificate files from the loaded
+// config, for auto_reload_config. The returned channel receives a value
+// whenever the watched files change.
+func (c *cmd) watchConfigFiles(ctx context.Context, cfg *config.RuntimeConfig) <-chan struct{} {
+	watched := append([]string{}, c.flagArgs.ConfigFiles...)
+	for _, f := range []string{cfg.CAFile, cfg.CertFile, cfg.KeyFile} {
+		if f != "" {
+			watched = append(watched, f)
+		}
+	}
+	return config.NewFileWatcher(watched).Watch(ctx)
+}
+
+// printRuntimeConfig renders the sanitized runtime configuration in the
+// format selected by -print-config so operators can confirm what defaults
+// and merges produced before rolling a change out.
+func (c *cmd) printRuntimeConfig(cfg *config.RuntimeConfig) error {
+	var out string
+	var err error
+	switch c.printConfig {
+	case "hcl":
+		out, err = cfg.SanitizedHCL()
+	case "json":
+		var b []byte
+		b, err = json.MarshalIndent(cfg.Sanitized(), "", "    ")
+		out = string(b)
+	}
+	if err != nil {
+		return fmt.Errorf("Failed to render config: %v", err)
+	}
+	c.UI.Output(out)
+	return nil
+}
+
+// checkConfig loads and validates the agent configuration exactly as a
+// normal start would, including template expansion and address detection,
+// and reports the result without ever starting listeners. Unlike
+// 'consul validate' this exercises the runtime-dependent checks that only
+// surface on the host the agent runs on.
+func (c *cmd) checkConfig() int {
+	_, warnings, err := config.Load(c.flagArgs, nil)
+	if err != nil {
+		c.UI.Error(fmt.Sprintf("Config validation failed: %v", err))
+		return 1
+	}
+	for _, w := range warnings {
+		c.UI.Warn(w)
+	}
+	c.UI.Output("Configuration is valid!")
+	return 0
+}
+
 func (c *cmd) run(args []string) int {
 	if err := c.flags.Parse(args); err != nil {
 		if !strings.Contains(err.Error(), "help requested") {
@@ -160,9 +224,22 @@ func (c *cmd) run(args []string) int {
 		return 1
 	}
 
+	if c.printConfig != "" && c.printConfig != "hcl" && c.printConfig != "json" {
+		c.UI.Error("-print-config must be either 'hcl' or 'json'")
+		return 1
+	}
+	if c.printConfigExit && c.printConfig == "" {
+		c.UI.Error("-print-config-exit requires -print-config")
+		return 1
+	}
+
+	if c.configCheckOnly {
+		return c.checkConfig()
+	}
+
 	logGate := &logging.GatedWriter{Writer: &cli.UiWriter{Ui: c.UI}}
-	loader := func(source config.Source) (cfg *config.RuntimeConfig, warnings []string, err error) {
-		return config.Load(c.flagArgs, source)
+	loader := func(source config.Source, overrides ...config.Source) (cfg *config.RuntimeConfig, warnings []string, err error) {
+		return config.Load(c.flagArgs, source, overrides...)
 	}
 	bd, err := agent.NewBaseDeps(loader, logGate)
 	if err != nil {
@@ -179,6 +256,16 @@ func (c *cmd) run(args []string) int {
 
 	config := bd.RuntimeConfig
 
+	if c.printConfig != "" {
+		if err := c.printRuntimeConfig(config); err != nil {
+			c.UI.Error(err.Error())
+			return 1
+		}
+		if c.printConfigExit {
+			return 0
+		}
+	}
+
 	// Setup gate to check if we should output CLI information
 	cli := GatedUi{
 		JSONoutput: config.Logging.LogJSON,
@@ -272,6 +359,15 @@ func (c *cmd) run(args []string) int {
 
 	cli.output("Consul agent running!")
 
+	// watch the loaded config files and reload automatically when they
+	// change. The nil channel blocks forever when the mode is disabled.
+	var autoReloadCh <-chan struct{}
+	if config.AutoReloadConfig {
+		watchCtx, cancelWatch := context.WithCancel(context.Background())
+		defer cancelWatch()
+		autoReloadCh = c.watchConfigFiles(watchCtx, config)
+	}
+
 	// wait for signal
 	signalCh = make(chan os.Signal, 10)
 	signal.Notify(signalCh, os.Interrupt, syscall.SIGTERM, syscall.SIGHUP, syscall.SIGPIPE)
@@ -281,6 +377,9 @@ func (c *cmd) run(args []string) int {
 		select {
 		case s := <-signalCh:
 			sig = s
+		case <-autoReloadCh:
+			c.logger.Info("Config files changed, triggering reload")
+			sig = syscall.SIGHUP
 		case <-service_os.Shutdown_Channel():
 			sig = os.Interrupt
 		case <-c.shutdownCh:
@@ -306,6 +405,9 @@ func (c *cmd) run(args []string) int {
 			err := agent.ReloadConfig()
 			if err != nil {
 				c.logger.Error("Reload config failed", "error", err)
+			} else {
+				metrics.SetGauge([]string{"agent", "config", "last_reload_success_timestamp"},
+					float32(time.Now().Unix()))
 			}
 			config = agent.GetConfig()
 		default:
@@ -82,6 +82,99 @@ func TestConfigFail(t *testing.T) {
 	}
 }
 
+// TestConfigCheckOnly tests that -config-check-only runs the full config
+// pipeline, prints warnings and exits without starting the agent.
+func TestConfigCheckOnly(t *testing.T) {
+	t.Parallel()
+
+	t.Run("valid config with warnings", func(t *testing.T) {
+		dataDir := testutil.TempDir(t, "consul")
+		ui := cli.NewMockUi()
+		cmd := New(ui, "", "", "", "", nil)
+		args := []string{
+			"-config-check-only",
+			"-data-dir", dataDir,
+			"-bind", "127.0.0.1",
+			"-server",
+			"-bootstrap",
+		}
+		if code := cmd.Run(args); code != 0 {
+			t.Fatalf("bad exit code %d: %s", code, ui.ErrorWriter.String())
+		}
+		if out := ui.OutputWriter.String(); !strings.Contains(out, "Configuration is valid!") {
+			t.Fatalf("expected valid message, got: %s", out)
+		}
+		if out := ui.ErrorWriter.String(); !strings.Contains(out, "bootstrap = true: do not enable unless necessary") {
+			t.Fatalf("expected bootstrap warning, got: %s", out)
+		}
+	})
+
+	t.Run("invalid config", func(t *testing.T) {
+		dataDir := testutil.TempDir(t, "consul")
+		ui := cli.NewMockUi()
+		cmd := New(ui, "", "", "", "", nil)
+		args := []string{
+			"-config-check-only",
+			"-data-dir", dataDir,
+			"-bind", "127.0.0.1",
+			"-datacenter", "",
+		}
+		if code := cmd.Run(args); code != 1 {
+			t.Fatalf("expected exit code 1, got %d", code)
+		}
+		if out := ui.ErrorWriter.String(); !strings.Contains(out, "datacenter cannot be empty") {
+			t.Fatalf("expected datacenter error, got: %s", out)
+		}
+	})
+}
+
+// TestPrintConfig tests that -print-config renders the resolved runtime
+// configuration and that -print-config-exit stops before starting the agent.
+func TestPrintConfig(t *testing.T) {
+	t.Parallel()
+
+	t.Run("json and exit", func(t *testing.T) {
+		dataDir := testutil.TempDir(t, "consul")
+		ui := cli.NewMockUi()
+		cmd := New(ui, "", "", "", "", nil)
+		args := []string{
+			"-print-config", "json",
+			"-print-config-exit",
+			"-data-dir", dataDir,
+			"-bind", "127.0.0.1",
+			"-node", "print-config-node",
+		}
+		if code := cmd.Run(args); code != 0 {
+			t.Fatalf("bad exit code %d: %s", code, ui.ErrorWriter.String())
+		}
+		if out := ui.OutputWriter.String(); !strings.Contains(out, `"NodeName": "print-config-node"`) {
+			t.Fatalf("expected rendered config, got: %s", out)
+		}
+	})
+
+	t.Run("invalid format", func(t *testing.T) {
+		ui := cli.NewMockUi()
+		cmd := New(ui, "", "", "", "", nil)
+		if code := cmd.Run([]string{"-print-config", "yaml"}); code != 1 {
+			t.Fatalf("expected exit code 1")
+		}
+		if out := ui.ErrorWriter.String(); !strings.Contains(out, "-print-config must be either 'hcl' or 'json'") {
+			t.Fatalf("expected format error, got: %s", out)
+		}
+	})
+
+	t.Run("exit flag requires print flag", func(t *testing.T) {
+		ui := cli.NewMockUi()
+		cmd := New(ui, "", "", "", "", nil)
+		if code := cmd.Run([]string{"-print-config-exit"}); code != 1 {
+			t.Fatalf("expected exit code 1")
+		}
+		if out := ui.ErrorWriter.String(); !strings.Contains(out, "-print-config-exit requires -print-config") {
+			t.Fatalf("expected flag error, got: %s", out)
+		}
+	})
+}
+
 func TestRetryJoin(t *testing.T) {
 	if testing.Short() {
 		t.Skip("too slow for testing.Short")
@@ -42,9 +42,13 @@ import (
 	catlistnodes "github.com/hashicorp/consul/command/catalog/list/nodes"
 	catlistsvc "github.com/hashicorp/consul/command/catalog/list/services"
 	"github.com/hashicorp/consul/command/config"
+	configagentdiff "github.com/hashicorp/consul/command/config/agentdiff"
 	configdelete "github.com/hashicorp/consul/command/config/delete"
+	configdeprecations "github.com/hashicorp/consul/command/config/deprecations"
 	configlist "github.com/hashicorp/consul/command/config/list"
 	configread "github.com/hashicorp/consul/command/config/read"
+	configschema "github.com/hashicorp/consul/command/config/schema"
+	configupgrade "github.com/hashicorp/consul/command/config/upgrade"
 	configwrite "github.com/hashicorp/consul/command/config/write"
 	"github.com/hashicorp/consul/command/connect"
 	"github.com/hashicorp/consul/command/connect/ca"
@@ -160,9 +164,13 @@ func init() {
 	Register("catalog nodes", func(ui cli.Ui) (cli.Command, error) { return catlistnodes.New(ui), nil })
 	Register("catalog services", func(ui cli.Ui) (cli.Command, error) { return catlistsvc.New(ui), nil })
 	Register("config", func(ui cli.Ui) (cli.Command, error) { return config.New(), nil })
+	Register("config agent-diff", func(ui cli.Ui) (cli.Command, error) { return configagentdiff.New(ui), nil })
 	Register("config delete", func(ui cli.Ui) (cli.Command, error) { return configdelete.New(ui), nil })
+	Register("config deprecations", func(ui cli.Ui) (cli.Command, error) { return configdeprecations.New(ui), nil })
 	Register("config list", func(ui cli.Ui) (cli.Command, error) { return configlist.New(ui), nil })
 	Register("config read", func(ui cli.Ui) (cli.Command, error) { return configread.New(ui), nil })
+	Register("config schema", func(ui cli.Ui) (cli.Command, error) { return configschema.New(ui), nil })
+	Register("config upgrade", func(ui cli.Ui) (cli.Command, error) { return configupgrade.New(ui), nil })
 	Register("config write", func(ui cli.Ui) (cli.Command, error) { return configwrite.New(ui), nil })
 	Register("connect", func(ui cli.Ui) (cli.Command, error) { return connect.New(), nil })
 	Register("connect ca", func(ui cli.Ui) (cli.Command, error) { return ca.New(), nil })
@@ -0,0 +1,146 @@
+package agentdiff
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"sort"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/hashicorp/consul/agent/config"
+	"github.com/hashicorp/consul/command/flags"
+	"github.com/mitchellh/cli"
+)
+
+func New(ui cli.Ui) *cmd {
+	c := &cmd{UI: ui}
+	c.init()
+	return c
+}
+
+type cmd struct {
+	UI    cli.Ui
+	flags *flag.FlagSet
+	http  *flags.HTTPFlags
+	help  string
+
+	configFiles  flags.AppendSliceValue
+	configFormat string
+}
+
+func (c *cmd) init() {
+	c.flags = flag.NewFlagSet("", flag.ContinueOnError)
+	c.flags.Var(&c.configFiles, "config-file",
+		"Path to a file to read for the local configuration. This can be specified multiple times.")
+	c.flags.Var(&c.configFiles, "config-dir",
+		"Path to a directory to read for the local configuration. This can be specified multiple times.")
+	c.flags.StringVar(&c.configFormat, "config-format", "",
+		"Config files are in this format irrespective of their extension. Must be 'hcl' or 'json'")
+	c.http = &flags.HTTPFlags{}
+	flags.Merge(c.flags, c.http.ClientFlags())
+	c.help = flags.Usage(help, c.flags)
+}
+
+func (c *cmd) Run(args []string) int {
+	if err := c.flags.Parse(args); err != nil {
+		return 1
+	}
+
+	if len(c.configFiles) < 1 {
+		c.UI.Error("Must specify at least one config file or directory with -config-file or -config-dir")
+		return 1
+	}
+
+	rt, _, err := config.Load(config.BuilderOpts{
+		ConfigFiles:  c.configFiles,
+		ConfigFormat: c.configFormat,
+	}, nil)
+	if err != nil {
+		c.UI.Error(fmt.Sprintf("Config validation failed: %v", err))
+		return 1
+	}
+
+	// Round-trip the sanitized local config through JSON so both sides of
+	// the diff have the same types. The agent returns its sanitized config
+	// as decoded JSON where all numbers are float64.
+	local := map[string]interface{}{}
+	b, err := json.Marshal(rt.Sanitized())
+	if err != nil {
+		c.UI.Error(fmt.Sprintf("Failed to encode local config: %v", err))
+		return 1
+	}
+	if err := json.Unmarshal(b, &local); err != nil {
+		c.UI.Error(fmt.Sprintf("Failed to decode local config: %v", err))
+		return 1
+	}
+
+	client, err := c.http.APIClient()
+	if err != nil {
+		c.UI.Error(fmt.Sprintf("Error connect to Consul agent: %s", err))
+		return 1
+	}
+
+	self, err := client.Agent().Self()
+	if err != nil {
+		c.UI.Error(fmt.Sprintf("Error querying agent: %s", err))
+		return 1
+	}
+	remote, ok := self["DebugConfig"]
+	if !ok {
+		c.UI.Error("Agent did not return its runtime configuration")
+		return 1
+	}
+
+	var fields []string
+	for k := range local {
+		fields = append(fields, k)
+	}
+	for k := range remote {
+		if _, ok := local[k]; !ok {
+			fields = append(fields, k)
+		}
+	}
+	sort.Strings(fields)
+
+	changed := 0
+	for _, k := range fields {
+		if diff := cmp.Diff(remote[k], local[k]); diff != "" {
+			if changed == 0 {
+				c.UI.Output("The following fields would change on restart:")
+			}
+			changed++
+			c.UI.Output(fmt.Sprintf("%s:\n%s", k, diff))
+		}
+	}
+	if changed == 0 {
+		c.UI.Output("Configuration is in sync with the running agent.")
+		return 0
+	}
+	return 2
+}
+
+func (c *cmd) Synopsis() string {
+	return synopsis
+}
+
+func (c *cmd) Help() string {
+	return flags.Usage(c.help, nil)
+}
+
+const synopsis = "Diff local config files against a running agent"
+const help = `
+Usage: consul config agent-diff [options] -config-dir <directory>
+
+  Builds the runtime configuration from the given config files and
+  directories, exactly as "consul agent" would, and diffs it against the
+  sanitized runtime configuration of the running agent. Fields that differ
+  are printed with the running value prefixed by '-' and the local value
+  prefixed by '+'.
+
+  Returns 0 if the configurations match, 2 if fields would change on
+  restart and 1 if an error occurred.
+
+  Example:
+
+    $ consul config agent-diff -config-dir /etc/consul.d
+`
@@ -0,0 +1,62 @@
+package agentdiff
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/consul/agent"
+	"github.com/hashicorp/consul/sdk/testutil"
+	"github.com/mitchellh/cli"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigAgentDiff_noTabs(t *testing.T) {
+	t.Parallel()
+	if strings.ContainsRune(New(cli.NewMockUi()).Help(), '\t') {
+		t.Fatal("help has tabs")
+	}
+}
+
+func TestConfigAgentDiff(t *testing.T) {
+	if testing.Short() {
+		t.Skip("too slow for testing.Short")
+	}
+
+	t.Parallel()
+	a := agent.NewTestAgent(t, ``)
+	defer a.Shutdown()
+
+	dir := testutil.TempDir(t, "agent-diff")
+	content := `
+		data_dir = "` + dir + `"
+		node_name = "agent-diff-test"
+	`
+	err := ioutil.WriteFile(filepath.Join(dir, "a.hcl"), []byte(content), 0644)
+	require.NoError(t, err)
+
+	ui := cli.NewMockUi()
+	c := New(ui)
+	args := []string{
+		"-http-addr=" + a.HTTPAddr(),
+		"-config-dir=" + dir,
+	}
+
+	// The test agent uses a generated node name and random ports so the
+	// local config must differ from the running one.
+	code := c.Run(args)
+	require.Equal(t, 2, code, ui.ErrorWriter.String())
+	require.Contains(t, ui.OutputWriter.String(), "The following fields would change on restart:")
+	require.Contains(t, ui.OutputWriter.String(), "NodeName:")
+}
+
+func TestConfigAgentDiff_noConfig(t *testing.T) {
+	t.Parallel()
+
+	ui := cli.NewMockUi()
+	c := New(ui)
+	code := c.Run(nil)
+	require.Equal(t, 1, code)
+	require.Contains(t, ui.ErrorWriter.String(), "Must specify at least one config file or directory")
+}
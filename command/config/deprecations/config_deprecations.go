@@ -0,0 +1,168 @@
+package deprecations
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/hashicorp/consul/agent/config"
+	"github.com/hashicorp/consul/command/flags"
+	"github.com/mitchellh/cli"
+)
+
+func New(ui cli.Ui) *cmd {
+	c := &cmd{UI: ui}
+	c.init()
+	return c
+}
+
+type cmd struct {
+	UI    cli.Ui
+	flags *flag.FlagSet
+	help  string
+
+	configFiles flags.AppendSliceValue
+}
+
+func (c *cmd) init() {
+	c.flags = flag.NewFlagSet("", flag.ContinueOnError)
+	c.flags.Var(&c.configFiles, "config-file",
+		"Path to a file to check for deprecated keys. This can be specified multiple times.")
+	c.flags.Var(&c.configFiles, "config-dir",
+		"Path to a directory to check for deprecated keys. This can be specified multiple times.")
+	c.help = flags.Usage(help, c.flags)
+}
+
+// occurrence is a deprecated key found in a config file.
+type occurrence struct {
+	file string
+	line int
+	key  config.DeprecatedKey
+}
+
+func (c *cmd) Run(args []string) int {
+	if err := c.flags.Parse(args); err != nil {
+		return 1
+	}
+
+	if len(c.configFiles) < 1 {
+		c.UI.Error("Must specify at least one config file or directory with -config-file or -config-dir")
+		return 1
+	}
+
+	var found []occurrence
+	for _, path := range c.configFiles {
+		occs, err := scanPath(path)
+		if err != nil {
+			c.UI.Error(err.Error())
+			return 1
+		}
+		found = append(found, occs...)
+	}
+
+	if len(found) == 0 {
+		c.UI.Output("No deprecated config keys in use.")
+		return 0
+	}
+
+	for _, o := range found {
+		msg := fmt.Sprintf("%s:%d: %q is deprecated and will be removed in Consul %s", o.file, o.line, o.key.Name, o.key.RemovedIn)
+		if o.key.ReplacedBy != "" {
+			msg += fmt.Sprintf("; use %q instead", o.key.ReplacedBy)
+		} else {
+			msg += "; remove it"
+		}
+		c.UI.Output(msg)
+	}
+	return 2
+}
+
+// scanPath scans a config file for deprecated keys. For directories every
+// file with a .hcl or .json extension is scanned, matching the files the
+// agent would read from a -config-dir.
+func scanPath(path string) ([]occurrence, error) {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read %s: %s", path, err)
+	}
+	if !fi.IsDir() {
+		return scanFile(path)
+	}
+
+	fis, err := ioutil.ReadDir(path)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read %s: %s", path, err)
+	}
+	var found []occurrence
+	for _, fi := range fis {
+		if fi.IsDir() {
+			continue
+		}
+		switch filepath.Ext(fi.Name()) {
+		case ".hcl", ".json":
+		default:
+			continue
+		}
+		occs, err := scanFile(filepath.Join(path, fi.Name()))
+		if err != nil {
+			return nil, err
+		}
+		found = append(found, occs...)
+	}
+	return found, nil
+}
+
+// scanFile reports every line of the file that sets a deprecated key. The
+// match is textual so it works the same for HCL and JSON and keeps the
+// original line numbers.
+func scanFile(path string) ([]occurrence, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read %s: %s", path, err)
+	}
+
+	keys := config.DeprecatedKeys()
+	res := make([]*regexp.Regexp, len(keys))
+	for i, key := range keys {
+		res[i] = regexp.MustCompile(`^\s*"?` + regexp.QuoteMeta(key.Name) + `"?\s*[:={]`)
+	}
+
+	var found []occurrence
+	for i, line := range strings.Split(string(data), "\n") {
+		for j, re := range res {
+			if re.MatchString(line) {
+				found = append(found, occurrence{file: path, line: i + 1, key: keys[j]})
+			}
+		}
+	}
+	return found, nil
+}
+
+func (c *cmd) Synopsis() string {
+	return synopsis
+}
+
+func (c *cmd) Help() string {
+	return flags.Usage(c.help, nil)
+}
+
+const synopsis = "List deprecated config keys used by config files"
+const help = `
+Usage: consul config deprecations [options] -config-dir <directory>
+
+  Scans the given config files and directories for config keys that are
+  deprecated and prints each occurrence with the file and line where it
+  appears, the version the key will be removed in and the key that
+  replaces it.
+
+  Returns 0 if no deprecated keys are in use, 2 if any were found and 1
+  if an error occurred.
+
+  Example:
+
+    $ consul config deprecations -config-dir /etc/consul.d
+`
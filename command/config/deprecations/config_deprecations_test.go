@@ -0,0 +1,66 @@
+package deprecations
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/consul/sdk/testutil"
+	"github.com/mitchellh/cli"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigDeprecations_noTabs(t *testing.T) {
+	t.Parallel()
+	if strings.ContainsRune(New(cli.NewMockUi()).Help(), '\t') {
+		t.Fatal("help has tabs")
+	}
+}
+
+func TestConfigDeprecations(t *testing.T) {
+	t.Parallel()
+
+	dir := testutil.TempDir(t, "deprecations")
+	hcl := `
+acl_datacenter = "dc1"
+node_name = "deprecations-test"
+ui_dir = "/opt/consul/ui"
+`
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "a.hcl"), []byte(hcl), 0644))
+	json := `{
+	"ui": true
+}`
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "b.json"), []byte(json), 0644))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "ignored.txt"), []byte(`acl_datacenter = "dc1"`), 0644))
+
+	ui := cli.NewMockUi()
+	c := New(ui)
+	code := c.Run([]string{"-config-dir", dir})
+	require.Equal(t, 2, code, ui.ErrorWriter.String())
+
+	out := ui.OutputWriter.String()
+	require.Contains(t, out, filepath.Join(dir, "a.hcl")+`:2: "acl_datacenter" is deprecated and will be removed in Consul 1.10.0; use "primary_datacenter" instead`)
+	require.Contains(t, out, filepath.Join(dir, "a.hcl")+`:4: "ui_dir" is deprecated and will be removed in Consul 1.10.0; use "ui_config.dir" instead`)
+	require.Contains(t, out, filepath.Join(dir, "b.json")+`:2: "ui" is deprecated and will be removed in Consul 1.10.0; use "ui_config.enabled" instead`)
+	require.NotContains(t, out, "ignored.txt")
+}
+
+func TestConfigDeprecations_clean(t *testing.T) {
+	t.Parallel()
+
+	dir := testutil.TempDir(t, "deprecations")
+	hcl := `
+primary_datacenter = "dc1"
+ui_config {
+	enabled = true
+}
+`
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "a.hcl"), []byte(hcl), 0644))
+
+	ui := cli.NewMockUi()
+	c := New(ui)
+	code := c.Run([]string{"-config-file", filepath.Join(dir, "a.hcl")})
+	require.Equal(t, 0, code, ui.ErrorWriter.String())
+	require.Contains(t, ui.OutputWriter.String(), "No deprecated config keys in use.")
+}
@@ -0,0 +1,64 @@
+package schema
+
+import (
+	"flag"
+	"fmt"
+
+	"github.com/hashicorp/consul/agent/config"
+	"github.com/hashicorp/consul/command/flags"
+	"github.com/mitchellh/cli"
+)
+
+func New(ui cli.Ui) *cmd {
+	c := &cmd{UI: ui}
+	c.init()
+	return c
+}
+
+type cmd struct {
+	UI    cli.Ui
+	flags *flag.FlagSet
+	help  string
+}
+
+func (c *cmd) init() {
+	c.flags = flag.NewFlagSet("", flag.ContinueOnError)
+	c.help = flags.Usage(help, c.flags)
+}
+
+func (c *cmd) Run(args []string) int {
+	if err := c.flags.Parse(args); err != nil {
+		return 1
+	}
+
+	b, err := config.JSONSchema()
+	if err != nil {
+		c.UI.Error(fmt.Sprintf("Error generating schema: %s", err))
+		return 1
+	}
+
+	c.UI.Info(string(b))
+	return 0
+}
+
+func (c *cmd) Synopsis() string {
+	return synopsis
+}
+
+func (c *cmd) Help() string {
+	return flags.Usage(c.help, nil)
+}
+
+const synopsis = "Output the JSON Schema for agent configuration files"
+const help = `
+Usage: consul config schema
+
+  Outputs a JSON Schema document describing the agent configuration file
+  format. The schema is generated from the configuration the agent accepts,
+  including deprecated keys which are marked as such, and can be used to
+  validate rendered config files before deploying them.
+
+  Example:
+
+    $ consul config schema > consul-config-schema.json
+`
@@ -0,0 +1,44 @@
+package schema
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/mitchellh/cli"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigSchema_noTabs(t *testing.T) {
+	t.Parallel()
+
+	require.NotContains(t, New(cli.NewMockUi()).Help(), "\t")
+}
+
+func TestConfigSchema(t *testing.T) {
+	t.Parallel()
+
+	ui := cli.NewMockUi()
+	c := New(ui)
+
+	code := c.Run(nil)
+	require.Equal(t, 0, code)
+	require.Empty(t, ui.ErrorWriter.String())
+
+	var schema map[string]interface{}
+	require.NoError(t, json.Unmarshal(ui.OutputWriter.Bytes(), &schema))
+
+	props, ok := schema["properties"].(map[string]interface{})
+	require.True(t, ok)
+	require.Contains(t, props, "node_name")
+}
+
+func TestConfigSchema_InvalidArgs(t *testing.T) {
+	t.Parallel()
+
+	ui := cli.NewMockUi()
+	c := New(ui)
+
+	code := c.Run([]string{"-badflag"})
+	require.Equal(t, 1, code)
+	require.Empty(t, ui.OutputWriter.String())
+}
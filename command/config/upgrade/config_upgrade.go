@@ -0,0 +1,266 @@
+package upgrade
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/consul/agent/config"
+	"github.com/hashicorp/consul/command/flags"
+	"github.com/mitchellh/cli"
+)
+
+func New(ui cli.Ui) *cmd {
+	c := &cmd{UI: ui}
+	c.init()
+	return c
+}
+
+type cmd struct {
+	UI    cli.Ui
+	flags *flag.FlagSet
+	help  string
+
+	configFiles flags.AppendSliceValue
+	dryRun      bool
+}
+
+func (c *cmd) init() {
+	c.flags = flag.NewFlagSet("", flag.ContinueOnError)
+	c.flags.Var(&c.configFiles, "config-file",
+		"Path to a file to rewrite. This can be specified multiple times.")
+	c.flags.Var(&c.configFiles, "config-dir",
+		"Path to a directory of config files to rewrite. This can be specified multiple times.")
+	c.flags.BoolVar(&c.dryRun, "dry-run", false,
+		"Report the files that would be rewritten without changing them.")
+	c.help = flags.Usage(help, c.flags)
+}
+
+func (c *cmd) Run(args []string) int {
+	if err := c.flags.Parse(args); err != nil {
+		return 1
+	}
+
+	if len(c.configFiles) < 1 {
+		c.UI.Error("Must specify at least one config file or directory with -config-file or -config-dir")
+		return 1
+	}
+
+	var files []string
+	for _, path := range c.configFiles {
+		expanded, err := expandPath(path)
+		if err != nil {
+			c.UI.Error(err.Error())
+			return 1
+		}
+		files = append(files, expanded...)
+	}
+
+	rewritten := 0
+	for _, path := range files {
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			c.UI.Error(fmt.Sprintf("Failed to read %s: %s", path, err))
+			return 1
+		}
+
+		var out string
+		var changes []string
+		switch filepath.Ext(path) {
+		case ".json":
+			out, changes, err = upgradeJSON(string(data))
+		default:
+			out, changes, err = upgradeHCL(string(data))
+		}
+		if err != nil {
+			c.UI.Error(fmt.Sprintf("Failed to rewrite %s: %s", path, err))
+			return 1
+		}
+		if len(changes) == 0 {
+			continue
+		}
+
+		rewritten++
+		verb := "Rewrote"
+		if c.dryRun {
+			verb = "Would rewrite"
+		} else {
+			mode := os.FileMode(0644)
+			if fi, err := os.Stat(path); err == nil {
+				mode = fi.Mode()
+			}
+			if err := ioutil.WriteFile(path, []byte(out), mode); err != nil {
+				c.UI.Error(fmt.Sprintf("Failed to write %s: %s", path, err))
+				return 1
+			}
+		}
+		c.UI.Output(fmt.Sprintf("%s %s: %s", verb, path, strings.Join(changes, ", ")))
+	}
+
+	if rewritten == 0 {
+		c.UI.Output("No deprecated config keys in use.")
+	}
+	return 0
+}
+
+// expandPath returns the config files a path refers to. Directories expand
+// to the .hcl and .json files they contain, matching the files the agent
+// would read from a -config-dir.
+func expandPath(path string) ([]string, error) {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read %s: %s", path, err)
+	}
+	if !fi.IsDir() {
+		return []string{path}, nil
+	}
+	fis, err := ioutil.ReadDir(path)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read %s: %s", path, err)
+	}
+	var files []string
+	for _, fi := range fis {
+		if fi.IsDir() {
+			continue
+		}
+		switch filepath.Ext(fi.Name()) {
+		case ".hcl", ".json":
+			files = append(files, filepath.Join(path, fi.Name()))
+		}
+	}
+	return files, nil
+}
+
+// upgradeHCL rewrites deprecated keys in an HCL config file and returns
+// the new content and a description of each change. The rewrite works on
+// lines so that comments and formatting are preserved. Keys whose
+// replacement lives in a block (e.g. ui -> ui_config.enabled) are removed
+// in place and collected into a block appended at the end of the file.
+func upgradeHCL(data string) (string, []string, error) {
+	keys := config.DeprecatedKeys()
+	res := make([]*regexp.Regexp, len(keys))
+	for i, key := range keys {
+		res[i] = regexp.MustCompile(`^(\s*)"?` + regexp.QuoteMeta(key.Name) + `"?\s*=\s*(.*?)\s*$`)
+	}
+
+	var changes []string
+	blocks := map[string][]string{}
+	var blockNames []string
+
+	var out []string
+	for _, line := range strings.Split(data, "\n") {
+		replaced := false
+		for i, key := range keys {
+			m := res[i].FindStringSubmatch(line)
+			if m == nil {
+				continue
+			}
+			replaced = true
+			switch {
+			case key.ReplacedBy == "":
+				changes = append(changes, fmt.Sprintf("removed %s", key.Name))
+			case strings.Contains(key.ReplacedBy, "."):
+				parts := strings.SplitN(key.ReplacedBy, ".", 2)
+				if _, ok := blocks[parts[0]]; !ok {
+					blockNames = append(blockNames, parts[0])
+				}
+				blocks[parts[0]] = append(blocks[parts[0]], fmt.Sprintf("  %s = %s", parts[1], m[2]))
+				changes = append(changes, fmt.Sprintf("%s -> %s", key.Name, key.ReplacedBy))
+			default:
+				out = append(out, m[1]+key.ReplacedBy+" = "+m[2])
+				changes = append(changes, fmt.Sprintf("%s -> %s", key.Name, key.ReplacedBy))
+			}
+			break
+		}
+		if !replaced {
+			out = append(out, line)
+		}
+	}
+
+	sort.Strings(blockNames)
+	for _, name := range blockNames {
+		for len(out) > 0 && strings.TrimSpace(out[len(out)-1]) == "" {
+			out = out[:len(out)-1]
+		}
+		out = append(out, "", name+" {")
+		out = append(out, blocks[name]...)
+		out = append(out, "}", "")
+	}
+
+	return strings.Join(out, "\n"), changes, nil
+}
+
+// upgradeJSON rewrites deprecated keys in a JSON config file and returns
+// the new content and a description of each change. JSON has no comments,
+// so the file is decoded, transformed and re-encoded.
+func upgradeJSON(data string) (string, []string, error) {
+	var m map[string]interface{}
+	if err := json.Unmarshal([]byte(data), &m); err != nil {
+		return "", nil, err
+	}
+
+	var changes []string
+	for _, key := range config.DeprecatedKeys() {
+		v, ok := m[key.Name]
+		if !ok {
+			continue
+		}
+		delete(m, key.Name)
+		switch {
+		case key.ReplacedBy == "":
+			changes = append(changes, fmt.Sprintf("removed %s", key.Name))
+		case strings.Contains(key.ReplacedBy, "."):
+			parts := strings.SplitN(key.ReplacedBy, ".", 2)
+			block, ok := m[parts[0]].(map[string]interface{})
+			if !ok {
+				block = map[string]interface{}{}
+				m[parts[0]] = block
+			}
+			block[parts[1]] = v
+			changes = append(changes, fmt.Sprintf("%s -> %s", key.Name, key.ReplacedBy))
+		default:
+			m[key.ReplacedBy] = v
+			changes = append(changes, fmt.Sprintf("%s -> %s", key.Name, key.ReplacedBy))
+		}
+	}
+	if len(changes) == 0 {
+		return data, nil, nil
+	}
+	sort.Strings(changes)
+
+	out, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return "", nil, err
+	}
+	return string(out) + "\n", changes, nil
+}
+
+func (c *cmd) Synopsis() string {
+	return synopsis
+}
+
+func (c *cmd) Help() string {
+	return flags.Usage(c.help, nil)
+}
+
+const synopsis = "Rewrite config files to replace deprecated keys"
+const help = `
+Usage: consul config upgrade [options] -config-dir <directory>
+
+  Rewrites the given config files and directories, replacing deprecated
+  keys with their modern equivalents, e.g. acl_datacenter with
+  primary_datacenter and ui with the ui_config block. Comments and
+  formatting in HCL files are preserved; JSON files are re-encoded.
+
+  Use -dry-run to see which files would change without rewriting them.
+
+  Example:
+
+    $ consul config upgrade -config-dir /etc/consul.d
+`
@@ -0,0 +1,93 @@
+package upgrade
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/consul/sdk/testutil"
+	"github.com/mitchellh/cli"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigUpgrade_noTabs(t *testing.T) {
+	t.Parallel()
+	if strings.ContainsRune(New(cli.NewMockUi()).Help(), '\t') {
+		t.Fatal("help has tabs")
+	}
+}
+
+func TestConfigUpgrade_HCL(t *testing.T) {
+	t.Parallel()
+
+	dir := testutil.TempDir(t, "upgrade")
+	path := filepath.Join(dir, "a.hcl")
+	hcl := `# keep this comment
+acl_datacenter = "dc1"
+acl_enforce_version_8 = true
+node_name = "upgrade-test"
+ui = true
+ui_content_path = "/bar"
+`
+	require.NoError(t, ioutil.WriteFile(path, []byte(hcl), 0644))
+
+	ui := cli.NewMockUi()
+	c := New(ui)
+	require.Equal(t, 0, c.Run([]string{"-config-file", path}), ui.ErrorWriter.String())
+
+	data, err := ioutil.ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, `# keep this comment
+primary_datacenter = "dc1"
+node_name = "upgrade-test"
+
+ui_config {
+  enabled = true
+  content_path = "/bar"
+}
+`, string(data))
+}
+
+func TestConfigUpgrade_JSON(t *testing.T) {
+	t.Parallel()
+
+	dir := testutil.TempDir(t, "upgrade")
+	path := filepath.Join(dir, "a.json")
+	json := `{
+	"acl_datacenter": "dc1",
+	"ui_dir": "/opt/consul/ui",
+	"node_name": "upgrade-test"
+}`
+	require.NoError(t, ioutil.WriteFile(path, []byte(json), 0644))
+
+	ui := cli.NewMockUi()
+	c := New(ui)
+	require.Equal(t, 0, c.Run([]string{"-config-file", path}), ui.ErrorWriter.String())
+
+	data, err := ioutil.ReadFile(path)
+	require.NoError(t, err)
+	require.JSONEq(t, `{
+		"primary_datacenter": "dc1",
+		"ui_config": { "dir": "/opt/consul/ui" },
+		"node_name": "upgrade-test"
+	}`, string(data))
+}
+
+func TestConfigUpgrade_DryRun(t *testing.T) {
+	t.Parallel()
+
+	dir := testutil.TempDir(t, "upgrade")
+	path := filepath.Join(dir, "a.hcl")
+	hcl := `acl_datacenter = "dc1"` + "\n"
+	require.NoError(t, ioutil.WriteFile(path, []byte(hcl), 0644))
+
+	ui := cli.NewMockUi()
+	c := New(ui)
+	require.Equal(t, 0, c.Run([]string{"-dry-run", "-config-dir", dir}), ui.ErrorWriter.String())
+	require.Contains(t, ui.OutputWriter.String(), "Would rewrite "+path+": acl_datacenter -> primary_datacenter")
+
+	data, err := ioutil.ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, hcl, string(data))
+}
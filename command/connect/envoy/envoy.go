@@ -553,17 +553,17 @@ func (c *cmd) grpcAddress(httpCfg *api.Config) (GRPC, error) {
 	addr := c.grpcAddr
 	// See if we need to lookup grpcAddr
 	if addr == "" {
-		port, err := c.lookupGRPCPort()
+		discovered, err := c.lookupGRPCAddress()
 		if err != nil {
 			c.UI.Error(fmt.Sprintf("Error connecting to Consul agent: %s", err))
 		}
-		if port <= 0 {
+		if discovered == "" {
 			// This is the dev mode default and recommended production setting if
 			// enabled.
-			port = 8502
-			c.UI.Info(fmt.Sprintf("Defaulting to grpc port = %d", port))
+			discovered = "localhost:8502"
+			c.UI.Info(fmt.Sprintf("Defaulting to grpc address = %s", discovered))
 		}
-		addr = fmt.Sprintf("localhost:%v", port)
+		addr = discovered
 	}
 
 	// TODO: parse addr as a url instead of strings.HasPrefix/TrimPrefix
@@ -612,25 +612,40 @@ func (c *cmd) grpcAddress(httpCfg *api.Config) (GRPC, error) {
 	return g, nil
 }
 
-func (c *cmd) lookupGRPCPort() (int, error) {
+// lookupGRPCAddress asks the agent for its gRPC listener address. A unix
+// socket listener is preferred over a TCP one since being able to reach the
+// agent's HTTP API doesn't imply the (possibly loopback-only) gRPC port is
+// reachable, while a socket path works for any local consumer. An empty
+// string with a nil error means the agent has gRPC disabled.
+func (c *cmd) lookupGRPCAddress() (string, error) {
 	self, err := c.client.Agent().Self()
 	if err != nil {
-		return 0, err
+		return "", err
 	}
 	cfg, ok := self["DebugConfig"]
 	if !ok {
-		return 0, fmt.Errorf("unexpected agent response: no debug config")
+		return "", fmt.Errorf("unexpected agent response: no debug config")
+	}
+	if addrs, ok := cfg["GRPCAddrs"].([]interface{}); ok {
+		for _, a := range addrs {
+			if addr, ok := a.(string); ok && strings.HasPrefix(addr, "unix://") {
+				return addr, nil
+			}
+		}
 	}
 	port, ok := cfg["GRPCPort"]
 	if !ok {
-		return 0, fmt.Errorf("agent does not have grpc port enabled")
+		return "", fmt.Errorf("agent does not have grpc port enabled")
 	}
 	portN, ok := port.(float64)
 	if !ok {
-		return 0, fmt.Errorf("invalid grpc port in agent response")
+		return "", fmt.Errorf("invalid grpc port in agent response")
+	}
+	if portN <= 0 {
+		return "", nil
 	}
 
-	return int(portN), nil
+	return fmt.Sprintf("localhost:%v", int(portN)), nil
 }
 
 func (c *cmd) Synopsis() string {
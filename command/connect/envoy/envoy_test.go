@@ -111,7 +111,8 @@ type generateConfigTestCase struct {
 	Files             map[string]string
 	ProxyConfig       map[string]interface{}
 	NamespacesEnabled bool
-	GRPCPort          int // only used for testing custom-configured grpc port
+	GRPCPort          int      // only used for testing custom-configured grpc port
+	GRPCAddrs         []string // only used for testing custom-configured grpc addresses
 	WantArgs          BootstrapTplArgs
 	WantErr           string
 }
@@ -341,6 +342,29 @@ func TestGenerateConfig(t *testing.T) {
 				LocalAgentClusterName: xds.LocalAgentClusterName,
 			},
 		},
+		{
+			Name:      "grpc-addr-unix-config",
+			Flags:     []string{"-proxy-id", "test-proxy"},
+			GRPCPort:  9999,
+			GRPCAddrs: []string{"unix:///var/run/consul-grpc.sock", "tcp://127.0.0.1:9999"},
+			WantArgs: BootstrapTplArgs{
+				EnvoyVersion: defaultEnvoyVersion,
+				ProxyCluster: "test-proxy",
+				ProxyID:      "test-proxy",
+				// We don't know this til after the lookup so it will be empty in the
+				// initial args call we are testing here.
+				ProxySourceService: "",
+				// The agent's advertised unix socket should win over the TCP port
+				// since any local process can reach it.
+				GRPC: GRPC{
+					AgentSocket: "/var/run/consul-grpc.sock",
+				},
+				AdminAccessLogPath:    "/dev/null",
+				AdminBindAddress:      "127.0.0.1",
+				AdminBindPort:         "19000",
+				LocalAgentClusterName: xds.LocalAgentClusterName,
+			},
+		},
 		{
 			Name:  "access-log-path",
 			Flags: []string{"-proxy-id", "test-proxy", "-admin-access-log-path", "/some/path/access.log"},
@@ -807,7 +831,7 @@ func TestGenerateConfig(t *testing.T) {
 
 			// Run a mock agent API that just always returns the proxy config in the
 			// test.
-			srv := httptest.NewServer(testMockAgent(tc.ProxyConfig, tc.GRPCPort, tc.NamespacesEnabled))
+			srv := httptest.NewServer(testMockAgent(tc.ProxyConfig, tc.GRPCPort, tc.GRPCAddrs, tc.NamespacesEnabled))
 			defer srv.Close()
 			client, err := api.NewClient(&api.Config{Address: srv.URL})
 			require.NoError(err)
@@ -957,7 +981,7 @@ func TestEnvoy_GatewayRegistration(t *testing.T) {
 // testMockAgent combines testMockAgentProxyConfig and testMockAgentSelf,
 // routing /agent/service/... requests to testMockAgentProxyConfig and
 // routing /agent/self requests to testMockAgentSelf.
-func testMockAgent(agentCfg map[string]interface{}, grpcPort int, namespacesEnabled bool) http.HandlerFunc {
+func testMockAgent(agentCfg map[string]interface{}, grpcPort int, grpcAddrs []string, namespacesEnabled bool) http.HandlerFunc {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if strings.Contains(r.URL.Path, "/agent/services") {
 			testMockAgentGatewayConfig(namespacesEnabled)(w, r)
@@ -970,7 +994,7 @@ func testMockAgent(agentCfg map[string]interface{}, grpcPort int, namespacesEnab
 		}
 
 		if strings.Contains(r.URL.Path, "/agent/self") {
-			testMockAgentSelf(grpcPort)(w, r)
+			testMockAgentSelf(grpcPort, grpcAddrs)(w, r)
 			return
 		}
 
@@ -1155,16 +1179,20 @@ func TestEnvoyCommand_canBindInternal(t *testing.T) {
 }
 
 // testMockAgentSelf returns an empty /v1/agent/self response except GRPC
-// port is filled in to match the given wantGRPCPort argument.
-func testMockAgentSelf(wantGRPCPort int) http.HandlerFunc {
+// port and addresses are filled in to match the given arguments.
+func testMockAgentSelf(wantGRPCPort int, wantGRPCAddrs []string) http.HandlerFunc {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		debugCfg := map[string]interface{}{
+			"GRPCPort": wantGRPCPort,
+		}
+		if len(wantGRPCAddrs) > 0 {
+			debugCfg["GRPCAddrs"] = wantGRPCAddrs
+		}
 		resp := agent.Self{
 			Config: map[string]interface{}{
 				"Datacenter": "dc1",
 			},
-			DebugConfig: map[string]interface{}{
-				"GRPCPort": wantGRPCPort,
-			},
+			DebugConfig: debugCfg,
 		}
 
 		selfJSON, err := json.Marshal(resp)
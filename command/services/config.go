@@ -31,7 +31,7 @@ func ServicesFromFiles(ui cli.Ui, files []string) ([]*api.AgentServiceRegistrati
 		return nil, err
 	}
 	for _, w := range b.Warnings {
-		ui.Warn(w)
+		ui.Warn(w.String())
 	}
 
 	// The services are now in "structs.ServiceDefinition" form and we need
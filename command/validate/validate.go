@@ -1,6 +1,7 @@
 package validate
 
 import (
+	"encoding/json"
 	"flag"
 	"fmt"
 
@@ -21,14 +22,30 @@ type cmd struct {
 	// configFormat forces all config files to be interpreted as this
 	// format independent of their extension.
 	configFormat string
+	format       string
+	printConfig  string
 	quiet        bool
 	help         string
 }
 
+// validateResult is the document emitted by -format=json. It contains
+// everything the text output would have printed so that automation does not
+// lose the warnings.
+type validateResult struct {
+	Valid    bool                     `json:"valid"`
+	Errors   []config.ValidationError `json:"errors"`
+	Warnings []string                 `json:"warnings"`
+	Files    []string                 `json:"files"`
+}
+
 func (c *cmd) init() {
 	c.flags = flag.NewFlagSet("", flag.ContinueOnError)
 	c.flags.StringVar(&c.configFormat, "config-format", "",
 		"Config files are in this format irrespective of their extension. Must be 'hcl' or 'json'")
+	c.flags.StringVar(&c.format, "format", "text",
+		"Output format. Must be 'text' or 'json'. The json format contains errors, warnings and the list of files parsed.")
+	c.flags.StringVar(&c.printConfig, "print-config", "",
+		"After successful validation, print the resolved runtime configuration in this format. Must be 'hcl' or 'json'.")
 	c.flags.BoolVar(&c.quiet, "quiet", false,
 		"When given, a successful run will produce no output.")
 	c.help = flags.Usage(help, c.flags)
@@ -51,21 +68,79 @@ func (c *cmd) Run(args []string) int {
 		return 1
 	}
 
+	if c.format != "text" && c.format != "json" {
+		c.UI.Error("-format must be either 'text' or 'json'")
+		return 1
+	}
+
+	if c.printConfig != "" && c.printConfig != "hcl" && c.printConfig != "json" {
+		c.UI.Error("-print-config must be either 'hcl' or 'json'")
+		return 1
+	}
+	if c.printConfig != "" && c.format == "json" {
+		c.UI.Error("-print-config cannot be combined with -format=json")
+		return 1
+	}
+
+	res := validateResult{Errors: []config.ValidationError{}, Warnings: []string{}, Files: []string{}}
+
+	var rt *config.RuntimeConfig
 	b, err := config.NewBuilder(config.BuilderOpts{ConfigFiles: configFiles, ConfigFormat: c.configFormat})
+	if err == nil {
+		for _, src := range b.Sources {
+			res.Files = append(res.Files, src.Source())
+		}
+		if builtRT, buildErr := b.BuildAndValidate(); buildErr != nil {
+			err = buildErr
+		} else {
+			rt = &builtRT
+		}
+		res.Warnings = append(res.Warnings, b.WarningStrings()...)
+	}
 	if err != nil {
-		c.UI.Error(fmt.Sprintf("Config validation failed: %v", err.Error()))
-		return 1
+		res.Errors = append(res.Errors, config.ValidationErrors(err)...)
+	}
+	res.Valid = len(res.Errors) == 0
+
+	if c.format == "json" {
+		out, err := json.MarshalIndent(&res, "", "    ")
+		if err != nil {
+			c.UI.Error(fmt.Sprintf("Failed to encode output: %v", err))
+			return 1
+		}
+		c.UI.Output(string(out))
+		if !res.Valid {
+			return 1
+		}
+		return 0
 	}
-	if _, err := b.BuildAndValidate(); err != nil {
-		c.UI.Error(fmt.Sprintf("Config validation failed: %v", err.Error()))
+
+	if !res.Valid {
+		c.UI.Error(fmt.Sprintf("Config validation failed: %v", res.Errors[0].Error()))
 		return 1
 	}
 	if !c.quiet {
-		for _, w := range b.Warnings {
+		for _, w := range res.Warnings {
 			c.UI.Warn(w)
 		}
 		c.UI.Output("Configuration is valid!")
 	}
+	if c.printConfig != "" {
+		var out string
+		switch c.printConfig {
+		case "hcl":
+			out, err = rt.SanitizedHCL()
+		case "json":
+			var b []byte
+			b, err = json.MarshalIndent(rt.Sanitized(), "", "    ")
+			out = string(b)
+		}
+		if err != nil {
+			c.UI.Error(fmt.Sprintf("Failed to render config: %v", err))
+			return 1
+		}
+		c.UI.Output(out)
+	}
 	return 0
 }
 
@@ -92,4 +167,10 @@ Usage: consul validate [options] FILE_OR_DIRECTORY...
   configuration fragments since those won't pass the full agent validation.
 
   Returns 0 if the configuration is valid, or 1 if there are problems.
+
+  With '-format=json' the result is emitted as a JSON document containing
+  the errors, the warnings and the list of files that were parsed, which is
+  easier to consume from automation than the plain text output. Each error
+  is an object carrying the config key, the violated constraint and, where
+  available, the offending value.
 `
@@ -1,12 +1,15 @@
 package validate
 
 import (
+	"encoding/json"
 	"io/ioutil"
 	"path/filepath"
 	"strings"
 	"testing"
 
+	"github.com/hashicorp/consul/agent/config"
 	"github.com/hashicorp/consul/sdk/testutil"
+	"github.com/hashicorp/hcl"
 	"github.com/mitchellh/cli"
 	require "github.com/stretchr/testify/require"
 )
@@ -118,6 +121,106 @@ func TestValidateCommand_FailForInvalidJSONConfigFormat(t *testing.T) {
 	require.NotEqual(t, 0, code)
 }
 
+func TestValidateCommand_JSONOutput(t *testing.T) {
+	t.Parallel()
+	td := testutil.TempDir(t, "consul")
+
+	fp := filepath.Join(td, "config.json")
+	err := ioutil.WriteFile(fp, []byte(`{"bind_addr":"10.0.0.1", "data_dir":"`+td+`", "acl_datacenter":"dc1"}`), 0644)
+	require.Nilf(t, err, "err: %s", err)
+
+	ui := cli.NewMockUi()
+	cmd := New(ui)
+	args := []string{"-format", "json", fp}
+
+	code := cmd.Run(args)
+	require.Equalf(t, 0, code, "return code - expected: 0, bad: %d, %s", code, ui.ErrorWriter.String())
+
+	var res struct {
+		Valid    bool                     `json:"valid"`
+		Errors   []config.ValidationError `json:"errors"`
+		Warnings []string                 `json:"warnings"`
+		Files    []string                 `json:"files"`
+	}
+	require.NoError(t, json.Unmarshal(ui.OutputWriter.Bytes(), &res))
+	require.True(t, res.Valid)
+	require.Empty(t, res.Errors)
+	require.Equal(t, []string{fp}, res.Files)
+	require.NotEmpty(t, res.Warnings, "expected the acl_datacenter deprecation warning")
+}
+
+func TestValidateCommand_PrintConfigHCL(t *testing.T) {
+	t.Parallel()
+	td := testutil.TempDir(t, "consul")
+
+	fp := filepath.Join(td, "config.json")
+	err := ioutil.WriteFile(fp, []byte(`{"bind_addr":"10.0.0.1", "data_dir":"`+td+`", "node_name":"print-config"}`), 0644)
+	require.Nilf(t, err, "err: %s", err)
+
+	ui := cli.NewMockUi()
+	cmd := New(ui)
+	args := []string{"-quiet", "-print-config", "hcl", fp}
+
+	code := cmd.Run(args)
+	require.Equalf(t, 0, code, "return code - expected: 0, bad: %d, %s", code, ui.ErrorWriter.String())
+
+	var m map[string]interface{}
+	require.NoError(t, hcl.Decode(&m, ui.OutputWriter.String()))
+	require.Equal(t, "print-config", m["NodeName"])
+}
+
+func TestValidateCommand_PrintConfigWithJSONFormat(t *testing.T) {
+	t.Parallel()
+
+	ui := cli.NewMockUi()
+	cmd := New(ui)
+	args := []string{"-format", "json", "-print-config", "hcl", "some.json"}
+
+	code := cmd.Run(args)
+	require.Equal(t, 1, code)
+	require.Contains(t, ui.ErrorWriter.String(), "-print-config cannot be combined with -format=json")
+}
+
+func TestValidateCommand_JSONOutputInvalidConfig(t *testing.T) {
+	t.Parallel()
+	td := testutil.TempDir(t, "consul")
+
+	fp := filepath.Join(td, "config.json")
+	err := ioutil.WriteFile(fp, []byte(`{"bind_addr":"10.0.0.1", "data_dir":"`+td+`", "no_such_key":true}`), 0644)
+	require.Nilf(t, err, "err: %s", err)
+
+	ui := cli.NewMockUi()
+	cmd := New(ui)
+	args := []string{"-format", "json", fp}
+
+	code := cmd.Run(args)
+	require.Equal(t, 1, code)
+
+	var res struct {
+		Valid  bool                     `json:"valid"`
+		Errors []config.ValidationError `json:"errors"`
+	}
+	require.NoError(t, json.Unmarshal(ui.OutputWriter.Bytes(), &res))
+	require.False(t, res.Valid)
+	require.Len(t, res.Errors, 1)
+	require.Contains(t, res.Errors[0].Constraint, "invalid config key no_such_key")
+}
+
+func TestValidateCommand_FailOnInvalidFormat(t *testing.T) {
+	t.Parallel()
+	td := testutil.TempDir(t, "consul")
+
+	fp := filepath.Join(td, "config.json")
+	err := ioutil.WriteFile(fp, []byte(`{"bind_addr":"10.0.0.1", "data_dir":"`+td+`"}`), 0644)
+	require.Nilf(t, err, "err: %s", err)
+
+	cmd := New(cli.NewMockUi())
+	args := []string{"-format", "yaml", fp}
+
+	code := cmd.Run(args)
+	require.NotEqual(t, 0, code)
+}
+
 func TestValidateCommand_Quiet(t *testing.T) {
 	t.Parallel()
 	td := testutil.TempDir(t, "consul")
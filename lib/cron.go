@@ -0,0 +1,141 @@
+package lib
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CronExpr is a parsed five-field cron expression (minute, hour, day of
+// month, month, day of week). Each field supports '*', lists, ranges and
+// step values, e.g. "0 3 * * 6" or "*/15 2-4 1,15 * *".
+type CronExpr struct {
+	minute map[int]bool
+	hour   map[int]bool
+	dom    map[int]bool
+	month  map[int]bool
+	dow    map[int]bool
+
+	// domStar and dowStar record whether the day fields were given as '*'.
+	// Per cron convention, when both day fields are restricted a time
+	// matches if either of them does.
+	domStar bool
+	dowStar bool
+}
+
+type cronField struct {
+	name     string
+	min, max int
+}
+
+var cronFields = []cronField{
+	{"minute", 0, 59},
+	{"hour", 0, 23},
+	{"day of month", 1, 31},
+	{"month", 1, 12},
+	{"day of week", 0, 7},
+}
+
+// ParseCron parses a five-field cron expression.
+func ParseCron(s string) (*CronExpr, error) {
+	fields := strings.Fields(s)
+	if len(fields) != len(cronFields) {
+		return nil, fmt.Errorf("expected %d fields, got %d", len(cronFields), len(fields))
+	}
+
+	sets := make([]map[int]bool, len(cronFields))
+	for i, f := range cronFields {
+		set, err := parseCronField(fields[i], f)
+		if err != nil {
+			return nil, err
+		}
+		sets[i] = set
+	}
+
+	// cron allows 7 as an alias for Sunday
+	if sets[4][7] {
+		delete(sets[4], 7)
+		sets[4][0] = true
+	}
+
+	return &CronExpr{
+		minute:  sets[0],
+		hour:    sets[1],
+		dom:     sets[2],
+		month:   sets[3],
+		dow:     sets[4],
+		domStar: fields[2] == "*",
+		dowStar: fields[4] == "*",
+	}, nil
+}
+
+// parseCronField parses a single cron field into the set of values it
+// matches.
+func parseCronField(s string, f cronField) (map[int]bool, error) {
+	set := make(map[int]bool)
+	for _, part := range strings.Split(s, ",") {
+		lo, hi, step := f.min, f.max, 1
+
+		rangePart := part
+		if i := strings.Index(part, "/"); i >= 0 {
+			rangePart = part[:i]
+			n, err := strconv.Atoi(part[i+1:])
+			if err != nil || n < 1 {
+				return nil, fmt.Errorf("%s: invalid step value in %q", f.name, part)
+			}
+			step = n
+		}
+
+		switch {
+		case rangePart == "*":
+			// full range
+		case strings.Contains(rangePart, "-"):
+			bounds := strings.SplitN(rangePart, "-", 2)
+			var err error
+			if lo, err = strconv.Atoi(bounds[0]); err != nil {
+				return nil, fmt.Errorf("%s: invalid range in %q", f.name, part)
+			}
+			if hi, err = strconv.Atoi(bounds[1]); err != nil {
+				return nil, fmt.Errorf("%s: invalid range in %q", f.name, part)
+			}
+			if lo > hi {
+				return nil, fmt.Errorf("%s: invalid range in %q", f.name, part)
+			}
+		default:
+			n, err := strconv.Atoi(rangePart)
+			if err != nil {
+				return nil, fmt.Errorf("%s: invalid value %q", f.name, part)
+			}
+			lo, hi = n, n
+		}
+
+		if lo < f.min || hi > f.max {
+			return nil, fmt.Errorf("%s: value out of range [%d, %d] in %q", f.name, f.min, f.max, part)
+		}
+		for v := lo; v <= hi; v += step {
+			set[v] = true
+		}
+	}
+	return set, nil
+}
+
+// Matches determines whether the time t falls into the cron expression,
+// ignoring seconds.
+func (c *CronExpr) Matches(t time.Time) bool {
+	if !c.minute[t.Minute()] || !c.hour[t.Hour()] || !c.month[int(t.Month())] {
+		return false
+	}
+	domOK := c.dom[t.Day()]
+	dowOK := c.dow[int(t.Weekday())]
+	switch {
+	case c.domStar && c.dowStar:
+		return true
+	case c.domStar:
+		return dowOK
+	case c.dowStar:
+		return domOK
+	default:
+		return domOK || dowOK
+	}
+}
@@ -0,0 +1,73 @@
+package lib
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCron(t *testing.T) {
+	valid := []string{
+		"* * * * *",
+		"0 3 * * 6",
+		"*/15 2-4 1,15 * *",
+		"30 22 * * 0,7",
+		"0 0 1 1 *",
+	}
+	for _, s := range valid {
+		_, err := ParseCron(s)
+		require.NoError(t, err, s)
+	}
+
+	invalid := []string{
+		"",
+		"* * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"*/0 * * * *",
+		"5-1 * * * *",
+		"x * * * *",
+	}
+	for _, s := range invalid {
+		_, err := ParseCron(s)
+		require.Error(t, err, s)
+	}
+}
+
+func TestCronExprMatches(t *testing.T) {
+	at := func(s string) time.Time {
+		ts, err := time.Parse("2006-01-02 15:04", s)
+		require.NoError(t, err)
+		return ts
+	}
+
+	tests := []struct {
+		expr  string
+		time  string
+		match bool
+	}{
+		{"* * * * *", "2021-03-01 00:00", true},
+		{"0 3 * * *", "2021-03-01 03:00", true},
+		{"0 3 * * *", "2021-03-01 03:01", false},
+		{"*/15 * * * *", "2021-03-01 00:45", true},
+		{"*/15 * * * *", "2021-03-01 00:50", false},
+		// 2021-03-06 is a Saturday
+		{"0 3 * * 6", "2021-03-06 03:00", true},
+		{"0 3 * * 6", "2021-03-05 03:00", false},
+		// restricted day of month and day of week match on either
+		{"0 0 15 * 1", "2021-03-15 00:00", true},
+		{"0 0 15 * 1", "2021-03-08 00:00", true},
+		{"0 0 15 * 1", "2021-03-09 00:00", false},
+		{"0 0 * 2 *", "2021-02-03 00:00", true},
+		{"0 0 * 2 *", "2021-03-03 00:00", false},
+	}
+	for _, tt := range tests {
+		expr, err := ParseCron(tt.expr)
+		require.NoError(t, err)
+		require.Equal(t, tt.match, expr.Matches(at(tt.time)), "%s @ %s", tt.expr, tt.time)
+	}
+}
@@ -0,0 +1,158 @@
+// Package proxyproto implements a net.Listener wrapper that understands
+// version 1 of the HAProxy PROXY protocol. It allows agents that sit behind
+// an L4 load balancer to recover the real client address for request logging
+// and source-based access checks.
+//
+// See https://www.haproxy.org/download/1.8/doc/proxy-protocol.txt for the
+// protocol specification.
+package proxyproto
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxHeaderLen is the longest possible version 1 header, including the
+// trailing CRLF, as defined by the specification.
+const maxHeaderLen = 107
+
+// signature is the fixed prefix every version 1 header starts with.
+const signature = "PROXY "
+
+// Listener wraps an accepting net.Listener and parses a leading PROXY
+// protocol header on each connection before handing it to the caller.
+type Listener struct {
+	net.Listener
+
+	// AllowedSources restricts which remote addresses are trusted to send
+	// a PROXY header. Connections from other sources are passed through
+	// untouched so a stray header cannot spoof the client address. When
+	// empty, every source is trusted.
+	AllowedSources []*net.IPNet
+}
+
+// Accept implements net.Listener. The PROXY header is not read here; it is
+// consumed lazily on the connection's first Read or RemoteAddr call so that
+// a slow proxy cannot stall the accept loop.
+func (l *Listener) Accept() (net.Conn, error) {
+	conn, err := l.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+	if !l.trusted(conn.RemoteAddr()) {
+		return conn, nil
+	}
+	return &Conn{conn: conn, br: bufio.NewReaderSize(conn, maxHeaderLen)}, nil
+}
+
+func (l *Listener) trusted(addr net.Addr) bool {
+	if len(l.AllowedSources) == 0 {
+		return true
+	}
+	tcp, ok := addr.(*net.TCPAddr)
+	if !ok {
+		return false
+	}
+	for _, n := range l.AllowedSources {
+		if n.Contains(tcp.IP) {
+			return true
+		}
+	}
+	return false
+}
+
+// Conn is a net.Conn whose RemoteAddr reflects the source address carried in
+// the connection's PROXY header, if one was sent.
+type Conn struct {
+	conn net.Conn
+	br   *bufio.Reader
+
+	once   sync.Once
+	remote net.Addr
+	err    error
+}
+
+// readHeader parses the PROXY header exactly once. Connections that do not
+// begin with the protocol signature are passed through untouched, matching
+// load balancers that only prepend headers on some pools.
+func (c *Conn) readHeader() {
+	c.once.Do(func() {
+		c.remote, c.err = parseHeader(c.br)
+	})
+}
+
+func (c *Conn) Read(b []byte) (int, error) {
+	c.readHeader()
+	if c.err != nil {
+		return 0, c.err
+	}
+	return c.br.Read(b)
+}
+
+func (c *Conn) Write(b []byte) (int, error) { return c.conn.Write(b) }
+func (c *Conn) Close() error                { return c.conn.Close() }
+func (c *Conn) LocalAddr() net.Addr         { return c.conn.LocalAddr() }
+
+func (c *Conn) RemoteAddr() net.Addr {
+	c.readHeader()
+	if c.remote != nil {
+		return c.remote
+	}
+	return c.conn.RemoteAddr()
+}
+
+func (c *Conn) SetDeadline(t time.Time) error      { return c.conn.SetDeadline(t) }
+func (c *Conn) SetReadDeadline(t time.Time) error  { return c.conn.SetReadDeadline(t) }
+func (c *Conn) SetWriteDeadline(t time.Time) error { return c.conn.SetWriteDeadline(t) }
+
+// parseHeader consumes a version 1 header from br and returns the source
+// address it carries. It returns a nil address when the connection does not
+// start with the protocol signature or the header is "PROXY UNKNOWN", in
+// which case the transport address remains authoritative.
+func parseHeader(br *bufio.Reader) (net.Addr, error) {
+	prefix, err := br.Peek(len(signature))
+	if err != nil || string(prefix) != signature {
+		// Not a PROXY header; leave the buffered bytes for the caller.
+		return nil, nil
+	}
+
+	line, err := br.ReadSlice('\n')
+	if err == bufio.ErrBufferFull {
+		return nil, fmt.Errorf("PROXY protocol header exceeds %d bytes", maxHeaderLen)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !strings.HasSuffix(string(line), "\r\n") {
+		return nil, fmt.Errorf("PROXY protocol header must end in CRLF")
+	}
+
+	parts := strings.Split(strings.TrimSuffix(string(line), "\r\n"), " ")
+	switch {
+	case len(parts) >= 2 && parts[1] == "UNKNOWN":
+		return nil, nil
+	case len(parts) != 6:
+		return nil, fmt.Errorf("invalid PROXY protocol header %q", string(line))
+	}
+
+	switch parts[1] {
+	case "TCP4", "TCP6":
+	default:
+		return nil, fmt.Errorf("unsupported PROXY protocol family %q", parts[1])
+	}
+
+	ip := net.ParseIP(parts[2])
+	if ip == nil {
+		return nil, fmt.Errorf("invalid PROXY protocol source address %q", parts[2])
+	}
+	port, err := strconv.Atoi(parts[4])
+	if err != nil || port < 0 || port > 65535 {
+		return nil, fmt.Errorf("invalid PROXY protocol source port %q", parts[4])
+	}
+	return &net.TCPAddr{IP: ip, Port: port}, nil
+}
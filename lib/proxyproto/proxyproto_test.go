@@ -0,0 +1,110 @@
+package proxyproto
+
+import (
+	"io/ioutil"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func testListener(t *testing.T, allowed []*net.IPNet) (*Listener, string) {
+	t.Helper()
+	inner, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { inner.Close() })
+	return &Listener{Listener: inner, AllowedSources: allowed}, inner.Addr().String()
+}
+
+func exchange(t *testing.T, l *Listener, payload string) (net.Conn, string) {
+	t.Helper()
+	client, err := net.Dial("tcp", l.Addr().String())
+	require.NoError(t, err)
+	t.Cleanup(func() { client.Close() })
+
+	server, err := l.Accept()
+	require.NoError(t, err)
+	t.Cleanup(func() { server.Close() })
+
+	_, err = client.Write([]byte(payload))
+	require.NoError(t, err)
+	require.NoError(t, client.(*net.TCPConn).CloseWrite())
+
+	body, err := ioutil.ReadAll(server)
+	require.NoError(t, err)
+	return server, string(body)
+}
+
+func TestListener_ParsesHeader(t *testing.T) {
+	l, _ := testListener(t, nil)
+	server, body := exchange(t, l, "PROXY TCP4 192.0.2.1 198.51.100.1 56324 443\r\nhello")
+	require.Equal(t, "hello", body)
+	require.Equal(t, "192.0.2.1:56324", server.RemoteAddr().String())
+}
+
+func TestListener_ParsesTCP6Header(t *testing.T) {
+	l, _ := testListener(t, nil)
+	server, body := exchange(t, l, "PROXY TCP6 2001:db8::1 2001:db8::2 56324 443\r\nhello")
+	require.Equal(t, "hello", body)
+	require.Equal(t, "[2001:db8::1]:56324", server.RemoteAddr().String())
+}
+
+func TestListener_NoHeaderPassthrough(t *testing.T) {
+	l, _ := testListener(t, nil)
+	server, body := exchange(t, l, "GET / HTTP/1.0\r\n\r\n")
+	require.Equal(t, "GET / HTTP/1.0\r\n\r\n", body)
+	host, _, err := net.SplitHostPort(server.RemoteAddr().String())
+	require.NoError(t, err)
+	require.Equal(t, "127.0.0.1", host)
+}
+
+func TestListener_UnknownFamilyPassthrough(t *testing.T) {
+	l, _ := testListener(t, nil)
+	server, body := exchange(t, l, "PROXY UNKNOWN\r\nhello")
+	require.Equal(t, "hello", body)
+	host, _, err := net.SplitHostPort(server.RemoteAddr().String())
+	require.NoError(t, err)
+	require.Equal(t, "127.0.0.1", host)
+}
+
+func TestListener_UntrustedSourceIgnoresHeader(t *testing.T) {
+	_, network, err := net.ParseCIDR("192.0.2.0/24")
+	require.NoError(t, err)
+
+	l, _ := testListener(t, []*net.IPNet{network})
+	header := "PROXY TCP4 192.0.2.1 198.51.100.1 56324 443\r\n"
+	server, body := exchange(t, l, header+"hello")
+
+	// The loopback client is not an allowed source, so the header is
+	// treated as ordinary payload and the transport address sticks.
+	require.Equal(t, header+"hello", body)
+	host, _, err := net.SplitHostPort(server.RemoteAddr().String())
+	require.NoError(t, err)
+	require.Equal(t, "127.0.0.1", host)
+}
+
+func TestListener_MalformedHeader(t *testing.T) {
+	for _, payload := range []string{
+		"PROXY TCP4 192.0.2.1 198.51.100.1 56324\r\nhello",
+		"PROXY TCP4 not-an-ip 198.51.100.1 56324 443\r\nhello",
+		"PROXY TCP4 192.0.2.1 198.51.100.1 99999 443\r\nhello",
+		"PROXY SCTP 192.0.2.1 198.51.100.1 56324 443\r\nhello",
+	} {
+		l, _ := testListener(t, nil)
+		client, err := net.Dial("tcp", l.Addr().String())
+		require.NoError(t, err)
+
+		server, err := l.Accept()
+		require.NoError(t, err)
+
+		_, err = client.Write([]byte(payload))
+		require.NoError(t, err)
+
+		buf := make([]byte, 1)
+		_, err = server.Read(buf)
+		require.Error(t, err, "payload %q should be rejected", payload)
+
+		client.Close()
+		server.Close()
+	}
+}
@@ -154,6 +154,13 @@ type TelemetryConfig struct {
 	// hcl: telemetry { dogstatsd_tags = []string }
 	DogstatsdTags []string `json:"dogstatsd_tags,omitempty" mapstructure:"dogstatsd_tags"`
 
+	// EnableDeprecatedConfigMetrics is a flag to emit a gauge labeled with the
+	// key name for every deprecated config key the agent was started with, so
+	// fleet dashboards can track migration progress before the keys are removed.
+	//
+	// hcl: telemetry { enable_deprecated_config_metrics = (true|false) }
+	EnableDeprecatedConfigMetrics bool `json:"enable_deprecated_config_metrics,omitempty" mapstructure:"enable_deprecated_config_metrics"`
+
 	// FilterDefault is the default for whether to allow a metric that's not
 	// covered by the filter.
 	//
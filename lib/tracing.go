@@ -0,0 +1,303 @@
+package lib
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	mrand "math/rand"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// TracingConfig carries the agent configuration for exporting traces of
+// agent HTTP and RPC handling to an OpenTelemetry collector. It is built
+// and validated by the agent/config package and threaded into the layers
+// that emit spans.
+type TracingConfig struct {
+	// OTLPEndpoint is the host:port or URL of the OTLP collector that
+	// traces are exported to. Tracing is disabled when it is empty.
+	//
+	// hcl: tracing { otlp_endpoint = string }
+	OTLPEndpoint string
+
+	// SamplingRatio is the fraction of requests to sample, between 0 and
+	// 1. Defaults to 1 so that an agent with an endpoint configured traces
+	// everything unless told otherwise.
+	//
+	// hcl: tracing { sampling_ratio = float64 }
+	SamplingRatio float64
+
+	// ServiceName overrides the service.name resource attribute reported
+	// with each span. Defaults to "consul".
+	//
+	// hcl: tracing { service_name = string }
+	ServiceName string
+}
+
+// Enabled returns whether traces should be exported.
+func (c TracingConfig) Enabled() bool {
+	return c.OTLPEndpoint != ""
+}
+
+const (
+	// tracingFlushInterval is how often buffered spans are exported.
+	tracingFlushInterval = 5 * time.Second
+
+	// tracingMaxBuffered bounds the number of finished spans held between
+	// exports. Further spans are dropped so that a slow or unreachable
+	// collector cannot grow agent memory without bound.
+	tracingMaxBuffered = 2048
+
+	// tracingExportTimeout bounds a single export request.
+	tracingExportTimeout = 10 * time.Second
+)
+
+// Tracer creates spans for agent request handling and exports them in
+// batches to the configured collector over OTLP/HTTP with the JSON
+// encoding. Export is best effort: spans that cannot be delivered are
+// dropped. A nil *Tracer is valid and records nothing, so call sites do
+// not need to check whether tracing is enabled.
+type Tracer struct {
+	cfg    TracingConfig
+	url    string
+	client *http.Client
+
+	mu      sync.Mutex
+	pending []*Span
+
+	stopOnce sync.Once
+	stopCh   chan struct{}
+	doneCh   chan struct{}
+}
+
+// NewTracer returns a Tracer exporting to the endpoint in cfg, or nil
+// when tracing is disabled. Endpoints without a scheme are taken as
+// plain HTTP and endpoints without a path get the standard OTLP traces
+// path appended.
+func NewTracer(cfg TracingConfig) (*Tracer, error) {
+	if !cfg.Enabled() {
+		return nil, nil
+	}
+	endpoint := cfg.OTLPEndpoint
+	if !strings.Contains(endpoint, "://") {
+		endpoint = "http://" + endpoint
+	}
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("tracing.otlp_endpoint: %s", err)
+	}
+	if u.Path == "" || u.Path == "/" {
+		u.Path = "/v1/traces"
+	}
+	t := &Tracer{
+		cfg:    cfg,
+		url:    u.String(),
+		client: &http.Client{Timeout: tracingExportTimeout},
+		stopCh: make(chan struct{}),
+		doneCh: make(chan struct{}),
+	}
+	go t.run()
+	return t, nil
+}
+
+// StartSpan begins a span for the given operation, or returns nil when
+// the tracer is disabled or the request was not sampled. All Span
+// methods accept a nil receiver so the result can be used untested.
+func (t *Tracer) StartSpan(name string) *Span {
+	if t == nil || !t.sample() {
+		return nil
+	}
+	s := &Span{
+		tracer: t,
+		name:   name,
+		start:  time.Now(),
+	}
+	rand.Read(s.traceID[:])
+	rand.Read(s.spanID[:])
+	return s
+}
+
+func (t *Tracer) sample() bool {
+	switch {
+	case t.cfg.SamplingRatio >= 1:
+		return true
+	case t.cfg.SamplingRatio <= 0:
+		return false
+	}
+	return mrand.Float64() < t.cfg.SamplingRatio
+}
+
+// Shutdown stops the export loop after flushing the buffered spans.
+func (t *Tracer) Shutdown() {
+	if t == nil {
+		return
+	}
+	t.stopOnce.Do(func() { close(t.stopCh) })
+	<-t.doneCh
+}
+
+func (t *Tracer) run() {
+	defer close(t.doneCh)
+	ticker := time.NewTicker(tracingFlushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			t.flush()
+		case <-t.stopCh:
+			t.flush()
+			return
+		}
+	}
+}
+
+func (t *Tracer) enqueue(s *Span) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if len(t.pending) >= tracingMaxBuffered {
+		return
+	}
+	t.pending = append(t.pending, s)
+}
+
+func (t *Tracer) flush() {
+	t.mu.Lock()
+	spans := t.pending
+	t.pending = nil
+	t.mu.Unlock()
+	if len(spans) == 0 {
+		return
+	}
+
+	body, err := json.Marshal(otlpTraceRequest(t.cfg.ServiceName, spans))
+	if err != nil {
+		return
+	}
+	resp, err := t.client.Post(t.url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return
+	}
+	resp.Body.Close()
+}
+
+// Span is a single traced operation. Spans are not safe for concurrent
+// use; the handler that started a span finishes it.
+type Span struct {
+	tracer     *Tracer
+	name       string
+	traceID    [16]byte
+	spanID     [8]byte
+	start      time.Time
+	end        time.Time
+	attributes []otlpKeyValue
+	errMsg     string
+}
+
+// SetAttribute records a string or integer attribute on the span.
+func (s *Span) SetAttribute(key string, value interface{}) {
+	if s == nil {
+		return
+	}
+	kv := otlpKeyValue{Key: key}
+	switch v := value.(type) {
+	case string:
+		kv.Value.StringValue = &v
+	case int:
+		n := strconv.FormatInt(int64(v), 10)
+		kv.Value.IntValue = &n
+	case int64:
+		n := strconv.FormatInt(v, 10)
+		kv.Value.IntValue = &n
+	default:
+		str := fmt.Sprintf("%v", v)
+		kv.Value.StringValue = &str
+	}
+	s.attributes = append(s.attributes, kv)
+}
+
+// Finish ends the span, recording err as its status when non-nil, and
+// queues it for export.
+func (s *Span) Finish(err error) {
+	if s == nil {
+		return
+	}
+	s.end = time.Now()
+	if err != nil {
+		s.errMsg = err.Error()
+	}
+	s.tracer.enqueue(s)
+}
+
+// The types below model the subset of the OTLP JSON encoding the agent
+// emits. Per the proto3 JSON mapping 64 bit integers are encoded as
+// strings and trace and span ids as lowercase hex.
+
+type otlpKeyValue struct {
+	Key   string       `json:"key"`
+	Value otlpAnyValue `json:"value"`
+}
+
+type otlpAnyValue struct {
+	StringValue *string `json:"stringValue,omitempty"`
+	IntValue    *string `json:"intValue,omitempty"`
+}
+
+type otlpStatus struct {
+	Code    int    `json:"code"`
+	Message string `json:"message,omitempty"`
+}
+
+type otlpSpan struct {
+	TraceID           string         `json:"traceId"`
+	SpanID            string         `json:"spanId"`
+	Name              string         `json:"name"`
+	Kind              int            `json:"kind"`
+	StartTimeUnixNano string         `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string         `json:"endTimeUnixNano"`
+	Attributes        []otlpKeyValue `json:"attributes,omitempty"`
+	Status            *otlpStatus    `json:"status,omitempty"`
+}
+
+const (
+	otlpSpanKindServer = 2
+	otlpStatusError    = 2
+)
+
+func otlpTraceRequest(serviceName string, spans []*Span) map[string]interface{} {
+	out := make([]otlpSpan, 0, len(spans))
+	for _, s := range spans {
+		o := otlpSpan{
+			TraceID:           hex.EncodeToString(s.traceID[:]),
+			SpanID:            hex.EncodeToString(s.spanID[:]),
+			Name:              s.name,
+			Kind:              otlpSpanKindServer,
+			StartTimeUnixNano: strconv.FormatInt(s.start.UnixNano(), 10),
+			EndTimeUnixNano:   strconv.FormatInt(s.end.UnixNano(), 10),
+			Attributes:        s.attributes,
+		}
+		if s.errMsg != "" {
+			o.Status = &otlpStatus{Code: otlpStatusError, Message: s.errMsg}
+		}
+		out = append(out, o)
+	}
+	return map[string]interface{}{
+		"resourceSpans": []map[string]interface{}{{
+			"resource": map[string]interface{}{
+				"attributes": []otlpKeyValue{{
+					Key:   "service.name",
+					Value: otlpAnyValue{StringValue: &serviceName},
+				}},
+			},
+			"scopeSpans": []map[string]interface{}{{
+				"scope": map[string]interface{}{"name": "github.com/hashicorp/consul"},
+				"spans": out,
+			}},
+		}},
+	}
+}
@@ -0,0 +1,112 @@
+package lib
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTracer_Disabled(t *testing.T) {
+	tracer, err := NewTracer(TracingConfig{})
+	require.NoError(t, err)
+	require.Nil(t, tracer)
+
+	// A nil tracer and the nil spans it hands out must be safe to use.
+	span := tracer.StartSpan("HTTP GET /v1/agent/self")
+	require.Nil(t, span)
+	span.SetAttribute("http.method", "GET")
+	span.Finish(nil)
+	tracer.Shutdown()
+}
+
+func TestTracer_InvalidEndpoint(t *testing.T) {
+	_, err := NewTracer(TracingConfig{OTLPEndpoint: "http://[::1"})
+	require.Error(t, err)
+}
+
+func TestTracer_SamplingRatioZero(t *testing.T) {
+	tracer, err := NewTracer(TracingConfig{
+		OTLPEndpoint:  "127.0.0.1:4318",
+		SamplingRatio: 0,
+	})
+	require.NoError(t, err)
+	defer tracer.Shutdown()
+
+	require.Nil(t, tracer.StartSpan("HTTP GET /v1/agent/self"))
+}
+
+func TestTracer_Export(t *testing.T) {
+	type received struct {
+		path string
+		body map[string]interface{}
+	}
+	ch := make(chan received, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, err := ioutil.ReadAll(req.Body)
+		require.NoError(t, err)
+		var out map[string]interface{}
+		require.NoError(t, json.Unmarshal(body, &out))
+		ch <- received{path: req.URL.Path, body: out}
+	}))
+	defer srv.Close()
+
+	tracer, err := NewTracer(TracingConfig{
+		OTLPEndpoint:  srv.URL,
+		SamplingRatio: 1,
+		ServiceName:   "consul",
+	})
+	require.NoError(t, err)
+
+	span := tracer.StartSpan("RPC Catalog.ListNodes")
+	require.NotNil(t, span)
+	span.SetAttribute("rpc.method", "Catalog.ListNodes")
+	span.SetAttribute("retries", 2)
+	span.Finish(errors.New("no leader"))
+
+	// Shutdown flushes the pending span.
+	tracer.Shutdown()
+
+	got := <-ch
+	require.Equal(t, "/v1/traces", got.path)
+
+	resourceSpans := got.body["resourceSpans"].([]interface{})
+	require.Len(t, resourceSpans, 1)
+	resource := resourceSpans[0].(map[string]interface{})
+
+	attrs := resource["resource"].(map[string]interface{})["attributes"].([]interface{})
+	require.Equal(t, map[string]interface{}{
+		"key":   "service.name",
+		"value": map[string]interface{}{"stringValue": "consul"},
+	}, attrs[0])
+
+	scopeSpans := resource["scopeSpans"].([]interface{})
+	require.Len(t, scopeSpans, 1)
+	spans := scopeSpans[0].(map[string]interface{})["spans"].([]interface{})
+	require.Len(t, spans, 1)
+
+	otlp := spans[0].(map[string]interface{})
+	require.Equal(t, "RPC Catalog.ListNodes", otlp["name"])
+	require.Regexp(t, regexp.MustCompile("^[0-9a-f]{32}$"), otlp["traceId"])
+	require.Regexp(t, regexp.MustCompile("^[0-9a-f]{16}$"), otlp["spanId"])
+	require.Equal(t, float64(otlpSpanKindServer), otlp["kind"])
+	require.Equal(t, []interface{}{
+		map[string]interface{}{
+			"key":   "rpc.method",
+			"value": map[string]interface{}{"stringValue": "Catalog.ListNodes"},
+		},
+		map[string]interface{}{
+			"key":   "retries",
+			"value": map[string]interface{}{"intValue": "2"},
+		},
+	}, otlp["attributes"])
+	require.Equal(t, map[string]interface{}{
+		"code":    float64(otlpStatusError),
+		"message": "no leader",
+	}, otlp["status"])
+}
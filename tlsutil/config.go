@@ -65,6 +65,13 @@ type Config struct {
 	// authority. This can be used to force client auth.
 	VerifyIncomingHTTPS bool
 
+	// VerifyIncomingHTTPSExemptCIDRs lists networks whose HTTPS clients are
+	// not required to present a client certificate even when
+	// VerifyIncomingHTTPS is in effect. It exists so that local tooling such
+	// as a localhost-only metrics scraper keeps working while mTLS is being
+	// rolled out.
+	VerifyIncomingHTTPSExemptCIDRs []*net.IPNet
+
 	// VerifyOutgoing is used to verify the authenticity of outgoing
 	// connections.  This means that TLS requests are used, and TCP
 	// requests are not made. TLS connections must match a provided
@@ -85,6 +92,11 @@ type Config struct {
 	// VerifyIncoming or VerifyOutgoing to verify the TLS connection.
 	CAFile string
 
+	// CAFileContents contains PEM encoded certificate authorities directly.
+	// It is used instead of CAFile so that configs can be fully
+	// self-contained.
+	CAFileContents string
+
 	// CAPath is a path to a directory containing certificate authority
 	// files. This is used with VerifyIncoming or VerifyOutgoing to verify
 	// the TLS connection.
@@ -94,10 +106,19 @@ type Config struct {
 	// serving TLS connections.  Must be provided to serve TLS connections.
 	CertFile string
 
+	// CertFileContents contains the PEM encoded TLS certificate directly.
+	// It is used instead of CertFile so that configs can be fully
+	// self-contained.
+	CertFileContents string
+
 	// KeyFile is used to provide a TLS key that is used for serving TLS
 	// connections.  Must be provided to serve TLS connections.
 	KeyFile string
 
+	// KeyFileContents contains the PEM encoded TLS key directly. It is used
+	// instead of KeyFile so that configs can be fully self-contained.
+	KeyFileContents string
+
 	// Node name is the name we use to advertise. Defaults to hostname.
 	NodeName string
 
@@ -128,6 +149,67 @@ type Config struct {
 	// AutoTLS opts the agent into provisioning agent
 	// TLS certificates.
 	AutoTLS bool
+
+	// HTTPS contains overrides that apply only to the HTTPS API listeners.
+	HTTPS ProtocolConfig
+
+	// InternalRPC contains overrides that apply only to the server RPC
+	// listener and outgoing server RPC connections.
+	InternalRPC ProtocolConfig
+
+	// GRPC contains overrides that apply only to the gRPC (xDS) listeners.
+	GRPC ProtocolConfig
+
+	// WAN contains overrides that apply only to cross-datacenter
+	// connections: traditional federation RPC and the ALPN-RPC variation
+	// used through mesh gateways. This allows the WAN trust domain to be
+	// managed by a different PKI than the LAN.
+	WAN ProtocolConfig
+
+	// Checks contains overrides applied on top of the agent-wide settings
+	// when agent checks call HTTPS endpoints and EnableAgentTLSForChecks
+	// is set.
+	Checks ProtocolConfig
+
+	// Datacenter is the datacenter this agent belongs to. It is used to
+	// decide whether an outgoing RPC connection crosses datacenters and
+	// should therefore use the WAN overrides.
+	Datacenter string
+}
+
+// ProtocolConfig contains TLS settings for a single listener that override
+// the agent-wide values above. Zero values fall back to the agent-wide
+// configuration, so listeners only diverge where the operator asks them to.
+type ProtocolConfig struct {
+	// CAFile is a path to a certificate authority file used to verify
+	// connections on this listener instead of the agent-wide CAs.
+	CAFile string
+
+	// CAPath is a path to a directory of certificate authority files used
+	// to verify connections on this listener instead of the agent-wide CAs.
+	CAPath string
+
+	// CertFile is a path to a certificate presented on this listener
+	// instead of the agent-wide certificate.
+	CertFile string
+
+	// KeyFile is the key for CertFile.
+	KeyFile string
+
+	// VerifyIncoming overrides the agent-wide incoming verification policy
+	// for this listener when set. This allows e.g. mTLS on the server RPC
+	// listener while the HTTPS API stays open to local tooling.
+	VerifyIncoming *bool
+
+	// VerifyOutgoing overrides the agent-wide outgoing verification policy
+	// for connections made by this subsystem when set. Only the WAN
+	// override makes outgoing connections of its own.
+	VerifyOutgoing *bool
+
+	// VerifyServerHostname overrides the agent-wide server hostname
+	// verification for connections made by this subsystem when set. Only
+	// the WAN override makes outgoing connections of its own.
+	VerifyServerHostname *bool
 }
 
 func tlsVersions() []string {
@@ -141,8 +223,12 @@ func tlsVersions() []string {
 	return versions
 }
 
-// KeyPair is used to open and parse a certificate and key file
+// KeyPair is used to open and parse a certificate and key file or, when the
+// contents are carried inline, to parse them directly.
 func (c *Config) KeyPair() (*tls.Certificate, error) {
+	if c.CertFileContents != "" || c.KeyFileContents != "" {
+		return parseKeyPair(c.CertFileContents, c.KeyFileContents)
+	}
 	return loadKeyPair(c.CertFile, c.KeyFile)
 }
 
@@ -173,6 +259,42 @@ type manual struct {
 	cert   *tls.Certificate
 }
 
+// protocol holds the certificate and CA pool loaded for a single listener
+// from a ProtocolConfig override. A nil *protocol means the listener uses
+// the agent-wide certificate and pool.
+type protocol struct {
+	cert *tls.Certificate
+	pool *x509.CertPool
+}
+
+// loadProtocol loads the certificate and CAs for a listener override. It
+// returns nil when the override carries no file paths so callers can fall
+// back to the agent-wide values.
+func loadProtocol(pcfg ProtocolConfig) (*protocol, error) {
+	if pcfg.CAFile == "" && pcfg.CAPath == "" && pcfg.CertFile == "" {
+		return nil, nil
+	}
+
+	p := &protocol{}
+	cert, err := loadKeyPair(pcfg.CertFile, pcfg.KeyFile)
+	if err != nil {
+		return nil, err
+	}
+	p.cert = cert
+
+	pems, err := LoadCAs(pcfg.CAFile, pcfg.CAPath)
+	if err != nil {
+		return nil, err
+	}
+	if len(pems) > 0 {
+		p.pool, err = pool(pems)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return p, nil
+}
+
 // Configurator holds a Config and is responsible for generating all the
 // *tls.Config necessary for Consul. Except the one in the api package.
 type Configurator struct {
@@ -182,6 +304,15 @@ type Configurator struct {
 	manual               *manual
 	peerDatacenterUseTLS map[string]bool
 
+	// per-listener overrides loaded from the tls { https / internal_rpc /
+	// grpc / wan } stanzas plus the checks profile. nil entries fall back
+	// to manual/caPool.
+	https       *protocol
+	internalRPC *protocol
+	grpc        *protocol
+	wan         *protocol
+	checks      *protocol
+
 	caPool  *x509.CertPool
 	logger  hclog.Logger
 	version int
@@ -232,7 +363,7 @@ func (c *Configurator) Update(config Config) error {
 	defer c.log("Update")
 	defer c.Unlock()
 
-	cert, err := loadKeyPair(config.CertFile, config.KeyFile)
+	cert, err := config.KeyPair()
 	if err != nil {
 		return err
 	}
@@ -240,6 +371,9 @@ func (c *Configurator) Update(config Config) error {
 	if err != nil {
 		return err
 	}
+	if config.CAFileContents != "" {
+		pems = append(pems, config.CAFileContents)
+	}
 	pool, err := pool(append(pems, c.autoTLS.caPems()...))
 	if err != nil {
 		return err
@@ -247,10 +381,74 @@ func (c *Configurator) Update(config Config) error {
 	if err = c.check(config, pool, cert); err != nil {
 		return err
 	}
+	https, err := loadProtocol(config.HTTPS)
+	if err != nil {
+		return err
+	}
+	internalRPC, err := loadProtocol(config.InternalRPC)
+	if err != nil {
+		return err
+	}
+	grpc, err := loadProtocol(config.GRPC)
+	if err != nil {
+		return err
+	}
+	wan, err := loadProtocol(config.WAN)
+	if err != nil {
+		return err
+	}
+	checks, err := loadProtocol(config.Checks)
+	if err != nil {
+		return err
+	}
+	if v := config.WAN.VerifyOutgoing; v != nil && *v {
+		if pool == nil && (wan == nil || wan.pool == nil) && !config.AutoTLS {
+			return fmt.Errorf("VerifyOutgoing set for wan but no CA certificates were provided")
+		}
+	}
+	protocols := []struct {
+		name string
+		pcfg ProtocolConfig
+		p    *protocol
+	}{
+		{"https", config.HTTPS, https},
+		{"internal_rpc", config.InternalRPC, internalRPC},
+		{"grpc", config.GRPC, grpc},
+		{"wan", config.WAN, wan},
+		{"checks", config.Checks, checks},
+	}
+	for _, pr := range protocols {
+		if (pr.pcfg.CertFile == "") != (pr.pcfg.KeyFile == "") {
+			return fmt.Errorf("%s: CertFile and KeyFile must both be set", pr.name)
+		}
+		if pr.pcfg.VerifyIncoming == nil || !*pr.pcfg.VerifyIncoming {
+			continue
+		}
+		effectivePool, effectiveCert := pool, cert
+		if pr.p != nil {
+			if pr.p.pool != nil {
+				effectivePool = pr.p.pool
+			}
+			if pr.p.cert != nil {
+				effectiveCert = pr.p.cert
+			}
+		}
+		if effectivePool == nil && !config.AutoTLS {
+			return fmt.Errorf("VerifyIncoming set for %s but no CA certificates were provided", pr.name)
+		}
+		if effectiveCert == nil && !config.AutoTLS {
+			return fmt.Errorf("VerifyIncoming set for %s but no Cert and Key pair was provided", pr.name)
+		}
+	}
 	c.base = &config
 	c.manual.cert = cert
 	c.manual.caPems = pems
 	c.caPool = pool
+	c.https = https
+	c.internalRPC = internalRPC
+	c.grpc = grpc
+	c.wan = wan
+	c.checks = checks
 	c.version++
 	return nil
 }
@@ -418,6 +616,19 @@ func loadKeyPair(certFile, keyFile string) (*tls.Certificate, error) {
 	return &cert, nil
 }
 
+// parseKeyPair is the in-memory counterpart of loadKeyPair for configs that
+// carry the PEM contents inline instead of paths.
+func parseKeyPair(certPEM, keyPEM string) (*tls.Certificate, error) {
+	if certPEM == "" || keyPEM == "" {
+		return nil, nil
+	}
+	cert, err := tls.X509KeyPair([]byte(certPEM), []byte(keyPEM))
+	if err != nil {
+		return nil, fmt.Errorf("Failed to parse cert/key pair: %v", err)
+	}
+	return &cert, nil
+}
+
 func LoadCAs(caFile, caPath string) ([]string, error) {
 	if caFile == "" && caPath == "" {
 		return nil, nil
@@ -610,6 +821,9 @@ func (c *Configurator) domain() string {
 func (c *Configurator) verifyIncomingRPC() bool {
 	c.RLock()
 	defer c.RUnlock()
+	if v := c.base.InternalRPC.VerifyIncoming; v != nil {
+		return *v
+	}
 	return c.base.verifyIncomingRPC()
 }
 
@@ -617,9 +831,25 @@ func (c *Configurator) verifyIncomingRPC() bool {
 func (c *Configurator) verifyIncomingHTTPS() bool {
 	c.RLock()
 	defer c.RUnlock()
+	if v := c.base.HTTPS.VerifyIncoming; v != nil {
+		return *v
+	}
 	return c.base.verifyIncomingHTTPS()
 }
 
+// This function acquires a read lock because it reads from the config.
+func (c *Configurator) verifyIncomingGRPC() bool {
+	c.RLock()
+	defer c.RUnlock()
+	if v := c.base.GRPC.VerifyIncoming; v != nil {
+		return *v
+	}
+	// There is no flat verify_incoming_grpc configuration option and
+	// verify_incoming never applied to the gRPC listener, so without an
+	// explicit override the listener does not require client certs.
+	return false
+}
+
 // This function acquires a read lock because it reads from the config.
 func (c *Configurator) enableAgentTLSForChecks() bool {
 	c.RLock()
@@ -644,16 +874,105 @@ func (c *Configurator) VerifyServerHostname() bool {
 	return c.base.VerifyServerHostname || c.autoTLS.verifyServerHostname
 }
 
+// applyProtocol overrides the certificate and CA pool on config with the
+// listener-specific values when an override is loaded for the listener.
+// This function acquires a read lock because it reads from the config.
+func (c *Configurator) applyProtocol(config *tls.Config, p *protocol) *tls.Config {
+	if p == nil {
+		return config
+	}
+	c.RLock()
+	defer c.RUnlock()
+	if cert := p.cert; cert != nil {
+		config.GetCertificate = func(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+			return cert, nil
+		}
+		config.GetClientCertificate = func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			return cert, nil
+		}
+	}
+	if p.pool != nil {
+		config.ClientCAs = p.pool
+		config.RootCAs = p.pool
+	}
+	return config
+}
+
+// httpsProtocol returns the loaded HTTPS listener override, if any.
+// This function acquires a read lock because it reads from the config.
+func (c *Configurator) httpsProtocol() *protocol {
+	c.RLock()
+	defer c.RUnlock()
+	return c.https
+}
+
+// internalRPCProtocol returns the loaded RPC listener override, if any.
+// This function acquires a read lock because it reads from the config.
+func (c *Configurator) internalRPCProtocol() *protocol {
+	c.RLock()
+	defer c.RUnlock()
+	return c.internalRPC
+}
+
+// grpcProtocol returns the loaded gRPC listener override, if any.
+// This function acquires a read lock because it reads from the config.
+func (c *Configurator) grpcProtocol() *protocol {
+	c.RLock()
+	defer c.RUnlock()
+	return c.grpc
+}
+
+// wanProtocol returns the loaded WAN override, if any.
+// This function acquires a read lock because it reads from the config.
+func (c *Configurator) wanProtocol() *protocol {
+	c.RLock()
+	defer c.RUnlock()
+	return c.wan
+}
+
+// checksProtocol returns the loaded checks profile override, if any.
+// This function acquires a read lock because it reads from the config.
+func (c *Configurator) checksProtocol() *protocol {
+	c.RLock()
+	defer c.RUnlock()
+	return c.checks
+}
+
+// isWANDatacenter returns whether dc names a datacenter other than our own,
+// meaning a connection to it crosses the WAN and should use the WAN
+// overrides. This function acquires a read lock because it reads from the
+// config.
+func (c *Configurator) isWANDatacenter(dc string) bool {
+	c.RLock()
+	defer c.RUnlock()
+	return dc != "" && c.base.Datacenter != "" && dc != c.base.Datacenter
+}
+
+// wanVerify applies the WAN overrides for outgoing verification on top of
+// the agent-wide values. This function acquires a read lock because it reads
+// from the config.
+func (c *Configurator) wanVerify(verifyOutgoing, verifyServerHostname bool) (bool, bool) {
+	c.RLock()
+	defer c.RUnlock()
+	if v := c.base.WAN.VerifyOutgoing; v != nil {
+		verifyOutgoing = *v
+	}
+	if v := c.base.WAN.VerifyServerHostname; v != nil {
+		verifyServerHostname = *v
+	}
+	return verifyOutgoing, verifyServerHostname
+}
+
 // IncomingGRPCConfig generates a *tls.Config for incoming GRPC connections.
 func (c *Configurator) IncomingGRPCConfig() *tls.Config {
 	c.log("IncomingGRPCConfig")
 
-	// false has the effect that this config doesn't require a client cert
-	// verification. This is because there is no verify_incoming_grpc
-	// configuration option. And using verify_incoming would be backwards
-	// incompatible, because even if it was set before, it didn't have an
-	// effect on the grpc server.
-	config := c.commonTLSConfig(false)
+	// Without a tls { grpc { verify_incoming } } override this config
+	// doesn't require client cert verification. This is because there is
+	// no flat verify_incoming_grpc configuration option. And using
+	// verify_incoming would be backwards incompatible, because even if it
+	// was set before, it didn't have an effect on the grpc server.
+	config := c.applyProtocol(c.commonTLSConfig(c.verifyIncomingGRPC()), c.grpcProtocol())
 	config.GetConfigForClient = func(*tls.ClientHelloInfo) (*tls.Config, error) {
 		return c.IncomingGRPCConfig(), nil
 	}
@@ -663,7 +982,7 @@ func (c *Configurator) IncomingGRPCConfig() *tls.Config {
 // IncomingRPCConfig generates a *tls.Config for incoming RPC connections.
 func (c *Configurator) IncomingRPCConfig() *tls.Config {
 	c.log("IncomingRPCConfig")
-	config := c.commonTLSConfig(c.verifyIncomingRPC())
+	config := c.applyProtocol(c.commonTLSConfig(c.verifyIncomingRPC()), c.internalRPCProtocol())
 	config.GetConfigForClient = func(*tls.ClientHelloInfo) (*tls.Config, error) {
 		return c.IncomingRPCConfig(), nil
 	}
@@ -676,7 +995,10 @@ func (c *Configurator) IncomingALPNRPCConfig(alpnProtos []string) *tls.Config {
 	c.log("IncomingALPNRPCConfig")
 	// Since the ALPN-RPC variation is indirectly exposed to the internet via
 	// mesh gateways we force mTLS and full server name verification.
-	config := c.commonTLSConfig(true)
+	config := c.applyProtocol(c.commonTLSConfig(true), c.internalRPCProtocol())
+	// ALPN-RPC connections come in from other datacenters, so the WAN
+	// material wins over the internal RPC override when both are set.
+	config = c.applyProtocol(config, c.wanProtocol())
 	config.InsecureSkipVerify = false
 
 	config.GetConfigForClient = func(*tls.ClientHelloInfo) (*tls.Config, error) {
@@ -703,14 +1025,52 @@ func (c *Configurator) IncomingInsecureRPCConfig() *tls.Config {
 // IncomingHTTPSConfig generates a *tls.Config for incoming HTTPS connections.
 func (c *Configurator) IncomingHTTPSConfig() *tls.Config {
 	c.log("IncomingHTTPSConfig")
-	config := c.commonTLSConfig(c.verifyIncomingHTTPS())
+	config := c.applyProtocol(c.commonTLSConfig(c.verifyIncomingHTTPS()), c.httpsProtocol())
 	config.NextProtos = []string{"h2", "http/1.1"}
-	config.GetConfigForClient = func(*tls.ClientHelloInfo) (*tls.Config, error) {
+	config.GetConfigForClient = func(hello *tls.ClientHelloInfo) (*tls.Config, error) {
+		if hello.Conn != nil && c.httpsVerifyExempt(hello.Conn.RemoteAddr()) {
+			config := c.applyProtocol(c.commonTLSConfig(false), c.httpsProtocol())
+			config.NextProtos = []string{"h2", "http/1.1"}
+			return config, nil
+		}
 		return c.IncomingHTTPSConfig(), nil
 	}
 	return config
 }
 
+// httpsVerifyExempt returns true if the given remote address falls inside one
+// of the configured VerifyIncomingHTTPSExemptCIDRs networks. This function
+// acquires a read lock because it reads from the config.
+func (c *Configurator) httpsVerifyExempt(remote net.Addr) bool {
+	c.RLock()
+	defer c.RUnlock()
+	if len(c.base.VerifyIncomingHTTPSExemptCIDRs) == 0 {
+		return false
+	}
+
+	var ip net.IP
+	switch a := remote.(type) {
+	case *net.TCPAddr:
+		ip = a.IP
+	default:
+		host, _, err := net.SplitHostPort(remote.String())
+		if err != nil {
+			return false
+		}
+		ip = net.ParseIP(host)
+	}
+	if ip == nil {
+		return false
+	}
+
+	for _, cidr := range c.base.VerifyIncomingHTTPSExemptCIDRs {
+		if cidr.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
 // IncomingTLSConfig generates a *tls.Config for outgoing TLS connections for
 // checks. This function is separated because there is an extra flag to
 // consider for checks. EnableAgentTLSForChecks and InsecureSkipVerify has to
@@ -724,6 +1084,7 @@ func (c *Configurator) OutgoingTLSConfigForCheck(skipVerify bool) *tls.Config {
 	}
 
 	config := c.commonTLSConfig(false)
+	config = c.applyProtocol(config, c.checksProtocol())
 	config.InsecureSkipVerify = skipVerify
 	config.ServerName = c.serverNameOrNodeName()
 
@@ -738,7 +1099,7 @@ func (c *Configurator) OutgoingRPCConfig() *tls.Config {
 	if c.outgoingRPCTLSDisabled() {
 		return nil
 	}
-	return c.commonTLSConfig(false)
+	return c.applyProtocol(c.commonTLSConfig(false), c.internalRPCProtocol())
 }
 
 // OutgoingALPNRPCConfig generates a *tls.Config for outgoing RPC connections
@@ -754,7 +1115,7 @@ func (c *Configurator) OutgoingALPNRPCConfig() *tls.Config {
 
 	// Since the ALPN-RPC variation is indirectly exposed to the internet via
 	// mesh gateways we force mTLS and full server name verification.
-	config := c.commonTLSConfig(true)
+	config := c.applyProtocol(c.commonTLSConfig(true), c.internalRPCProtocol())
 	config.InsecureSkipVerify = false
 	return config
 }
@@ -790,6 +1151,22 @@ func (c *Configurator) OutgoingALPNRPCWrapper() ALPNWrapper {
 	}
 }
 
+// AutoEncryptCert returns the parsed auto_encrypt/auto_config certificate or
+// nil in case the agent doesn't currently hold one.
+func (c *Configurator) AutoEncryptCert() *x509.Certificate {
+	c.RLock()
+	defer c.RUnlock()
+	tlsCert := c.autoTLS.cert
+	if tlsCert == nil || tlsCert.Certificate == nil {
+		return nil
+	}
+	cert, err := x509.ParseCertificate(tlsCert.Certificate[0])
+	if err != nil {
+		return nil
+	}
+	return cert
+}
+
 // AutoEncryptCertNotAfter returns NotAfter from the auto_encrypt cert. In case
 // there is no cert, it will return a time in the past.
 func (c *Configurator) AutoEncryptCertNotAfter() time.Time {
@@ -836,6 +1213,14 @@ func (c *Configurator) wrapTLSClient(dc string, conn net.Conn) (net.Conn, error)
 	verifyOutgoing := c.verifyOutgoing()
 	domain := c.domain()
 
+	// Connections to other datacenters use the WAN material and verify
+	// settings when a tls.wan override is configured.
+	if c.isWANDatacenter(dc) {
+		config = c.applyProtocol(config, c.wanProtocol())
+		verifyOutgoing, verifyServerHostname = c.wanVerify(verifyOutgoing, verifyServerHostname)
+		config.InsecureSkipVerify = !verifyServerHostname
+	}
+
 	if verifyServerHostname {
 		// Strip the trailing '.' from the domain if any
 		domain = strings.TrimSuffix(domain, ".")
@@ -901,6 +1286,9 @@ func (c *Configurator) wrapALPNTLSClient(dc, nodeName, alpnProto string, conn ne
 	if config == nil {
 		return nil, fmt.Errorf("cannot dial via a mesh gateway when outgoing TLS is disabled")
 	}
+	if c.isWANDatacenter(dc) {
+		config = c.applyProtocol(config, c.wanProtocol())
+	}
 
 	// Since the ALPN-RPC variation is indirectly exposed to the internet via
 	// mesh gateways we force mTLS and full hostname validation (forcing
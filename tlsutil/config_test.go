@@ -904,6 +904,123 @@ func TestConfigurator_IncomingHTTPSConfig(t *testing.T) {
 	require.Equal(t, []string{"h2", "http/1.1"}, c.IncomingHTTPSConfig().NextProtos)
 }
 
+func TestConfigurator_ProtocolConfigOverrides(t *testing.T) {
+	verify := true
+	c, err := NewConfigurator(Config{
+		CAFile: "../test/ca/root.cer",
+		HTTPS: ProtocolConfig{
+			CertFile:       "../test/key/ourdomain.cer",
+			KeyFile:        "../test/key/ourdomain.key",
+			VerifyIncoming: &verify,
+		},
+	}, nil)
+	require.NoError(t, err)
+
+	// The HTTPS listener serves its own certificate and requires client
+	// certs, while the RPC listener keeps the agent-wide behavior.
+	httpsConfig := c.IncomingHTTPSConfig()
+	cert, err := httpsConfig.GetCertificate(nil)
+	require.NoError(t, err)
+	require.NotNil(t, cert)
+	require.Equal(t, tls.RequireAndVerifyClientCert, httpsConfig.ClientAuth)
+
+	rpcConfig := c.IncomingRPCConfig()
+	cert, err = rpcConfig.GetCertificate(nil)
+	require.NoError(t, err)
+	require.Nil(t, cert)
+	require.Equal(t, tls.NoClientCert, rpcConfig.ClientAuth)
+}
+
+func TestConfigurator_ProtocolConfigVerifyIncomingOptOut(t *testing.T) {
+	noVerify := false
+	c, err := NewConfigurator(Config{
+		VerifyIncoming: true,
+		CAFile:         "../test/ca/root.cer",
+		CertFile:       "../test/key/ourdomain.cer",
+		KeyFile:        "../test/key/ourdomain.key",
+		HTTPS:          ProtocolConfig{VerifyIncoming: &noVerify},
+	}, nil)
+	require.NoError(t, err)
+
+	require.Equal(t, tls.NoClientCert, c.IncomingHTTPSConfig().ClientAuth)
+	require.Equal(t, tls.RequireAndVerifyClientCert, c.IncomingRPCConfig().ClientAuth)
+}
+
+func TestConfigurator_ProtocolConfigErrors(t *testing.T) {
+	verify := true
+
+	_, err := NewConfigurator(Config{
+		HTTPS: ProtocolConfig{CertFile: "../test/key/ourdomain.cer"},
+	}, nil)
+	testutil.RequireErrorContains(t, err, "CertFile and KeyFile must both be set")
+
+	_, err = NewConfigurator(Config{
+		HTTPS: ProtocolConfig{VerifyIncoming: &verify},
+	}, nil)
+	testutil.RequireErrorContains(t, err, "VerifyIncoming set for https but no CA certificates were provided")
+
+	_, err = NewConfigurator(Config{
+		CAFile: "../test/ca/root.cer",
+		GRPC:   ProtocolConfig{VerifyIncoming: &verify},
+	}, nil)
+	testutil.RequireErrorContains(t, err, "VerifyIncoming set for grpc but no Cert and Key pair was provided")
+}
+
+func TestConfigurator_WANProtocolOverrides(t *testing.T) {
+	verify := true
+	noVerify := false
+	c, err := NewConfigurator(Config{
+		Datacenter: "dc1",
+		WAN: ProtocolConfig{
+			CAFile:               "../test/ca/root.cer",
+			CertFile:             "../test/key/ourdomain.cer",
+			KeyFile:              "../test/key/ourdomain.key",
+			VerifyOutgoing:       &verify,
+			VerifyServerHostname: &noVerify,
+		},
+	}, nil)
+	require.NoError(t, err)
+
+	// Only connections that leave the local datacenter use the WAN
+	// material.
+	require.False(t, c.isWANDatacenter("dc1"))
+	require.False(t, c.isWANDatacenter(""))
+	require.True(t, c.isWANDatacenter("dc2"))
+
+	verifyOutgoing, verifyServerHostname := c.wanVerify(false, true)
+	require.True(t, verifyOutgoing)
+	require.False(t, verifyServerHostname)
+
+	wanConfig := c.applyProtocol(c.commonTLSConfig(false), c.wanProtocol())
+	cert, err := wanConfig.GetClientCertificate(nil)
+	require.NoError(t, err)
+	require.NotNil(t, cert.Certificate)
+}
+
+func TestConfigurator_WANVerifyOutgoingRequiresCAs(t *testing.T) {
+	verify := true
+	_, err := NewConfigurator(Config{
+		Datacenter: "dc1",
+		WAN:        ProtocolConfig{VerifyOutgoing: &verify},
+	}, nil)
+	testutil.RequireErrorContains(t, err, "VerifyOutgoing set for wan but no CA certificates were provided")
+}
+
+func TestConfigurator_HTTPSVerifyExempt(t *testing.T) {
+	mustCIDR := func(s string) *net.IPNet {
+		_, net, err := net.ParseCIDR(s)
+		require.NoError(t, err)
+		return net
+	}
+
+	c := Configurator{base: &Config{}, autoTLS: &autoTLS{}}
+	require.False(t, c.httpsVerifyExempt(&net.TCPAddr{IP: net.ParseIP("127.0.0.1"), Port: 1234}))
+
+	c.base.VerifyIncomingHTTPSExemptCIDRs = []*net.IPNet{mustCIDR("127.0.0.0/8")}
+	require.True(t, c.httpsVerifyExempt(&net.TCPAddr{IP: net.ParseIP("127.0.0.1"), Port: 1234}))
+	require.False(t, c.httpsVerifyExempt(&net.TCPAddr{IP: net.ParseIP("10.0.0.1"), Port: 1234}))
+}
+
 func TestConfigurator_OutgoingTLSConfigForChecks(t *testing.T) {
 	c := Configurator{base: &Config{
 		TLSMinVersion:           "tls12",
@@ -921,6 +1038,26 @@ func TestConfigurator_OutgoingTLSConfigForChecks(t *testing.T) {
 	require.Equal(t, c.base.ServerName, tlsConf.ServerName)
 }
 
+func TestConfigurator_OutgoingTLSConfigForChecksProfile(t *testing.T) {
+	c, err := NewConfigurator(Config{
+		EnableAgentTLSForChecks: true,
+		Checks: ProtocolConfig{
+			CAFile:   "../test/ca/root.cer",
+			CertFile: "../test/key/ourdomain.cer",
+			KeyFile:  "../test/key/ourdomain.key",
+		},
+	}, nil)
+	require.NoError(t, err)
+
+	// The checks profile supplies its own client material on top of the
+	// (empty) agent-wide configuration.
+	tlsConf := c.OutgoingTLSConfigForCheck(false)
+	require.NotNil(t, tlsConf.RootCAs)
+	cert, err := tlsConf.GetClientCertificate(nil)
+	require.NoError(t, err)
+	require.NotNil(t, cert.Certificate)
+}
+
 func TestConfigurator_OutgoingRPCConfig(t *testing.T) {
 	c := &Configurator{base: &Config{}, autoTLS: &autoTLS{}}
 	require.Nil(t, c.OutgoingRPCConfig())